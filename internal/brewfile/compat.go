@@ -0,0 +1,237 @@
+package brewfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Requirements are the platform constraints a package declares
+type Requirements struct {
+	Arch     []string // architectures the package runs on (arm64, x86_64); empty means any
+	MinMacOS string   // minimum macOS version, e.g. "13"
+}
+
+// CompatIssue is a package that may not work on the target machine
+type CompatIssue struct {
+	Package Package `json:"-"`
+	Reason  string  `json:"reason"`
+}
+
+// String formats the issue as "cask:virtualbox: Intel-only; ..."
+func (c CompatIssue) String() string {
+	return fmt.Sprintf("%s: %s", c.Package.ID(), c.Reason)
+}
+
+// brewRequirementsInfo is the subset of `brew info --json=v2` output that
+// describes platform requirements
+type brewRequirementsInfo struct {
+	Formulae []struct {
+		Name         string `json:"name"`
+		FullName     string `json:"full_name"`
+		Requirements []struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"requirements"`
+	} `json:"formulae"`
+	Casks []struct {
+		Token     string `json:"token"`
+		FullToken string `json:"full_token"`
+		DependsOn struct {
+			MacOS map[string][]string `json:"macos"`
+			Arch  []struct {
+				Type string `json:"type"`
+			} `json:"arch"`
+		} `json:"depends_on"`
+	} `json:"casks"`
+}
+
+// ParseBrewRequirements extracts architecture and macOS version requirements
+// from `brew info --json=v2` output, keyed by package ID. Packages without
+// requirements are omitted.
+func ParseBrewRequirements(data []byte) (map[string]Requirements, error) {
+	var info brewRequirementsInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse brew info output: %w", err)
+	}
+
+	reqs := make(map[string]Requirements)
+	add := func(pkgType PackageType, r Requirements, names ...string) {
+		if len(r.Arch) == 0 && r.MinMacOS == "" {
+			return
+		}
+		for _, name := range names {
+			if name != "" {
+				reqs[string(pkgType)+":"+name] = r
+			}
+		}
+	}
+
+	for _, f := range info.Formulae {
+		var r Requirements
+		for _, req := range f.Requirements {
+			switch req.Name {
+			case "arch":
+				if arch := NormalizeArch(req.Version); arch != "" {
+					r.Arch = append(r.Arch, arch)
+				}
+			case "macos":
+				r.MinMacOS = req.Version
+			}
+		}
+		add(TypeBrew, r, f.Name, f.FullName)
+	}
+
+	for _, c := range info.Casks {
+		var r Requirements
+		for _, a := range c.DependsOn.Arch {
+			if arch := NormalizeArch(a.Type); arch != "" {
+				r.Arch = append(r.Arch, arch)
+			}
+		}
+		if versions := c.DependsOn.MacOS[">="]; len(versions) > 0 {
+			r.MinMacOS = versions[0]
+		}
+		add(TypeCask, r, c.Token, c.FullToken)
+	}
+
+	return reqs, nil
+}
+
+// NormalizeArch maps architecture names used by Go, uname and Homebrew
+// (amd64, intel, arm, aarch64) to arm64 or x86_64
+func NormalizeArch(arch string) string {
+	switch strings.ToLower(strings.TrimSpace(arch)) {
+	case "arm64", "arm", "aarch64":
+		return "arm64"
+	case "x86_64", "amd64", "intel", "x86-64":
+		return "x86_64"
+	default:
+		return ""
+	}
+}
+
+// archLabel describes an architecture, e.g. "Apple Silicon (arm64)"
+func archLabel(arch string) string {
+	switch arch {
+	case "arm64":
+		return "Apple Silicon (arm64)"
+	case "x86_64":
+		return "Intel (x86_64)"
+	default:
+		return arch
+	}
+}
+
+// CheckCompat reports packages from the source machine that may not work on
+// the target: packages limited to another architecture, packages requiring a
+// newer macOS, and options that refer to the source's Homebrew prefix.
+// reqs is keyed by package ID and may be nil.
+func CheckCompat(packages Packages, source, target SystemFacts, reqs map[string]Requirements) []CompatIssue {
+	var issues []CompatIssue
+	report := func(pkg Package, format string, args ...interface{}) {
+		issues = append(issues, CompatIssue{Package: pkg, Reason: fmt.Sprintf(format, args...)})
+	}
+
+	prefixMoved := source.HomebrewPrefix != "" && target.HomebrewPrefix != "" &&
+		source.HomebrewPrefix != target.HomebrewPrefix
+
+	for _, pkg := range packages {
+		if r, ok := reqs[pkg.ID()]; ok {
+			if target.Arch != "" && len(r.Arch) > 0 && !containsString(r.Arch, target.Arch) {
+				switch {
+				case len(r.Arch) == 1 && r.Arch[0] == "x86_64" && target.Arch == "arm64":
+					report(pkg, "Intel-only; needs Rosetta 2 on Apple Silicon")
+				case len(r.Arch) == 1 && r.Arch[0] == "arm64":
+					report(pkg, "requires Apple Silicon; this machine is %s", archLabel(target.Arch))
+				default:
+					report(pkg, "not available for %s", archLabel(target.Arch))
+				}
+			}
+			if r.MinMacOS != "" && target.OS == "darwin" && target.OSVersion != "" &&
+				CompareVersions(target.OSVersion, r.MinMacOS) < 0 {
+				report(pkg, "requires macOS %s or later; this machine runs %s", r.MinMacOS, target.OSVersion)
+			}
+		}
+
+		if prefixMoved {
+			for _, key := range pkg.Options.Keys() {
+				if strings.Contains(pkg.Options[key].Ruby(), source.HomebrewPrefix) {
+					report(pkg, "option %s refers to %s, but Homebrew is installed in %s here",
+						key, source.HomebrewPrefix, target.HomebrewPrefix)
+				}
+			}
+		}
+	}
+	return issues
+}
+
+// CompareSystems describes differences between the named source machine
+// and this machine that affect which packages can be installed
+func CompareSystems(name string, source, target SystemFacts) []string {
+	var notes []string
+	if source.OS != "" && target.OS != "" && source.OS != target.OS {
+		notes = append(notes, fmt.Sprintf("%s runs %s, this machine runs %s", name, source.OS, target.OS))
+	}
+	if source.Arch != "" && target.Arch != "" && source.Arch != target.Arch {
+		notes = append(notes, fmt.Sprintf("%s is %s, this machine is %s", name, archLabel(source.Arch), archLabel(target.Arch)))
+	}
+	if source.HomebrewPrefix != "" && target.HomebrewPrefix != "" && source.HomebrewPrefix != target.HomebrewPrefix {
+		notes = append(notes, fmt.Sprintf("Homebrew prefix is %s on %s, %s here", source.HomebrewPrefix, name, target.HomebrewPrefix))
+	}
+	if source.OS == "darwin" && target.OS == "darwin" && source.OSVersion != "" && target.OSVersion != "" &&
+		CompareVersions(majorVersion(source.OSVersion), majorVersion(target.OSVersion)) > 0 {
+		notes = append(notes, fmt.Sprintf("%s runs macOS %s, this machine runs %s; some packages may need a newer macOS",
+			name, source.OSVersion, target.OSVersion))
+	}
+	return notes
+}
+
+// CompareVersions compares dotted numeric versions such as "13.6.1" and "14",
+// returning -1, 0 or 1. Missing components count as zero and non-numeric
+// components compare as strings.
+func CompareVersions(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		x, y := "0", "0"
+		if i < len(as) {
+			x = as[i]
+		}
+		if i < len(bs) {
+			y = bs[i]
+		}
+		xi, xerr := strconv.Atoi(x)
+		yi, yerr := strconv.Atoi(y)
+		switch {
+		case xerr == nil && yerr == nil:
+			if xi != yi {
+				if xi < yi {
+					return -1
+				}
+				return 1
+			}
+		case x != y:
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// majorVersion returns the first component of a dotted version
+func majorVersion(v string) string {
+	major, _, _ := strings.Cut(v, ".")
+	return major
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,156 @@
+package brewfile
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const brewInfoRequirements = `{
+  "formulae": [
+    {"name": "git", "full_name": "git", "requirements": []},
+    {
+      "name": "mas-cli",
+      "full_name": "mas-cli",
+      "requirements": [
+        {"name": "macos", "version": "12", "contexts": []},
+        {"name": "arch", "version": "arm64", "contexts": []}
+      ]
+    }
+  ],
+  "casks": [
+    {
+      "token": "virtualbox",
+      "full_token": "virtualbox",
+      "depends_on": {"macos": {">=": ["10.15"]}, "arch": [{"type": "intel", "bits": 64}]}
+    },
+    {"token": "firefox", "full_token": "firefox", "depends_on": {}}
+  ]
+}`
+
+func TestParseBrewRequirements(t *testing.T) {
+	reqs, err := ParseBrewRequirements([]byte(brewInfoRequirements))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]Requirements{
+		"brew:mas-cli":    {Arch: []string{"arm64"}, MinMacOS: "12"},
+		"cask:virtualbox": {Arch: []string{"x86_64"}, MinMacOS: "10.15"},
+	}, reqs)
+
+	_, err = ParseBrewRequirements([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestCheckCompat(t *testing.T) {
+	intel := SystemFacts{OS: "darwin", OSVersion: "13.6", Arch: "x86_64", HomebrewPrefix: "/usr/local"}
+	silicon := SystemFacts{OS: "darwin", OSVersion: "13.6", Arch: "arm64", HomebrewPrefix: "/opt/homebrew"}
+
+	reqs := map[string]Requirements{
+		"cask:virtualbox": {Arch: []string{"x86_64"}},
+		"cask:ollama":     {MinMacOS: "14"},
+		"brew:asitop":     {Arch: []string{"arm64"}},
+	}
+
+	packages := Packages{
+		NewPackage(TypeCask, "virtualbox"),
+		NewPackage(TypeCask, "ollama"),
+		NewPackage(TypeBrew, "asitop"),
+		NewPackage(TypeBrew, "postgresql@16").WithValue("postinstall", StringValue("/usr/local/bin/initdb")),
+		NewPackage(TypeBrew, "git"),
+	}
+
+	t.Run("intel to apple silicon", func(t *testing.T) {
+		issues := CheckCompat(packages, intel, silicon, reqs)
+		require.Len(t, issues, 3)
+		assert.Equal(t, "cask:virtualbox: Intel-only; needs Rosetta 2 on Apple Silicon", issues[0].String())
+		assert.Equal(t, "cask:ollama", issues[1].Package.ID())
+		assert.Contains(t, issues[1].Reason, "requires macOS 14 or later")
+		assert.Equal(t, "brew:postgresql@16", issues[2].Package.ID())
+		assert.Contains(t, issues[2].Reason, "option postinstall refers to /usr/local")
+	})
+
+	t.Run("apple silicon to intel", func(t *testing.T) {
+		issues := CheckCompat(packages, silicon, intel, reqs)
+		require.Len(t, issues, 2)
+		assert.Equal(t, "cask:ollama", issues[0].Package.ID())
+		assert.Equal(t, "brew:asitop: requires Apple Silicon; this machine is Intel (x86_64)", issues[1].String())
+	})
+
+	t.Run("unknown facts", func(t *testing.T) {
+		assert.Empty(t, CheckCompat(packages, SystemFacts{}, SystemFacts{}, reqs))
+		// Without requirements only the prefix can be checked
+		issues := CheckCompat(packages, intel, silicon, nil)
+		require.Len(t, issues, 1)
+		assert.Equal(t, "brew:postgresql@16", issues[0].Package.ID())
+	})
+}
+
+func TestCompareSystems(t *testing.T) {
+	source := SystemFacts{OS: "darwin", OSVersion: "15.1", Arch: "x86_64", HomebrewPrefix: "/usr/local"}
+	target := SystemFacts{OS: "darwin", OSVersion: "13.6", Arch: "arm64", HomebrewPrefix: "/opt/homebrew"}
+
+	assert.Equal(t, []string{
+		"mini is Intel (x86_64), this machine is Apple Silicon (arm64)",
+		"Homebrew prefix is /usr/local on mini, /opt/homebrew here",
+		"mini runs macOS 15.1, this machine runs 13.6; some packages may need a newer macOS",
+	}, CompareSystems("mini", source, target))
+
+	assert.Empty(t, CompareSystems("mini", target, target))
+	assert.Empty(t, CompareSystems("mini", SystemFacts{}, target))
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"13.6", "14", -1},
+		{"14", "14.0", 0},
+		{"14.1", "14.0.9", 1},
+		{"10.15", "10.9", 1},
+		{"1.0rc1", "1.0rc2", -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.expected, CompareVersions(tt.a, tt.b))
+		})
+	}
+}
+
+func TestNormalizeArch(t *testing.T) {
+	assert.Equal(t, "arm64", NormalizeArch("arm"))
+	assert.Equal(t, "arm64", NormalizeArch("aarch64"))
+	assert.Equal(t, "x86_64", NormalizeArch("amd64"))
+	assert.Equal(t, "x86_64", NormalizeArch("intel"))
+	assert.Equal(t, "", NormalizeArch("ppc"))
+}
+
+func TestLoadSystemFacts(t *testing.T) {
+	dir := t.TempDir()
+	brewfilePath := filepath.Join(dir, "Brewfile")
+
+	_, ok := LoadSystemFacts(brewfilePath)
+	assert.False(t, ok)
+
+	facts := SystemFacts{OS: "darwin", OSVersion: "14.5", Arch: "arm64", HomebrewPrefix: "/opt/homebrew", BrewsyncVersion: "1.0.0"}
+	pkgs := Packages{NewPackage(TypeBrew, "git"), NewPackage(TypeCask, "firefox")}
+	require.NoError(t, UpdateMetadata(MetadataPath(brewfilePath), "mini", pkgs, facts))
+
+	loaded, ok := LoadSystemFacts(brewfilePath)
+	assert.True(t, ok)
+	assert.Equal(t, facts, loaded)
+
+	meta, err := LoadMetadata(MetadataPath(brewfilePath))
+	require.NoError(t, err)
+	assert.Equal(t, "14.5", meta.MacOSVersion)
+	assert.Equal(t, "1.0.0", meta.BrewsyncVersion)
+	assert.Equal(t, 1, meta.PackageCounts["cask"])
+
+	// Metadata written before system facts were recorded
+	require.NoError(t, SaveMetadata(MetadataPath(brewfilePath), &Metadata{Machine: "mini", MacOSVersion: "13.6"}))
+	loaded, ok = LoadSystemFacts(brewfilePath)
+	assert.True(t, ok)
+	assert.Equal(t, SystemFacts{OS: "darwin", OSVersion: "13.6"}, loaded)
+}
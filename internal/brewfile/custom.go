@@ -0,0 +1,136 @@
+package brewfile
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// CustomType is a package type declared in config.yaml and managed with
+// command templates instead of a built-in installer. In the install,
+// uninstall and version templates {name} is replaced by the package name.
+type CustomType struct {
+	Name        string `yaml:"-" mapstructure:"-"`
+	Description string `yaml:"description,omitempty" mapstructure:"description"`
+	Icon        string `yaml:"icon,omitempty" mapstructure:"icon"`
+	// List prints the installed packages. Each output line matching Pattern
+	// is a package; the pattern's "name" group (or first group, or the whole
+	// match) is its name. Without a pattern every non-empty line is a name.
+	List      string `yaml:"list" mapstructure:"list"`
+	Pattern   string `yaml:"pattern,omitempty" mapstructure:"pattern"`
+	Install   string `yaml:"install" mapstructure:"install"`
+	Uninstall string `yaml:"uninstall" mapstructure:"uninstall"`
+	// Version prints installed versions, matched line by line against
+	// VersionPattern's "name" and "version" groups. A Version template using
+	// {name} is run once per package and its first line (or the pattern's
+	// "version" group) is the version.
+	Version        string `yaml:"version,omitempty" mapstructure:"version"`
+	VersionPattern string `yaml:"version_pattern,omitempty" mapstructure:"version_pattern"`
+	// Editor is set for editor extension types declared under editors,
+	// which are managed through the editor's CLI instead of templates
+	Editor *Editor `yaml:"-" mapstructure:"-"`
+}
+
+var (
+	customMu    sync.RWMutex
+	customTypes = make(map[PackageType]CustomType)
+)
+
+// typeNamePattern restricts custom type names to valid Brewfile directives
+var typeNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// Validate checks that the type has a usable name, the required command
+// templates and valid patterns
+func (c CustomType) Validate() error {
+	if !typeNamePattern.MatchString(c.Name) {
+		return fmt.Errorf("invalid custom type name %q: use lowercase letters, digits and underscores", c.Name)
+	}
+	if _, builtin := keywordTypes[c.Name]; builtin || c.Name == "agy" || c.Name == includeKeyword || unsupportedDirectives[c.Name] {
+		return fmt.Errorf("custom type %q clashes with a built-in directive", c.Name)
+	}
+	for _, f := range [][2]string{{"list", c.List}, {"install", c.Install}, {"uninstall", c.Uninstall}} {
+		if f[1] == "" && c.Editor == nil {
+			return fmt.Errorf("custom type %q has no %s command", c.Name, f[0])
+		}
+	}
+	for _, f := range [][2]string{{"pattern", c.Pattern}, {"version_pattern", c.VersionPattern}} {
+		if _, err := regexp.Compile(f[1]); err != nil {
+			return fmt.Errorf("custom type %q has an invalid %s: %w", c.Name, f[0], err)
+		}
+	}
+	return nil
+}
+
+// RegisterType makes a custom type known to the parser, writer and the rest
+// of BrewSync. Registering a name again replaces its definition.
+func RegisterType(c CustomType) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	customMu.Lock()
+	defer customMu.Unlock()
+	customTypes[PackageType(c.Name)] = c
+	return nil
+}
+
+// UnregisterType removes a custom type
+func UnregisterType(name string) {
+	customMu.Lock()
+	defer customMu.Unlock()
+	delete(customTypes, PackageType(name))
+}
+
+// LookupCustomType returns the definition of a custom type
+func LookupCustomType(t PackageType) (CustomType, bool) {
+	customMu.RLock()
+	defer customMu.RUnlock()
+	c, ok := customTypes[t]
+	return c, ok
+}
+
+// IsCustomType reports whether t was declared in config rather than built in
+func IsCustomType(t PackageType) bool {
+	_, ok := LookupCustomType(t)
+	return ok
+}
+
+// CustomTypes returns the registered custom types sorted by name
+func CustomTypes() []PackageType {
+	customMu.RLock()
+	defer customMu.RUnlock()
+	types := make([]PackageType, 0, len(customTypes))
+	for t := range customTypes {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+	return types
+}
+
+// TypeIcon returns the icon configured for a custom type, or "" for
+// built-in types and custom types without one
+func TypeIcon(t PackageType) string {
+	c, _ := LookupCustomType(t)
+	return c.Icon
+}
+
+// typeForKeyword returns the package type of a Brewfile directive
+func typeForKeyword(keyword string) (PackageType, bool) {
+	if t, ok := keywordTypes[keyword]; ok {
+		return t, true
+	}
+	t := PackageType(keyword)
+	return t, IsCustomType(t)
+}
+
+// isExtensionType reports whether t is written under a
+// "# type (brewsync extension)" header because brew bundle does not know it
+func isExtensionType(t PackageType) bool {
+	return t == TypeCursor || t == TypeAntigravity || t == TypeGo || IsCustomType(t)
+}
+
+// writerOrder returns the order in which package groups are written:
+// built-in types followed by custom types
+func writerOrder() []PackageType {
+	return append(append([]PackageType(nil), writerTypeOrder...), CustomTypes()...)
+}
@@ -0,0 +1,88 @@
+package brewfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// registerNpm registers an npm custom type for the duration of a test
+func registerNpm(t *testing.T) {
+	t.Helper()
+	require.NoError(t, RegisterType(CustomType{
+		Name:      "npm",
+		Icon:      "📦",
+		List:      "npm ls -g --depth=0 --parseable",
+		Pattern:   `node_modules/(?P<name>.+)$`,
+		Install:   "npm install -g {name}",
+		Uninstall: "npm uninstall -g {name}",
+	}))
+	t.Cleanup(func() { UnregisterType("npm") })
+}
+
+func TestCustomType_Validate(t *testing.T) {
+	valid := CustomType{Name: "npm", List: "npm ls", Install: "npm i {name}", Uninstall: "npm rm {name}"}
+
+	tests := []struct {
+		name    string
+		modify  func(c *CustomType)
+		wantErr string
+	}{
+		{"valid", func(c *CustomType) {}, ""},
+		{"bad name", func(c *CustomType) { c.Name = "Npm-Pkgs" }, "invalid custom type name"},
+		{"built-in directive", func(c *CustomType) { c.Name = "brew" }, "clashes with a built-in"},
+		{"include", func(c *CustomType) { c.Name = "include" }, "clashes with a built-in"},
+		{"missing install", func(c *CustomType) { c.Install = "" }, "has no install command"},
+		{"bad pattern", func(c *CustomType) { c.Pattern = "(" }, "invalid pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid
+			tt.modify(&c)
+			err := c.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCustomType_Brewfile(t *testing.T) {
+	content := `brew "git"
+npm "typescript"
+npm "@anthropic-ai/sdk"
+`
+
+	t.Run("unknown before registration", func(t *testing.T) {
+		pkgs, err := NewParser().ParseString(content)
+		require.NoError(t, err)
+		assert.Len(t, pkgs, 1)
+		assert.True(t, Lint(content).HasErrors())
+	})
+
+	t.Run("first-class after registration", func(t *testing.T) {
+		registerNpm(t)
+
+		pkgs, err := NewStrictParser().ParseString(content)
+		require.NoError(t, err)
+		require.Len(t, pkgs, 3)
+		assert.Equal(t, "npm:typescript", pkgs[1].ID())
+
+		typ, err := ParsePackageType("npm")
+		require.NoError(t, err)
+		assert.Equal(t, PackageType("npm"), typ)
+		assert.Contains(t, AllTypes(), PackageType("npm"))
+		assert.Equal(t, "📦", TypeIcon("npm"))
+
+		out := NewWriter(pkgs).Format()
+		assert.Contains(t, out, "# npm (brewsync extension)\nnpm \"@anthropic-ai/sdk\"\nnpm \"typescript\"\n")
+
+		doc := NewDocument("brew \"git\"\n")
+		doc.Insert(NewPackage("npm", "typescript"))
+		assert.Equal(t, "brew \"git\"\n\n# npm (brewsync extension)\nnpm \"typescript\"\n", doc.String())
+	})
+}
@@ -1,6 +1,10 @@
 package brewfile
 
-import "strconv"
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
 
 // DiffResult contains the results of comparing two package lists
 type DiffResult struct {
@@ -10,11 +14,144 @@ type DiffResult struct {
 	Removals Packages
 	// Common are packages in both
 	Common Packages
+	// Changed are packages in both whose options, full name or mas id differ
+	Changed []Change
+	// Normalized lists entries whose names were canonicalized before comparing
+	Normalized []Normalization
+}
+
+// Change pairs the source and current declarations of a package whose
+// type and name match but whose options, arguments or mas id differ
+type Change struct {
+	Source  Package
+	Current Package
+}
+
+// Change fields reported by Fields
+const (
+	ChangedOptions  = "options"
+	ChangedFullName = "full_name"
+	ChangedURL      = "url"
+	ChangedMasID    = "id"
+)
+
+// ID returns the package ID of the changed package
+func (c Change) ID() string {
+	return c.Source.ID()
+}
+
+// Fields returns which attributes differ between source and current
+func (c Change) Fields() []string {
+	var fields []string
+	if !c.optionsWithoutID(c.Source).Equal(c.optionsWithoutID(c.Current)) {
+		fields = append(fields, ChangedOptions)
+	}
+	if c.Source.FullName != "" && c.Current.FullName != "" && c.Source.FullName != c.Current.FullName {
+		fields = append(fields, ChangedFullName)
+	}
+	if c.Source.URL != c.Current.URL {
+		fields = append(fields, ChangedURL)
+	}
+	if c.Source.Type == TypeMas && !c.Source.Options["id"].Equal(c.Current.Options["id"]) {
+		fields = append(fields, ChangedMasID)
+	}
+	return fields
+}
+
+// OptionsOnly reports whether only the given options differ, e.g. "link"
+func (c Change) OptionsOnly(keys ...string) bool {
+	allowed := make(map[string]bool, len(keys))
+	for _, k := range keys {
+		allowed[k] = true
+	}
+	for _, d := range c.Details() {
+		if !allowed[d.Key] {
+			return false
+		}
+	}
+	return true
+}
+
+// ChangeDetail describes a single attribute that differs; empty values mean unset
+type ChangeDetail struct {
+	Key     string `json:"key"`
+	Source  string `json:"source"`
+	Current string `json:"current"`
+}
+
+// String formats the detail as "key: source → current"
+func (d ChangeDetail) String() string {
+	from, to := d.Source, d.Current
+	if from == "" {
+		from = "(unset)"
+	}
+	if to == "" {
+		to = "(unset)"
+	}
+	return fmt.Sprintf("%s: %s → %s", d.Key, from, to)
+}
+
+// Details lists every differing option and argument in a stable order. A
+// service running on one machine only is reported as a single service
+// detail instead of the options that declare it.
+func (c Change) Details() []ChangeDetail {
+	var details []ChangeDetail
+	if c.Source.URL != c.Current.URL {
+		details = append(details, ChangeDetail{Key: ChangedURL, Source: c.Source.URL, Current: c.Current.URL})
+	}
+	if c.Source.FullName != "" && c.Current.FullName != "" && c.Source.FullName != c.Current.FullName {
+		details = append(details, ChangeDetail{Key: ChangedFullName, Source: c.Source.FullName, Current: c.Current.FullName})
+	}
+	serviceChanged := c.ServiceChanged()
+	if serviceChanged {
+		details = append(details, ChangeDetail{
+			Key:     ServiceDetail,
+			Source:  serviceState(c.Source.ServiceRunning()),
+			Current: serviceState(c.Current.ServiceRunning()),
+		})
+	}
+
+	keys := c.Source.Options.Keys()
+	for _, k := range c.Current.Options.Keys() {
+		if _, ok := c.Source.Options[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		sv, sok := c.Source.Options[k]
+		cv, cok := c.Current.Options[k]
+		if sok && cok && sv.Equal(cv) {
+			continue
+		}
+		if serviceChanged && (k == RestartServiceOption || k == StartServiceOption) {
+			continue
+		}
+		detail := ChangeDetail{Key: k}
+		if sok {
+			detail.Source = sv.Ruby()
+		}
+		if cok {
+			detail.Current = cv.Ruby()
+		}
+		details = append(details, detail)
+	}
+	return details
+}
+
+// optionsWithoutID returns a package's options, leaving out a mas app's id
+func (c Change) optionsWithoutID(pkg Package) Options {
+	if pkg.Type != TypeMas {
+		return pkg.Options
+	}
+	opts := pkg.Options.Clone()
+	delete(opts, "id")
+	return opts
 }
 
 // IsEmpty returns true if there are no differences
 func (d *DiffResult) IsEmpty() bool {
-	return len(d.Additions) == 0 && len(d.Removals) == 0
+	return len(d.Additions) == 0 && len(d.Removals) == 0 && len(d.Changed) == 0
 }
 
 // AdditionsByType returns additions grouped by package type
@@ -30,44 +167,33 @@ func (d *DiffResult) RemovalsByType() map[PackageType][]Package {
 // Diff computes the differences between source and current package lists
 // source: the packages we want to have (e.g., from another machine)
 // current: the packages we currently have
+// Names are canonicalized with DefaultNormalizer before comparing.
 func Diff(source, current Packages) *DiffResult {
-	result := &DiffResult{
-		Additions: make(Packages, 0),
-		Removals:  make(Packages, 0),
-		Common:    make(Packages, 0),
-	}
+	return DiffWith(source, current, DefaultNormalizer)
+}
 
-	// Build a map of current packages for quick lookup
-	currentMap := make(map[string]Package)
-	for _, pkg := range current {
-		key := packageKey(pkg)
-		currentMap[key] = pkg
-	}
+// DiffWith computes differences using the given normalizer to match names
+func DiffWith(source, current Packages, n *Normalizer) *DiffResult {
+	sourceSet := NewPackageSetFunc(n.Key, source...)
+	currentSet := NewPackageSetFunc(n.Key, current...)
 
-	// Build a map of source packages
-	sourceMap := make(map[string]Package)
-	for _, pkg := range source {
-		key := packageKey(pkg)
-		sourceMap[key] = pkg
+	result := &DiffResult{
+		// Additions are in source but not in current
+		Additions: sourceSet.Difference(currentSet).Packages(),
+		// Removals are in current but not in source
+		Removals: currentSet.Difference(sourceSet).Packages(),
+		Common:   sourceSet.Intersect(currentSet).Packages(),
 	}
 
-	// Find additions (in source but not in current)
-	for _, pkg := range source {
-		key := packageKey(pkg)
-		if _, exists := currentMap[key]; !exists {
-			result.Additions = append(result.Additions, pkg)
-		} else {
-			result.Common = append(result.Common, pkg)
+	// Packages in both whose declarations differ
+	for _, pkg := range result.Common {
+		cur, _ := currentSet.Lookup(pkg)
+		if change := (Change{Source: pkg, Current: cur}); len(change.Fields()) > 0 {
+			result.Changed = append(result.Changed, change)
 		}
 	}
 
-	// Find removals (in current but not in source)
-	for _, pkg := range current {
-		key := packageKey(pkg)
-		if _, exists := sourceMap[key]; !exists {
-			result.Removals = append(result.Removals, pkg)
-		}
-	}
+	result.Normalized = n.normalizationsOf(source, current)
 
 	return result
 }
@@ -89,18 +215,57 @@ func packageKey(pkg Package) string {
 // FilterIgnored removes packages from a diff result that should be ignored
 func (d *DiffResult) FilterIgnored(ignoredPackages map[string]bool) *DiffResult {
 	return &DiffResult{
-		Additions: filterByKey(d.Additions, ignoredPackages),
-		Removals:  filterByKey(d.Removals, ignoredPackages),
-		Common:    d.Common,
+		Additions:  filterByKey(d.Additions, ignoredPackages),
+		Removals:   filterByKey(d.Removals, ignoredPackages),
+		Common:     d.Common,
+		Changed:    filterChanges(d.Changed, ignoredPackages),
+		Normalized: d.Normalized,
 	}
 }
 
 // FilterMachineSpecific removes packages that are designated for a specific machine
 func (d *DiffResult) FilterMachineSpecific(machinePackages map[string]bool) *DiffResult {
 	return &DiffResult{
-		Additions: filterByKey(d.Additions, machinePackages),
-		Removals:  filterByKey(d.Removals, machinePackages),
-		Common:    d.Common,
+		Additions:  filterByKey(d.Additions, machinePackages),
+		Removals:   filterByKey(d.Removals, machinePackages),
+		Common:     d.Common,
+		Changed:    filterChanges(d.Changed, machinePackages),
+		Normalized: d.Normalized,
+	}
+}
+
+// ExcludeTypes removes packages of the given types from a diff result, e.g.
+// types that cannot be installed on one of the machines compared
+func (d *DiffResult) ExcludeTypes(types ...PackageType) *DiffResult {
+	if len(types) == 0 {
+		return d
+	}
+	excluded := make(map[PackageType]bool, len(types))
+	for _, t := range types {
+		excluded[t] = true
+	}
+	keep := func(pkgs Packages) Packages {
+		var result Packages
+		for _, pkg := range pkgs {
+			if !excluded[pkg.Type] {
+				result = append(result, pkg)
+			}
+		}
+		return result
+	}
+
+	var changes []Change
+	for _, c := range d.Changed {
+		if !excluded[c.Source.Type] {
+			changes = append(changes, c)
+		}
+	}
+	return &DiffResult{
+		Additions:  keep(d.Additions),
+		Removals:   keep(d.Removals),
+		Common:     keep(d.Common),
+		Changed:    changes,
+		Normalized: d.Normalized,
 	}
 }
 
@@ -116,6 +281,17 @@ func filterByKey(pkgs Packages, excluded map[string]bool) Packages {
 	return result
 }
 
+// filterChanges filters out changes whose package keys are in the excluded map
+func filterChanges(changes []Change, excluded map[string]bool) []Change {
+	var result []Change
+	for _, c := range changes {
+		if !excluded[packageKey(c.Source)] {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
 // Summary returns a human-readable summary of the diff
 func (d *DiffResult) Summary() string {
 	if d.IsEmpty() {
@@ -129,6 +305,9 @@ func (d *DiffResult) Summary() string {
 	if len(d.Removals) > 0 {
 		parts = append(parts, formatCount(len(d.Removals), "removal"))
 	}
+	if len(d.Changed) > 0 {
+		parts = append(parts, strconv.Itoa(len(d.Changed))+" changed")
+	}
 
 	return join(parts, ", ")
 }
@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestDiff_NoChanges(t *testing.T) {
@@ -262,3 +263,65 @@ func TestDiffResult_Summary(t *testing.T) {
 		assert.Contains(t, summary, "removal")
 	})
 }
+
+func TestDiff_Changed(t *testing.T) {
+	source := Packages{
+		NewPackage(TypeBrew, "postgresql@16").WithOption("restart_service", ":changed"),
+		NewPackage(TypeBrew, "libpq").WithOption("link", "true"),
+		NewPackage(TypeBrew, "git"),
+		NewPackage(TypeMas, "Xcode").WithOption("id", "497799835"),
+		NewPackage(TypeTap, "user/tools").WithValue("clone_target", StringValue("x")),
+	}
+	source[4].URL = "https://example.com/tools.git"
+
+	current := Packages{
+		NewPackage(TypeBrew, "postgresql@16"),
+		NewPackage(TypeBrew, "libpq").WithOption("link", "false"),
+		NewPackage(TypeBrew, "git"),
+		NewPackage(TypeMas, "Xcode").WithOption("id", "111111111"),
+		NewPackage(TypeTap, "user/tools").WithValue("clone_target", StringValue("x")),
+	}
+
+	diff := Diff(source, current)
+	assert.Empty(t, diff.Additions)
+	assert.Empty(t, diff.Removals)
+	assert.Len(t, diff.Common, 5)
+	require.Len(t, diff.Changed, 4)
+	assert.False(t, diff.IsEmpty())
+	assert.Equal(t, "4 changed", diff.Summary())
+
+	pg := diff.Changed[0]
+	assert.Equal(t, "brew:postgresql@16", pg.ID())
+	assert.Equal(t, []string{ChangedOptions}, pg.Fields())
+	assert.Equal(t, []ChangeDetail{{Key: "service", Source: "running", Current: "stopped"}}, pg.Details())
+	assert.Equal(t, "service: running → stopped", pg.Details()[0].String())
+	assert.False(t, pg.OptionsOnly("link"))
+	assert.True(t, pg.ServiceOnly())
+
+	libpq := diff.Changed[1]
+	assert.True(t, libpq.OptionsOnly("link"))
+	assert.Equal(t, []ChangeDetail{{Key: "link", Source: "true", Current: "false"}}, libpq.Details())
+
+	xcode := diff.Changed[2]
+	assert.Equal(t, []string{ChangedMasID}, xcode.Fields())
+
+	tap := diff.Changed[3]
+	assert.Equal(t, []string{ChangedURL}, tap.Fields())
+
+	t.Run("full name", func(t *testing.T) {
+		a := NewPackage(TypeMas, "497799835")
+		a.FullName = "Xcode"
+		b := NewPackage(TypeMas, "497799835")
+		b.FullName = "Xcode Beta"
+		c := NewPackage(TypeMas, "497799835")
+
+		assert.Equal(t, []string{ChangedFullName}, Diff(Packages{a}, Packages{b}).Changed[0].Fields())
+		// An unknown full name is not a change
+		assert.Empty(t, Diff(Packages{a}, Packages{c}).Changed)
+	})
+
+	t.Run("filter ignored", func(t *testing.T) {
+		filtered := diff.FilterIgnored(map[string]bool{"brew:libpq": true})
+		assert.Len(t, filtered.Changed, 3)
+	})
+}
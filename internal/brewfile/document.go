@@ -0,0 +1,410 @@
+package brewfile
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Document is an editable Brewfile that preserves comments, blank lines and
+// entry ordering. Edits touch only the lines of the affected entry; everything
+// else is kept byte-for-byte.
+type Document struct {
+	src   string
+	nodes []node
+}
+
+// NewDocument creates a document from Brewfile content
+func NewDocument(content string) *Document {
+	d := &Document{src: content}
+	d.reparse()
+	return d
+}
+
+// LoadDocument reads a Brewfile into a document.
+// A missing file yields an empty document.
+func LoadDocument(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewDocument(""), nil
+		}
+		return nil, fmt.Errorf("failed to read Brewfile: %w", err)
+	}
+	return NewDocument(string(data)), nil
+}
+
+// Save writes the document to the given path
+func (d *Document) Save(path string) error {
+	return os.WriteFile(path, []byte(d.src), 0644)
+}
+
+// String returns the document content
+func (d *Document) String() string {
+	return d.src
+}
+
+// Packages returns the packages declared in the document, in source order
+func (d *Document) Packages() Packages {
+	packages, _ := NewParser().parse(d.src)
+	return packages
+}
+
+// Contains checks if the document declares a package with the given ID
+func (d *Document) Contains(id string) bool {
+	_, _, ok := d.find(id)
+	return ok
+}
+
+// Insert adds a package entry next to the entries of the same type.
+// Sorted groups stay sorted; otherwise the entry goes between neighbours that
+// bracket its name, or at the end of its group. New groups are placed following the Writer's type order.
+// Returns false if the package is already present.
+func (d *Document) Insert(pkg Package) bool {
+	if d.Contains(pkg.ID()) {
+		return false
+	}
+
+	text := formatEntry(pkg)
+
+	if off, ok := d.groupInsertOffset(pkg); ok {
+		d.insertAt(off, text)
+		return true
+	}
+
+	// First entry of its type: start a new group
+	if isExtensionType(pkg.Type) {
+		text = fmt.Sprintf("# %s (brewsync extension)\n", pkg.Type) + text
+	}
+
+	if i, ok := d.lastEntryBefore(pkg.Type); ok {
+		d.insertAt(d.lineEnd(d.nodes[i].end), "\n"+text)
+		return true
+	}
+
+	if i, ok := d.firstEntryAfter(pkg.Type); ok {
+		d.insertAt(d.blockStart(i), text+"\n")
+		return true
+	}
+
+	// No entries at all: append, separated from any existing content
+	if strings.TrimSpace(d.src) != "" && !strings.HasSuffix(d.src, "\n\n") {
+		if !strings.HasSuffix(d.src, "\n") {
+			text = "\n" + text
+		}
+		text = "\n" + text
+	}
+	d.insertAt(len(d.src), text)
+	return true
+}
+
+// Remove deletes the entry with the given ID along with its description
+// comment. Section headers and other comments are left in place.
+// Returns false if the package is not present.
+func (d *Document) Remove(id string) bool {
+	i, _, ok := d.find(id)
+	if !ok {
+		return false
+	}
+
+	n := d.nodes[i]
+	start := d.lineStart(n.pos.offset)
+	if d.hasDescription(i) {
+		start = d.lineStart(d.nodes[i-1].pos.offset)
+	}
+	end := d.lineEnd(n.end)
+
+	// Avoid leaving two blank lines where the entry used to be
+	if d.blankBefore(start) && d.blankAt(end) {
+		if end < len(d.src) {
+			end = d.lineEnd(end)
+		} else if start > 0 {
+			start = d.lineStart(start - 1)
+		}
+	}
+
+	d.src = d.src[:start] + d.src[end:]
+	d.reparse()
+	return true
+}
+
+// Update rewrites the entry for the package's ID when its options, tap URL
+// or app name differ. Indentation, trailing comments and descriptions are
+// preserved. Returns false if the package is not present.
+func (d *Document) Update(pkg Package) bool {
+	i, existing, ok := d.find(pkg.ID())
+	if !ok {
+		return false
+	}
+	if sameEntry(existing, pkg) {
+		return true
+	}
+
+	e := d.nodes[i].entry
+	d.src = d.src[:e.start.offset] + formatPackage(pkg) + d.src[e.end:]
+	d.reparse()
+	return true
+}
+
+// Sync makes the document declare exactly the given packages.
+// Entries that are no longer present are removed, changed entries are
+// rewritten in place and new packages are inserted into their groups.
+func (d *Document) Sync(packages Packages) {
+	wanted := make(map[string]bool, len(packages))
+	for _, pkg := range packages {
+		wanted[pkg.ID()] = true
+	}
+
+	// Drop stale and duplicate entries
+	seen := make(map[string]bool)
+	for _, pkg := range d.Packages() {
+		id := pkg.ID()
+		if !wanted[id] || seen[id] {
+			d.removeLast(id, seen[id])
+			continue
+		}
+		seen[id] = true
+	}
+
+	for _, pkg := range packages {
+		if !d.Update(pkg) {
+			d.Insert(pkg)
+		}
+	}
+}
+
+// removeLast removes an entry; for duplicates it removes the last occurrence
+func (d *Document) removeLast(id string, duplicate bool) {
+	if !duplicate {
+		d.Remove(id)
+		return
+	}
+	for i := len(d.nodes) - 1; i >= 0; i-- {
+		if pkg, ok := d.packageAt(i); ok && pkg.ID() == id {
+			n := d.nodes[i]
+			d.src = d.src[:d.lineStart(n.pos.offset)] + d.src[d.lineEnd(n.end):]
+			d.reparse()
+			return
+		}
+	}
+}
+
+// reparse rebuilds the node list after an edit
+func (d *Document) reparse() {
+	d.nodes = parseSyntax(d.src)
+}
+
+// packageAt returns the package declared by node i, if any
+func (d *Document) packageAt(i int) (Package, bool) {
+	if d.nodes[i].kind != nodeEntry {
+		return Package{}, false
+	}
+	return entryToPackage(d.nodes[i].entry)
+}
+
+// find returns the node index and package for the first entry with the given ID
+func (d *Document) find(id string) (int, Package, bool) {
+	for i := range d.nodes {
+		if pkg, ok := d.packageAt(i); ok && pkg.ID() == id {
+			return i, pkg, true
+		}
+	}
+	return -1, Package{}, false
+}
+
+// hasDescription reports whether the comment directly above entry i is its
+// description rather than a section header. A comment counts as a description
+// when it sits between two entries, or when the following entry also carries
+// a comment directly above it (the layout the Writer produces).
+func (d *Document) hasDescription(i int) bool {
+	if i == 0 || !d.adjacentComment(i) {
+		return false
+	}
+	if i >= 2 && d.nodes[i-2].kind == nodeEntry && d.nodes[i-2].endLine == d.nodes[i-1].pos.line-1 {
+		return true
+	}
+	if i+2 < len(d.nodes) && d.nodes[i+2].kind == nodeEntry && d.adjacentComment(i+2) &&
+		d.nodes[i+1].pos.line == d.nodes[i].endLine+1 {
+		return true
+	}
+	return false
+}
+
+// adjacentComment reports whether node i is an entry directly below a comment line
+func (d *Document) adjacentComment(i int) bool {
+	return i > 0 && d.nodes[i].kind == nodeEntry && d.nodes[i-1].kind == nodeComment &&
+		d.nodes[i-1].endLine == d.nodes[i].pos.line-1
+}
+
+// groupInsertOffset finds where to insert a package among entries of its type
+func (d *Document) groupInsertOffset(pkg Package) (int, bool) {
+	var indexes []int
+	sorted := true
+	for i := range d.nodes {
+		p, ok := d.packageAt(i)
+		if !ok || p.Type != pkg.Type {
+			continue
+		}
+		if len(indexes) > 0 {
+			prev, _ := d.packageAt(indexes[len(indexes)-1])
+			if prev.Name > p.Name {
+				sorted = false
+			}
+		}
+		indexes = append(indexes, i)
+	}
+	if len(indexes) == 0 {
+		return 0, false
+	}
+
+	// Sorted groups stay sorted; otherwise look for two neighbours that
+	// bracket the name and fall back to the end of the group
+	last := indexes[len(indexes)-1]
+	for k, i := range indexes {
+		p, _ := d.packageAt(i)
+		if pkg.Name >= p.Name {
+			continue
+		}
+		if k == 0 && !sorted {
+			continue
+		}
+		if k > 0 {
+			prev, _ := d.packageAt(indexes[k-1])
+			if pkg.Name < prev.Name {
+				continue
+			}
+		}
+		if d.hasDescription(i) {
+			return d.lineStart(d.nodes[i-1].pos.offset), true
+		}
+		return d.lineStart(d.nodes[i].pos.offset), true
+	}
+	return d.lineEnd(d.nodes[last].end), true
+}
+
+// lastEntryBefore finds the last entry whose type precedes t in the Writer's order
+func (d *Document) lastEntryBefore(t PackageType) (int, bool) {
+	rank := typeRank(t)
+	found := -1
+	best := -1
+	for i := range d.nodes {
+		p, ok := d.packageAt(i)
+		if !ok {
+			continue
+		}
+		if r := typeRank(p.Type); r < rank && r >= best {
+			best = r
+			found = i
+		}
+	}
+	return found, found >= 0
+}
+
+// firstEntryAfter finds the first entry whose type follows t in the Writer's order
+func (d *Document) firstEntryAfter(t PackageType) (int, bool) {
+	rank := typeRank(t)
+	for i := range d.nodes {
+		if p, ok := d.packageAt(i); ok && typeRank(p.Type) > rank {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// blockStart returns the start of entry i including the comment lines directly above it
+func (d *Document) blockStart(i int) int {
+	start := d.lineStart(d.nodes[i].pos.offset)
+	line := d.nodes[i].pos.line
+	for j := i - 1; j >= 0; j-- {
+		if d.nodes[j].kind != nodeComment || d.nodes[j].endLine != line-1 {
+			break
+		}
+		start = d.lineStart(d.nodes[j].pos.offset)
+		line = d.nodes[j].pos.line
+	}
+	return start
+}
+
+// insertAt inserts text at the given offset, starting a new line if needed
+func (d *Document) insertAt(off int, text string) {
+	if off > 0 && d.src[off-1] != '\n' {
+		text = "\n" + text
+	}
+	d.src = d.src[:off] + text + d.src[off:]
+	d.reparse()
+}
+
+// lineStart returns the offset of the start of the line containing off
+func (d *Document) lineStart(off int) int {
+	return strings.LastIndexByte(d.src[:off], '\n') + 1
+}
+
+// lineEnd returns the offset just past the newline ending the line containing off
+func (d *Document) lineEnd(off int) int {
+	i := strings.IndexByte(d.src[off:], '\n')
+	if i < 0 {
+		return len(d.src)
+	}
+	return off + i + 1
+}
+
+// blankBefore reports whether the line before offset start is blank or absent
+func (d *Document) blankBefore(start int) bool {
+	if start == 0 {
+		return true
+	}
+	prev := d.lineStart(start - 1)
+	return strings.TrimSpace(d.src[prev:start]) == ""
+}
+
+// blankAt reports whether the line starting at off is blank or absent
+func (d *Document) blankAt(off int) bool {
+	if off >= len(d.src) {
+		return true
+	}
+	return strings.TrimSpace(d.src[off:d.lineEnd(off)]) == ""
+}
+
+// formatEntry formats a package with its description comment and a newline
+func formatEntry(pkg Package) string {
+	var sb strings.Builder
+	if pkg.Description != "" {
+		sb.WriteString(fmt.Sprintf("# %s\n", pkg.Description))
+	}
+	sb.WriteString(formatPackage(pkg))
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+// sameEntry reports whether two packages would be written identically
+func sameEntry(a, b Package) bool {
+	if a.URL != b.URL || !a.Options.Equal(b.Options) {
+		return false
+	}
+	return formatPackage(a) == formatPackage(b)
+}
+
+// typeRank returns the position of t in the Writer's type order
+func typeRank(t PackageType) int {
+	order := writerOrder()
+	for i, ot := range order {
+		if ot == t {
+			return i
+		}
+	}
+	return len(order)
+}
+
+// SyncFile updates the Brewfile at path to declare exactly the given packages,
+// preserving its comments and layout. A missing file is created.
+func SyncFile(path string, packages Packages) error {
+	doc, err := LoadDocument(path)
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(doc.String()) == "" {
+		return NewWriter(packages).Write(path)
+	}
+	doc.Sync(packages)
+	return doc.Save(path)
+}
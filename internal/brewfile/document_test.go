@@ -0,0 +1,232 @@
+package brewfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const curatedBrewfile = `# Brewfile for the work laptop
+tap "homebrew/bundle"
+
+# --- work tools ---
+brew "awscli"
+brew "kubectl" # pinned by platform team
+brew "terraform"
+
+# --- personal ---
+brew "yt-dlp"
+brew "ffmpeg"
+
+cask "firefox", args: {
+  appdir: "~/Applications",
+}
+`
+
+func TestDocument_PreservesUnchangedContent(t *testing.T) {
+	doc := NewDocument(curatedBrewfile)
+	assert.Equal(t, curatedBrewfile, doc.String())
+
+	pkgs := doc.Packages()
+	require.Len(t, pkgs, 7)
+	assert.Equal(t, "awscli", pkgs[1].Name)
+	assert.Equal(t, "--- work tools ---", pkgs[1].Description)
+}
+
+func TestDocument_Insert(t *testing.T) {
+	t.Run("into sorted group", func(t *testing.T) {
+		doc := NewDocument(curatedBrewfile)
+		assert.True(t, doc.Insert(NewPackage(TypeBrew, "helm")))
+		assert.Contains(t, doc.String(), "brew \"awscli\"\nbrew \"helm\"\nbrew \"kubectl\" # pinned by platform team\n")
+	})
+
+	t.Run("into unsorted group appends", func(t *testing.T) {
+		doc := NewDocument("brew \"zsh\"\nbrew \"git\"\n\ncask \"firefox\"\n")
+		doc.Insert(NewPackage(TypeBrew, "jq"))
+		assert.Equal(t, "brew \"zsh\"\nbrew \"git\"\nbrew \"jq\"\n\ncask \"firefox\"\n", doc.String())
+	})
+
+	t.Run("new group follows type order", func(t *testing.T) {
+		doc := NewDocument(curatedBrewfile)
+		doc.Insert(NewPackage(TypeVSCode, "golang.go"))
+		doc.Insert(NewPackage(TypeGo, "golang.org/x/tools/gopls"))
+		doc.Insert(NewPackage(TypeMas, "Xcode").WithOption("id", "497799835"))
+
+		expected := curatedBrewfile + `
+mas "Xcode", id: 497799835
+
+vscode "golang.go"
+
+# go (brewsync extension)
+go "golang.org/x/tools/gopls"
+`
+		assert.Equal(t, expected, doc.String())
+	})
+
+	t.Run("before earlier types", func(t *testing.T) {
+		doc := NewDocument("# header\nbrew \"git\"\n")
+		doc.Insert(NewPackage(TypeTap, "homebrew/bundle"))
+		assert.Equal(t, "tap \"homebrew/bundle\"\n\n# header\nbrew \"git\"\n", doc.String())
+	})
+
+	t.Run("with description", func(t *testing.T) {
+		doc := NewDocument("brew \"git\"\n")
+		pkg := NewPackage(TypeBrew, "jq")
+		pkg.Description = "JSON processor"
+		doc.Insert(pkg)
+		assert.Equal(t, "brew \"git\"\n# JSON processor\nbrew \"jq\"\n", doc.String())
+	})
+
+	t.Run("existing package", func(t *testing.T) {
+		doc := NewDocument(curatedBrewfile)
+		assert.False(t, doc.Insert(NewPackage(TypeBrew, "awscli")))
+		assert.Equal(t, curatedBrewfile, doc.String())
+	})
+
+	t.Run("empty document", func(t *testing.T) {
+		doc := NewDocument("")
+		doc.Insert(NewPackage(TypeBrew, "git"))
+		assert.Equal(t, "brew \"git\"\n", doc.String())
+	})
+
+	t.Run("missing trailing newline", func(t *testing.T) {
+		doc := NewDocument(`brew "git"`)
+		doc.Insert(NewPackage(TypeBrew, "jq"))
+		assert.Equal(t, "brew \"git\"\nbrew \"jq\"\n", doc.String())
+	})
+}
+
+func TestDocument_Remove(t *testing.T) {
+	t.Run("keeps section header", func(t *testing.T) {
+		doc := NewDocument(curatedBrewfile)
+		assert.True(t, doc.Remove("brew:awscli"))
+		assert.Contains(t, doc.String(), "# --- work tools ---\nbrew \"kubectl\" # pinned by platform team\n")
+		assert.False(t, doc.Contains("brew:awscli"))
+	})
+
+	t.Run("multi-line entry", func(t *testing.T) {
+		doc := NewDocument(curatedBrewfile)
+		assert.True(t, doc.Remove("cask:firefox"))
+		assert.True(t, strings.HasSuffix(doc.String(), "brew \"ffmpeg\"\n"))
+		assert.False(t, strings.HasSuffix(doc.String(), "\n\n"))
+		assert.NotContains(t, doc.String(), "appdir")
+	})
+
+	t.Run("removes description between entries", func(t *testing.T) {
+		doc := NewDocument("brew \"git\"\n# JSON processor\nbrew \"jq\"\nbrew \"zsh\"\n")
+		doc.Remove("brew:jq")
+		assert.Equal(t, "brew \"git\"\nbrew \"zsh\"\n", doc.String())
+	})
+
+	t.Run("does not leave double blank lines", func(t *testing.T) {
+		doc := NewDocument("tap \"a/b\"\n\nbrew \"git\"\n\ncask \"firefox\"\n")
+		doc.Remove("brew:git")
+		assert.Equal(t, "tap \"a/b\"\n\ncask \"firefox\"\n", doc.String())
+	})
+
+	t.Run("missing package", func(t *testing.T) {
+		doc := NewDocument(curatedBrewfile)
+		assert.False(t, doc.Remove("brew:nope"))
+		assert.Equal(t, curatedBrewfile, doc.String())
+	})
+}
+
+func TestDocument_Update(t *testing.T) {
+	t.Run("rewrites changed entry only", func(t *testing.T) {
+		doc := NewDocument(curatedBrewfile)
+		assert.True(t, doc.Update(NewPackage(TypeBrew, "kubectl").WithOption("link", "false")))
+		assert.Contains(t, doc.String(), "brew \"kubectl\", link: false # pinned by platform team\n")
+		assert.Contains(t, doc.String(), "# --- work tools ---\nbrew \"awscli\"\n")
+	})
+
+	t.Run("unchanged multi-line entry is untouched", func(t *testing.T) {
+		doc := NewDocument(curatedBrewfile)
+		pkg := NewPackage(TypeCask, "firefox").WithValue("args",
+			HashValue(HashEntry{Key: "appdir", Value: StringValue("~/Applications")}))
+		assert.True(t, doc.Update(pkg))
+		assert.Equal(t, curatedBrewfile, doc.String())
+	})
+
+	t.Run("missing package", func(t *testing.T) {
+		doc := NewDocument(curatedBrewfile)
+		assert.False(t, doc.Update(NewPackage(TypeBrew, "nope")))
+	})
+}
+
+func TestDocument_Sync(t *testing.T) {
+	t.Run("minimal edits", func(t *testing.T) {
+		doc := NewDocument(curatedBrewfile)
+		pkgs := doc.Packages().Filter(TypeTap, TypeBrew, TypeCask)
+
+		var wanted Packages
+		for _, p := range pkgs {
+			if p.Name != "yt-dlp" {
+				wanted = append(wanted, p)
+			}
+		}
+		wanted = append(wanted, NewPackage(TypeBrew, "helm"))
+
+		doc.Sync(wanted)
+		assert.NotContains(t, doc.String(), "yt-dlp")
+		assert.Contains(t, doc.String(), "# --- personal ---\nbrew \"ffmpeg\"\n")
+		assert.Contains(t, doc.String(), "brew \"awscli\"\nbrew \"helm\"\n")
+		assert.Contains(t, doc.String(), "# Brewfile for the work laptop\n")
+	})
+
+	t.Run("removes duplicates", func(t *testing.T) {
+		doc := NewDocument("brew \"git\"\nbrew \"jq\"\nbrew \"git\"\n")
+		doc.Sync(Packages{NewPackage(TypeBrew, "git"), NewPackage(TypeBrew, "jq")})
+		assert.Equal(t, "brew \"git\"\nbrew \"jq\"\n", doc.String())
+	})
+
+	t.Run("empty document matches writer", func(t *testing.T) {
+		pkgs := Packages{
+			NewPackage(TypeTap, "homebrew/bundle"),
+			NewPackage(TypeBrew, "git"),
+			NewPackage(TypeBrew, "jq"),
+			NewPackage(TypeCask, "firefox"),
+			NewPackage(TypeVSCode, "golang.go"),
+			NewPackage(TypeGo, "golang.org/x/tools/gopls"),
+		}
+		pkgs[2].Description = "JSON processor"
+
+		doc := NewDocument("")
+		doc.Sync(pkgs)
+		assert.Equal(t, NewWriter(pkgs).Format(), doc.String())
+	})
+}
+
+func TestSyncFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "Brewfile")
+	require.NoError(t, os.WriteFile(path, []byte(curatedBrewfile), 0644))
+
+	doc, err := LoadDocument(path)
+	require.NoError(t, err)
+	pkgs := doc.Packages()
+
+	// Syncing the same packages leaves the file untouched
+	require.NoError(t, SyncFile(path, pkgs))
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, curatedBrewfile, string(content))
+
+	// Missing files are created with the writer layout
+	newPath := filepath.Join(tmpDir, "new", "Brewfile")
+	require.NoError(t, os.MkdirAll(filepath.Dir(newPath), 0755))
+	require.NoError(t, SyncFile(newPath, pkgs))
+	content, err = os.ReadFile(newPath)
+	require.NoError(t, err)
+	assert.Equal(t, NewWriter(pkgs).Format(), string(content))
+}
+
+func TestDocument_SyncKeepsIncludes(t *testing.T) {
+	content := "include \"common.Brewfile\"\n\nbrew \"git\"\n"
+	doc := NewDocument(content)
+	doc.Sync(Packages{NewPackage(TypeBrew, "jq")})
+	assert.Equal(t, "include \"common.Brewfile\"\n\nbrew \"jq\"\n", doc.String())
+}
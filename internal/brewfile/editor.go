@@ -0,0 +1,128 @@
+package brewfile
+
+import "fmt"
+
+// Extension galleries an editor installs from
+const (
+	GalleryMarketplace = "marketplace"
+	GalleryOpenVSX     = "openvsx"
+)
+
+// PreReleaseOption marks an editor extension installed from its pre-release channel
+const PreReleaseOption = "pre_release"
+
+// Editor is a VS Code-family editor whose extensions are managed through
+// its command line. VS Code, Cursor and Antigravity are built in; other
+// editors such as VSCodium or Windsurf are declared under editors in
+// config.yaml and become package types named after their keyword.
+type Editor struct {
+	Name    string `yaml:"name" mapstructure:"name"`       // display name, e.g. VSCodium
+	Command string `yaml:"command" mapstructure:"command"` // CLI, e.g. codium
+	Keyword string `yaml:"keyword" mapstructure:"keyword"` // Brewfile directive, e.g. vscodium
+	// ExtensionsDir lists installed extensions without starting the editor,
+	// e.g. ~/.vscode-oss/extensions; it also records pre-release installs
+	ExtensionsDir string `yaml:"extensions_dir,omitempty" mapstructure:"extensions_dir"`
+	// Gallery is where newer versions are looked up: marketplace or openvsx (default)
+	Gallery string `yaml:"gallery,omitempty" mapstructure:"gallery"`
+	Icon    string `yaml:"icon,omitempty" mapstructure:"icon"`
+}
+
+// builtinEditors are the editors with their own built-in package types
+var builtinEditors = map[PackageType]Editor{
+	TypeVSCode: {
+		Name:          "VSCode",
+		Command:       "code",
+		Keyword:       "vscode",
+		ExtensionsDir: "~/.vscode/extensions",
+		Gallery:       GalleryMarketplace,
+	},
+	TypeCursor: {
+		Name:          "Cursor",
+		Command:       "cursor",
+		Keyword:       "cursor",
+		ExtensionsDir: "~/.cursor/extensions",
+		Gallery:       GalleryOpenVSX,
+	},
+	TypeAntigravity: {
+		Name:          "Antigravity",
+		Command:       "agy",
+		Keyword:       "antigravity",
+		ExtensionsDir: "~/.antigravity/extensions",
+		Gallery:       GalleryOpenVSX,
+	},
+}
+
+// Type returns the package type of the editor's extensions
+func (e Editor) Type() PackageType {
+	return PackageType(e.Keyword)
+}
+
+// DisplayName returns the editor's name, or its keyword when it has none
+func (e Editor) DisplayName() string {
+	if e.Name != "" {
+		return e.Name
+	}
+	return e.Keyword
+}
+
+// Validate checks that the editor has a usable keyword, a command and a
+// known gallery
+func (e Editor) Validate() error {
+	def := CustomType{Name: e.Keyword, Editor: &e}
+	if err := def.Validate(); err != nil {
+		return fmt.Errorf("editor %q: %w", e.Keyword, err)
+	}
+	if e.Command == "" {
+		return fmt.Errorf("editor %q has no command", e.Keyword)
+	}
+	switch e.Gallery {
+	case "", GalleryMarketplace, GalleryOpenVSX:
+	default:
+		return fmt.Errorf("editor %q has an unknown gallery %q (want %q or %q)", e.Keyword, e.Gallery, GalleryMarketplace, GalleryOpenVSX)
+	}
+	return nil
+}
+
+// RegisterEditor makes an editor's extensions a package type known to the
+// parser, writer and the rest of BrewSync, like a custom type
+func RegisterEditor(e Editor) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+	if e.Gallery == "" {
+		e.Gallery = GalleryOpenVSX
+	}
+	customMu.Lock()
+	defer customMu.Unlock()
+	customTypes[e.Type()] = CustomType{Name: e.Keyword, Description: e.DisplayName() + " extensions", Icon: e.Icon, Editor: &e}
+	return nil
+}
+
+// LookupEditor returns the editor whose extensions have package type t
+func LookupEditor(t PackageType) (Editor, bool) {
+	if e, ok := builtinEditors[t]; ok {
+		return e, true
+	}
+	if c, ok := LookupCustomType(t); ok && c.Editor != nil {
+		return *c.Editor, true
+	}
+	return Editor{}, false
+}
+
+// IsEditorType reports whether t holds editor extensions
+func IsEditorType(t PackageType) bool {
+	_, ok := LookupEditor(t)
+	return ok
+}
+
+// Editors returns the built-in editors followed by the registered ones
+// sorted by keyword
+func Editors() []Editor {
+	editors := []Editor{builtinEditors[TypeVSCode], builtinEditors[TypeCursor], builtinEditors[TypeAntigravity]}
+	for _, t := range CustomTypes() {
+		if c, ok := LookupCustomType(t); ok && c.Editor != nil {
+			editors = append(editors, *c.Editor)
+		}
+	}
+	return editors
+}
@@ -0,0 +1,90 @@
+package brewfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// registerCodium registers a VSCodium editor for the duration of a test
+func registerCodium(t *testing.T) {
+	t.Helper()
+	require.NoError(t, RegisterEditor(Editor{
+		Name:          "VSCodium",
+		Command:       "codium",
+		Keyword:       "vscodium",
+		ExtensionsDir: "~/.vscode-oss/extensions",
+	}))
+	t.Cleanup(func() { UnregisterType("vscodium") })
+}
+
+func TestEditor_Validate(t *testing.T) {
+	valid := Editor{Name: "Windsurf", Command: "windsurf", Keyword: "windsurf"}
+
+	tests := []struct {
+		name    string
+		modify  func(e *Editor)
+		wantErr string
+	}{
+		{"valid", func(e *Editor) {}, ""},
+		{"marketplace", func(e *Editor) { e.Gallery = GalleryMarketplace }, ""},
+		{"bad keyword", func(e *Editor) { e.Keyword = "Wind-Surf" }, "invalid custom type name"},
+		{"built-in directive", func(e *Editor) { e.Keyword = "vscode" }, "clashes with a built-in"},
+		{"missing command", func(e *Editor) { e.Command = "" }, "has no command"},
+		{"unknown gallery", func(e *Editor) { e.Gallery = "store" }, "unknown gallery"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := valid
+			tt.modify(&e)
+			err := e.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLookupEditor(t *testing.T) {
+	vscode, ok := LookupEditor(TypeVSCode)
+	require.True(t, ok)
+	assert.Equal(t, "code", vscode.Command)
+	assert.Equal(t, GalleryMarketplace, vscode.Gallery)
+
+	antigravity, ok := LookupEditor(TypeAntigravity)
+	require.True(t, ok)
+	assert.Equal(t, "agy", antigravity.Command)
+
+	assert.False(t, IsEditorType(TypeBrew))
+	assert.False(t, IsEditorType("vscodium"))
+	assert.Len(t, Editors(), 3)
+}
+
+func TestRegisterEditor(t *testing.T) {
+	registerCodium(t)
+
+	editor, ok := LookupEditor("vscodium")
+	require.True(t, ok)
+	assert.Equal(t, "codium", editor.Command)
+	assert.Equal(t, GalleryOpenVSX, editor.Gallery, "editors default to Open VSX")
+	assert.True(t, IsCustomType("vscodium"))
+	assert.Equal(t, PackageType("vscodium"), Editors()[3].Type())
+
+	content := "vscode \"golang.go\"\nvscodium \"golang.Go\", pre_release: true\n"
+	pkgs, err := NewStrictParser().ParseString(content)
+	require.NoError(t, err)
+	require.Len(t, pkgs, 2)
+	assert.Equal(t, "vscodium:golang.Go", pkgs[1].ID())
+	assert.True(t, pkgs[1].Options[PreReleaseOption].Bool)
+
+	out := NewWriter(pkgs).Format()
+	assert.Contains(t, out, "# vscodium (brewsync extension)\nvscodium \"golang.Go\", pre_release: true\n")
+
+	name, reason := NewNormalizer(DefaultNameTable()).Canonical(pkgs[1])
+	assert.Equal(t, "golang.go", name, "extension ids are case-insensitive")
+	assert.Equal(t, ReasonCase, reason)
+}
@@ -0,0 +1,339 @@
+package brewfile
+
+import (
+	"fmt"
+	"strings"
+)
+
+// tokenKind identifies the type of a lexical token
+type tokenKind int
+
+const (
+	tokEOF      tokenKind = iota
+	tokNewline            // end of a logical line
+	tokComment            // # ... (text holds the comment body)
+	tokIdent              // bare identifier, e.g. brew, true, nil
+	tokLabel              // hash label, e.g. link: (text holds "link")
+	tokString             // "..." or '...' (text holds the decoded string)
+	tokSymbol             // :name (text holds "name")
+	tokInt                // integer literal
+	tokComma              // ,
+	tokArrow              // =>
+	tokLBracket           // [
+	tokRBracket           // ]
+	tokLBrace             // {
+	tokRBrace             // }
+	tokLParen             // (
+	tokRParen             // )
+	tokIllegal            // anything the lexer could not understand
+)
+
+// String returns a human-readable name for the token kind
+func (k tokenKind) String() string {
+	switch k {
+	case tokEOF:
+		return "end of file"
+	case tokNewline:
+		return "newline"
+	case tokComment:
+		return "comment"
+	case tokIdent:
+		return "identifier"
+	case tokLabel:
+		return "label"
+	case tokString:
+		return "string"
+	case tokSymbol:
+		return "symbol"
+	case tokInt:
+		return "integer"
+	case tokComma:
+		return "','"
+	case tokArrow:
+		return "'=>'"
+	case tokLBracket:
+		return "'['"
+	case tokRBracket:
+		return "']'"
+	case tokLBrace:
+		return "'{'"
+	case tokRBrace:
+		return "'}'"
+	case tokLParen:
+		return "'('"
+	case tokRParen:
+		return "')'"
+	default:
+		return "invalid token"
+	}
+}
+
+// position is a location in Brewfile source
+type position struct {
+	offset int // byte offset
+	line   int // 1-based line
+	col    int // 1-based column (in bytes)
+}
+
+// token is a single lexical token
+type token struct {
+	kind tokenKind
+	text string
+	pos  position
+	end  int // byte offset just past the token
+	err  string
+}
+
+// lexer splits Brewfile source into tokens
+type lexer struct {
+	src  string
+	off  int
+	line int
+	col  int
+}
+
+// newLexer creates a lexer over the given source
+func newLexer(src string) *lexer {
+	return &lexer{src: src, line: 1, col: 1}
+}
+
+// tokenize returns all tokens of the source, ending with tokEOF
+func tokenize(src string) []token {
+	l := newLexer(src)
+	var tokens []token
+	for {
+		tok := l.next()
+		tokens = append(tokens, tok)
+		if tok.kind == tokEOF {
+			return tokens
+		}
+	}
+}
+
+func (l *lexer) pos() position {
+	return position{offset: l.off, line: l.line, col: l.col}
+}
+
+func (l *lexer) peek(n int) byte {
+	if l.off+n >= len(l.src) {
+		return 0
+	}
+	return l.src[l.off+n]
+}
+
+func (l *lexer) advance() byte {
+	c := l.src[l.off]
+	l.off++
+	if c == '\n' {
+		l.line++
+		l.col = 1
+	} else {
+		l.col++
+	}
+	return c
+}
+
+// next returns the next token
+func (l *lexer) next() token {
+	l.skipSpace()
+	start := l.pos()
+
+	if l.off >= len(l.src) {
+		return token{kind: tokEOF, pos: start, end: l.off}
+	}
+
+	c := l.peek(0)
+	switch {
+	case c == '\n':
+		l.advance()
+		return token{kind: tokNewline, pos: start, end: l.off}
+	case c == '#':
+		for l.off < len(l.src) && l.peek(0) != '\n' {
+			l.advance()
+		}
+		body := strings.TrimRight(l.src[start.offset+1:l.off], "\r")
+		return token{kind: tokComment, text: strings.TrimSpace(body), pos: start, end: l.off}
+	case c == '"' || c == '\'':
+		return l.lexString(start)
+	case c == ':':
+		return l.lexSymbol(start)
+	case c == '-' || isDigit(c):
+		if c == '-' && !isDigit(l.peek(1)) {
+			break
+		}
+		return l.lexInt(start)
+	case isIdentStart(c):
+		return l.lexIdent(start)
+	case c == '=' && l.peek(1) == '>':
+		l.advance()
+		l.advance()
+		return token{kind: tokArrow, text: "=>", pos: start, end: l.off}
+	}
+
+	l.advance()
+	kind := tokIllegal
+	switch c {
+	case ',':
+		kind = tokComma
+	case '[':
+		kind = tokLBracket
+	case ']':
+		kind = tokRBracket
+	case '{':
+		kind = tokLBrace
+	case '}':
+		kind = tokRBrace
+	case '(':
+		kind = tokLParen
+	case ')':
+		kind = tokRParen
+	}
+	tok := token{kind: kind, text: string(c), pos: start, end: l.off}
+	if kind == tokIllegal {
+		tok.err = fmt.Sprintf("unexpected character %q", c)
+	}
+	return tok
+}
+
+// skipSpace skips blanks and backslash line continuations
+func (l *lexer) skipSpace() {
+	for l.off < len(l.src) {
+		c := l.peek(0)
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			l.advance()
+		case c == '\\' && l.peek(1) == '\n':
+			l.advance()
+			l.advance()
+		case c == '\\' && l.peek(1) == '\r' && l.peek(2) == '\n':
+			l.advance()
+			l.advance()
+			l.advance()
+		default:
+			return
+		}
+	}
+}
+
+// lexString lexes a single- or double-quoted string, decoding escapes.
+// A string immediately followed by a single colon is a label ("key": value).
+func (l *lexer) lexString(start position) token {
+	quote := l.advance()
+	var sb strings.Builder
+	for {
+		if l.off >= len(l.src) || l.peek(0) == '\n' {
+			return token{kind: tokIllegal, pos: start, end: l.off, err: "unterminated string"}
+		}
+		c := l.advance()
+		if c == quote {
+			break
+		}
+		if c != '\\' || l.off >= len(l.src) {
+			sb.WriteByte(c)
+			continue
+		}
+		esc := l.advance()
+		if quote == '\'' {
+			// Single-quoted strings only escape the quote and backslash
+			if esc != '\'' && esc != '\\' {
+				sb.WriteByte('\\')
+			}
+			sb.WriteByte(esc)
+			continue
+		}
+		switch esc {
+		case 'n':
+			sb.WriteByte('\n')
+		case 't':
+			sb.WriteByte('\t')
+		case 'r':
+			sb.WriteByte('\r')
+		case '0':
+			sb.WriteByte(0)
+		case 's':
+			sb.WriteByte(' ')
+		default:
+			sb.WriteByte(esc)
+		}
+	}
+
+	if l.peek(0) == ':' && l.peek(1) != ':' {
+		l.advance()
+		return token{kind: tokLabel, text: sb.String(), pos: start, end: l.off}
+	}
+	return token{kind: tokString, text: sb.String(), pos: start, end: l.off}
+}
+
+// lexSymbol lexes :name or :"quoted name"
+func (l *lexer) lexSymbol(start position) token {
+	l.advance() // ':'
+	if l.peek(0) == '"' || l.peek(0) == '\'' {
+		str := l.lexString(l.pos())
+		if str.kind == tokIllegal {
+			str.pos = start
+			return str
+		}
+		return token{kind: tokSymbol, text: str.text, pos: start, end: l.off}
+	}
+	if !isIdentStart(l.peek(0)) {
+		return token{kind: tokIllegal, text: ":", pos: start, end: l.off, err: "expected symbol name after ':'"}
+	}
+	nameStart := l.off
+	for l.off < len(l.src) && isIdentChar(l.peek(0)) {
+		l.advance()
+	}
+	if c := l.peek(0); c == '?' || c == '!' || c == '=' {
+		if c != '=' || l.peek(1) != '>' {
+			l.advance()
+		}
+	}
+	return token{kind: tokSymbol, text: l.src[nameStart:l.off], pos: start, end: l.off}
+}
+
+// lexInt lexes an optionally negative integer with optional underscores
+func (l *lexer) lexInt(start position) token {
+	if l.peek(0) == '-' {
+		l.advance()
+	}
+	for l.off < len(l.src) && (isDigit(l.peek(0)) || l.peek(0) == '_') {
+		l.advance()
+	}
+	if isIdentChar(l.peek(0)) || l.peek(0) == '.' {
+		// Something like 1.5 or 12abc: consume it and report it as invalid
+		for l.off < len(l.src) && (isIdentChar(l.peek(0)) || l.peek(0) == '.') {
+			l.advance()
+		}
+		text := l.src[start.offset:l.off]
+		return token{kind: tokIllegal, text: text, pos: start, end: l.off, err: fmt.Sprintf("invalid number %q", text)}
+	}
+	text := strings.ReplaceAll(l.src[start.offset:l.off], "_", "")
+	return token{kind: tokInt, text: text, pos: start, end: l.off}
+}
+
+// lexIdent lexes an identifier or a label (identifier followed by a single colon)
+func (l *lexer) lexIdent(start position) token {
+	for l.off < len(l.src) && isIdentChar(l.peek(0)) {
+		l.advance()
+	}
+	if c := l.peek(0); c == '?' || c == '!' {
+		l.advance()
+	}
+	text := l.src[start.offset:l.off]
+	if l.peek(0) == ':' && l.peek(1) != ':' {
+		l.advance()
+		return token{kind: tokLabel, text: text, pos: start, end: l.off}
+	}
+	return token{kind: tokIdent, text: text, pos: start, end: l.off}
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
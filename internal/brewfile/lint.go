@@ -0,0 +1,349 @@
+package brewfile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Severity indicates how serious a diagnostic is
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Diagnostic is a problem found in a Brewfile
+type Diagnostic struct {
+	File     string   `json:"file,omitempty"`
+	Line     int      `json:"line"`
+	Column   int      `json:"column"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+}
+
+// String formats the diagnostic as file:line:col: severity: message
+func (d Diagnostic) String() string {
+	loc := fmt.Sprintf("%d:%d", d.Line, d.Column)
+	if d.File != "" {
+		loc = d.File + ":" + loc
+	}
+	return fmt.Sprintf("%s: %s: %s", loc, d.Severity, d.Message)
+}
+
+// Diagnostics is a list of diagnostics in source order
+type Diagnostics []Diagnostic
+
+// HasErrors reports whether any diagnostic is an error
+func (ds Diagnostics) HasErrors() bool {
+	return ds.Count(SeverityError) > 0
+}
+
+// Count returns the number of diagnostics with the given severity
+func (ds Diagnostics) Count(severity Severity) int {
+	count := 0
+	for _, d := range ds {
+		if d.Severity == severity {
+			count++
+		}
+	}
+	return count
+}
+
+// ParseError is returned by a strict parser when a Brewfile has errors
+type ParseError struct {
+	Diagnostics Diagnostics
+}
+
+func (e *ParseError) Error() string {
+	var errs Diagnostics
+	for _, d := range e.Diagnostics {
+		if d.Severity == SeverityError {
+			errs = append(errs, d)
+		}
+	}
+	if len(errs) == 0 {
+		return "invalid Brewfile"
+	}
+	if len(errs) == 1 {
+		return errs[0].String()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", errs[0], len(errs)-1)
+}
+
+// unsupportedDirectives are valid brew bundle directives that BrewSync ignores
+var unsupportedDirectives = map[string]bool{
+	"cask_args": true,
+	"whalebrew": true,
+	"flatpak":   true,
+	"cargo":     true,
+}
+
+// optionKinds lists the known options per package type and their allowed kinds
+var optionKinds = map[PackageType]map[string][]ValueKind{
+	TypeTap: {
+		"clone_target":      {KindString},
+		"force_auto_update": {KindBool},
+	},
+	TypeBrew: {
+		"args":            {KindList},
+		"link":            {KindBool, KindSymbol},
+		"restart_service": {KindBool, KindSymbol},
+		"start_service":   {KindBool},
+		"conflicts_with":  {KindList},
+		"postinstall":     {KindString},
+		"version_file":    {KindString},
+	},
+	TypeCask: {
+		"args":        {KindHash},
+		"greedy":      {KindBool},
+		"postinstall": {KindString},
+	},
+	TypeMas: {
+		"id": {KindInt},
+	},
+	TypeGo: {
+		"version": {KindString},
+	},
+}
+
+// editorOptions lists the options every editor type takes, including
+// editors registered from the config
+var editorOptions = map[string][]ValueKind{
+	PreReleaseOption: {KindBool},
+}
+
+// knownOptions returns the known options of a package type
+func knownOptions(t PackageType) map[string][]ValueKind {
+	if IsEditorType(t) {
+		return editorOptions
+	}
+	return optionKinds[t]
+}
+
+// LintFile checks a Brewfile on disk and returns its diagnostics
+func LintFile(path string) (Diagnostics, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	diags := lint(string(data), filepath.Dir(path))
+	for i := range diags {
+		diags[i].File = path
+	}
+	return diags, nil
+}
+
+// Lint checks Brewfile content for syntax errors, unknown directives,
+// duplicate entries, malformed options, mas entries without ids and
+// description comments that are not attached to any package
+func Lint(content string) Diagnostics {
+	return lint(content, "")
+}
+
+// lint checks content; when dir is set, included files must exist in it
+func lint(content, dir string) Diagnostics {
+	var diags Diagnostics
+	report := func(pos position, severity Severity, format string, args ...interface{}) {
+		diags = append(diags, Diagnostic{
+			Line:     pos.line,
+			Column:   pos.col,
+			Severity: severity,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	seen := make(map[string]int) // package ID -> line
+	var pending *node            // comment waiting to become a description
+	orphan := func() {
+		if pending != nil {
+			report(pending.pos, SeverityWarning, "description comment %q is not attached to any package", pending.comment)
+			pending = nil
+		}
+	}
+
+	nodes := parseSyntax(content)
+	for i := range nodes {
+		n := &nodes[i]
+		switch n.kind {
+		case nodeComment:
+			pending = n
+
+		case nodeInvalid:
+			report(n.err.pos, SeverityError, "%s", n.err.msg)
+			orphan()
+
+		case nodeEntry:
+			e := n.entry
+			if e.keyword == includeKeyword {
+				pending = nil
+				path, ok := includeTarget(e)
+				if !ok || len(e.args) != 1 || len(e.options) > 0 {
+					report(e.start, SeverityError, "include requires a single Brewfile path")
+					continue
+				}
+				if dir != "" {
+					if _, err := os.Stat(IncludePath(dir, path)); err != nil {
+						report(e.start, SeverityError, "included file %q not found", path)
+					}
+				}
+				continue
+			}
+			if _, ok := typeForKeyword(e.keyword); !ok {
+				if unsupportedDirectives[e.keyword] {
+					report(e.start, SeverityWarning, "directive %q is not supported by brewsync and will be ignored", e.keyword)
+					pending = nil
+					continue
+				}
+				msg := fmt.Sprintf("unknown directive %q", e.keyword)
+				if s := suggestKeyword(e.keyword); s != "" {
+					msg += fmt.Sprintf(" (did you mean %q?)", s)
+				}
+				report(e.start, SeverityError, "%s", msg)
+				orphan()
+				continue
+			}
+
+			before := len(diags)
+			lintEntry(e, report)
+			pkg, ok := entryToPackage(e)
+			if !ok {
+				if len(diags) == before {
+					report(e.start, SeverityError, "%s entry is invalid", e.keyword)
+				}
+				orphan()
+				continue
+			}
+			pending = nil
+
+			if line, dup := seen[pkg.ID()]; dup {
+				report(e.start, SeverityWarning, "duplicate entry %s (first declared on line %d)", pkg.ID(), line)
+			} else {
+				seen[pkg.ID()] = e.start.line
+			}
+		}
+	}
+	orphan()
+
+	return diags
+}
+
+// lintEntry validates the arguments and options of a known directive
+func lintEntry(e *entry, report func(position, Severity, string, ...interface{})) {
+	t, _ := typeForKeyword(e.keyword)
+
+	if len(e.args) == 0 {
+		report(e.start, SeverityError, "%s entry requires a name", e.keyword)
+		return
+	}
+	if e.args[0].Kind != KindString {
+		report(e.start, SeverityError, "%s name must be a string, got %s", e.keyword, e.args[0].Kind)
+		return
+	}
+
+	maxArgs := 1
+	if t == TypeTap {
+		maxArgs = 2
+		if len(e.args) > 1 && e.args[1].Kind != KindString {
+			report(e.start, SeverityError, "tap URL must be a string, got %s", e.args[1].Kind)
+		}
+	}
+	if len(e.args) > maxArgs {
+		report(e.start, SeverityError, "%s entry has %d unexpected arguments", e.keyword, len(e.args)-maxArgs)
+	}
+
+	known := knownOptions(t)
+	for i, opt := range e.options {
+		pos := e.start
+		if i < len(e.optPos) {
+			pos = e.optPos[i]
+		}
+
+		kinds, ok := known[opt.Key]
+		if !ok {
+			if len(known) == 0 {
+				report(pos, SeverityWarning, "%s entries do not take options; %q is ignored", e.keyword, opt.Key)
+			} else {
+				report(pos, SeverityWarning, "unknown option %q for %s", opt.Key, e.keyword)
+			}
+			continue
+		}
+		if !kindAllowed(opt.Value.Kind, kinds) {
+			report(pos, SeverityError, "malformed option %q: expected %s, got %s", opt.Key, joinKinds(kinds), opt.Value.Kind)
+		}
+	}
+
+	if t == TypeMas {
+		hasID := false
+		for _, opt := range e.options {
+			if opt.Key == "id" {
+				hasID = true
+			}
+		}
+		if !hasID {
+			report(e.start, SeverityError, "mas entry %q has no id", e.args[0].Str)
+		}
+	}
+}
+
+func kindAllowed(k ValueKind, kinds []ValueKind) bool {
+	for _, allowed := range kinds {
+		if k == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func joinKinds(kinds []ValueKind) string {
+	names := make([]string, len(kinds))
+	for i, k := range kinds {
+		names[i] = k.String()
+	}
+	return strings.Join(names, " or ")
+}
+
+// suggestKeyword returns the closest known directive for a likely typo
+func suggestKeyword(word string) string {
+	candidates := []string{includeKeyword}
+	for kw := range keywordTypes {
+		candidates = append(candidates, kw)
+	}
+	for _, t := range CustomTypes() {
+		candidates = append(candidates, string(t))
+	}
+
+	best, bestDist := "", 3
+	for _, kw := range candidates {
+		if d := editDistance(word, kw); d < bestDist || (d == bestDist && kw < best) {
+			best, bestDist = kw, d
+		}
+	}
+	if bestDist > 2 || bestDist >= len(word) {
+		return ""
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,221 @@
+package brewfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLint_Clean(t *testing.T) {
+	content := `# Core tools
+tap "homebrew/bundle"
+brew "git"
+brew "postgresql@16", restart_service: :changed, link: false
+cask "firefox", args: { appdir: "~/Applications" }
+mas "Xcode", id: 497799835
+vscode "golang.go"
+go "golang.org/x/tools/gopls"
+`
+	assert.Empty(t, Lint(content))
+}
+
+func TestLint_Diagnostics(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		line     int
+		column   int
+		severity Severity
+		message  string
+	}{
+		{
+			name:     "unknown directive with suggestion",
+			content:  "brew \"jq\"\nbew \"git\"\n",
+			line:     2,
+			column:   1,
+			severity: SeverityError,
+			message:  `unknown directive "bew" (did you mean "brew"?)`,
+		},
+		{
+			name:     "unsupported directive",
+			content:  "cask_args appdir: \"~/Applications\"\n",
+			line:     1,
+			column:   1,
+			severity: SeverityWarning,
+			message:  `directive "cask_args" is not supported by brewsync and will be ignored`,
+		},
+		{
+			name:     "duplicate entry",
+			content:  "brew \"git\"\nbrew \"jq\"\nbrew \"git\"\n",
+			line:     3,
+			column:   1,
+			severity: SeverityWarning,
+			message:  "duplicate entry brew:git (first declared on line 1)",
+		},
+		{
+			name:     "malformed option",
+			content:  "brew \"git\", link: \"yes\"\n",
+			line:     1,
+			column:   13,
+			severity: SeverityError,
+			message:  `malformed option "link": expected bool or symbol, got string`,
+		},
+		{
+			name:     "mas without id",
+			content:  "mas \"Xcode\"\n",
+			line:     1,
+			column:   1,
+			severity: SeverityError,
+			message:  `mas entry "Xcode" has no id`,
+		},
+		{
+			name:     "orphan description",
+			content:  "brew \"git\"\n\n# removed package\n",
+			line:     3,
+			column:   1,
+			severity: SeverityWarning,
+			message:  `description comment "removed package" is not attached to any package`,
+		},
+		{
+			name:     "syntax error",
+			content:  "brew \"git\", args: [\"HEAD\"\n",
+			line:     1,
+			column:   19,
+			severity: SeverityError,
+			message:  "unclosed '['",
+		},
+		{
+			name:     "unterminated string",
+			content:  "\n  brew \"git\n",
+			line:     2,
+			column:   8,
+			severity: SeverityError,
+			message:  "unterminated string",
+		},
+		{
+			name:     "unknown option on editor types",
+			content:  "vscode \"golang.go\", version: \"1.0\"\n",
+			line:     1,
+			column:   21,
+			severity: SeverityWarning,
+			message:  `unknown option "version" for vscode`,
+		},
+		{
+			name:     "malformed pre_release",
+			content:  "cursor \"golang.go\", pre_release: \"yes\"\n",
+			line:     1,
+			column:   21,
+			severity: SeverityError,
+			message:  `malformed option "pre_release": expected bool, got string`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			diags := Lint(tc.content)
+			require.Len(t, diags, 1, "diagnostics: %v", diags)
+			assert.Equal(t, tc.line, diags[0].Line)
+			assert.Equal(t, tc.column, diags[0].Column)
+			assert.Equal(t, tc.severity, diags[0].Severity)
+			assert.Equal(t, tc.message, diags[0].Message)
+		})
+	}
+}
+
+func TestLint_DumpedGoTool(t *testing.T) {
+	pkg := NewPackage(TypeGo, "golang.org/x/tools/gopls").WithValue("version", StringValue("v0.15.0"))
+	content := NewWriter(Packages{pkg}).Format()
+	require.Contains(t, content, `go "golang.org/x/tools/gopls", version: "v0.15.0"`)
+	assert.Empty(t, Lint(content))
+
+	parsed, err := ParseContent(content)
+	require.NoError(t, err)
+	require.Len(t, parsed, 1)
+	assert.Equal(t, "v0.15.0", parsed[0].Options["version"].String())
+}
+
+func TestLint_PreReleaseEditors(t *testing.T) {
+	registerCodium(t)
+
+	pkgs := Packages{
+		NewPackage(TypeVSCode, "golang.go").WithValue(PreReleaseOption, BoolValue(true)),
+		NewPackage(TypeAntigravity, "golang.go").WithValue(PreReleaseOption, BoolValue(true)),
+		NewPackage("vscodium", "golang.go").WithValue(PreReleaseOption, BoolValue(true)),
+	}
+	content := NewWriter(pkgs).Format()
+	require.Contains(t, content, `vscodium "golang.go", pre_release: true`)
+	assert.Empty(t, Lint(content))
+}
+
+func TestLint_OrphanBeforeUnknownDirective(t *testing.T) {
+	diags := Lint("# Version control\nbew \"git\"\n")
+	require.Len(t, diags, 2)
+	assert.Equal(t, SeverityError, diags[0].Severity)
+	assert.Equal(t, 1, diags[1].Line)
+	assert.Equal(t, SeverityWarning, diags[1].Severity)
+}
+
+func TestLintFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "Brewfile")
+	require.NoError(t, os.WriteFile(path, []byte("bew \"git\"\n"), 0644))
+
+	diags, err := LintFile(path)
+	require.NoError(t, err)
+	require.Len(t, diags, 1)
+	assert.Equal(t, path+`:1:1: error: unknown directive "bew" (did you mean "brew"?)`, diags[0].String())
+
+	_, err = LintFile(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
+
+func TestStrictParser(t *testing.T) {
+	content := "brew \"git\"\nbew \"jq\"\nmas \"Xcode\"\n"
+
+	// Lenient parser skips the typo
+	packages, err := ParseContent(content)
+	require.NoError(t, err)
+	assert.Len(t, packages, 2)
+
+	_, err = NewStrictParser().ParseString(content)
+	require.Error(t, err)
+	var parseErr *ParseError
+	require.ErrorAs(t, err, &parseErr)
+	assert.Equal(t, 2, parseErr.Diagnostics.Count(SeverityError))
+	assert.Contains(t, err.Error(), "2:1: error: unknown directive")
+	assert.Contains(t, err.Error(), "and 1 more errors")
+
+	// Warnings alone do not fail strict parsing
+	packages, err = NewStrictParser().ParseString("brew \"git\"\nbrew \"git\"\n")
+	require.NoError(t, err)
+	assert.Len(t, packages, 2)
+}
+
+func TestSuggestKeyword(t *testing.T) {
+	assert.Equal(t, "brew", suggestKeyword("bew"))
+	assert.Equal(t, "cask", suggestKeyword("casks"))
+	assert.Equal(t, "vscode", suggestKeyword("vscod"))
+	assert.Empty(t, suggestKeyword("whatever"))
+	assert.Empty(t, suggestKeyword("x"))
+}
+
+func TestLint_Includes(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "Brewfile")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "common.Brewfile"), []byte(""), 0644))
+	require.NoError(t, os.WriteFile(path, []byte(`# Shared
+include "common.Brewfile"
+include "missing.Brewfile"
+include :oops
+`), 0644))
+
+	diags, err := LintFile(path)
+	require.NoError(t, err)
+	require.Len(t, diags, 2)
+	assert.Equal(t, 3, diags[0].Line)
+	assert.Equal(t, `included file "missing.Brewfile" not found`, diags[0].Message)
+	assert.Equal(t, 4, diags[1].Line)
+	assert.Equal(t, "include requires a single Brewfile path", diags[1].Message)
+}
@@ -0,0 +1,171 @@
+package brewfile
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"strings"
+)
+
+// LockEntry records the installed state of a single package
+type LockEntry struct {
+	Version string `json:"version,omitempty"`
+	Commit  string `json:"commit,omitempty"` // taps only
+}
+
+// Lock is the Brewfile.lock.json companion written by dump. It records the
+// installed version of every Brewfile entry, grouped by package type.
+type Lock struct {
+	Entries map[PackageType]map[string]LockEntry `json:"entries"`
+}
+
+// NewLock creates an empty lock
+func NewLock() *Lock {
+	return &Lock{Entries: make(map[PackageType]map[string]LockEntry)}
+}
+
+// BuildLock creates a lock for the given packages. versions and commits are
+// keyed by package ID; packages without a known version are still recorded.
+func BuildLock(packages Packages, versions, commits map[string]string) *Lock {
+	lock := NewLock()
+	for _, pkg := range packages {
+		id := lockID(pkg)
+		lock.Set(pkg, LockEntry{
+			Version: versions[id],
+			Commit:  commits[id],
+		})
+	}
+	return lock
+}
+
+// LockPath returns the lock file path for a Brewfile, e.g. Brewfile.lock.json
+func LockPath(brewfilePath string) string {
+	return brewfilePath + ".lock.json"
+}
+
+// LoadLock loads a lock file. A missing file yields an empty lock.
+func LoadLock(path string) (*Lock, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewLock(), nil
+		}
+		return nil, err
+	}
+
+	lock := NewLock()
+	if err := json.Unmarshal(data, lock); err != nil {
+		return nil, err
+	}
+	if lock.Entries == nil {
+		lock.Entries = make(map[PackageType]map[string]LockEntry)
+	}
+	return lock, nil
+}
+
+// Save writes the lock to the given path
+func (l *Lock) Save(path string) error {
+	data, err := json.MarshalIndent(l, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// Get returns the lock entry for a package
+func (l *Lock) Get(pkg Package) (LockEntry, bool) {
+	if l == nil {
+		return LockEntry{}, false
+	}
+	entry, ok := l.Entries[pkg.Type][lockName(pkg)]
+	return entry, ok
+}
+
+// Set records the lock entry for a package
+func (l *Lock) Set(pkg Package, entry LockEntry) {
+	if l.Entries[pkg.Type] == nil {
+		l.Entries[pkg.Type] = make(map[string]LockEntry)
+	}
+	l.Entries[pkg.Type][lockName(pkg)] = entry
+}
+
+// Len returns the number of locked packages
+func (l *Lock) Len() int {
+	count := 0
+	for _, entries := range l.Entries {
+		count += len(entries)
+	}
+	return count
+}
+
+// lockName returns the key a package is locked under. mas apps are keyed by
+// their App Store id so Brewfile entries (named by app) and installed apps
+// (named by id) resolve to the same entry.
+func lockName(pkg Package) string {
+	if pkg.Type == TypeMas {
+		if id, ok := pkg.Options["id"]; ok {
+			return id.String()
+		}
+	}
+	return pkg.Name
+}
+
+// lockID returns the package ID used to look up installed versions
+func lockID(pkg Package) string {
+	return string(pkg.Type) + ":" + lockName(pkg)
+}
+
+// VersionDrift describes a package installed at different versions on two machines
+type VersionDrift struct {
+	Package        Package `json:"-"`
+	SourceVersion  string  `json:"source"`
+	CurrentVersion string  `json:"current"`
+}
+
+// DiffVersions reports version drift for packages present on both machines.
+// Packages whose version is unknown on either side are skipped. Taps are
+// compared by commit.
+func DiffVersions(common Packages, source, current *Lock) []VersionDrift {
+	var drift []VersionDrift
+	for _, pkg := range common {
+		s, ok := source.Get(pkg)
+		if !ok {
+			continue
+		}
+		c, ok := current.Get(pkg)
+		if !ok {
+			continue
+		}
+
+		sv, cv := s.Version, c.Version
+		if pkg.Type == TypeTap {
+			sv, cv = shortCommit(s.Commit), shortCommit(c.Commit)
+		}
+		if sv == "" || cv == "" || sv == cv {
+			continue
+		}
+		drift = append(drift, VersionDrift{Package: pkg, SourceVersion: sv, CurrentVersion: cv})
+	}
+
+	sort.SliceStable(drift, func(i, j int) bool {
+		if drift[i].Package.Type != drift[j].Package.Type {
+			return typeRank(drift[i].Package.Type) < typeRank(drift[j].Package.Type)
+		}
+		return drift[i].Package.Name < drift[j].Package.Name
+	})
+	return drift
+}
+
+// VersionDrift reports version drift for the packages common to both sides
+func (d *DiffResult) VersionDrift(source, current *Lock) []VersionDrift {
+	return DiffVersions(d.Common, source, current)
+}
+
+// shortCommit abbreviates a git commit hash for display
+func shortCommit(commit string) string {
+	commit = strings.TrimSpace(commit)
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
@@ -0,0 +1,118 @@
+package brewfile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildLock(t *testing.T) {
+	packages := Packages{
+		NewPackage(TypeTap, "homebrew/core"),
+		NewPackage(TypeBrew, "git"),
+		NewPackage(TypeCask, "firefox"),
+		NewPackage(TypeMas, "Xcode").WithOption("id", "497799835"),
+		NewPackage(TypeGo, "golang.org/x/tools/gopls"),
+	}
+	versions := map[string]string{
+		"brew:git":                    "2.43.0",
+		"cask:firefox":                "121.0",
+		"mas:497799835":               "15.2",
+		"go:golang.org/x/tools/gopls": "v0.15.0",
+		"brew:unrelated":              "1.0",
+	}
+	commits := map[string]string{
+		"tap:homebrew/core": "0123456789abcdef0123456789abcdef01234567",
+	}
+
+	lock := BuildLock(packages, versions, commits)
+	assert.Equal(t, 5, lock.Len())
+
+	entry, ok := lock.Get(NewPackage(TypeBrew, "git"))
+	require.True(t, ok)
+	assert.Equal(t, "2.43.0", entry.Version)
+
+	entry, ok = lock.Get(NewPackage(TypeTap, "homebrew/core"))
+	require.True(t, ok)
+	assert.Equal(t, "0123456789abcdef0123456789abcdef01234567", entry.Commit)
+
+	// mas apps resolve by id regardless of the name they are declared with
+	entry, ok = lock.Get(NewPackage(TypeMas, "497799835").WithOption("id", "497799835"))
+	require.True(t, ok)
+	assert.Equal(t, "15.2", entry.Version)
+
+	_, ok = lock.Get(NewPackage(TypeBrew, "unrelated"))
+	assert.False(t, ok)
+}
+
+func TestLockSaveLoad(t *testing.T) {
+	dir := t.TempDir()
+	path := LockPath(filepath.Join(dir, "Brewfile"))
+	assert.Equal(t, filepath.Join(dir, "Brewfile.lock.json"), path)
+
+	lock := NewLock()
+	lock.Set(NewPackage(TypeBrew, "git"), LockEntry{Version: "2.43.0"})
+	lock.Set(NewPackage(TypeVSCode, "golang.go"), LockEntry{Version: "0.40.0"})
+	require.NoError(t, lock.Save(path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"brew": {`)
+	assert.Contains(t, string(data), `"version": "2.43.0"`)
+
+	loaded, err := LoadLock(path)
+	require.NoError(t, err)
+	assert.Equal(t, lock, loaded)
+
+	t.Run("missing file", func(t *testing.T) {
+		loaded, err := LoadLock(filepath.Join(dir, "missing.lock.json"))
+		require.NoError(t, err)
+		assert.Equal(t, 0, loaded.Len())
+	})
+
+	t.Run("invalid file", func(t *testing.T) {
+		bad := filepath.Join(dir, "bad.lock.json")
+		require.NoError(t, os.WriteFile(bad, []byte("{"), 0644))
+		_, err := LoadLock(bad)
+		assert.Error(t, err)
+	})
+}
+
+func TestDiffVersions(t *testing.T) {
+	git := NewPackage(TypeBrew, "git")
+	jq := NewPackage(TypeBrew, "jq")
+	wget := NewPackage(TypeBrew, "wget")
+	tap := NewPackage(TypeTap, "homebrew/cask")
+	ext := NewPackage(TypeVSCode, "golang.go")
+
+	source := NewLock()
+	source.Set(git, LockEntry{Version: "2.43.0"})
+	source.Set(jq, LockEntry{Version: "1.7"})
+	source.Set(wget, LockEntry{Version: "1.21"})
+	source.Set(tap, LockEntry{Commit: "aaaaaaaaaaaaaaaaaaaa"})
+	source.Set(ext, LockEntry{Version: "0.41.0"})
+
+	current := NewLock()
+	current.Set(git, LockEntry{Version: "2.42.0"})
+	current.Set(jq, LockEntry{Version: "1.7"})
+	current.Set(wget, LockEntry{})
+	current.Set(tap, LockEntry{Commit: "bbbbbbbbbbbbbbbbbbbb"})
+
+	result := Diff(Packages{ext, wget, jq, git, tap}, Packages{git, jq, wget, tap, ext})
+	drift := result.VersionDrift(source, current)
+
+	require.Len(t, drift, 2)
+	assert.Equal(t, tap, drift[0].Package)
+	assert.Equal(t, "aaaaaaaaaaaa", drift[0].SourceVersion)
+	assert.Equal(t, "bbbbbbbbbbbb", drift[0].CurrentVersion)
+	assert.Equal(t, git, drift[1].Package)
+	assert.Equal(t, "2.43.0", drift[1].SourceVersion)
+	assert.Equal(t, "2.42.0", drift[1].CurrentVersion)
+
+	t.Run("nil lock", func(t *testing.T) {
+		assert.Empty(t, DiffVersions(Packages{git}, source, nil))
+	})
+}
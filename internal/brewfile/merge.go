@@ -0,0 +1,479 @@
+package brewfile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ConflictKind describes why a package could not be merged automatically
+type ConflictKind string
+
+const (
+	ConflictChanged      ConflictKind = "both changed"  // both sides set an option or tap URL differently
+	ConflictModifyDelete ConflictKind = "modify/delete" // one side removed a package the other changed
+)
+
+// MergeConflict is a package both sides changed in incompatible ways.
+// Base, Ours and Theirs are nil when the package is absent on that side.
+type MergeConflict struct {
+	Kind   ConflictKind
+	Keys   []string // conflicting option keys and "url", for ConflictChanged
+	Base   *Package
+	Ours   *Package
+	Theirs *Package
+}
+
+// Package returns the conflicting package from whichever side has it
+func (c MergeConflict) Package() Package {
+	switch {
+	case c.Ours != nil:
+		return *c.Ours
+	case c.Theirs != nil:
+		return *c.Theirs
+	default:
+		return *c.Base
+	}
+}
+
+// String formats the conflict as "brew:git: both changed args"
+func (c MergeConflict) String() string {
+	msg := string(c.Kind)
+	if len(c.Keys) > 0 {
+		msg += " " + strings.Join(c.Keys, ", ")
+	}
+	return fmt.Sprintf("%s: %s", c.Package().ID(), msg)
+}
+
+// DirectiveConflict is a directive other than a package, such as an include
+// or cask_args, that both sides changed differently. Base, Ours and Theirs
+// hold its lines on each side, empty when absent.
+type DirectiveConflict struct {
+	Key    string // the directive keyword, or "include <path>"
+	Base   string
+	Ours   string
+	Theirs string
+}
+
+// String formats the conflict as "cask_args: both changed"
+func (c DirectiveConflict) String() string {
+	kind := ConflictChanged
+	if c.Base != "" && (c.Ours == "" || c.Theirs == "") {
+		kind = ConflictModifyDelete
+	}
+	return fmt.Sprintf("%s: %s", c.Key, kind)
+}
+
+// MergeResult is the outcome of a three-way merge
+type MergeResult struct {
+	// Packages is the merged package list. Conflicting packages are
+	// included using our side, or theirs when we removed them.
+	Packages  Packages
+	Conflicts []MergeConflict
+	// Directives are the conflicting non-package directives, from
+	// Merge3Content
+	Directives []DirectiveConflict
+	// Diagnostics are the errors found in the merged versions, with File
+	// set to "base", "ours" or "theirs", from Merge3Content
+	Diagnostics Diagnostics
+}
+
+// HasConflicts reports whether the merge needs manual resolution
+func (r *MergeResult) HasConflicts() bool {
+	return len(r.Conflicts) > 0 || len(r.Directives) > 0
+}
+
+// Merge3 merges two descendants of a common base Brewfile by package
+// identity. Additions and removals from either side are kept; descriptions,
+// names and options come from whichever side changed them. Only changes that
+// contradict each other (the same option set to different values, or a
+// removal of a package the other side changed) are reported as conflicts.
+// The result keeps our order, followed by packages only they added.
+func Merge3(base, ours, theirs Packages) *MergeResult {
+	n := DefaultNormalizer
+	baseSet := NewPackageSetFunc(n.Key, base...)
+	oursSet := NewPackageSetFunc(n.Key, ours...)
+	theirsSet := NewPackageSetFunc(n.Key, theirs...)
+
+	result := &MergeResult{}
+	for _, pkg := range oursSet.Union(theirsSet).Packages() {
+		b, inBase := baseSet.Lookup(pkg)
+		o, inOurs := oursSet.Lookup(pkg)
+		t, inTheirs := theirsSet.Lookup(pkg)
+
+		switch {
+		case inOurs && inTheirs:
+			if !inBase {
+				b = Package{Type: pkg.Type, Name: o.Name}
+			}
+			merged, keys := mergePackage(b, o, t)
+			result.Packages = append(result.Packages, merged)
+			if len(keys) > 0 {
+				result.Conflicts = append(result.Conflicts, newConflict(ConflictChanged, keys, b, inBase, &o, &t))
+			}
+
+		case inOurs && !inBase:
+			result.Packages = append(result.Packages, o)
+
+		case inTheirs && !inBase:
+			result.Packages = append(result.Packages, t)
+
+		case inOurs:
+			// They removed it; keep it only if we changed what it installs
+			if sameInstall(b, o) {
+				continue
+			}
+			result.Packages = append(result.Packages, o)
+			result.Conflicts = append(result.Conflicts, newConflict(ConflictModifyDelete, nil, b, true, &o, nil))
+
+		case inTheirs:
+			if sameInstall(b, t) {
+				continue
+			}
+			result.Packages = append(result.Packages, t)
+			result.Conflicts = append(result.Conflicts, newConflict(ConflictModifyDelete, nil, b, true, nil, &t))
+		}
+	}
+
+	return result
+}
+
+func newConflict(kind ConflictKind, keys []string, base Package, inBase bool, ours, theirs *Package) MergeConflict {
+	c := MergeConflict{Kind: kind, Keys: keys, Ours: ours, Theirs: theirs}
+	if inBase {
+		c.Base = &base
+	}
+	return c
+}
+
+// mergePackage merges the fields of a package present on both sides. It
+// returns the option keys (and "url") that could not be merged; our values
+// are used for those.
+func mergePackage(base, ours, theirs Package) (Package, []string) {
+	merged := ours
+
+	// Spellings, app names and descriptions do not change what gets
+	// installed, so when both sides changed them ours wins silently
+	merged.Name, _ = merge3(base.Name, ours.Name, theirs.Name)
+	merged.FullName, _ = merge3(base.FullName, ours.FullName, theirs.FullName)
+	merged.Description, _ = merge3(base.Description, ours.Description, theirs.Description)
+
+	var conflicts []string
+	var urlConflict bool
+	merged.URL, urlConflict = merge3(base.URL, ours.URL, theirs.URL)
+	if urlConflict {
+		conflicts = append(conflicts, ChangedURL)
+	}
+
+	keys := make(map[string]bool)
+	for _, opts := range []Options{base.Options, ours.Options, theirs.Options} {
+		for k := range opts {
+			keys[k] = true
+		}
+	}
+
+	options := make(Options)
+	for k := range keys {
+		b, o, t := optionRuby(base.Options, k), optionRuby(ours.Options, k), optionRuby(theirs.Options, k)
+		value, conflict := merge3(b, o, t)
+		if conflict {
+			conflicts = append(conflicts, k)
+		}
+		switch value {
+		case "":
+		case o:
+			options[k] = ours.Options[k]
+		default:
+			options[k] = theirs.Options[k]
+		}
+	}
+	if len(options) == 0 {
+		options = nil
+	}
+	merged.Options = options
+
+	sort.Strings(conflicts)
+	return merged, conflicts
+}
+
+// merge3 merges a single value. When both sides changed it differently,
+// ours is returned along with a conflict.
+func merge3(base, ours, theirs string) (string, bool) {
+	switch {
+	case ours == theirs, theirs == base:
+		return ours, false
+	case ours == base:
+		return theirs, false
+	default:
+		return ours, true
+	}
+}
+
+// optionRuby returns an option's value in Brewfile syntax, empty when unset
+func optionRuby(opts Options, key string) string {
+	v, ok := opts[key]
+	if !ok {
+		return ""
+	}
+	return v.Ruby()
+}
+
+// sameInstall reports whether two packages install the same thing,
+// ignoring descriptions and name spelling
+func sameInstall(a, b Package) bool {
+	return a.URL == b.URL && a.Options.Equal(b.Options)
+}
+
+// Merge3Content merges the content of three Brewfiles. The result keeps the
+// layout and comments of ours; conflicting entries are written between git
+// style conflict markers. Includes, other directives and lines that do not
+// parse are merged three-way by their text, and the parse errors of each
+// version are returned in the result's Diagnostics.
+func Merge3Content(base, ours, theirs string) (string, *MergeResult) {
+	p := NewParser()
+	basePkgs, _ := p.parse(base)
+	oursPkgs, _ := p.parse(ours)
+	theirsPkgs, _ := p.parse(theirs)
+
+	result := Merge3(basePkgs, oursPkgs, theirsPkgs)
+	for _, side := range []struct{ name, content string }{{"base", base}, {"ours", ours}, {"theirs", theirs}} {
+		for _, diag := range lint(side.content, "") {
+			if diag.Severity == SeverityError {
+				diag.File = side.name
+				result.Diagnostics = append(result.Diagnostics, diag)
+			}
+		}
+	}
+
+	doc := NewDocument(ours)
+	if strings.TrimSpace(ours) == "" {
+		doc = NewDocument(NewWriter(result.Packages).Format())
+	}
+	doc.Sync(result.Packages)
+	for _, pkg := range result.Packages {
+		doc.describe(pkg.ID(), pkg.Description)
+	}
+	for _, c := range result.Conflicts {
+		doc.markConflict(c)
+	}
+	result.Directives = doc.mergeDirectives(
+		NewDocument(base).directiveLines(), NewDocument(ours).directiveLines(), NewDocument(theirs).directiveLines())
+	return doc.String(), result
+}
+
+// directive is a top-level statement that does not declare a package: an
+// include, a directive such as cask_args, or a line that does not parse
+type directive struct {
+	key  string
+	node int
+}
+
+// directives returns the directives of the document in source order. They
+// are keyed by keyword, includes by their path and unparsable lines by
+// their text.
+func (d *Document) directives() []directive {
+	var directives []directive
+	for i, n := range d.nodes {
+		var key string
+		switch n.kind {
+		case nodeEntry:
+			if _, ok := d.packageAt(i); ok {
+				continue
+			}
+			key = n.entry.keyword
+			if path, ok := includeTarget(n.entry); ok {
+				key = "include " + path
+			}
+		case nodeInvalid:
+			key = strings.TrimSpace(d.src[n.pos.offset:n.end])
+		default:
+			continue
+		}
+		directives = append(directives, directive{key: key, node: i})
+	}
+	return directives
+}
+
+// directiveLines returns the text of each directive key, with the lines of
+// a repeated key joined in source order
+func (d *Document) directiveLines() map[string]string {
+	lines := make(map[string]string)
+	for _, dir := range d.directives() {
+		n := d.nodes[dir.node]
+		text := d.src[d.lineStart(n.pos.offset):n.end]
+		if prev, ok := lines[dir.key]; ok {
+			text = prev + "\n" + text
+		}
+		lines[dir.key] = text
+	}
+	return lines
+}
+
+// mergeDirectives applies their directive changes to the document, which
+// holds ours. Directives both sides changed differently are written between
+// conflict markers and returned.
+func (d *Document) mergeDirectives(base, ours, theirs map[string]string) []DirectiveConflict {
+	keys := make([]string, 0, len(ours)+len(theirs))
+	for _, dir := range d.directives() {
+		if _, ok := ours[dir.key]; ok && !contains(keys, dir.key) {
+			keys = append(keys, dir.key)
+		}
+	}
+	var added []string
+	for key := range theirs {
+		if _, ok := ours[key]; !ok {
+			added = append(added, key)
+		}
+	}
+	for key := range base {
+		if _, ok := ours[key]; !ok && !contains(added, key) {
+			added = append(added, key)
+		}
+	}
+	sort.Strings(added)
+	keys = append(keys, added...)
+
+	var conflicts []DirectiveConflict
+	for _, key := range keys {
+		value, conflict := merge3(base[key], ours[key], theirs[key])
+		if conflict {
+			c := DirectiveConflict{Key: key, Base: base[key], Ours: ours[key], Theirs: theirs[key]}
+			conflicts = append(conflicts, c)
+			value = conflictMarkers(c.Ours, c.Theirs)
+		}
+		if value != ours[key] {
+			d.replaceDirective(key, value)
+		}
+	}
+	return conflicts
+}
+
+// replaceDirective replaces the lines of a directive with text, removing
+// them when text is empty. A new directive goes after the last directive,
+// or above the first entry when there is none.
+func (d *Document) replaceDirective(key, text string) {
+	var at []int
+	for _, dir := range d.directives() {
+		if dir.key == key {
+			at = append(at, dir.node)
+		}
+	}
+
+	if len(at) == 0 {
+		if text == "" {
+			return
+		}
+		if dirs := d.directives(); len(dirs) > 0 {
+			d.insertAt(d.lineEnd(d.nodes[dirs[len(dirs)-1].node].end), text+"\n")
+			return
+		}
+		for i, n := range d.nodes {
+			if n.kind == nodeEntry {
+				d.insertAt(d.blockStart(i), text+"\n\n")
+				return
+			}
+		}
+		d.insertAt(len(d.src), text+"\n")
+		return
+	}
+
+	// Remove from the end so earlier offsets stay valid
+	first := d.lineStart(d.nodes[at[0]].pos.offset)
+	for j := len(at) - 1; j >= 0; j-- {
+		n := d.nodes[at[j]]
+		d.src = d.src[:d.lineStart(n.pos.offset)] + d.src[d.lineEnd(n.end):]
+	}
+	if text != "" {
+		d.src = d.src[:first] + text + "\n" + d.src[first:]
+	}
+	d.reparse()
+}
+
+// conflictMarkers writes both sides of a conflict between git style markers
+func conflictMarkers(ours, theirs string) string {
+	var sb strings.Builder
+	sb.WriteString("<<<<<<< " + conflictOursLabel + "\n")
+	if ours != "" {
+		sb.WriteString(ours + "\n")
+	}
+	sb.WriteString("=======\n")
+	if theirs != "" {
+		sb.WriteString(theirs + "\n")
+	}
+	sb.WriteString(">>>>>>> " + conflictTheirsLabel)
+	return sb.String()
+}
+
+// contains reports whether list holds s
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Conflict marker labels written around unresolved entries
+const (
+	conflictOursLabel   = "ours"
+	conflictTheirsLabel = "theirs"
+)
+
+// describe sets the description comment of an entry, rewriting or removing
+// its description line or adding one directly above it
+func (d *Document) describe(id, description string) {
+	i, _, ok := d.find(id)
+	if !ok {
+		return
+	}
+
+	// The parser attaches the closest preceding comment as the description
+	var current string
+	if i > 0 && d.nodes[i-1].kind == nodeComment {
+		current = d.nodes[i-1].comment
+	}
+	if current == description {
+		return
+	}
+
+	text := ""
+	if description != "" {
+		text = fmt.Sprintf("# %s\n", description)
+	}
+	if d.hasDescription(i) {
+		c := d.nodes[i-1]
+		d.src = d.src[:d.lineStart(c.pos.offset)] + text + d.src[d.lineEnd(c.end):]
+	} else if text != "" {
+		d.insertAt(d.lineStart(d.nodes[i].pos.offset), text)
+		return
+	} else {
+		return
+	}
+	d.reparse()
+}
+
+// markConflict replaces a conflicting entry with both sides of the conflict
+// between git style conflict markers
+func (d *Document) markConflict(c MergeConflict) {
+	key := DefaultNormalizer.Key(c.Package())
+	for i := range d.nodes {
+		pkg, ok := d.packageAt(i)
+		if !ok || DefaultNormalizer.Key(pkg) != key {
+			continue
+		}
+
+		var ours, theirs string
+		if c.Ours != nil {
+			ours = formatPackage(*c.Ours)
+		}
+		if c.Theirs != nil {
+			theirs = formatPackage(*c.Theirs)
+		}
+
+		start := d.lineStart(d.nodes[i].pos.offset)
+		d.src = d.src[:start] + conflictMarkers(ours, theirs) + "\n" + d.src[d.lineEnd(d.nodes[i].end):]
+		d.reparse()
+		return
+	}
+}
@@ -0,0 +1,253 @@
+package brewfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge3(t *testing.T) {
+	git := NewPackage(TypeBrew, "git")
+	jq := NewPackage(TypeBrew, "jq")
+	fzf := NewPackage(TypeBrew, "fzf")
+	raycast := NewPackage(TypeCask, "raycast")
+
+	t.Run("keeps additions and removals from both sides", func(t *testing.T) {
+		base := Packages{git, jq}
+		ours := Packages{git, jq, fzf}
+		theirs := Packages{git, raycast}
+
+		result := Merge3(base, ours, theirs)
+		assert.False(t, result.HasConflicts())
+		assert.Equal(t, []string{"brew:git", "brew:fzf", "cask:raycast"}, packageIDs(result.Packages))
+	})
+
+	t.Run("takes fields from the side that changed them", func(t *testing.T) {
+		base := Packages{git.WithOption("link", "true"), jq}
+		ours := Packages{git.WithOption("link", "true"), Package{Type: TypeBrew, Name: "jq", Description: "JSON processor"}}
+		theirs := Packages{git.WithOption("link", "false"), jq}
+
+		result := Merge3(base, ours, theirs)
+		assert.False(t, result.HasConflicts())
+		require.Len(t, result.Packages, 2)
+		assert.Equal(t, "false", result.Packages[0].Options["link"].String())
+		assert.Equal(t, "JSON processor", result.Packages[1].Description)
+	})
+
+	t.Run("merges independent option changes", func(t *testing.T) {
+		base := Packages{git}
+		ours := Packages{git.WithOption("link", "true")}
+		theirs := Packages{git.WithValue("args", ListValue(StringValue("HEAD")))}
+
+		result := Merge3(base, ours, theirs)
+		assert.False(t, result.HasConflicts())
+		require.Len(t, result.Packages, 1)
+		assert.Equal(t, []string{"args", "link"}, result.Packages[0].Options.Keys())
+	})
+
+	t.Run("same change on both sides is not a conflict", func(t *testing.T) {
+		base := Packages{git}
+		ours := Packages{git.WithOption("link", "false"), fzf}
+		theirs := Packages{git.WithOption("link", "false"), fzf}
+
+		result := Merge3(base, ours, theirs)
+		assert.False(t, result.HasConflicts())
+		assert.Len(t, result.Packages, 2)
+	})
+
+	t.Run("description edits on both sides prefer ours", func(t *testing.T) {
+		base := Packages{git}
+		ours := Packages{Package{Type: TypeBrew, Name: "git", Description: "ours"}}
+		theirs := Packages{Package{Type: TypeBrew, Name: "git", Description: "theirs"}}
+
+		result := Merge3(base, ours, theirs)
+		assert.False(t, result.HasConflicts())
+		assert.Equal(t, "ours", result.Packages[0].Description)
+	})
+
+	t.Run("conflicting option values", func(t *testing.T) {
+		base := Packages{git}
+		ours := Packages{git.WithOption("link", "true")}
+		theirs := Packages{git.WithOption("link", "false")}
+
+		result := Merge3(base, ours, theirs)
+		require.Len(t, result.Conflicts, 1)
+		c := result.Conflicts[0]
+		assert.Equal(t, ConflictChanged, c.Kind)
+		assert.Equal(t, []string{"link"}, c.Keys)
+		assert.NotNil(t, c.Base)
+		assert.Equal(t, "brew:git: both changed link", c.String())
+		assert.Equal(t, "true", result.Packages[0].Options["link"].String())
+	})
+
+	t.Run("both added with different options", func(t *testing.T) {
+		ours := Packages{fzf.WithOption("link", "true")}
+		theirs := Packages{fzf.WithOption("link", "false")}
+
+		result := Merge3(nil, ours, theirs)
+		require.Len(t, result.Conflicts, 1)
+		assert.Nil(t, result.Conflicts[0].Base)
+	})
+
+	t.Run("removal of a changed package", func(t *testing.T) {
+		base := Packages{git, jq}
+		ours := Packages{git.WithOption("link", "false"), jq}
+		theirs := Packages{jq}
+
+		result := Merge3(base, ours, theirs)
+		require.Len(t, result.Conflicts, 1)
+		assert.Equal(t, ConflictModifyDelete, result.Conflicts[0].Kind)
+		assert.Nil(t, result.Conflicts[0].Theirs)
+		assert.Equal(t, []string{"brew:git", "brew:jq"}, packageIDs(result.Packages))
+	})
+
+	t.Run("removal of a package whose description changed", func(t *testing.T) {
+		base := Packages{git, jq}
+		ours := Packages{jq}
+		theirs := Packages{Package{Type: TypeBrew, Name: "git", Description: "vcs"}, jq}
+
+		result := Merge3(base, ours, theirs)
+		assert.False(t, result.HasConflicts())
+		assert.Equal(t, []string{"brew:jq"}, packageIDs(result.Packages))
+	})
+
+	t.Run("renamed packages match by identity", func(t *testing.T) {
+		base := Packages{NewPackage(TypeBrew, "youtube-dl")}
+		ours := Packages{NewPackage(TypeBrew, "youtube-dl")}
+		theirs := Packages{NewPackage(TypeBrew, "yt-dlp")}
+
+		result := Merge3(base, ours, theirs)
+		assert.False(t, result.HasConflicts())
+		assert.Equal(t, []string{"brew:yt-dlp"}, packageIDs(result.Packages))
+	})
+}
+
+func TestMerge3Content(t *testing.T) {
+	base := `tap "homebrew/bundle"
+
+# --- tools ---
+brew "git"
+brew "jq"
+`
+
+	t.Run("clean merge keeps our layout", func(t *testing.T) {
+		ours := `tap "homebrew/bundle"
+
+# --- tools ---
+brew "git"
+brew "jq" # keep
+brew "wget"
+`
+		theirs := `tap "homebrew/bundle"
+
+# --- tools ---
+brew "git", link: false
+# JSON processor
+brew "jq"
+cask "raycast"
+`
+		content, result := Merge3Content(base, ours, theirs)
+		assert.False(t, result.HasConflicts())
+		assert.Equal(t, `tap "homebrew/bundle"
+
+# --- tools ---
+brew "git", link: false
+# JSON processor
+brew "jq" # keep
+brew "wget"
+
+cask "raycast"
+`, content)
+	})
+
+	t.Run("conflicts are written between markers", func(t *testing.T) {
+		ours := `tap "homebrew/bundle"
+
+# --- tools ---
+brew "git", link: true
+brew "jq"
+`
+		theirs := `tap "homebrew/bundle"
+
+# --- tools ---
+brew "git", link: false
+`
+		content, result := Merge3Content(base, ours, theirs)
+		require.Len(t, result.Conflicts, 1)
+		assert.Equal(t, `tap "homebrew/bundle"
+
+# --- tools ---
+<<<<<<< ours
+brew "git", link: true
+=======
+brew "git", link: false
+>>>>>>> theirs
+`, content)
+	})
+
+	t.Run("directives they add are kept", func(t *testing.T) {
+		theirs := `cask_args appdir: "~/Applications"
+include "common.Brewfile"
+tap "homebrew/bundle"
+
+# --- tools ---
+brew "git"
+brew "jq"
+`
+		content, result := Merge3Content(base, base, theirs)
+		assert.False(t, result.HasConflicts())
+		assert.Empty(t, result.Diagnostics)
+		assert.Equal(t, `cask_args appdir: "~/Applications"
+include "common.Brewfile"
+
+tap "homebrew/bundle"
+
+# --- tools ---
+brew "git"
+brew "jq"
+`, content)
+	})
+
+	t.Run("directives they change or remove follow them", func(t *testing.T) {
+		withArgs := "cask_args appdir: \"~/Applications\"\ninclude \"common.Brewfile\"\n" + base
+		theirs := "cask_args appdir: \"/Applications\"\n" + base
+		ours := withArgs + "brew \"wget\"\n"
+
+		content, result := Merge3Content(withArgs, ours, theirs)
+		assert.False(t, result.HasConflicts())
+		assert.Equal(t, "cask_args appdir: \"/Applications\"\n"+base+"brew \"wget\"\n", content)
+	})
+
+	t.Run("directives both changed conflict", func(t *testing.T) {
+		withArgs := "cask_args appdir: \"~/Applications\"\n" + base
+		ours := "cask_args appdir: \"/Applications\"\n" + base
+		theirs := "cask_args appdir: \"~/Apps\"\n" + base
+
+		content, result := Merge3Content(withArgs, ours, theirs)
+		require.Len(t, result.Directives, 1)
+		assert.Equal(t, "cask_args: both changed", result.Directives[0].String())
+		assert.True(t, result.HasConflicts())
+		assert.Equal(t, `<<<<<<< ours
+cask_args appdir: "/Applications"
+=======
+cask_args appdir: "~/Apps"
+>>>>>>> theirs
+`+base, content)
+	})
+
+	t.Run("lines that do not parse are kept and reported", func(t *testing.T) {
+		theirs := base + "brew \"fzf\", args: [\n"
+		content, result := Merge3Content(base, base, theirs)
+		assert.Contains(t, content, "brew \"fzf\", args: [")
+		require.NotEmpty(t, result.Diagnostics)
+		assert.Equal(t, "theirs", result.Diagnostics[0].File)
+		assert.Equal(t, SeverityError, result.Diagnostics[0].Severity)
+	})
+
+	t.Run("empty ours uses the writer layout", func(t *testing.T) {
+		content, result := Merge3Content("", "", "brew \"git\"\n")
+		assert.False(t, result.HasConflicts())
+		assert.Equal(t, "brew \"git\"\n", content)
+	})
+}
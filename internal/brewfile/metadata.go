@@ -2,11 +2,15 @@ package brewfile
 
 import (
 	"os"
+	"path/filepath"
 	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
+// MetadataFileName is the metadata file written next to each Brewfile
+const MetadataFileName = ".brewsync-meta"
+
 // Metadata represents the .brewsync-meta file
 type Metadata struct {
 	Machine         string         `yaml:"machine"`
@@ -15,6 +19,28 @@ type Metadata struct {
 	PackageCounts   map[string]int `yaml:"package_counts,omitempty"`
 	MacOSVersion    string         `yaml:"macos_version,omitempty"`
 	BrewsyncVersion string         `yaml:"brewsync_version,omitempty"`
+	System          SystemFacts    `yaml:"system,omitempty"`
+}
+
+// SystemFacts describes the machine a Brewfile was dumped on
+type SystemFacts struct {
+	OS              string `yaml:"os,omitempty" json:"os,omitempty"`                 // darwin, linux
+	OSVersion       string `yaml:"os_version,omitempty" json:"os_version,omitempty"` // e.g. 14.5
+	Arch            string `yaml:"arch,omitempty" json:"arch,omitempty"`             // arm64, x86_64
+	Rosetta         bool   `yaml:"rosetta,omitempty" json:"rosetta,omitempty"`       // running translated on Apple Silicon
+	HomebrewPrefix  string `yaml:"homebrew_prefix,omitempty" json:"homebrew_prefix,omitempty"`
+	HomebrewVersion string `yaml:"homebrew_version,omitempty" json:"homebrew_version,omitempty"`
+	BrewsyncVersion string `yaml:"brewsync_version,omitempty" json:"brewsync_version,omitempty"`
+}
+
+// IsZero reports whether no facts were recorded
+func (f SystemFacts) IsZero() bool {
+	return f == SystemFacts{}
+}
+
+// MetadataPath returns the metadata file path for a Brewfile
+func MetadataPath(brewfilePath string) string {
+	return filepath.Join(filepath.Dir(brewfilePath), MetadataFileName)
 }
 
 // LastSyncInfo contains information about the last sync operation
@@ -40,6 +66,22 @@ func LoadMetadata(path string) (*Metadata, error) {
 	return &meta, nil
 }
 
+// LoadSystemFacts returns the system facts recorded by the last dump of a
+// Brewfile. Metadata written before facts were recorded only yields the
+// macOS version. Returns false when nothing is known.
+func LoadSystemFacts(brewfilePath string) (SystemFacts, bool) {
+	meta, err := LoadMetadata(MetadataPath(brewfilePath))
+	if err != nil {
+		return SystemFacts{}, false
+	}
+	facts := meta.System
+	if facts.IsZero() && meta.MacOSVersion != "" {
+		facts.OS = "darwin"
+		facts.OSVersion = meta.MacOSVersion
+	}
+	return facts, !facts.IsZero()
+}
+
 // SaveMetadata saves metadata to the given path
 func SaveMetadata(path string, meta *Metadata) error {
 	data, err := yaml.Marshal(meta)
@@ -50,20 +92,24 @@ func SaveMetadata(path string, meta *Metadata) error {
 	return os.WriteFile(path, data, 0644)
 }
 
-// UpdateMetadata updates the metadata file with new dump information
-func UpdateMetadata(path string, machine string, packages Packages, version string) error {
+// UpdateMetadata updates the metadata file with new dump information and
+// the facts of the system the dump was taken on
+func UpdateMetadata(path string, machine string, packages Packages, facts SystemFacts) error {
 	meta := &Metadata{
 		Machine:         machine,
 		LastDump:        time.Now(),
 		PackageCounts:   make(map[string]int),
-		BrewsyncVersion: version,
+		BrewsyncVersion: facts.BrewsyncVersion,
+		System:          facts,
+	}
+	if facts.OS == "darwin" {
+		meta.MacOSVersion = facts.OSVersion
 	}
 
 	// Try to load existing metadata to preserve last_sync info
 	existing, err := LoadMetadata(path)
 	if err == nil && existing != nil {
 		meta.LastSync = existing.LastSync
-		meta.MacOSVersion = existing.MacOSVersion
 	}
 
 	// Count packages by type
@@ -0,0 +1,373 @@
+package brewfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NormalizeReason explains why a package name was canonicalized
+type NormalizeReason string
+
+const (
+	ReasonTapQualified NormalizeReason = "tap-qualified" // homebrew/core/node -> node
+	ReasonRepoName     NormalizeReason = "repo-name"     // homebrew/homebrew-cask -> homebrew/cask
+	ReasonRenamed      NormalizeReason = "renamed"       // youtube-dl -> yt-dlp
+	ReasonAlias        NormalizeReason = "alias"         // nodejs -> node
+	ReasonCase         NormalizeReason = "case"          // GoLang.Go -> golang.go
+)
+
+// Normalization records a package whose name was canonicalized
+type Normalization struct {
+	Type     PackageType     `json:"type"`
+	Original string          `json:"original"`
+	Name     string          `json:"name"`
+	Reason   NormalizeReason `json:"reason"`
+}
+
+// String formats the normalization as "brew: youtube-dl → yt-dlp (renamed)"
+func (n Normalization) String() string {
+	return fmt.Sprintf("%s: %s → %s (%s)", n.Type, n.Original, n.Name, n.Reason)
+}
+
+// NameTable maps old and alternative package names to their canonical names
+type NameTable struct {
+	Renames map[PackageType]map[string]string `yaml:"renames,omitempty"`
+	Aliases map[PackageType]map[string]string `yaml:"aliases,omitempty"`
+}
+
+// NewNameTable creates an empty name table
+func NewNameTable() *NameTable {
+	return &NameTable{
+		Renames: make(map[PackageType]map[string]string),
+		Aliases: make(map[PackageType]map[string]string),
+	}
+}
+
+// DefaultNameTable returns the built-in table of well-known renames and aliases.
+// Refresh it with brew info --json=v2 output for an up-to-date table.
+func DefaultNameTable() *NameTable {
+	t := NewNameTable()
+	t.AddRename(TypeBrew, "youtube-dl", "yt-dlp")
+	t.AddAlias(TypeBrew, "nodejs", "node")
+	t.AddAlias(TypeBrew, "golang", "go")
+	t.AddAlias(TypeBrew, "gpg", "gnupg")
+	t.AddAlias(TypeBrew, "kubectl", "kubernetes-cli")
+	t.AddRename(TypeCask, "docker", "docker-desktop")
+	return t
+}
+
+// AddRename records that a package was renamed from old to name
+func (t *NameTable) AddRename(pkgType PackageType, old, name string) {
+	addName(t.Renames, pkgType, old, name)
+}
+
+// AddAlias records that alias refers to the package name
+func (t *NameTable) AddAlias(pkgType PackageType, alias, name string) {
+	addName(t.Aliases, pkgType, alias, name)
+}
+
+func addName(m map[PackageType]map[string]string, pkgType PackageType, from, to string) {
+	if from == "" || from == to {
+		return
+	}
+	if m[pkgType] == nil {
+		m[pkgType] = make(map[string]string)
+	}
+	m[pkgType][from] = to
+}
+
+// Merge copies the entries of other into the table, overriding existing ones
+func (t *NameTable) Merge(other *NameTable) {
+	if other == nil {
+		return
+	}
+	for pkgType, names := range other.Renames {
+		for from, to := range names {
+			t.AddRename(pkgType, from, to)
+		}
+	}
+	for pkgType, names := range other.Aliases {
+		for from, to := range names {
+			t.AddAlias(pkgType, from, to)
+		}
+	}
+}
+
+// Len returns the number of entries in the table
+func (t *NameTable) Len() int {
+	count := 0
+	for _, names := range t.Renames {
+		count += len(names)
+	}
+	for _, names := range t.Aliases {
+		count += len(names)
+	}
+	return count
+}
+
+// LoadNameTable loads a name table from a YAML file.
+// A missing file yields an empty table.
+func LoadNameTable(path string) (*NameTable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return NewNameTable(), nil
+		}
+		return nil, err
+	}
+
+	t := NewNameTable()
+	if err := yaml.Unmarshal(data, t); err != nil {
+		return nil, err
+	}
+	if t.Renames == nil {
+		t.Renames = make(map[PackageType]map[string]string)
+	}
+	if t.Aliases == nil {
+		t.Aliases = make(map[PackageType]map[string]string)
+	}
+	return t, nil
+}
+
+// Save writes the name table to a YAML file
+func (t *NameTable) Save(path string) error {
+	data, err := yaml.Marshal(t)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// brewInfo is the subset of `brew info --json=v2` output used for names
+type brewInfo struct {
+	Formulae []struct {
+		Name     string   `json:"name"`
+		FullName string   `json:"full_name"`
+		Tap      string   `json:"tap"`
+		Aliases  []string `json:"aliases"`
+		Oldname  string   `json:"oldname"`
+		Oldnames []string `json:"oldnames"`
+	} `json:"formulae"`
+	Casks []struct {
+		Token     string   `json:"token"`
+		FullToken string   `json:"full_token"`
+		Tap       string   `json:"tap"`
+		OldTokens []string `json:"old_tokens"`
+	} `json:"casks"`
+}
+
+// ParseBrewInfo builds a name table from `brew info --json=v2` output.
+// Formulae and casks from third-party taps are canonicalized to their
+// tap-qualified names, so `brew list` style short names resolve to them.
+func ParseBrewInfo(data []byte) (*NameTable, error) {
+	var info brewInfo
+	if err := json.Unmarshal(data, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse brew info output: %w", err)
+	}
+
+	// Short names of official packages must keep resolving to them
+	official := make(map[string]bool)
+	for _, f := range info.Formulae {
+		if f.Tap == "" || f.Tap == "homebrew/core" {
+			official["brew:"+f.Name] = true
+		}
+	}
+	for _, c := range info.Casks {
+		if c.Tap == "" || c.Tap == "homebrew/cask" {
+			official["cask:"+c.Token] = true
+		}
+	}
+
+	t := NewNameTable()
+	for _, f := range info.Formulae {
+		name := canonicalTapName(f.Name, f.FullName, f.Tap, "homebrew/core")
+		if name == "" {
+			continue
+		}
+		if !official["brew:"+f.Name] {
+			t.AddAlias(TypeBrew, f.Name, name)
+		}
+		for _, alias := range f.Aliases {
+			t.AddAlias(TypeBrew, qualify(alias, f.Tap, "homebrew/core"), name)
+		}
+		oldnames := f.Oldnames
+		if f.Oldname != "" {
+			oldnames = append(oldnames, f.Oldname)
+		}
+		for _, old := range oldnames {
+			t.AddRename(TypeBrew, qualify(old, f.Tap, "homebrew/core"), name)
+		}
+	}
+	for _, c := range info.Casks {
+		name := canonicalTapName(c.Token, c.FullToken, c.Tap, "homebrew/cask")
+		if name == "" {
+			continue
+		}
+		if !official["cask:"+c.Token] {
+			t.AddAlias(TypeCask, c.Token, name)
+		}
+		for _, old := range c.OldTokens {
+			t.AddRename(TypeCask, qualify(old, c.Tap, "homebrew/cask"), name)
+		}
+	}
+	return t, nil
+}
+
+// canonicalTapName returns the short name for official packages and the
+// tap-qualified name for third-party ones
+func canonicalTapName(name, fullName, tap, official string) string {
+	if tap == "" || tap == official {
+		return name
+	}
+	if fullName != "" {
+		return fullName
+	}
+	return tap + "/" + name
+}
+
+// qualify prefixes a third-party name with its tap
+func qualify(name, tap, official string) string {
+	if tap == "" || tap == official || strings.Contains(name, "/") {
+		return name
+	}
+	return tap + "/" + name
+}
+
+// Normalizer canonicalizes package names so that equivalent spellings
+// compare equal: tap-qualified official names, renamed formulae and casks,
+// aliases and differently-cased editor extension ids
+type Normalizer struct {
+	mu    sync.RWMutex
+	table *NameTable
+}
+
+// NewNormalizer creates a normalizer using the given table
+func NewNormalizer(table *NameTable) *Normalizer {
+	if table == nil {
+		table = NewNameTable()
+	}
+	return &Normalizer{table: table}
+}
+
+// DefaultNormalizer is used by Diff; it starts with the built-in name table
+var DefaultNormalizer = NewNormalizer(DefaultNameTable())
+
+// Extend merges additional entries into the normalizer's table
+func (n *Normalizer) Extend(table *NameTable) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.table.Merge(table)
+}
+
+// maxRenameHops bounds rename chains such as a -> b -> c
+const maxRenameHops = 8
+
+// Canonical returns the canonical name of a package and why it differs from
+// the declared name. The reason is empty when the name is already canonical.
+func (n *Normalizer) Canonical(pkg Package) (string, NormalizeReason) {
+	name := pkg.Name
+	var reason NormalizeReason
+
+	switch pkg.Type {
+	case TypeTap:
+		lower := strings.ToLower(name)
+		if lower != name {
+			name, reason = lower, ReasonCase
+		}
+		if user, repo, ok := strings.Cut(name, "/"); ok && strings.HasPrefix(repo, "homebrew-") {
+			name, reason = user+"/"+strings.TrimPrefix(repo, "homebrew-"), ReasonRepoName
+		}
+
+	case TypeBrew, TypeCask:
+		official := "homebrew/core/"
+		if pkg.Type == TypeCask {
+			official = "homebrew/cask/"
+		}
+		if strings.HasPrefix(name, official) {
+			name, reason = strings.TrimPrefix(name, official), ReasonTapQualified
+		}
+
+		n.mu.RLock()
+		for i := 0; i < maxRenameHops; i++ {
+			if to, ok := n.table.Renames[pkg.Type][name]; ok {
+				name, reason = to, ReasonRenamed
+				continue
+			}
+			if to, ok := n.table.Aliases[pkg.Type][name]; ok {
+				if reason == "" {
+					reason = ReasonAlias
+					if strings.HasSuffix(to, "/"+name) {
+						reason = ReasonTapQualified
+					}
+				}
+				name = to
+				continue
+			}
+			break
+		}
+		n.mu.RUnlock()
+
+	default:
+		// Extension ids are case-insensitive
+		if lower := strings.ToLower(name); IsEditorType(pkg.Type) && lower != name {
+			name, reason = lower, ReasonCase
+		}
+	}
+
+	if name == pkg.Name {
+		return name, ""
+	}
+	return name, reason
+}
+
+// Key returns the identity key of a package after normalization
+func (n *Normalizer) Key(pkg Package) string {
+	name, _ := n.Canonical(pkg)
+	return string(pkg.Type) + ":" + name
+}
+
+// Normalize returns the packages with canonical names along with a report of
+// every entry that was renamed
+func (n *Normalizer) Normalize(packages Packages) (Packages, []Normalization) {
+	result := make(Packages, len(packages))
+	var report []Normalization
+	for i, pkg := range packages {
+		name, reason := n.Canonical(pkg)
+		if reason != "" {
+			report = append(report, Normalization{Type: pkg.Type, Original: pkg.Name, Name: name, Reason: reason})
+			pkg.Name = name
+		}
+		result[i] = pkg
+	}
+	return result, report
+}
+
+// normalizationsOf reports the normalized entries across package lists,
+// listing each original spelling once
+func (n *Normalizer) normalizationsOf(lists ...Packages) []Normalization {
+	seen := make(map[string]bool)
+	var report []Normalization
+	for _, packages := range lists {
+		for _, pkg := range packages {
+			name, reason := n.Canonical(pkg)
+			if reason == "" || seen[pkg.ID()] {
+				continue
+			}
+			seen[pkg.ID()] = true
+			report = append(report, Normalization{Type: pkg.Type, Original: pkg.Name, Name: name, Reason: reason})
+		}
+	}
+	sort.SliceStable(report, func(i, j int) bool {
+		if report[i].Type != report[j].Type {
+			return typeRank(report[i].Type) < typeRank(report[j].Type)
+		}
+		return report[i].Original < report[j].Original
+	})
+	return report
+}
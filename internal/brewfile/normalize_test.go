@@ -0,0 +1,160 @@
+package brewfile
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizer_Canonical(t *testing.T) {
+	n := NewNormalizer(DefaultNameTable())
+
+	tests := []struct {
+		pkg    Package
+		name   string
+		reason NormalizeReason
+	}{
+		{NewPackage(TypeBrew, "node"), "node", ""},
+		{NewPackage(TypeBrew, "homebrew/core/node"), "node", ReasonTapQualified},
+		{NewPackage(TypeBrew, "nodejs"), "node", ReasonAlias},
+		{NewPackage(TypeBrew, "youtube-dl"), "yt-dlp", ReasonRenamed},
+		{NewPackage(TypeBrew, "homebrew/core/youtube-dl"), "yt-dlp", ReasonRenamed},
+		{NewPackage(TypeBrew, "user/tap/tool"), "user/tap/tool", ""},
+		{NewPackage(TypeCask, "homebrew/cask/firefox"), "firefox", ReasonTapQualified},
+		{NewPackage(TypeCask, "docker"), "docker-desktop", ReasonRenamed},
+		{NewPackage(TypeTap, "Homebrew/Bundle"), "homebrew/bundle", ReasonCase},
+		{NewPackage(TypeTap, "homebrew/homebrew-cask"), "homebrew/cask", ReasonRepoName},
+		{NewPackage(TypeVSCode, "GoLang.Go"), "golang.go", ReasonCase},
+		{NewPackage(TypeCursor, "ms-python.Python"), "ms-python.python", ReasonCase},
+		{NewPackage(TypeGo, "github.com/User/Tool"), "github.com/User/Tool", ""},
+		{NewPackage(TypeMas, "Xcode"), "Xcode", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pkg.ID(), func(t *testing.T) {
+			name, reason := n.Canonical(tt.pkg)
+			assert.Equal(t, tt.name, name)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+}
+
+func TestNormalizer_RenameCycle(t *testing.T) {
+	table := NewNameTable()
+	table.AddRename(TypeBrew, "a", "b")
+	table.AddRename(TypeBrew, "b", "a")
+	n := NewNormalizer(table)
+
+	// Must terminate
+	name, _ := n.Canonical(NewPackage(TypeBrew, "a"))
+	assert.Contains(t, []string{"a", "b"}, name)
+}
+
+func TestNormalizer_Normalize(t *testing.T) {
+	n := NewNormalizer(DefaultNameTable())
+	pkgs, report := n.Normalize(Packages{
+		NewPackage(TypeBrew, "git"),
+		NewPackage(TypeBrew, "homebrew/core/node"),
+		NewPackage(TypeVSCode, "GoLang.Go"),
+	})
+
+	assert.Equal(t, []string{"git", "node", "golang.go"}, pkgs.Names())
+	require.Len(t, report, 2)
+	assert.Equal(t, "brew: homebrew/core/node → node (tap-qualified)", report[0].String())
+	assert.Equal(t, Normalization{Type: TypeVSCode, Original: "GoLang.Go", Name: "golang.go", Reason: ReasonCase}, report[1])
+}
+
+func TestDiff_Normalized(t *testing.T) {
+	source := Packages{
+		NewPackage(TypeBrew, "homebrew/core/node"),
+		NewPackage(TypeBrew, "youtube-dl"),
+		NewPackage(TypeVSCode, "GoLang.Go"),
+		NewPackage(TypeBrew, "jq"),
+	}
+	current := Packages{
+		NewPackage(TypeBrew, "node"),
+		NewPackage(TypeBrew, "yt-dlp"),
+		NewPackage(TypeVSCode, "golang.go"),
+	}
+
+	diff := Diff(source, current)
+	assert.Equal(t, []string{"jq"}, diff.Additions.Names())
+	assert.Empty(t, diff.Removals)
+	assert.Len(t, diff.Common, 3)
+	assert.Len(t, diff.Normalized, 3)
+
+	t.Run("empty name table", func(t *testing.T) {
+		// Tap qualifiers and case are still normalized; renames are not
+		diff := DiffWith(source, current, NewNormalizer(nil))
+		assert.Equal(t, []string{"youtube-dl", "jq"}, diff.Additions.Names())
+		assert.Equal(t, []string{"yt-dlp"}, diff.Removals.Names())
+		assert.Len(t, diff.Normalized, 2)
+	})
+}
+
+func TestParseBrewInfo(t *testing.T) {
+	data := []byte(`{
+  "formulae": [
+    {"name": "node", "full_name": "node", "tap": "homebrew/core", "aliases": ["nodejs", "node.js"], "oldnames": []},
+    {"name": "yt-dlp", "full_name": "yt-dlp", "tap": "homebrew/core", "aliases": [], "oldnames": ["youtube-dl"]},
+    {"name": "tool", "full_name": "user/tap/tool", "tap": "user/tap", "aliases": ["tl"], "oldname": "oldtool"},
+    {"name": "git", "full_name": "other/tap/git", "tap": "other/tap"},
+    {"name": "git", "full_name": "git", "tap": "homebrew/core"}
+  ],
+  "casks": [
+    {"token": "docker-desktop", "full_token": "docker-desktop", "tap": "homebrew/cask", "old_tokens": ["docker"]},
+    {"token": "app", "full_token": "user/tap/app", "tap": "user/tap", "old_tokens": []}
+  ]
+}`)
+
+	table, err := ParseBrewInfo(data)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]string{
+		"nodejs":      "node",
+		"node.js":     "node",
+		"tool":        "user/tap/tool",
+		"user/tap/tl": "user/tap/tool",
+	}, table.Aliases[TypeBrew])
+	assert.Equal(t, map[string]string{
+		"youtube-dl":       "yt-dlp",
+		"user/tap/oldtool": "user/tap/tool",
+	}, table.Renames[TypeBrew])
+	assert.Equal(t, map[string]string{"docker": "docker-desktop"}, table.Renames[TypeCask])
+	assert.Equal(t, map[string]string{"app": "user/tap/app"}, table.Aliases[TypeCask])
+
+	n := NewNormalizer(table)
+	name, reason := n.Canonical(NewPackage(TypeBrew, "tool"))
+	assert.Equal(t, "user/tap/tool", name)
+	assert.Equal(t, ReasonTapQualified, reason)
+
+	// Official short names are never redirected to a third-party tap
+	name, _ = n.Canonical(NewPackage(TypeBrew, "git"))
+	assert.Equal(t, "git", name)
+
+	_, err = ParseBrewInfo([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestNameTableSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "names.yaml")
+
+	table := DefaultNameTable()
+	require.NoError(t, table.Save(path))
+
+	loaded, err := LoadNameTable(path)
+	require.NoError(t, err)
+	assert.Equal(t, table, loaded)
+
+	missing, err := LoadNameTable(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, 0, missing.Len())
+
+	merged := NewNameTable()
+	merged.Merge(loaded)
+	merged.AddAlias(TypeBrew, "nodejs", "node@22")
+	assert.Equal(t, table.Len(), merged.Len())
+	assert.Equal(t, "node@22", merged.Aliases[TypeBrew]["nodejs"])
+}
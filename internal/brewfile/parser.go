@@ -1,213 +1,265 @@
 package brewfile
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"regexp"
+	"path/filepath"
+	"sort"
 	"strings"
 )
 
 // Parser parses Brewfile format files
-type Parser struct{}
+type Parser struct {
+	strict bool
+}
 
-// NewParser creates a new Parser
+// NewParser creates a new Parser that skips entries it cannot understand
 func NewParser() *Parser {
 	return &Parser{}
 }
 
-// Patterns for parsing Brewfile lines
-var (
-	// Match: tap "name" or tap "name", args
-	tapPattern = regexp.MustCompile(`^tap\s+"([^"]+)"(?:\s*,\s*(.+))?`)
-	// Match: brew "name" or brew "name", options
-	brewPattern = regexp.MustCompile(`^brew\s+"([^"]+)"(?:\s*,\s*(.+))?`)
-	// Match: cask "name" or cask "name", options
-	caskPattern = regexp.MustCompile(`^cask\s+"([^"]+)"(?:\s*,\s*(.+))?`)
-	// Match: mas "name", id: 123
-	masPattern = regexp.MustCompile(`^mas\s+"([^"]+)"(?:\s*,\s*(.+))?`)
-	// Match: vscode "name"
-	vscodePattern = regexp.MustCompile(`^vscode\s+"([^"]+)"`)
-	// Match: cursor "name" (BrewSync extension)
-	cursorPattern = regexp.MustCompile(`^cursor\s+"([^"]+)"`)
-	// Match: antigravity "name" (BrewSync extension)
-	antigravityPattern = regexp.MustCompile(`^antigravity\s+"([^"]+)"`)
-	// Match: go "name" (BrewSync extension)
-	goPattern = regexp.MustCompile(`^go\s+"([^"]+)"`)
-	// Match options like: link: true, args: ["--foo"]
-	optionPattern = regexp.MustCompile(`(\w+):\s*(.+?)(?:,\s*|$)`)
-)
-
-// ParseFile parses a Brewfile from the given path
-func (p *Parser) ParseFile(path string) (Packages, error) {
-	file, err := os.Open(path)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %w", err)
-	}
-	defer file.Close()
+// NewStrictParser creates a Parser that fails with a *ParseError when the
+// Brewfile contains errors such as unknown directives or malformed options
+func NewStrictParser() *Parser {
+	return &Parser{strict: true}
+}
 
-	var packages Packages
-	scanner := bufio.NewScanner(file)
-	lineNum := 0
-	var lastComment string // Track comment from previous line
+// keywordTypes maps Brewfile directives to package types
+var keywordTypes = map[string]PackageType{
+	"tap":         TypeTap,
+	"brew":        TypeBrew,
+	"cask":        TypeCask,
+	"mas":         TypeMas,
+	"vscode":      TypeVSCode,
+	"cursor":      TypeCursor,      // BrewSync extension
+	"antigravity": TypeAntigravity, // BrewSync extension
+	"go":          TypeGo,          // BrewSync extension
+}
 
-	for scanner.Scan() {
-		lineNum++
-		line := strings.TrimSpace(scanner.Text())
+// includeKeyword is the BrewSync directive that pulls in another Brewfile
+const includeKeyword = "include"
 
-		// Skip empty lines
-		if line == "" {
-			continue
-		}
+// ErrIncludeCycle is returned when Brewfiles include each other in a loop
+var ErrIncludeCycle = errors.New("include cycle")
 
-		// Capture comments as potential descriptions
-		if strings.HasPrefix(line, "#") {
-			// Extract comment text (remove leading # and whitespace)
-			lastComment = strings.TrimSpace(strings.TrimPrefix(line, "#"))
-			continue
-		}
+// includeRef is an include directive found while parsing
+type includeRef struct {
+	path  string // path as written in the directive
+	index int    // number of packages declared before the directive
+}
 
-		pkg, ok := p.parseLine(line)
-		if !ok {
-			// Skip lines that don't match any pattern
-			lastComment = "" // Reset if we skip a line
-			continue
-		}
+// ParseFile parses a Brewfile from the given path, resolving include
+// directives relative to the file's directory
+func (p *Parser) ParseFile(path string) (Packages, error) {
+	return p.parseFile(path, nil)
+}
 
-		// Attach the last comment as description if available
-		if lastComment != "" {
-			pkg.Description = lastComment
-			lastComment = "" // Reset after using
+// parseFile parses a file; stack holds the absolute paths of the files
+// currently being included and is used to detect cycles
+func (p *Parser) parseFile(path string, stack []string) (Packages, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve path: %w", err)
+	}
+	for i, s := range stack {
+		if s == abs {
+			var chain []string
+			for _, f := range append(stack[i:], abs) {
+				chain = append(chain, filepath.Base(f))
+			}
+			return nil, fmt.Errorf("%w: %s", ErrIncludeCycle, strings.Join(chain, " -> "))
 		}
+	}
 
-		packages = append(packages, pkg)
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
+	defer file.Close()
 
-	if err := scanner.Err(); err != nil {
+	data, err := io.ReadAll(file)
+	if err != nil {
 		return nil, fmt.Errorf("error reading file: %w", err)
 	}
 
-	return packages, nil
+	if err := p.check(path, string(data)); err != nil {
+		return nil, err
+	}
+	return p.resolve(string(data), filepath.Dir(path), append(stack, abs))
 }
 
-// ParseString parses Brewfile content from a string
+// ParseString parses Brewfile content from a string.
+// Include directives are resolved relative to the working directory.
 func (p *Parser) ParseString(content string) (Packages, error) {
-	var packages Packages
-	scanner := bufio.NewScanner(strings.NewReader(content))
-	var lastComment string // Track comment from previous line
-
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
+	if err := p.check("", content); err != nil {
+		return nil, err
+	}
+	return p.resolve(content, ".", nil)
+}
 
-		// Skip empty lines
-		if line == "" {
-			continue
-		}
+// resolve parses content and expands its include directives in place.
+// Packages declared by the including file take precedence over included
+// ones; included packages record the file they came from in Source.
+func (p *Parser) resolve(content, dir string, stack []string) (Packages, error) {
+	own, includes := p.parse(content)
+	if len(includes) == 0 {
+		return own, nil
+	}
 
-		// Capture comments as potential descriptions
-		if strings.HasPrefix(line, "#") {
-			// Extract comment text (remove leading # and whitespace)
-			lastComment = strings.TrimSpace(strings.TrimPrefix(line, "#"))
-			continue
-		}
+	ownIDs := make(map[string]bool, len(own))
+	for _, pkg := range own {
+		ownIDs[pkg.ID()] = true
+	}
 
-		pkg, ok := p.parseLine(line)
-		if !ok {
-			lastComment = "" // Reset if we skip a line
-			continue
+	var result Packages
+	seen := make(map[string]bool)
+	next := 0
+	for _, inc := range includes {
+		result = append(result, own[next:inc.index]...)
+		next = inc.index
+
+		incPath := IncludePath(dir, inc.path)
+		pkgs, err := p.parseFile(incPath, stack)
+		if err != nil {
+			return nil, fmt.Errorf("failed to include %s: %w", inc.path, err)
 		}
-
-		// Attach the last comment as description if available
-		if lastComment != "" {
-			pkg.Description = lastComment
-			lastComment = "" // Reset after using
+		for _, pkg := range pkgs {
+			id := pkg.ID()
+			if ownIDs[id] || seen[id] {
+				continue
+			}
+			seen[id] = true
+			if pkg.Source == "" {
+				pkg.Source = incPath
+			}
+			result = append(result, pkg)
 		}
-
-		packages = append(packages, pkg)
 	}
+	result = append(result, own[next:]...)
 
-	return packages, nil
+	return result, nil
 }
 
-// parseLine parses a single Brewfile line
-func (p *Parser) parseLine(line string) (Package, bool) {
-	// Try each pattern in order
-	if matches := tapPattern.FindStringSubmatch(line); matches != nil {
-		pkg := NewPackage(TypeTap, matches[1])
-		if len(matches) > 2 && matches[2] != "" {
-			pkg = p.parseOptions(matches[2], pkg)
+// IncludePath resolves an include directive's path relative to the
+// directory of the including Brewfile, expanding a leading ~/
+func IncludePath(dir, path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
 		}
-		return pkg, true
 	}
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(dir, path)
+}
 
-	if matches := brewPattern.FindStringSubmatch(line); matches != nil {
-		pkg := NewPackage(TypeBrew, matches[1])
-		if len(matches) > 2 && matches[2] != "" {
-			pkg = p.parseOptions(matches[2], pkg)
+// IncludedPackages returns the packages a Brewfile gets from its include
+// directives, i.e. everything it does not declare itself
+func IncludedPackages(path string) (Packages, error) {
+	packages, err := Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	var included Packages
+	for _, pkg := range packages {
+		if pkg.Source != "" {
+			included = append(included, pkg)
 		}
-		return pkg, true
 	}
+	return included, nil
+}
 
-	if matches := caskPattern.FindStringSubmatch(line); matches != nil {
-		pkg := NewPackage(TypeCask, matches[1])
-		if len(matches) > 2 && matches[2] != "" {
-			pkg = p.parseOptions(matches[2], pkg)
-		}
-		return pkg, true
+// check lints the content in strict mode and returns a *ParseError on errors
+func (p *Parser) check(path, content string) error {
+	if !p.strict {
+		return nil
 	}
+	diags := Lint(content)
+	if !diags.HasErrors() {
+		return nil
+	}
+	for i := range diags {
+		diags[i].File = path
+	}
+	return &ParseError{Diagnostics: diags}
+}
 
-	if matches := masPattern.FindStringSubmatch(line); matches != nil {
-		pkg := NewPackage(TypeMas, matches[1])
-		if len(matches) > 2 && matches[2] != "" {
-			pkg = p.parseOptions(matches[2], pkg)
+// parse converts Brewfile source into packages and include directives.
+// Entries that cannot be parsed or use unknown directives are skipped.
+func (p *Parser) parse(content string) (Packages, []includeRef) {
+	var packages Packages
+	var includes []includeRef
+	var lastComment string // Track comment from previous line
+
+	for _, n := range parseSyntax(content) {
+		switch n.kind {
+		case nodeComment:
+			// Capture comments as potential descriptions
+			lastComment = n.comment
+
+		case nodeEntry:
+			if path, ok := includeTarget(n.entry); ok {
+				includes = append(includes, includeRef{path: path, index: len(packages)})
+				lastComment = ""
+				continue
+			}
+
+			pkg, ok := entryToPackage(n.entry)
+			if !ok {
+				lastComment = "" // Reset if we skip an entry
+				continue
+			}
+
+			// Attach the last comment as description if available
+			if lastComment != "" {
+				pkg.Description = lastComment
+				lastComment = ""
+			}
+			packages = append(packages, pkg)
+
+		case nodeInvalid:
+			lastComment = ""
 		}
-		return pkg, true
 	}
 
-	if matches := vscodePattern.FindStringSubmatch(line); matches != nil {
-		return NewPackage(TypeVSCode, matches[1]), true
-	}
+	return packages, includes
+}
 
-	if matches := cursorPattern.FindStringSubmatch(line); matches != nil {
-		return NewPackage(TypeCursor, matches[1]), true
+// includeTarget returns the path of an include directive
+func includeTarget(e *entry) (string, bool) {
+	if e.keyword != includeKeyword || len(e.args) == 0 || e.args[0].Kind != KindString {
+		return "", false
 	}
+	return e.args[0].Str, true
+}
 
-	if matches := antigravityPattern.FindStringSubmatch(line); matches != nil {
-		return NewPackage(TypeAntigravity, matches[1]), true
+// entryToPackage converts a parsed statement into a package
+func entryToPackage(e *entry) (Package, bool) {
+	t, ok := typeForKeyword(e.keyword)
+	if !ok || len(e.args) == 0 || e.args[0].Kind != KindString {
+		return Package{}, false
 	}
 
-	if matches := goPattern.FindStringSubmatch(line); matches != nil {
-		return NewPackage(TypeGo, matches[1]), true
-	}
+	pkg := NewPackage(t, e.args[0].Str)
 
-	return Package{}, false
-}
+	// tap "user/repo", "https://..." takes an optional clone URL
+	if t == TypeTap && len(e.args) > 1 && e.args[1].Kind == KindString {
+		pkg.URL = e.args[1].Str
+	}
 
-// parseOptions parses option string like "link: true, args: [\"--foo\"]"
-func (p *Parser) parseOptions(optStr string, pkg Package) Package {
-	if pkg.Options == nil {
-		pkg.Options = make(map[string]string)
+	keys := make([]string, 0, len(e.options))
+	for _, opt := range e.options {
+		pkg = pkg.WithValue(opt.Key, opt.Value)
+		keys = append(keys, opt.Key)
 	}
-	// Handle simple key: value patterns
-	matches := optionPattern.FindAllStringSubmatch(optStr, -1)
-	for _, match := range matches {
-		if len(match) >= 3 {
-			key := match[1]
-			value := strings.TrimSpace(match[2])
-			// Remove trailing comma if present
-			value = strings.TrimSuffix(value, ",")
-			// Store the option as string
-			pkg.Options[key] = p.parseValueAsString(value)
-		}
+	if !sort.StringsAreSorted(keys) {
+		pkg.optionOrder = keys
 	}
-	return pkg
-}
 
-// parseValueAsString converts a value to string representation
-func (p *Parser) parseValueAsString(value string) string {
-	// Remove quotes if present
-	value = strings.Trim(value, `"'`)
-	return value
+	return pkg, true
 }
 
 // Parse is a convenience function to parse a file
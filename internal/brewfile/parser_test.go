@@ -45,7 +45,7 @@ brew "python@3.11"
 	}
 	assert.Equal(t, "git", packages[0].Name)
 	assert.Equal(t, "libpq", packages[3].Name)
-	assert.Equal(t, "true", packages[3].Options["link"])
+	assert.Equal(t, "true", packages[3].Options["link"].String())
 }
 
 func TestParser_ParseString_Casks(t *testing.T) {
@@ -79,7 +79,7 @@ mas "TestFlight", id: 899247664
 		assert.Equal(t, TypeMas, pkg.Type)
 	}
 	assert.Equal(t, "Xcode", packages[0].Name)
-	assert.Equal(t, "497799835", packages[0].Options["id"])
+	assert.Equal(t, "497799835", packages[0].Options["id"].String())
 }
 
 func TestParser_ParseString_VSCode(t *testing.T) {
@@ -246,7 +246,7 @@ func TestParser_ParseOptions_Link(t *testing.T) {
 	packages, err := ParseContent(content)
 	require.NoError(t, err)
 	assert.Len(t, packages, 1)
-	assert.Equal(t, "true", packages[0].Options["link"])
+	assert.Equal(t, "true", packages[0].Options["link"].String())
 }
 
 func TestParser_ParseOptions_Args(t *testing.T) {
@@ -254,7 +254,7 @@ func TestParser_ParseOptions_Args(t *testing.T) {
 	packages, err := ParseContent(content)
 	require.NoError(t, err)
 	assert.Len(t, packages, 1)
-	assert.Contains(t, packages[0].Options["args"], "HEAD")
+	assert.Equal(t, []string{"HEAD"}, packages[0].Options["args"].Strings())
 }
 
 func TestParser_UnrecognizedLines(t *testing.T) {
@@ -321,3 +321,228 @@ cask "raycast"`
 		assert.Empty(t, pkg.Description)
 	}
 }
+
+func TestParser_TypedOptions(t *testing.T) {
+	content := `brew "postgresql@16", restart_service: :changed, link: false
+brew "neovim", args: ["HEAD", "with-luajit"]
+cask "firefox", args: { appdir: "~/Applications", "no-quarantine" => true }
+mas "Xcode", id: 497799835
+brew "vim", conflicts_with: nil`
+
+	packages, err := ParseContent(content)
+	require.NoError(t, err)
+	require.Len(t, packages, 5)
+
+	pg := packages[0].Options
+	assert.Equal(t, SymbolValue("changed"), pg["restart_service"])
+	assert.Equal(t, BoolValue(false), pg["link"])
+
+	args := packages[1].Options["args"]
+	assert.Equal(t, KindList, args.Kind)
+	assert.Equal(t, []string{"HEAD", "with-luajit"}, args.Strings())
+
+	caskArgs := packages[2].Options["args"]
+	require.Equal(t, KindHash, caskArgs.Kind)
+	appdir, ok := caskArgs.Get("appdir")
+	require.True(t, ok)
+	assert.Equal(t, StringValue("~/Applications"), appdir)
+	quarantine, ok := caskArgs.Get("no-quarantine")
+	require.True(t, ok)
+	assert.Equal(t, BoolValue(true), quarantine)
+
+	assert.Equal(t, IntValue(497799835), packages[3].Options["id"])
+	assert.Equal(t, KindNil, packages[4].Options["conflicts_with"].Kind)
+}
+
+func TestParser_MultiLineEntries(t *testing.T) {
+	content := `# Editor
+brew "neovim",
+  args: [
+    "HEAD", # build from source
+    "with-luajit",
+  ],
+  link: true
+cask "firefox", args: {
+  appdir: "~/Applications",
+}
+brew("git")
+brew "fzf", \
+  link: false
+tap "user/tools", "https://example.com/tools.git"`
+
+	packages, err := ParseContent(content)
+	require.NoError(t, err)
+	require.Len(t, packages, 5)
+
+	assert.Equal(t, "neovim", packages[0].Name)
+	assert.Equal(t, "Editor", packages[0].Description)
+	assert.Equal(t, []string{"HEAD", "with-luajit"}, packages[0].Options["args"].Strings())
+	assert.Equal(t, BoolValue(true), packages[0].Options["link"])
+
+	assert.Equal(t, "firefox", packages[1].Name)
+	assert.Equal(t, KindHash, packages[1].Options["args"].Kind)
+
+	assert.Equal(t, "git", packages[2].Name)
+	assert.Equal(t, BoolValue(false), packages[3].Options["link"])
+
+	assert.Equal(t, TypeTap, packages[4].Type)
+	assert.Equal(t, "https://example.com/tools.git", packages[4].URL)
+}
+
+func TestParser_Strings(t *testing.T) {
+	content := `brew 'single-quoted'
+brew "escaped \"quote\""
+cask "trailing" # trailing comments are not descriptions`
+
+	packages, err := ParseContent(content)
+	require.NoError(t, err)
+	require.Len(t, packages, 3)
+
+	assert.Equal(t, "single-quoted", packages[0].Name)
+	assert.Equal(t, `escaped "quote"`, packages[1].Name)
+	assert.Equal(t, "trailing", packages[2].Name)
+	assert.Empty(t, packages[2].Description)
+}
+
+func TestParser_SkipsInvalidEntries(t *testing.T) {
+	content := `# lost description
+brew "broken", args: [
+brew "git"
+cask_args appdir: "~/Applications"
+brew "fzf", link: maybe
+cask "raycast"`
+
+	packages, err := ParseContent(content)
+	require.NoError(t, err)
+	require.Len(t, packages, 1)
+	assert.Equal(t, "raycast", packages[0].Name)
+}
+
+func TestParser_RoundTrip(t *testing.T) {
+	content := `tap "homebrew/bundle"
+tap "user/tools", "https://example.com/tools.git"
+
+brew "git"
+brew "neovim", args: ["HEAD", "with-luajit"], link: true
+brew "postgresql@16", restart_service: :changed
+# Text editor
+brew "vim", conflicts_with: nil
+
+cask "firefox", args: { appdir: "~/Applications", "no-quarantine" => true }
+
+mas "Xcode", id: 497799835
+
+vscode "golang.go"
+`
+
+	packages, err := ParseContent(content)
+	require.NoError(t, err)
+	assert.Equal(t, content, NewWriter(packages).Format())
+
+	// Parsing the written output again yields identical packages
+	reparsed, err := ParseContent(NewWriter(packages).Format())
+	require.NoError(t, err)
+	assert.Equal(t, packages, reparsed)
+}
+
+func TestParser_RoundTripOptionOrder(t *testing.T) {
+	content := `brew "x", link: false, conflicts_with: ["y"]
+brew "z", start_service: true, args: ["HEAD"], link: false
+`
+
+	packages, err := ParseContent(content)
+	require.NoError(t, err)
+	assert.Equal(t, content, NewWriter(packages).Format())
+	assert.Equal(t, []string{"link", "conflicts_with"}, packages[0].OptionKeys())
+
+	// Options added later follow the declared ones
+	added := packages[0].WithValue("args", ListValue(StringValue("HEAD")))
+	assert.Equal(t, `brew "x", link: false, conflicts_with: ["y"], args: ["HEAD"]`, formatPackage(added))
+}
+
+func TestParser_Includes(t *testing.T) {
+	dir := t.TempDir()
+	common := filepath.Join(dir, "common.Brewfile")
+	base := filepath.Join(dir, "shared", "base.Brewfile")
+	machine := filepath.Join(dir, "Brewfile.mini")
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(base), 0755))
+	require.NoError(t, os.WriteFile(base, []byte(`brew "curl"`+"\n"), 0644))
+	require.NoError(t, os.WriteFile(common, []byte(`include "shared/base.Brewfile"
+brew "git"
+brew "jq", link: true
+`), 0644))
+	require.NoError(t, os.WriteFile(machine, []byte(`tap "homebrew/bundle"
+# Shared packages
+include "common.Brewfile"
+brew "jq", link: false
+cask "firefox"
+`), 0644))
+
+	packages, err := Parse(machine)
+	require.NoError(t, err)
+	require.Len(t, packages, 5)
+
+	assert.Equal(t, "tap:homebrew/bundle", packages[0].ID())
+	assert.Empty(t, packages[0].Source)
+
+	// Included packages appear where the include directive is
+	assert.Equal(t, "brew:curl", packages[1].ID())
+	assert.Equal(t, base, packages[1].Source)
+	assert.Equal(t, "from base.Brewfile", packages[1].Provenance())
+	assert.Equal(t, "brew:git", packages[2].ID())
+	assert.Equal(t, common, packages[2].Source)
+	assert.Empty(t, packages[2].Description, "comment above include is not a description")
+
+	// The including file's own declaration wins
+	assert.Equal(t, "brew:jq", packages[3].ID())
+	assert.Empty(t, packages[3].Source)
+	assert.Equal(t, BoolValue(false), packages[3].Options["link"])
+
+	included, err := IncludedPackages(machine)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"curl", "git"}, included.Names())
+}
+
+func TestParser_IncludeErrors(t *testing.T) {
+	t.Run("cycle", func(t *testing.T) {
+		dir := t.TempDir()
+		a := filepath.Join(dir, "a.Brewfile")
+		b := filepath.Join(dir, "b.Brewfile")
+		require.NoError(t, os.WriteFile(a, []byte("include \"b.Brewfile\"\nbrew \"git\"\n"), 0644))
+		require.NoError(t, os.WriteFile(b, []byte("include \"a.Brewfile\"\n"), 0644))
+
+		_, err := Parse(a)
+		require.Error(t, err)
+		assert.ErrorIs(t, err, ErrIncludeCycle)
+		assert.Contains(t, err.Error(), "a.Brewfile -> b.Brewfile -> a.Brewfile")
+	})
+
+	t.Run("self include", func(t *testing.T) {
+		dir := t.TempDir()
+		a := filepath.Join(dir, "Brewfile")
+		require.NoError(t, os.WriteFile(a, []byte("include \"Brewfile\"\n"), 0644))
+
+		_, err := Parse(a)
+		assert.ErrorIs(t, err, ErrIncludeCycle)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		dir := t.TempDir()
+		a := filepath.Join(dir, "Brewfile")
+		require.NoError(t, os.WriteFile(a, []byte("include \"missing.Brewfile\"\n"), 0644))
+
+		_, err := Parse(a)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to include missing.Brewfile")
+	})
+}
+
+func TestIncludePath(t *testing.T) {
+	home, err := os.UserHomeDir()
+	require.NoError(t, err)
+
+	assert.Equal(t, filepath.Join("/dotfiles", "common.Brewfile"), IncludePath("/dotfiles", "common.Brewfile"))
+	assert.Equal(t, "/etc/Brewfile", IncludePath("/dotfiles", "/etc/Brewfile"))
+	assert.Equal(t, filepath.Join(home, "common.Brewfile"), IncludePath("/dotfiles", "~/common.Brewfile"))
+}
@@ -0,0 +1,29 @@
+package brewfile
+
+// macOSOnlyTypes are package types that only exist on macOS
+var macOSOnlyTypes = map[PackageType]bool{
+	TypeCask: true,
+	TypeMas:  true,
+}
+
+// SupportedOn reports whether packages of this type can be installed on
+// the given operating system ("darwin", "linux"). Every type is supported
+// on an unknown system.
+func (t PackageType) SupportedOn(goos string) bool {
+	return goos == "" || goos == "darwin" || !macOSOnlyTypes[t]
+}
+
+// UnsupportedTypes returns the package types that cannot be installed on
+// at least one of the given operating systems
+func UnsupportedTypes(systems ...string) []PackageType {
+	var types []PackageType
+	for _, t := range AllTypes() {
+		for _, goos := range systems {
+			if !t.SupportedOn(goos) {
+				types = append(types, t)
+				break
+			}
+		}
+	}
+	return types
+}
@@ -0,0 +1,60 @@
+package brewfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageType_SupportedOn(t *testing.T) {
+	tests := []struct {
+		pkgType PackageType
+		goos    string
+		want    bool
+	}{
+		{TypeBrew, "linux", true},
+		{TypeTap, "linux", true},
+		{TypeGo, "linux", true},
+		{TypeVSCode, "linux", true},
+		{TypeCask, "linux", false},
+		{TypeMas, "linux", false},
+		{TypeCask, "darwin", true},
+		{TypeMas, "darwin", true},
+		{TypeCask, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.pkgType)+"/"+tt.goos, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.pkgType.SupportedOn(tt.goos))
+		})
+	}
+}
+
+func TestUnsupportedTypes(t *testing.T) {
+	assert.Empty(t, UnsupportedTypes())
+	assert.Empty(t, UnsupportedTypes("darwin", ""))
+	assert.ElementsMatch(t, []PackageType{TypeCask, TypeMas}, UnsupportedTypes("darwin", "linux"))
+}
+
+func TestDiffResult_ExcludeTypes(t *testing.T) {
+	diff := Diff(
+		Packages{
+			NewPackage(TypeBrew, "git"),
+			NewPackage(TypeCask, "slack"),
+			NewPackage(TypeMas, "Xcode"),
+			NewPackage(TypeCask, "raycast"),
+		},
+		Packages{
+			NewPackage(TypeBrew, "bat"),
+			NewPackage(TypeCask, "orbstack"),
+			NewPackage(TypeCask, "raycast"),
+		},
+	)
+
+	filtered := diff.ExcludeTypes(TypeCask, TypeMas)
+
+	assert.Equal(t, Packages{NewPackage(TypeBrew, "git")}, filtered.Additions)
+	assert.Equal(t, Packages{NewPackage(TypeBrew, "bat")}, filtered.Removals)
+	assert.Empty(t, filtered.Common)
+	assert.Same(t, diff, diff.ExcludeTypes())
+}
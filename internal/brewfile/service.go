@@ -0,0 +1,85 @@
+package brewfile
+
+// Options that keep a formula's service running. brew bundle dump records
+// running services as restart_service: :changed, so BrewSync does the same.
+const (
+	RestartServiceOption = "restart_service"
+	StartServiceOption   = "start_service"
+)
+
+// ServiceDetail is the ChangeDetail key for a formula whose service runs on
+// one machine and not the other
+const ServiceDetail = "service"
+
+// Service states reported in change details
+const (
+	ServiceStateRunning = "running"
+	ServiceStateStopped = "stopped"
+)
+
+// ServiceRunning reports whether a formula is declared with its service running
+func (p Package) ServiceRunning() bool {
+	if p.Type != TypeBrew {
+		return false
+	}
+	for _, key := range []string{RestartServiceOption, StartServiceOption} {
+		v, ok := p.Options[key]
+		if !ok {
+			continue
+		}
+		if (v.Kind == KindBool && v.Bool) || v.Kind == KindSymbol {
+			return true
+		}
+	}
+	return false
+}
+
+// WithService returns the formula declared with its service running or
+// stopped. A formula already declared as running keeps its options.
+func (p Package) WithService(running bool) Package {
+	if p.Type != TypeBrew || running == p.ServiceRunning() {
+		return p
+	}
+	if running {
+		return p.WithValue(RestartServiceOption, SymbolValue("changed"))
+	}
+	opts := p.Options.Clone()
+	delete(opts, RestartServiceOption)
+	delete(opts, StartServiceOption)
+	if len(opts) == 0 {
+		opts = nil
+	}
+	p.Options = opts
+	return p
+}
+
+// serviceState names a service state for change details
+func serviceState(running bool) string {
+	if running {
+		return ServiceStateRunning
+	}
+	return ServiceStateStopped
+}
+
+// ServiceChanged reports whether a formula's service runs on one machine
+// and not the other
+func (c Change) ServiceChanged() bool {
+	return c.Source.Type == TypeBrew && c.Source.ServiceRunning() != c.Current.ServiceRunning()
+}
+
+// ServiceOnly reports whether only the formula's service options differ, so
+// starting or stopping the service is enough to converge it
+func (c Change) ServiceOnly() bool {
+	return c.OptionsOnly(ServiceDetail, RestartServiceOption, StartServiceOption)
+}
+
+// ServiceChanges returns the changed formulae whose service state differs
+func (d *DiffResult) ServiceChanges() []Change {
+	var changes []Change
+	for _, c := range d.Changed {
+		if c.ServiceChanged() {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
@@ -0,0 +1,93 @@
+package brewfile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackage_ServiceRunning(t *testing.T) {
+	tests := []struct {
+		name string
+		pkg  Package
+		want bool
+	}{
+		{"no options", NewPackage(TypeBrew, "redis"), false},
+		{"restart changed", NewPackage(TypeBrew, "redis").WithOption("restart_service", ":changed"), true},
+		{"restart true", NewPackage(TypeBrew, "redis").WithOption("restart_service", "true"), true},
+		{"restart false", NewPackage(TypeBrew, "redis").WithOption("restart_service", "false"), false},
+		{"start true", NewPackage(TypeBrew, "redis").WithOption("start_service", "true"), true},
+		{"cask", NewPackage(TypeCask, "docker").WithOption("restart_service", "true"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.pkg.ServiceRunning())
+		})
+	}
+}
+
+func TestPackage_WithService(t *testing.T) {
+	redis := NewPackage(TypeBrew, "redis").WithOption("link", "false")
+
+	running := redis.WithService(true)
+	assert.True(t, running.ServiceRunning())
+	assert.Equal(t, SymbolValue("changed"), running.Options[RestartServiceOption])
+	assert.False(t, redis.ServiceRunning(), "original is not modified")
+
+	// A formula already declared as running keeps its options
+	started := NewPackage(TypeBrew, "redis").WithOption("start_service", "true")
+	assert.Equal(t, started, started.WithService(true))
+
+	stopped := running.WithService(false)
+	assert.False(t, stopped.ServiceRunning())
+	assert.Equal(t, Options{"link": BoolValue(false)}, stopped.Options)
+
+	assert.Nil(t, NewPackage(TypeBrew, "redis").WithService(true).WithService(false).Options)
+	assert.Equal(t, NewPackage(TypeCask, "docker"), NewPackage(TypeCask, "docker").WithService(true))
+}
+
+func TestDiff_ServiceChanges(t *testing.T) {
+	source := Packages{
+		NewPackage(TypeBrew, "postgresql@16").WithOption("restart_service", ":changed"),
+		NewPackage(TypeBrew, "redis"),
+		NewPackage(TypeBrew, "nginx").WithOption("restart_service", "true").WithOption("link", "false"),
+		NewPackage(TypeBrew, "mysql").WithOption("start_service", "true"),
+	}
+	current := Packages{
+		NewPackage(TypeBrew, "postgresql@16"),
+		NewPackage(TypeBrew, "redis").WithOption("restart_service", ":changed"),
+		NewPackage(TypeBrew, "nginx"),
+		NewPackage(TypeBrew, "mysql").WithOption("restart_service", ":changed"),
+	}
+
+	diff := Diff(source, current)
+	require.Len(t, diff.Changed, 4)
+
+	services := diff.ServiceChanges()
+	require.Len(t, services, 3)
+	assert.Equal(t, "brew:postgresql@16", services[0].ID())
+	assert.Equal(t, "brew:redis", services[1].ID())
+	assert.Equal(t, "brew:nginx", services[2].ID())
+
+	nginx := services[2]
+	assert.False(t, nginx.ServiceOnly())
+	assert.Equal(t, []ChangeDetail{
+		{Key: "service", Source: "running", Current: "stopped"},
+		{Key: "link", Source: "false", Current: ""},
+	}, nginx.Details())
+
+	redis := services[1]
+	assert.True(t, redis.ServiceOnly())
+	assert.Equal(t, "service: stopped → running", redis.Details()[0].String())
+
+	// Both machines run mysql's service, only the option spelling differs
+	var mysql Change
+	for _, c := range diff.Changed {
+		if c.Source.Name == "mysql" {
+			mysql = c
+		}
+	}
+	assert.False(t, mysql.ServiceChanged())
+	assert.True(t, mysql.ServiceOnly())
+}
@@ -0,0 +1,164 @@
+package brewfile
+
+import "strings"
+
+// PackageSet is an ordered set of packages with O(1) membership checks.
+// Packages keep their insertion order; each key appears at most once.
+type PackageSet struct {
+	items []Package
+	index map[string]int
+	key   func(Package) string
+}
+
+// NewPackageSet creates a set keyed by package ID. When the same ID is
+// added more than once, the first package is kept.
+func NewPackageSet(packages ...Package) *PackageSet {
+	return NewPackageSetFunc(Package.ID, packages...)
+}
+
+// NewPackageSetFunc creates a set that identifies packages by a custom key,
+// e.g. a Normalizer's Key so that aliases collapse into one entry
+func NewPackageSetFunc(key func(Package) string, packages ...Package) *PackageSet {
+	s := &PackageSet{
+		index: make(map[string]int, len(packages)),
+		key:   key,
+	}
+	s.Add(packages...)
+	return s
+}
+
+// Set returns the packages as a PackageSet keyed by ID
+func (ps Packages) Set() *PackageSet {
+	return NewPackageSet(ps...)
+}
+
+// ParseID converts a package ID such as "brew:git" back into a package
+func ParseID(id string) (Package, bool) {
+	t, name, ok := strings.Cut(id, ":")
+	if !ok || name == "" {
+		return Package{}, false
+	}
+	pkgType, err := ParsePackageType(t)
+	if err != nil {
+		return Package{}, false
+	}
+	return NewPackage(pkgType, name), true
+}
+
+// Len returns the number of packages in the set
+func (s *PackageSet) Len() int {
+	return len(s.items)
+}
+
+// Add appends packages whose keys are not yet present and returns how many were added
+func (s *PackageSet) Add(packages ...Package) int {
+	added := 0
+	for _, pkg := range packages {
+		k := s.key(pkg)
+		if _, ok := s.index[k]; ok {
+			continue
+		}
+		s.index[k] = len(s.items)
+		s.items = append(s.items, pkg)
+		added++
+	}
+	return added
+}
+
+// Put adds a package or replaces the one with the same key, keeping its position
+func (s *PackageSet) Put(pkg Package) {
+	k := s.key(pkg)
+	if i, ok := s.index[k]; ok {
+		s.items[i] = pkg
+		return
+	}
+	s.index[k] = len(s.items)
+	s.items = append(s.items, pkg)
+}
+
+// Remove deletes the package with the given key. Returns false if absent.
+func (s *PackageSet) Remove(key string) bool {
+	i, ok := s.index[key]
+	if !ok {
+		return false
+	}
+	delete(s.index, key)
+	s.items = append(s.items[:i], s.items[i+1:]...)
+	for j := i; j < len(s.items); j++ {
+		s.index[s.key(s.items[j])] = j
+	}
+	return true
+}
+
+// Contains checks if a package with the given key is in the set
+func (s *PackageSet) Contains(key string) bool {
+	_, ok := s.index[key]
+	return ok
+}
+
+// Has checks if the set contains a package with the same key as pkg
+func (s *PackageSet) Has(pkg Package) bool {
+	return s.Contains(s.key(pkg))
+}
+
+// Get returns the package with the given key
+func (s *PackageSet) Get(key string) (Package, bool) {
+	i, ok := s.index[key]
+	if !ok {
+		return Package{}, false
+	}
+	return s.items[i], true
+}
+
+// Lookup returns the set's package with the same key as pkg
+func (s *PackageSet) Lookup(pkg Package) (Package, bool) {
+	return s.Get(s.key(pkg))
+}
+
+// Packages returns the packages in insertion order
+func (s *PackageSet) Packages() Packages {
+	result := make(Packages, len(s.items))
+	copy(result, s.items)
+	return result
+}
+
+// Union returns the packages of s followed by those of other not in s
+func (s *PackageSet) Union(other *PackageSet) *PackageSet {
+	result := s.clone()
+	result.Add(other.items...)
+	return result
+}
+
+// Intersect returns the packages of s that are also in other, in s's order
+func (s *PackageSet) Intersect(other *PackageSet) *PackageSet {
+	return s.filter(func(pkg Package) bool { return other.Has(pkg) })
+}
+
+// Difference returns the packages of s that are not in other, in s's order
+func (s *PackageSet) Difference(other *PackageSet) *PackageSet {
+	return s.filter(func(pkg Package) bool { return !other.Has(pkg) })
+}
+
+// SymmetricDifference returns the packages in exactly one of the sets:
+// those only in s followed by those only in other
+func (s *PackageSet) SymmetricDifference(other *PackageSet) *PackageSet {
+	result := s.Difference(other)
+	result.Add(other.Difference(s).items...)
+	return result
+}
+
+// clone returns a copy of the set with the same key function
+func (s *PackageSet) clone() *PackageSet {
+	return NewPackageSetFunc(s.key, s.items...)
+}
+
+// filter returns a set of the packages matching keep, in order
+func (s *PackageSet) filter(keep func(Package) bool) *PackageSet {
+	result := NewPackageSetFunc(s.key)
+	for _, pkg := range s.items {
+		if keep(pkg) {
+			result.Add(pkg)
+		}
+	}
+	return result
+}
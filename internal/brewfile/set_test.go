@@ -0,0 +1,172 @@
+package brewfile
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func packageIDs(pkgs Packages) []string {
+	ids := make([]string, len(pkgs))
+	for i, pkg := range pkgs {
+		ids[i] = pkg.ID()
+	}
+	return ids
+}
+
+func setIDs(s *PackageSet) []string {
+	return packageIDs(s.Packages())
+}
+
+func TestPackageSet_AddKeepsOrder(t *testing.T) {
+	s := NewPackageSet(
+		NewPackage(TypeBrew, "jq"),
+		NewPackage(TypeBrew, "git"),
+		NewPackage(TypeBrew, "jq"),
+	)
+
+	assert.Equal(t, 2, s.Len())
+	assert.Equal(t, []string{"brew:jq", "brew:git"}, setIDs(s))
+
+	added := s.Add(NewPackage(TypeBrew, "git"), NewPackage(TypeCask, "git"))
+	assert.Equal(t, 1, added)
+	assert.Equal(t, []string{"brew:jq", "brew:git", "cask:git"}, setIDs(s))
+}
+
+func TestPackageSet_Membership(t *testing.T) {
+	s := NewPackageSet(NewPackage(TypeBrew, "git"))
+
+	assert.True(t, s.Contains("brew:git"))
+	assert.True(t, s.Has(NewPackage(TypeBrew, "git")))
+	assert.False(t, s.Has(NewPackage(TypeCask, "git")))
+
+	pkg, ok := s.Get("brew:git")
+	assert.True(t, ok)
+	assert.Equal(t, "git", pkg.Name)
+
+	_, ok = s.Get("brew:jq")
+	assert.False(t, ok)
+}
+
+func TestPackageSet_Put(t *testing.T) {
+	s := NewPackageSet(
+		Package{Type: TypeBrew, Name: "git", Description: "old"},
+		NewPackage(TypeBrew, "jq"),
+	)
+
+	s.Put(Package{Type: TypeBrew, Name: "git", Description: "new"})
+	s.Put(NewPackage(TypeBrew, "fzf"))
+
+	assert.Equal(t, []string{"brew:git", "brew:jq", "brew:fzf"}, setIDs(s))
+	pkg, _ := s.Get("brew:git")
+	assert.Equal(t, "new", pkg.Description)
+}
+
+func TestPackageSet_Remove(t *testing.T) {
+	s := NewPackageSet(
+		NewPackage(TypeBrew, "git"),
+		NewPackage(TypeBrew, "jq"),
+		NewPackage(TypeBrew, "fzf"),
+	)
+
+	assert.True(t, s.Remove("brew:git"))
+	assert.False(t, s.Remove("brew:git"))
+	assert.Equal(t, []string{"brew:jq", "brew:fzf"}, setIDs(s))
+
+	// The index must still point at the right positions
+	pkg, ok := s.Get("brew:fzf")
+	assert.True(t, ok)
+	assert.Equal(t, "fzf", pkg.Name)
+}
+
+func TestPackageSet_Operations(t *testing.T) {
+	a := NewPackageSet(
+		NewPackage(TypeBrew, "git"),
+		NewPackage(TypeBrew, "jq"),
+		NewPackage(TypeCask, "raycast"),
+	)
+	b := NewPackageSet(
+		NewPackage(TypeBrew, "fzf"),
+		NewPackage(TypeCask, "raycast"),
+		NewPackage(TypeBrew, "git"),
+	)
+
+	tests := []struct {
+		name     string
+		result   *PackageSet
+		expected []string
+	}{
+		{"union", a.Union(b), []string{"brew:git", "brew:jq", "cask:raycast", "brew:fzf"}},
+		{"intersect", a.Intersect(b), []string{"brew:git", "cask:raycast"}},
+		{"difference", a.Difference(b), []string{"brew:jq"}},
+		{"reverse difference", b.Difference(a), []string{"brew:fzf"}},
+		{"symmetric difference", a.SymmetricDifference(b), []string{"brew:jq", "brew:fzf"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, setIDs(tt.result))
+		})
+	}
+
+	// Operations must not modify their operands
+	assert.Equal(t, 3, a.Len())
+	assert.Equal(t, 3, b.Len())
+}
+
+func TestPackageSet_PackagesIsCopy(t *testing.T) {
+	s := NewPackageSet(NewPackage(TypeBrew, "git"))
+	pkgs := s.Packages()
+	pkgs[0].Name = "jq"
+
+	assert.True(t, s.Contains("brew:git"))
+	pkg, _ := s.Get("brew:git")
+	assert.Equal(t, "git", pkg.Name)
+}
+
+func TestPackageSet_CustomKey(t *testing.T) {
+	key := func(pkg Package) string {
+		return string(pkg.Type) + ":" + strings.ToLower(pkg.Name)
+	}
+	s := NewPackageSetFunc(key,
+		NewPackage(TypeVSCode, "GoLang.Go"),
+		NewPackage(TypeVSCode, "golang.go"),
+	)
+
+	assert.Equal(t, 1, s.Len())
+	assert.True(t, s.Has(NewPackage(TypeVSCode, "GOLANG.GO")))
+
+	pkg, ok := s.Lookup(NewPackage(TypeVSCode, "golang.go"))
+	assert.True(t, ok)
+	assert.Equal(t, "GoLang.Go", pkg.Name)
+
+	// Derived sets keep the key function
+	other := NewPackageSetFunc(key, NewPackage(TypeVSCode, "golang.GO"))
+	assert.Equal(t, 1, s.Intersect(other).Len())
+}
+
+func TestParseID(t *testing.T) {
+	tests := []struct {
+		id       string
+		expected Package
+		ok       bool
+	}{
+		{"brew:git", NewPackage(TypeBrew, "git"), true},
+		{"tap:homebrew/core", NewPackage(TypeTap, "homebrew/core"), true},
+		{"vscode:golang.go", NewPackage(TypeVSCode, "golang.go"), true},
+		{"git", Package{}, false},
+		{"brew:", Package{}, false},
+		{"unknown:git", Package{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			pkg, ok := ParseID(tt.id)
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok {
+				assert.Equal(t, tt.expected.ID(), pkg.ID())
+			}
+		})
+	}
+}
@@ -0,0 +1,348 @@
+package brewfile
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// nodeKind identifies the type of a top-level Brewfile node
+type nodeKind int
+
+const (
+	nodeEntry   nodeKind = iota // a statement such as brew "git"
+	nodeComment                 // a standalone comment line
+	nodeBlank                   // an empty line
+	nodeInvalid                 // a statement that could not be parsed
+)
+
+// entry is a parsed Brewfile statement such as `brew "git", link: false`
+type entry struct {
+	keyword string
+	args    []Value     // positional arguments
+	options []HashEntry // keyword options in source order
+	optPos  []position  // position of each option key
+	start   position    // position of the keyword
+	end     int         // byte offset just past the last token of the statement
+	endLine int         // line of the last token of the statement
+	comment string      // trailing comment on the last line, if any
+}
+
+// syntaxError describes a statement that could not be parsed
+type syntaxError struct {
+	pos position
+	msg string
+}
+
+func (e *syntaxError) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.pos.line, e.pos.col, e.msg)
+}
+
+// node is a single top-level item of a Brewfile, in source order
+type node struct {
+	kind    nodeKind
+	entry   *entry       // nodeEntry
+	comment string       // nodeComment
+	err     *syntaxError // nodeInvalid
+	pos     position     // start of the node
+	end     int          // byte offset just past the node (excluding the newline)
+	endLine int
+}
+
+// syntaxParser builds nodes from a token stream
+type syntaxParser struct {
+	tokens []token
+	i      int
+}
+
+// parseSyntax parses Brewfile source into top-level nodes.
+// Statements that fail to parse are returned as nodeInvalid so callers
+// can decide whether to skip or report them.
+func parseSyntax(src string) []node {
+	p := &syntaxParser{tokens: tokenize(src)}
+	var nodes []node
+	atLineStart := true
+
+	for {
+		tok := p.peek()
+		switch tok.kind {
+		case tokEOF:
+			return nodes
+
+		case tokNewline:
+			p.i++
+			if atLineStart {
+				nodes = append(nodes, node{kind: nodeBlank, pos: tok.pos, end: tok.pos.offset, endLine: tok.pos.line})
+			}
+			atLineStart = true
+
+		case tokComment:
+			p.i++
+			if atLineStart {
+				nodes = append(nodes, node{kind: nodeComment, comment: tok.text, pos: tok.pos, end: tok.end, endLine: tok.pos.line})
+			}
+			atLineStart = false
+
+		default:
+			atLineStart = false
+			e, err := p.parseStatement()
+			if err != nil {
+				end := p.recover()
+				nodes = append(nodes, node{kind: nodeInvalid, err: err, pos: tok.pos, end: end, endLine: err.pos.line})
+				continue
+			}
+			nodes = append(nodes, node{kind: nodeEntry, entry: e, pos: e.start, end: e.end, endLine: e.endLine})
+		}
+	}
+}
+
+func (p *syntaxParser) peek() token {
+	return p.tokens[p.i]
+}
+
+func (p *syntaxParser) advance() token {
+	tok := p.tokens[p.i]
+	if tok.kind != tokEOF {
+		p.i++
+	}
+	return tok
+}
+
+// skipLayout skips newlines and comments, used inside brackets and after commas
+func (p *syntaxParser) skipLayout() {
+	for p.peek().kind == tokNewline || p.peek().kind == tokComment {
+		p.i++
+	}
+}
+
+// recover skips to the end of the current line and returns its end offset
+func (p *syntaxParser) recover() int {
+	end := p.tokens[p.i].pos.offset
+	for {
+		tok := p.peek()
+		if tok.kind == tokNewline || tok.kind == tokEOF {
+			return end
+		}
+		end = tok.end
+		p.i++
+	}
+}
+
+func errorAt(tok token, format string, args ...interface{}) *syntaxError {
+	if tok.kind == tokIllegal && tok.err != "" {
+		return &syntaxError{pos: tok.pos, msg: tok.err}
+	}
+	return &syntaxError{pos: tok.pos, msg: fmt.Sprintf(format, args...)}
+}
+
+// parseStatement parses: keyword [ '(' ] [ arg { ',' arg } ] [ ')' ] [ comment ]
+func (p *syntaxParser) parseStatement() (*entry, *syntaxError) {
+	kw := p.advance()
+	if kw.kind != tokIdent {
+		return nil, errorAt(kw, "expected a directive, found %s", kw.kind)
+	}
+
+	e := &entry{keyword: kw.text, start: kw.pos, end: kw.end, endLine: kw.pos.line}
+
+	paren := false
+	if p.peek().kind == tokLParen {
+		paren = true
+		p.advance()
+		p.skipLayout()
+	}
+
+	if !p.atStatementEnd(paren) {
+		for {
+			if err := p.parseArg(e); err != nil {
+				return nil, err
+			}
+			if p.peek().kind != tokComma {
+				break
+			}
+			p.advance()
+			// A trailing comma continues the statement on the next line
+			p.skipLayout()
+		}
+	}
+
+	if paren {
+		p.skipLayout()
+		tok := p.advance()
+		if tok.kind != tokRParen {
+			return nil, errorAt(tok, "expected ')', found %s", tok.kind)
+		}
+	}
+
+	last := p.tokens[p.i-1]
+	e.end = last.end
+	e.endLine = last.pos.line
+
+	switch tok := p.peek(); tok.kind {
+	case tokComment:
+		e.comment = tok.text
+		p.advance()
+	case tokNewline, tokEOF:
+	default:
+		return nil, errorAt(tok, "unexpected %s after %s entry", tok.kind, e.keyword)
+	}
+	return e, nil
+}
+
+// atStatementEnd reports whether the statement has no (more) arguments
+func (p *syntaxParser) atStatementEnd(paren bool) bool {
+	switch p.peek().kind {
+	case tokNewline, tokEOF, tokComment:
+		return true
+	case tokRParen:
+		return paren
+	}
+	return false
+}
+
+// parseArg parses a positional argument or a keyword option
+func (p *syntaxParser) parseArg(e *entry) *syntaxError {
+	tok := p.peek()
+	if tok.kind == tokLabel {
+		p.advance()
+		p.skipLayout()
+		v, err := p.parseValue()
+		if err != nil {
+			return err
+		}
+		e.options = append(e.options, HashEntry{Key: tok.text, Value: v})
+		e.optPos = append(e.optPos, tok.pos)
+		return nil
+	}
+
+	v, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+
+	// "key" => value or :key => value
+	if p.peek().kind == tokArrow {
+		if v.Kind != KindString && v.Kind != KindSymbol {
+			return errorAt(tok, "hash key must be a string or symbol")
+		}
+		p.advance()
+		p.skipLayout()
+		val, err := p.parseValue()
+		if err != nil {
+			return err
+		}
+		e.options = append(e.options, HashEntry{Key: v.Str, Value: val})
+		e.optPos = append(e.optPos, tok.pos)
+		return nil
+	}
+
+	if len(e.options) > 0 {
+		return errorAt(tok, "positional argument after options")
+	}
+	e.args = append(e.args, v)
+	return nil
+}
+
+// parseValue parses a single literal value
+func (p *syntaxParser) parseValue() (Value, *syntaxError) {
+	tok := p.advance()
+	switch tok.kind {
+	case tokString:
+		return StringValue(tok.text), nil
+	case tokSymbol:
+		return SymbolValue(tok.text), nil
+	case tokInt:
+		i, err := strconv.ParseInt(tok.text, 10, 64)
+		if err != nil {
+			return Value{}, errorAt(tok, "invalid integer %q", tok.text)
+		}
+		return IntValue(i), nil
+	case tokIdent:
+		switch tok.text {
+		case "true":
+			return BoolValue(true), nil
+		case "false":
+			return BoolValue(false), nil
+		case "nil":
+			return NilValue(), nil
+		}
+		return Value{}, errorAt(tok, "unsupported expression %q", tok.text)
+	case tokLBracket:
+		return p.parseList(tok)
+	case tokLBrace:
+		return p.parseHash(tok)
+	case tokLabel:
+		return Value{}, errorAt(tok, "unexpected label %q", tok.text+":")
+	}
+	return Value{}, errorAt(tok, "expected a value, found %s", tok.kind)
+}
+
+// parseList parses the rest of [a, b, c] after the opening bracket
+func (p *syntaxParser) parseList(open token) (Value, *syntaxError) {
+	items := []Value{}
+	for {
+		p.skipLayout()
+		if p.peek().kind == tokRBracket {
+			p.advance()
+			return ListValue(items...), nil
+		}
+		v, err := p.parseValue()
+		if err != nil {
+			return Value{}, err
+		}
+		items = append(items, v)
+		p.skipLayout()
+		switch tok := p.advance(); tok.kind {
+		case tokComma:
+		case tokRBracket:
+			return ListValue(items...), nil
+		case tokEOF:
+			return Value{}, errorAt(open, "unclosed '['")
+		default:
+			return Value{}, errorAt(tok, "expected ',' or ']' in list, found %s", tok.kind)
+		}
+	}
+}
+
+// parseHash parses the rest of { key: value, "k" => v } after the opening brace
+func (p *syntaxParser) parseHash(open token) (Value, *syntaxError) {
+	entries := []HashEntry{}
+	for {
+		p.skipLayout()
+		if p.peek().kind == tokRBrace {
+			p.advance()
+			return HashValue(entries...), nil
+		}
+
+		var key string
+		tok := p.advance()
+		switch tok.kind {
+		case tokLabel:
+			key = tok.text
+		case tokString, tokSymbol:
+			key = tok.text
+			p.skipLayout()
+			if arrow := p.advance(); arrow.kind != tokArrow {
+				return Value{}, errorAt(arrow, "expected '=>' after hash key, found %s", arrow.kind)
+			}
+		default:
+			return Value{}, errorAt(tok, "expected hash key, found %s", tok.kind)
+		}
+
+		p.skipLayout()
+		v, err := p.parseValue()
+		if err != nil {
+			return Value{}, err
+		}
+		entries = append(entries, HashEntry{Key: key, Value: v})
+
+		p.skipLayout()
+		switch tok := p.advance(); tok.kind {
+		case tokComma:
+		case tokRBrace:
+			return HashValue(entries...), nil
+		case tokEOF:
+			return Value{}, errorAt(open, "unclosed '{'")
+		default:
+			return Value{}, errorAt(tok, "expected ',' or '}' in hash, found %s", tok.kind)
+		}
+	}
+}
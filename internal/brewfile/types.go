@@ -2,6 +2,7 @@ package brewfile
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 )
 
@@ -19,9 +20,10 @@ const (
 	TypeMas         PackageType = "mas"
 )
 
-// AllTypes returns all package types
+// AllTypes returns all package types, followed by the custom types
+// registered from config
 func AllTypes() []PackageType {
-	return []PackageType{
+	return append([]PackageType{
 		TypeTap,
 		TypeBrew,
 		TypeCask,
@@ -30,7 +32,7 @@ func AllTypes() []PackageType {
 		TypeAntigravity,
 		TypeGo,
 		TypeMas,
-	}
+	}, CustomTypes()...)
 }
 
 // ParsePackageType parses a string into a PackageType
@@ -53,17 +55,26 @@ func ParsePackageType(s string) (PackageType, error) {
 	case "mas":
 		return TypeMas, nil
 	default:
+		if t := PackageType(strings.ToLower(s)); IsCustomType(t) {
+			return t, nil
+		}
 		return "", fmt.Errorf("unknown package type: %s", s)
 	}
 }
 
 // Package represents a single package entry
 type Package struct {
-	Type        PackageType       `json:"type" yaml:"type"`
-	Name        string            `json:"name" yaml:"name"`
-	FullName    string            `json:"full_name,omitempty" yaml:"full_name,omitempty"` // For mas: app name
-	Options     map[string]string `json:"options,omitempty" yaml:"options,omitempty"`     // link: true, id: 123, etc.
-	Description string            `json:"description,omitempty" yaml:"description,omitempty"`
+	Type        PackageType `json:"type" yaml:"type"`
+	Name        string      `json:"name" yaml:"name"`
+	FullName    string      `json:"full_name,omitempty" yaml:"full_name,omitempty"` // For mas: app name
+	URL         string      `json:"url,omitempty" yaml:"url,omitempty"`             // For tap: custom clone URL
+	Options     Options     `json:"options,omitempty" yaml:"options,omitempty"`     // link: true, id: 123, etc.
+	Description string      `json:"description,omitempty" yaml:"description,omitempty"`
+	Source      string      `json:"source,omitempty" yaml:"source,omitempty"` // Included Brewfile that declared the package, empty for the file itself
+
+	// optionOrder is the order the options were written in, kept only when
+	// it is not sorted so the Writer can reproduce it
+	optionOrder []string
 }
 
 // NewPackage creates a new package
@@ -74,15 +85,52 @@ func NewPackage(t PackageType, name string) Package {
 	}
 }
 
-// WithOption adds an option to the package
+// WithOption adds an option to the package, inferring its type from the
+// string ("true" becomes a boolean, "123" an integer, ":sym" a symbol)
 func (p Package) WithOption(key, value string) Package {
-	if p.Options == nil {
-		p.Options = make(map[string]string)
+	return p.WithValue(key, InferValue(value))
+}
+
+// WithValue adds a typed option to the package
+func (p Package) WithValue(key string, value Value) Package {
+	opts := p.Options.Clone()
+	if opts == nil {
+		opts = make(Options)
 	}
-	p.Options[key] = value
+	opts[key] = value
+	p.Options = opts
 	return p
 }
 
+// OptionKeys returns the option keys in the order they were declared.
+// Options added since, and those of packages built in code, follow in
+// sorted order.
+func (p Package) OptionKeys() []string {
+	keys := make([]string, 0, len(p.Options))
+	seen := make(map[string]bool, len(p.optionOrder))
+	for _, k := range p.optionOrder {
+		if _, ok := p.Options[k]; ok && !seen[k] {
+			keys = append(keys, k)
+			seen[k] = true
+		}
+	}
+	for _, k := range p.Options.Keys() {
+		if !seen[k] {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// Provenance returns a short label for where an included package came from,
+// e.g. "from common.Brewfile", or an empty string for local packages
+func (p Package) Provenance() string {
+	if p.Source == "" {
+		return ""
+	}
+	return "from " + filepath.Base(p.Source)
+}
+
 // ID returns a unique identifier for the package
 func (p Package) ID() string {
 	return fmt.Sprintf("%s:%s", p.Type, p.Name)
@@ -150,36 +198,36 @@ func (ps Packages) Contains(id string) bool {
 // AddUnique appends packages that don't already exist in the collection
 // Returns the updated Packages with only unique packages added
 func (ps Packages) AddUnique(packages ...Package) Packages {
+	seen := ps.Set()
 	result := ps
 	for _, pkg := range packages {
-		if !ps.Contains(pkg.ID()) {
+		if seen.Add(pkg) > 0 {
 			result = append(result, pkg)
 		}
 	}
 	return result
 }
 
-// MergeUnique combines two package lists, removing duplicates
-// If a package exists in both, the one from 'other' is used (preserving descriptions)
-func (ps Packages) MergeUnique(other Packages) Packages {
-	// Create a map for quick lookup
-	seen := make(map[string]Package)
+// Exclude returns the packages whose IDs do not appear in other
+func (ps Packages) Exclude(other Packages) Packages {
+	excluded := other.Set()
 
-	// Add packages from current list
+	var result Packages
 	for _, p := range ps {
-		seen[p.ID()] = p
+		if !excluded.Has(p) {
+			result = append(result, p)
+		}
 	}
+	return result
+}
 
-	// Add or update with packages from other list
+// MergeUnique combines two package lists, removing duplicates
+// If a package exists in both, the one from 'other' is used (preserving descriptions)
+// The result keeps the order of ps followed by the new packages of other.
+func (ps Packages) MergeUnique(other Packages) Packages {
+	set := ps.Set()
 	for _, p := range other {
-		seen[p.ID()] = p
+		set.Put(p)
 	}
-
-	// Convert back to slice
-	result := make(Packages, 0, len(seen))
-	for _, p := range seen {
-		result = append(result, p)
-	}
-
-	return result
+	return set.Packages()
 }
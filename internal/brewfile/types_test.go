@@ -69,14 +69,14 @@ func TestPackage_WithOption(t *testing.T) {
 
 	t.Run("add first option", func(t *testing.T) {
 		newPkg := pkg.WithOption("link", "true")
-		assert.Equal(t, "true", newPkg.Options["link"])
+		assert.Equal(t, "true", newPkg.Options["link"].String())
 		assert.Nil(t, pkg.Options, "original should be unchanged")
 	})
 
 	t.Run("add multiple options", func(t *testing.T) {
 		newPkg := pkg.WithOption("link", "true").WithOption("force", "true")
-		assert.Equal(t, "true", newPkg.Options["link"])
-		assert.Equal(t, "true", newPkg.Options["force"])
+		assert.Equal(t, "true", newPkg.Options["link"].String())
+		assert.Equal(t, "true", newPkg.Options["force"].String())
 	})
 }
 
@@ -232,6 +232,7 @@ func TestPackages_MergeUnique(t *testing.T) {
 
 	result := list1.MergeUnique(list2)
 	assert.Len(t, result, 3) // git, raycast, fzf
+	assert.Equal(t, []string{"git", "raycast", "fzf"}, result.Names())
 
 	// Verify git has the new description (from list2)
 	for _, p := range result {
@@ -240,3 +241,15 @@ func TestPackages_MergeUnique(t *testing.T) {
 		}
 	}
 }
+
+func TestPackages_Exclude(t *testing.T) {
+	pkgs := Packages{
+		NewPackage(TypeBrew, "git"),
+		NewPackage(TypeBrew, "jq"),
+		NewPackage(TypeCask, "git"),
+	}
+	result := pkgs.Exclude(Packages{NewPackage(TypeBrew, "git")})
+	assert.Len(t, result, 2)
+	assert.Equal(t, "brew:jq", result[0].ID())
+	assert.Equal(t, "cask:git", result[1].ID())
+}
@@ -0,0 +1,419 @@
+package brewfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ValueKind identifies the Ruby type of an option value
+type ValueKind int
+
+const (
+	KindString ValueKind = iota
+	KindBool
+	KindSymbol
+	KindInt
+	KindNil
+	KindList
+	KindHash
+)
+
+// String returns the name of the kind
+func (k ValueKind) String() string {
+	switch k {
+	case KindString:
+		return "string"
+	case KindBool:
+		return "bool"
+	case KindSymbol:
+		return "symbol"
+	case KindInt:
+		return "int"
+	case KindNil:
+		return "nil"
+	case KindList:
+		return "list"
+	case KindHash:
+		return "hash"
+	default:
+		return "unknown"
+	}
+}
+
+// Value is a typed option value from the Ruby subset used by Brewfiles
+type Value struct {
+	Kind ValueKind
+	Str  string      // KindString and KindSymbol (symbol without the leading colon)
+	Bool bool        // KindBool
+	Int  int64       // KindInt
+	List []Value     // KindList
+	Hash []HashEntry // KindHash, in source order
+}
+
+// HashEntry is a single key/value pair of a Ruby hash
+type HashEntry struct {
+	Key   string
+	Value Value
+}
+
+// Options holds the keyword options of a Brewfile entry (link: false, args: [...], etc.)
+type Options map[string]Value
+
+// StringValue creates a string value
+func StringValue(s string) Value {
+	return Value{Kind: KindString, Str: s}
+}
+
+// BoolValue creates a boolean value
+func BoolValue(b bool) Value {
+	return Value{Kind: KindBool, Bool: b}
+}
+
+// SymbolValue creates a symbol value; a leading colon is optional
+func SymbolValue(s string) Value {
+	return Value{Kind: KindSymbol, Str: strings.TrimPrefix(s, ":")}
+}
+
+// IntValue creates an integer value
+func IntValue(i int64) Value {
+	return Value{Kind: KindInt, Int: i}
+}
+
+// NilValue creates a nil value
+func NilValue() Value {
+	return Value{Kind: KindNil}
+}
+
+// ListValue creates a list value
+func ListValue(items ...Value) Value {
+	return Value{Kind: KindList, List: items}
+}
+
+// HashValue creates a hash value from ordered entries
+func HashValue(entries ...HashEntry) Value {
+	return Value{Kind: KindHash, Hash: entries}
+}
+
+// InferValue converts a plain string into the most likely typed value.
+// "true"/"false" become booleans, ":sym" becomes a symbol, digits become
+// an integer and anything else stays a string.
+func InferValue(s string) Value {
+	switch s {
+	case "true":
+		return BoolValue(true)
+	case "false":
+		return BoolValue(false)
+	}
+	if len(s) > 1 && strings.HasPrefix(s, ":") && isIdentString(s[1:]) {
+		return SymbolValue(s)
+	}
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return IntValue(i)
+	}
+	return StringValue(s)
+}
+
+// String returns a plain representation of the value.
+// Strings are returned unquoted, symbols keep their leading colon and
+// collections are rendered as Ruby literals.
+func (v Value) String() string {
+	switch v.Kind {
+	case KindString:
+		return v.Str
+	case KindSymbol:
+		return ":" + v.Str
+	case KindBool:
+		return strconv.FormatBool(v.Bool)
+	case KindInt:
+		return strconv.FormatInt(v.Int, 10)
+	case KindNil:
+		return "nil"
+	default:
+		return v.Ruby()
+	}
+}
+
+// Ruby returns the value formatted as a Ruby literal
+func (v Value) Ruby() string {
+	switch v.Kind {
+	case KindString:
+		return quoteRuby(v.Str)
+	case KindSymbol:
+		if isIdentString(v.Str) {
+			return ":" + v.Str
+		}
+		return ":" + quoteRuby(v.Str)
+	case KindList:
+		parts := make([]string, len(v.List))
+		for i, item := range v.List {
+			parts[i] = item.Ruby()
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	case KindHash:
+		if len(v.Hash) == 0 {
+			return "{}"
+		}
+		parts := make([]string, len(v.Hash))
+		for i, e := range v.Hash {
+			parts[i] = formatPair(e.Key, e.Value)
+		}
+		return "{ " + strings.Join(parts, ", ") + " }"
+	default:
+		return v.String()
+	}
+}
+
+// Strings returns the elements of a list value as plain strings.
+// A scalar value is returned as a single-element slice.
+func (v Value) Strings() []string {
+	if v.Kind != KindList {
+		return []string{v.String()}
+	}
+	result := make([]string, len(v.List))
+	for i, item := range v.List {
+		result[i] = item.String()
+	}
+	return result
+}
+
+// Get returns the value stored under key in a hash value
+func (v Value) Get(key string) (Value, bool) {
+	for _, e := range v.Hash {
+		if e.Key == key {
+			return e.Value, true
+		}
+	}
+	return Value{}, false
+}
+
+// Equal reports whether two values are identical
+func (v Value) Equal(other Value) bool {
+	if v.Kind != other.Kind {
+		return false
+	}
+	switch v.Kind {
+	case KindString, KindSymbol:
+		return v.Str == other.Str
+	case KindBool:
+		return v.Bool == other.Bool
+	case KindInt:
+		return v.Int == other.Int
+	case KindNil:
+		return true
+	case KindList:
+		if len(v.List) != len(other.List) {
+			return false
+		}
+		for i := range v.List {
+			if !v.List[i].Equal(other.List[i]) {
+				return false
+			}
+		}
+		return true
+	case KindHash:
+		if len(v.Hash) != len(other.Hash) {
+			return false
+		}
+		for _, e := range v.Hash {
+			ov, ok := other.Get(e.Key)
+			if !ok || !e.Value.Equal(ov) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// MarshalJSON encodes the value as the closest native JSON type.
+// Symbols are encoded as strings with a leading colon.
+func (v Value) MarshalJSON() ([]byte, error) {
+	return json.Marshal(v.native())
+}
+
+// UnmarshalJSON decodes a value previously produced by MarshalJSON
+func (v *Value) UnmarshalJSON(data []byte) error {
+	var raw interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	val, err := fromNative(raw)
+	if err != nil {
+		return err
+	}
+	*v = val
+	return nil
+}
+
+// MarshalYAML encodes the value as the closest native YAML type
+func (v Value) MarshalYAML() (interface{}, error) {
+	return v.native(), nil
+}
+
+// native converts the value into plain Go types for serialization
+func (v Value) native() interface{} {
+	switch v.Kind {
+	case KindString:
+		return v.Str
+	case KindSymbol:
+		return ":" + v.Str
+	case KindBool:
+		return v.Bool
+	case KindInt:
+		return v.Int
+	case KindList:
+		items := make([]interface{}, len(v.List))
+		for i, item := range v.List {
+			items[i] = item.native()
+		}
+		return items
+	case KindHash:
+		m := make(map[string]interface{}, len(v.Hash))
+		for _, e := range v.Hash {
+			m[e.Key] = e.Value.native()
+		}
+		return m
+	default:
+		return nil
+	}
+}
+
+// fromNative converts decoded JSON data back into a Value
+func fromNative(raw interface{}) (Value, error) {
+	switch x := raw.(type) {
+	case nil:
+		return NilValue(), nil
+	case bool:
+		return BoolValue(x), nil
+	case float64:
+		return IntValue(int64(x)), nil
+	case string:
+		if len(x) > 1 && strings.HasPrefix(x, ":") {
+			return SymbolValue(x), nil
+		}
+		return StringValue(x), nil
+	case []interface{}:
+		items := make([]Value, 0, len(x))
+		for _, item := range x {
+			val, err := fromNative(item)
+			if err != nil {
+				return Value{}, err
+			}
+			items = append(items, val)
+		}
+		return ListValue(items...), nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		entries := make([]HashEntry, 0, len(keys))
+		for _, k := range keys {
+			val, err := fromNative(x[k])
+			if err != nil {
+				return Value{}, err
+			}
+			entries = append(entries, HashEntry{Key: k, Value: val})
+		}
+		return HashValue(entries...), nil
+	default:
+		return Value{}, fmt.Errorf("unsupported option value %v", raw)
+	}
+}
+
+// Keys returns the option keys in sorted order
+func (o Options) Keys() []string {
+	keys := make([]string, 0, len(o))
+	for k := range o {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Equal reports whether two option sets hold the same keys and values
+func (o Options) Equal(other Options) bool {
+	if len(o) != len(other) {
+		return false
+	}
+	for k, v := range o {
+		ov, ok := other[k]
+		if !ok || !v.Equal(ov) {
+			return false
+		}
+	}
+	return true
+}
+
+// Clone returns a shallow copy of the options
+func (o Options) Clone() Options {
+	if o == nil {
+		return nil
+	}
+	clone := make(Options, len(o))
+	for k, v := range o {
+		clone[k] = v
+	}
+	return clone
+}
+
+// formatPair formats a key/value pair using label syntax when possible
+func formatPair(key string, v Value) string {
+	if isIdentString(key) {
+		return fmt.Sprintf("%s: %s", key, v.Ruby())
+	}
+	return fmt.Sprintf("%s => %s", quoteRuby(key), v.Ruby())
+}
+
+// quoteRuby quotes a string as a double-quoted Ruby literal
+func quoteRuby(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for i, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\t':
+			sb.WriteString(`\t`)
+		case '#':
+			// Avoid accidental string interpolation
+			if i+1 < len(s) && strings.ContainsRune("{$@", rune(s[i+1])) {
+				sb.WriteString(`\#`)
+			} else {
+				sb.WriteRune(r)
+			}
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// isIdentString reports whether s is a valid bare Ruby identifier
+func isIdentString(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		switch {
+		case r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'):
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				return false
+			}
+		case (r == '?' || r == '!') && i == len(s)-1:
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,85 @@
+package brewfile
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInferValue(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected Value
+	}{
+		{"true", BoolValue(true)},
+		{"false", BoolValue(false)},
+		{"497799835", IntValue(497799835)},
+		{":changed", SymbolValue("changed")},
+		{"Xcode", StringValue("Xcode")},
+		{":", StringValue(":")},
+		{"", StringValue("")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			assert.Equal(t, tc.expected, InferValue(tc.input))
+		})
+	}
+}
+
+func TestValue_Ruby(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    Value
+		expected string
+	}{
+		{"string", StringValue("Xcode"), `"Xcode"`},
+		{"escaped string", StringValue(`a "b" \c`), `"a \"b\" \\c"`},
+		{"interpolation", StringValue("#{x} #1"), `"\#{x} #1"`},
+		{"symbol", SymbolValue("changed"), ":changed"},
+		{"quoted symbol", SymbolValue("foo-bar"), `:"foo-bar"`},
+		{"int", IntValue(-3), "-3"},
+		{"nil", NilValue(), "nil"},
+		{"empty list", ListValue(), "[]"},
+		{"empty hash", HashValue(), "{}"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.value.Ruby())
+		})
+	}
+}
+
+func TestValue_Equal(t *testing.T) {
+	a := HashValue(
+		HashEntry{Key: "a", Value: IntValue(1)},
+		HashEntry{Key: "b", Value: ListValue(StringValue("x"))},
+	)
+	b := HashValue(
+		HashEntry{Key: "b", Value: ListValue(StringValue("x"))},
+		HashEntry{Key: "a", Value: IntValue(1)},
+	)
+	assert.True(t, a.Equal(b), "hash equality ignores key order")
+	assert.False(t, StringValue("changed").Equal(SymbolValue("changed")))
+	assert.False(t, ListValue(IntValue(1)).Equal(ListValue(IntValue(1), IntValue(2))))
+}
+
+func TestOptions_JSONRoundTrip(t *testing.T) {
+	opts := Options{
+		"id":              IntValue(497799835),
+		"link":            BoolValue(false),
+		"restart_service": SymbolValue("changed"),
+		"args":            ListValue(StringValue("HEAD")),
+		"cask_args":       HashValue(HashEntry{Key: "appdir", Value: StringValue("~/Applications")}),
+	}
+
+	data, err := json.Marshal(opts)
+	require.NoError(t, err)
+
+	var decoded Options
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.True(t, opts.Equal(decoded))
+}
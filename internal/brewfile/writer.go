@@ -7,6 +7,9 @@ import (
 	"strings"
 )
 
+// writerTypeOrder is the order in which package groups are written
+var writerTypeOrder = []PackageType{TypeTap, TypeBrew, TypeCask, TypeMas, TypeVSCode, TypeCursor, TypeAntigravity, TypeGo}
+
 // Writer writes packages to Brewfile format
 type Writer struct {
 	packages Packages
@@ -30,10 +33,7 @@ func (w *Writer) Format() string {
 	// Group packages by type
 	byType := w.packages.ByType()
 
-	// Write in specific order
-	typeOrder := []PackageType{TypeTap, TypeBrew, TypeCask, TypeMas, TypeVSCode, TypeCursor, TypeAntigravity, TypeGo}
-
-	for _, t := range typeOrder {
+	for _, t := range writerOrder() {
 		pkgs, ok := byType[t]
 		if !ok || len(pkgs) == 0 {
 			continue
@@ -45,7 +45,7 @@ func (w *Writer) Format() string {
 		})
 
 		// Add section comment for non-standard types
-		if t == TypeCursor || t == TypeAntigravity || t == TypeGo {
+		if isExtensionType(t) {
 			sb.WriteString(fmt.Sprintf("\n# %s (brewsync extension)\n", t))
 		} else if sb.Len() > 0 {
 			sb.WriteString("\n")
@@ -67,86 +67,50 @@ func (w *Writer) Format() string {
 // formatPackage formats a single package entry
 func formatPackage(p Package) string {
 	switch p.Type {
-	case TypeTap:
-		return fmt.Sprintf(`tap "%s"`, p.Name)
-
-	case TypeBrew:
-		if len(p.Options) > 0 {
-			return fmt.Sprintf(`brew "%s", %s`, p.Name, formatOptions(p.Options))
+	case TypeTap, TypeBrew, TypeCask, TypeMas, TypeVSCode, TypeCursor, TypeAntigravity, TypeGo:
+	default:
+		if !IsCustomType(p.Type) {
+			return fmt.Sprintf(`# unknown type: %s "%s"`, p.Type, p.Name)
 		}
-		return fmt.Sprintf(`brew "%s"`, p.Name)
+	}
 
-	case TypeCask:
-		if len(p.Options) > 0 {
-			return fmt.Sprintf(`cask "%s", %s`, p.Name, formatOptions(p.Options))
+	name := p.Name
+	// mas entries are written with the app name, the id lives in the options
+	if p.Type == TypeMas && p.FullName != "" {
+		if _, ok := p.Options["id"]; ok {
+			name = p.FullName
 		}
-		return fmt.Sprintf(`cask "%s"`, p.Name)
-
-	case TypeMas:
-		// mas entries need the id option
-		if id, ok := p.Options["id"]; ok {
-			name := p.FullName
-			if name == "" {
-				name = p.Name
-			}
-			return fmt.Sprintf(`mas "%s", id: %s`, name, id)
-		}
-		return fmt.Sprintf(`mas "%s"`, p.Name)
-
-	case TypeVSCode:
-		return fmt.Sprintf(`vscode "%s"`, p.Name)
-
-	case TypeCursor:
-		return fmt.Sprintf(`cursor "%s"`, p.Name)
-
-	case TypeAntigravity:
-		return fmt.Sprintf(`antigravity "%s"`, p.Name)
-
-	case TypeGo:
-		return fmt.Sprintf(`go "%s"`, p.Name)
+	}
 
-	default:
-		return fmt.Sprintf(`# unknown type: %s "%s"`, p.Type, p.Name)
+	parts := []string{fmt.Sprintf("%s %s", p.Type, quoteRuby(name))}
+	if p.Type == TypeTap && p.URL != "" {
+		parts = append(parts, quoteRuby(p.URL))
 	}
+	if len(p.Options) > 0 {
+		parts = append(parts, formatOptions(p))
+	}
+	return strings.Join(parts, ", ")
 }
 
-// formatOptions formats package options as Ruby hash syntax
-func formatOptions(opts map[string]string) string {
-	var parts []string
-	for k, v := range opts {
-		// Handle special cases
-		if v == "true" || v == "false" {
-			parts = append(parts, fmt.Sprintf("%s: %s", k, v))
-		} else if strings.HasPrefix(v, ":") {
-			// Symbol value
-			parts = append(parts, fmt.Sprintf("%s: %s", k, v))
-		} else if _, err := fmt.Sscanf(v, "%d", new(int)); err == nil {
-			// Numeric value
-			parts = append(parts, fmt.Sprintf("%s: %s", k, v))
-		} else {
-			// String value
-			parts = append(parts, fmt.Sprintf(`%s: "%s"`, k, v))
-		}
+// formatOptions formats package options as Ruby hash syntax, in the order
+// they were declared
+func formatOptions(p Package) string {
+	parts := make([]string, 0, len(p.Options))
+	for _, k := range p.OptionKeys() {
+		parts = append(parts, formatPair(k, p.Options[k]))
 	}
-	sort.Strings(parts)
 	return strings.Join(parts, ", ")
 }
 
-// Append adds packages to an existing Brewfile
+// Append adds packages to an existing Brewfile, inserting each one into its
+// group and leaving the rest of the file untouched
 func Append(path string, packages Packages) error {
-	content := ""
-	if data, err := os.ReadFile(path); err == nil {
-		content = string(data)
+	doc, err := LoadDocument(path)
+	if err != nil {
+		return err
 	}
-
-	// Add newline if file doesn't end with one
-	if content != "" && !strings.HasSuffix(content, "\n") {
-		content += "\n"
+	for _, pkg := range packages {
+		doc.Insert(pkg)
 	}
-
-	// Add new packages
-	w := NewWriter(packages)
-	content += w.Format()
-
-	return os.WriteFile(path, []byte(content), 0644)
+	return doc.Save(path)
 }
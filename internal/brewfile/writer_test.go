@@ -234,44 +234,62 @@ func TestAppend(t *testing.T) {
 func TestFormatOptions(t *testing.T) {
 	testCases := []struct {
 		name     string
-		options  map[string]string
+		options  Options
 		expected string
 	}{
 		{
 			name:     "boolean true",
-			options:  map[string]string{"link": "true"},
+			options:  Options{"link": BoolValue(true)},
 			expected: "link: true",
 		},
 		{
 			name:     "boolean false",
-			options:  map[string]string{"link": "false"},
+			options:  Options{"link": BoolValue(false)},
 			expected: "link: false",
 		},
 		{
 			name:     "numeric value",
-			options:  map[string]string{"id": "497799835"},
+			options:  Options{"id": IntValue(497799835)},
 			expected: "id: 497799835",
 		},
 		{
 			name:     "string value",
-			options:  map[string]string{"name": "Xcode"},
+			options:  Options{"name": StringValue("Xcode")},
 			expected: `name: "Xcode"`,
 		},
 		{
 			name:     "symbol value",
-			options:  map[string]string{"args": ":head"},
+			options:  Options{"args": SymbolValue("head")},
 			expected: "args: :head",
 		},
 		{
 			name:     "multiple options sorted",
-			options:  map[string]string{"link": "true", "force": "true"},
+			options:  Options{"link": BoolValue(true), "force": BoolValue(true)},
 			expected: "force: true, link: true",
 		},
+		{
+			name:     "array value",
+			options:  Options{"args": ListValue(StringValue("with-foo"), StringValue("HEAD"))},
+			expected: `args: ["with-foo", "HEAD"]`,
+		},
+		{
+			name: "hash value",
+			options: Options{"args": HashValue(
+				HashEntry{Key: "appdir", Value: StringValue("~/Applications")},
+				HashEntry{Key: "require_sha", Value: BoolValue(true)},
+			)},
+			expected: `args: { appdir: "~/Applications", require_sha: true }`,
+		},
+		{
+			name:     "nil value",
+			options:  Options{"conflicts_with": NilValue()},
+			expected: "conflicts_with: nil",
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			result := formatOptions(tc.options)
+			result := formatOptions(Package{Options: tc.options})
 			assert.Equal(t, tc.expected, result)
 		})
 	}
@@ -72,6 +72,7 @@ var (
 	addMachineHostname    string
 	addMachineBrewfile    string
 	addMachineDescription string
+	addMachineOS          string
 )
 
 var configAddMachineCmd = &cobra.Command{
@@ -92,6 +93,7 @@ func init() {
 	configAddMachineCmd.Flags().StringVar(&addMachineHostname, "hostname", "", "hostname for auto-detection")
 	configAddMachineCmd.Flags().StringVar(&addMachineBrewfile, "brewfile", "", "path to Brewfile")
 	configAddMachineCmd.Flags().StringVar(&addMachineDescription, "description", "", "machine description")
+	configAddMachineCmd.Flags().StringVar(&addMachineOS, "os", "", "operating system: darwin, linux (detected from its Brewfile when empty)")
 
 	configCmd.AddCommand(configShowCmd)
 	configCmd.AddCommand(configEditCmd)
@@ -309,6 +311,7 @@ func runConfigInit(cmd *cobra.Command, args []string) error {
 				"hostname":    machineHostname,
 				"brewfile":    brewfilePath,
 				"description": description,
+				"os":          config.LocalOS(),
 			},
 		},
 		"current_machine":     "auto",
@@ -325,6 +328,9 @@ func runConfigInit(cmd *cobra.Command, args []string) error {
 		"dump": map[string]interface{}{
 			"use_brew_bundle": true,
 		},
+		"go_tools": map[string]interface{}{
+			"versions": "latest",
+		},
 		"machine_specific": map[string]interface{}{},
 		"output": map[string]interface{}{
 			"color":             true,
@@ -381,6 +387,9 @@ func runConfigInit(cmd *cobra.Command, args []string) error {
 
 func runConfigAddMachine(cmd *cobra.Command, args []string) error {
 	machineName := args[0]
+	if err := config.ValidateOS(addMachineOS); err != nil {
+		return err
+	}
 
 	path, err := config.ConfigPath()
 	if err != nil {
@@ -431,6 +440,10 @@ func runConfigAddMachine(cmd *cobra.Command, args []string) error {
 		machineConfig["description"] = addMachineDescription
 	}
 
+	if addMachineOS != "" {
+		machineConfig["os"] = addMachineOS
+	}
+
 	machines[machineName] = machineConfig
 
 	// Ensure directory exists
@@ -11,12 +11,16 @@ import (
 
 	"github.com/andrew-sameh/brewsync/internal/brewfile"
 	"github.com/andrew-sameh/brewsync/internal/config"
+	"github.com/andrew-sameh/brewsync/internal/installer"
+	"github.com/andrew-sameh/brewsync/internal/system"
+	"github.com/andrew-sameh/brewsync/pkg/version"
 )
 
 var (
-	diffFrom   string
-	diffOnly   []string
-	diffFormat string
+	diffFrom     string
+	diffOnly     []string
+	diffFormat   string
+	diffVersions bool
 )
 
 var diffCmd = &cobra.Command{
@@ -31,7 +35,25 @@ Examples:
   brewsync diff                  # Compare with default source
   brewsync diff --from air       # Compare with specific machine
   brewsync diff --only brew,cask # Filter to specific types
-  brewsync diff --format json    # Output as JSON`,
+  brewsync diff --format json    # Output as JSON
+  brewsync diff --versions       # Version drift of shared packages
+
+Package names are normalized before comparing, so tap-qualified names,
+aliases, renamed formulae and differently-cased extension ids match
+(see 'brewsync names'). Normalized entries are reported.
+
+Packages declared on both machines with different options (e.g. link),
+tap URLs or mas ids are listed as changed. Formulae whose Homebrew service
+runs on one machine and not the other are reported as
+"service: running → stopped".
+
+With --versions, packages present on both machines are compared using the
+lock files written by dump, reporting those installed at different versions.
+
+The system facts recorded by the source machine's last dump (architecture,
+macOS version, Homebrew prefix) are compared with this machine. Packages
+that may not work here, such as Intel-only casks on Apple Silicon or casks
+requiring a newer macOS, are reported under Compatibility.`,
 	RunE: runDiff,
 }
 
@@ -39,6 +61,7 @@ func init() {
 	diffCmd.Flags().StringVar(&diffFrom, "from", "", "source machine to compare with")
 	diffCmd.Flags().StringSliceVar(&diffOnly, "only", nil, "only include these package types")
 	diffCmd.Flags().StringVar(&diffFormat, "format", "table", "output format: table, json")
+	diffCmd.Flags().BoolVar(&diffVersions, "versions", false, "report version drift between machines")
 	rootCmd.AddCommand(diffCmd)
 }
 
@@ -110,13 +133,254 @@ func runDiff(cmd *cobra.Command, args []string) error {
 		diff = brewfile.Diff(sourcePackages, currentPackages)
 	}
 
+	if diffVersions {
+		return runDiffVersions(diff, sourceMachine.Brewfile, current.Brewfile, source, currentMachine)
+	}
+
+	diff, unsupported := excludeUnsupported(cfg, diff, source, currentMachine)
+	compat := checkCompat(cfg, []string{source}, diff.Additions)
+	compat.Notes = append(compat.Notes, unsupported...)
+
 	// Output results
 	switch diffFormat {
 	case "json":
-		return outputDiffJSON(diff)
+		return outputDiffJSON(diff, compat)
+	default:
+		return outputDiffTable(diff, source, currentMachine, compat)
+	}
+}
+
+// excludeUnsupported drops the package types that cannot be installed on
+// one of the machines from a diff, e.g. casks when one of them runs Linux,
+// and returns a note for each machine that caused types to be skipped
+func excludeUnsupported(cfg *config.Config, diff *brewfile.DiffResult, machines ...string) (*brewfile.DiffResult, []string) {
+	var notes []string
+	for _, name := range machines {
+		types := cfg.UnsupportedTypes(name)
+		if len(types) == 0 {
+			continue
+		}
+		names := make([]string, len(types))
+		for i, t := range types {
+			names[i] = string(t)
+		}
+		notes = append(notes, fmt.Sprintf("%s runs %s; %s packages are skipped",
+			name, cfg.MachineOS(name), strings.Join(names, ", ")))
+	}
+	return diff.ExcludeTypes(cfg.UnsupportedTypes(machines...)...), notes
+}
+
+// compatReport lists platform differences between source machines and this
+// machine, and source packages that may not work here
+type compatReport struct {
+	Notes  []string
+	Issues []brewfile.CompatIssue
+}
+
+// IsEmpty reports whether there is nothing to warn about
+func (r compatReport) IsEmpty() bool {
+	return len(r.Notes) == 0 && len(r.Issues) == 0
+}
+
+// checkCompat compares the system facts recorded by each source machine's
+// last dump with this machine and checks the packages about to be installed
+func checkCompat(cfg *config.Config, sources []string, packages brewfile.Packages) compatReport {
+	var report compatReport
+	target := system.Facts(version.Version)
+	reqs := packageRequirements(packages)
+
+	seen := make(map[string]bool)
+	for _, source := range sources {
+		facts, ok := brewfile.LoadSystemFacts(cfg.Machines[source].Brewfile)
+		if !ok {
+			printVerbose("No system facts recorded for %s; run 'brewsync dump' there", source)
+		}
+		report.Notes = append(report.Notes, brewfile.CompareSystems(source, facts, target)...)
+		for _, issue := range brewfile.CheckCompat(packages, facts, target, reqs) {
+			if !seen[issue.String()] {
+				seen[issue.String()] = true
+				report.Issues = append(report.Issues, issue)
+			}
+		}
+	}
+	return report
+}
+
+// packageRequirements looks up the platform requirements of the formulae
+// and casks in packages. Failures are reported in verbose mode only.
+func packageRequirements(packages brewfile.Packages) map[string]brewfile.Requirements {
+	brewPkgs := packages.Filter(brewfile.TypeBrew, brewfile.TypeCask)
+	brew := installer.NewBrewInstaller()
+	if len(brewPkgs) == 0 || !brew.IsAvailable() {
+		return nil
+	}
+	reqs, err := brew.Requirements(brewPkgs)
+	if err != nil {
+		printVerbose("Could not read package requirements: %v", err)
+		return nil
+	}
+	return reqs
+}
+
+// compatEntries returns the JSON form of a compatibility report
+func compatEntries(report compatReport) map[string]interface{} {
+	type issueEntry struct {
+		Type   string `json:"type"`
+		Name   string `json:"name"`
+		Reason string `json:"reason"`
+	}
+
+	issues := make([]issueEntry, 0, len(report.Issues))
+	for _, issue := range report.Issues {
+		issues = append(issues, issueEntry{
+			Type:   string(issue.Package.Type),
+			Name:   issue.Package.Name,
+			Reason: issue.Reason,
+		})
+	}
+	notes := report.Notes
+	if notes == nil {
+		notes = []string{}
+	}
+	return map[string]interface{}{
+		"notes":  notes,
+		"issues": issues,
+	}
+}
+
+// printCompat reports platform differences and incompatible packages
+func printCompat(report compatReport) {
+	if report.IsEmpty() || quiet {
+		return
+	}
+	fmt.Println(styleWarning.Render("⚠ Compatibility"))
+	for _, note := range report.Notes {
+		fmt.Println("  " + note)
+	}
+	for _, issue := range report.Issues {
+		fmt.Println("  " + styleBold.Render(issue.Package.ID()) + ": " + issue.Reason)
+	}
+	fmt.Println()
+}
+
+// runDiffVersions reports version drift for packages common to both machines
+func runDiffVersions(diff *brewfile.DiffResult, sourceBrewfile, currentBrewfile, source, current string) error {
+	sourceLock, err := brewfile.LoadLock(brewfile.LockPath(sourceBrewfile))
+	if err != nil {
+		return fmt.Errorf("failed to load source lock file: %w", err)
+	}
+	currentLock, err := brewfile.LoadLock(brewfile.LockPath(currentBrewfile))
+	if err != nil {
+		return fmt.Errorf("failed to load current lock file: %w", err)
+	}
+	if sourceLock.Len() == 0 {
+		printWarning("No lock file for %s; run 'brewsync dump' on that machine", source)
+	}
+	if currentLock.Len() == 0 {
+		printWarning("No lock file for %s; run 'brewsync dump' first", current)
+	}
+
+	drift := diff.VersionDrift(sourceLock, currentLock)
+
+	switch diffFormat {
+	case "json":
+		return outputVersionDriftJSON(drift, len(diff.Common))
 	default:
-		return outputDiffTable(diff, source, currentMachine)
+		return outputVersionDriftTable(drift, source, current)
+	}
+}
+
+func outputVersionDriftJSON(drift []brewfile.VersionDrift, common int) error {
+	type driftEntry struct {
+		Type    string `json:"type"`
+		Name    string `json:"name"`
+		Source  string `json:"source"`
+		Current string `json:"current"`
+	}
+
+	entries := make([]driftEntry, 0, len(drift))
+	for _, d := range drift {
+		entries = append(entries, driftEntry{
+			Type:    string(d.Package.Type),
+			Name:    d.Package.Name,
+			Source:  d.SourceVersion,
+			Current: d.CurrentVersion,
+		})
+	}
+
+	output := map[string]interface{}{
+		"drift":  entries,
+		"common": common,
 	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(output)
+}
+
+func outputVersionDriftTable(drift []brewfile.VersionDrift, source, current string) error {
+	const tableWidth = 80
+
+	headerBox := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(catOverlay0).
+		Padding(0, 2).
+		Width(tableWidth).
+		Align(lipgloss.Center).
+		Foreground(catLavender).
+		Bold(true)
+
+	fmt.Println()
+	fmt.Println(headerBox.Render(fmt.Sprintf("Versions: %s → %s", source, current)))
+	fmt.Println()
+
+	if len(drift) == 0 {
+		msg := lipgloss.NewStyle().
+			Foreground(catGreen).
+			Bold(true).
+			Render("✓ No version drift between shared packages")
+		fmt.Println(msg)
+		fmt.Println()
+		return nil
+	}
+
+	nameWidth := 0
+	for _, d := range drift {
+		if len(d.Package.Name) > nameWidth {
+			nameWidth = len(d.Package.Name)
+		}
+	}
+
+	var rows []string
+	var lastType brewfile.PackageType
+	for _, d := range drift {
+		if d.Package.Type != lastType {
+			if lastType != "" {
+				rows = append(rows, "")
+			}
+			rows = append(rows, lipgloss.NewStyle().
+				Foreground(catMauve).
+				Bold(true).
+				Render(string(d.Package.Type)))
+			lastType = d.Package.Type
+		}
+
+		name := lipgloss.NewStyle().Foreground(catText).Render(fmt.Sprintf("%-*s", nameWidth, d.Package.Name))
+		from := lipgloss.NewStyle().Foreground(catYellow).Render(d.SourceVersion)
+		to := lipgloss.NewStyle().Foreground(catPeach).Render(d.CurrentVersion)
+		rows = append(rows, fmt.Sprintf("  %s  %s → %s", name, from, to))
+	}
+
+	contentBox := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(catOverlay0).
+		Padding(1, 2).
+		Width(tableWidth)
+
+	fmt.Println(contentBox.Render(strings.Join(rows, "\n")))
+	fmt.Println()
+	printInfo("%d packages differ in version (%s → %s)", len(drift), source, current)
+	return nil
 }
 
 func parsePackageTypes(types []string) []brewfile.PackageType {
@@ -142,11 +406,26 @@ func parsePackageTypes(types []string) []brewfile.PackageType {
 	return result
 }
 
-func outputDiffJSON(diff *brewfile.DiffResult) error {
+func outputDiffJSON(diff *brewfile.DiffResult, compat compatReport) error {
 	output := map[string]interface{}{
 		"additions": packageNames(diff.Additions),
 		"removals":  packageNames(diff.Removals),
 		"common":    len(diff.Common),
+		"changed":   changedEntries(diff.Changed),
+	}
+
+	var all brewfile.Packages
+	all = append(all, diff.Additions...)
+	all = append(all, diff.Removals...)
+	all = append(all, diff.Common...)
+	if sources := packageSources(all); len(sources) > 0 {
+		output["sources"] = sources
+	}
+	if len(diff.Normalized) > 0 {
+		output["normalized"] = diff.Normalized
+	}
+	if !compat.IsEmpty() {
+		output["compatibility"] = compatEntries(compat)
 	}
 
 	enc := json.NewEncoder(os.Stdout)
@@ -163,7 +442,88 @@ func packageNames(pkgs brewfile.Packages) map[string][]string {
 	return result
 }
 
-func outputDiffTable(diff *brewfile.DiffResult, source, current string) error {
+// changedEntry is the JSON form of a package whose declaration differs
+type changedEntry struct {
+	Type    string                  `json:"type"`
+	Name    string                  `json:"name"`
+	Fields  []string                `json:"fields"`
+	Details []brewfile.ChangeDetail `json:"details"`
+}
+
+func changedEntries(changes []brewfile.Change) []changedEntry {
+	entries := make([]changedEntry, 0, len(changes))
+	for _, c := range changes {
+		entries = append(entries, changedEntry{
+			Type:    string(c.Source.Type),
+			Name:    c.Source.Name,
+			Fields:  c.Fields(),
+			Details: c.Details(),
+		})
+	}
+	return entries
+}
+
+// formatChangedRows renders changed packages as "~ name  key: from → to" lines
+func formatChangedRows(changes []brewfile.Change, tableWidth int) []string {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	var rows []string
+	header := lipgloss.NewStyle().
+		Foreground(catYellow).
+		Bold(true).
+		Render(fmt.Sprintf("≠ Changed (%d)", len(changes)))
+	rows = append(rows, header)
+
+	separator := lipgloss.NewStyle().
+		Foreground(catOverlay0).
+		Render(strings.Repeat("─", tableWidth-4))
+	rows = append(rows, separator)
+
+	for _, c := range changes {
+		prefix := lipgloss.NewStyle().
+			Foreground(catYellow).
+			Bold(true).
+			Render("~")
+		pkgName := lipgloss.NewStyle().
+			Foreground(catText).
+			Render(fmt.Sprintf("%s:%s", c.Source.Type, c.Source.Name))
+		rows = append(rows, fmt.Sprintf("  %s %s", prefix, pkgName))
+
+		for _, d := range c.Details() {
+			detail := lipgloss.NewStyle().
+				Foreground(catOverlay1).
+				Render(d.String())
+			rows = append(rows, "      "+detail)
+		}
+	}
+	rows = append(rows, "")
+	return rows
+}
+
+// packageSources maps the IDs of included packages to the file that declared them
+func packageSources(pkgs brewfile.Packages) map[string]string {
+	result := make(map[string]string)
+	for _, pkg := range pkgs {
+		if pkg.Source != "" {
+			result[pkg.ID()] = pkg.Source
+		}
+	}
+	return result
+}
+
+// formatProvenance renders an included package's origin, e.g. "(from common.Brewfile)"
+func formatProvenance(pkg brewfile.Package) string {
+	if pkg.Source == "" {
+		return ""
+	}
+	return " " + lipgloss.NewStyle().
+		Foreground(catOverlay1).
+		Render("("+pkg.Provenance()+")")
+}
+
+func outputDiffTable(diff *brewfile.DiffResult, source, current string, compat compatReport) error {
 	cfg, _ := config.Get()
 
 	const tableWidth = 80
@@ -206,6 +566,8 @@ func outputDiffTable(diff *brewfile.DiffResult, source, current string) error {
 		content := lipgloss.JoinHorizontal(lipgloss.Left, successIcon, " ", msg)
 		fmt.Println(noDiffBox.Render(content))
 		fmt.Println()
+		printNormalized(diff.Normalized)
+		printCompat(compat)
 		return nil
 	}
 
@@ -247,6 +609,13 @@ func outputDiffTable(diff *brewfile.DiffResult, source, current string) error {
 		brewfile.TypeGo:          {"🔷", catSapphire},
 		brewfile.TypeMas:         {"🍎", catRed},
 	}
+	for _, t := range brewfile.CustomTypes() {
+		typeOrder = append(typeOrder, t)
+		typeInfo[t] = struct {
+			icon  string
+			color lipgloss.Color
+		}{customTypeIcon(t), catLavender}
+	}
 
 	var allRows []string
 
@@ -293,7 +662,7 @@ func outputDiffTable(diff *brewfile.DiffResult, source, current string) error {
 
 				pkgName := lipgloss.NewStyle().
 					Foreground(catText).
-					Render(pkg.Name)
+					Render(pkg.Name) + formatProvenance(pkg)
 
 				ignored := ignoredIDs != nil && ignoredIDs[pkg.ID()]
 				if ignored {
@@ -325,7 +694,7 @@ func outputDiffTable(diff *brewfile.DiffResult, source, current string) error {
 
 				pkgName := lipgloss.NewStyle().
 					Foreground(catText).
-					Render(pkg.Name)
+					Render(pkg.Name) + formatProvenance(pkg)
 
 				ignored := ignoredIDs != nil && ignoredIDs[pkg.ID()]
 				if ignored {
@@ -376,6 +745,9 @@ func outputDiffTable(diff *brewfile.DiffResult, source, current string) error {
 		allRows = append(allRows, "")
 	}
 
+	// Packages on both machines whose options or arguments differ
+	allRows = append(allRows, formatChangedRows(diff.Changed, tableWidth)...)
+
 	// Content box
 	contentBox := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
@@ -399,10 +771,24 @@ func outputDiffTable(diff *brewfile.DiffResult, source, current string) error {
 	summaryText := diff.Summary()
 	fmt.Println(summaryBox.Render(summaryText))
 	fmt.Println()
+	printNormalized(diff.Normalized)
+	printCompat(compat)
 
 	return nil
 }
 
+// printNormalized reports entries whose names were canonicalized before comparing
+func printNormalized(normalized []brewfile.Normalization) {
+	if len(normalized) == 0 || quiet {
+		return
+	}
+	fmt.Println(styleDim.Render(fmt.Sprintf("Normalized %d package names before comparing:", len(normalized))))
+	for _, n := range normalized {
+		fmt.Println(styleDim.Render("  " + n.String()))
+	}
+	fmt.Println()
+}
+
 func formatPackagesByType(pkgs brewfile.Packages, prefix string, prefixColor lipgloss.Color, ignoredIDs map[string]bool) []string {
 	var lines []string
 
@@ -432,6 +818,13 @@ func formatPackagesByType(pkgs brewfile.Packages, prefix string, prefixColor lip
 		brewfile.TypeGo:          {"🔷", catSapphire},
 		brewfile.TypeMas:         {"🍎", catRed},
 	}
+	for _, t := range brewfile.CustomTypes() {
+		typeOrder = append(typeOrder, t)
+		typeInfo[t] = struct {
+			icon  string
+			color lipgloss.Color
+		}{customTypeIcon(t), catLavender}
+	}
 
 	for _, t := range typeOrder {
 		typePkgs := byType[t]
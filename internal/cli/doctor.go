@@ -3,13 +3,16 @@ package cli
 import (
 	"fmt"
 	"os"
+	"runtime"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
 
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
 	"github.com/andrew-sameh/brewsync/internal/config"
 	"github.com/andrew-sameh/brewsync/internal/exec"
+	"github.com/andrew-sameh/brewsync/internal/installer"
 	"github.com/andrew-sameh/brewsync/pkg/version"
 )
 
@@ -24,7 +27,12 @@ Validates:
   - Ignore file exists
   - Current machine is detected
   - Brewfile paths exist
-  - Required CLI tools are available (brew, code, cursor, antigravity, mas, go)`,
+  - Required CLI tools are available (brew, code, cursor, antigravity, mas, go)
+  - Homebrew is installed in its default prefix: /opt/homebrew on Apple
+    Silicon, /usr/local on Intel Macs, /home/linuxbrew/.linuxbrew on Linux
+
+On Linux, the Mac App Store CLI is not needed and casks and mas packages
+are skipped.`,
 	RunE: runDoctor,
 }
 
@@ -209,25 +217,48 @@ func checkDefaultSource(cfg *config.Config) checkResult {
 func checkCLITools() []checkResult {
 	var results []checkResult
 
-	tools := []struct {
+	mgr := installer.NewManager()
+	type tool struct {
 		name     string
 		command  string
 		required bool
-	}{
-		{"Homebrew", "brew", true},
-		{"brew bundle", "brew", true}, // Will check bundle separately
-		{"VSCode CLI", "code", false},
-		{"Cursor CLI", "cursor", false},
-		{"Antigravity CLI", "agy", false},
-		{"Mac App Store CLI", "mas", false},
-		{"Go", "go", false},
+		pkgType  brewfile.PackageType // type whose capabilities are shown
+	}
+	tools := []tool{
+		{"Homebrew", "brew", true, brewfile.TypeBrew},
+		{"brew bundle", "brew", true, ""}, // Will check bundle separately
 	}
+	// Built-in editors and those declared under editors in config
+	for _, editor := range brewfile.Editors() {
+		tools = append(tools, tool{editor.DisplayName() + " CLI", editor.Command, false, editor.Type()})
+	}
+	tools = append(tools,
+		tool{"Mac App Store CLI", "mas", false, brewfile.TypeMas},
+		tool{"Go", "go", false, brewfile.TypeGo},
+	)
+	goos := config.LocalOS()
+	arch := brewfile.NormalizeArch(runtime.GOARCH)
 
 	for _, tool := range tools {
+		if tool.pkgType != "" && !tool.pkgType.SupportedOn(goos) {
+			results = append(results, checkResult{
+				name:    tool.name,
+				ok:      true,
+				message: fmt.Sprintf("Not applicable on %s", goos),
+			})
+			continue
+		}
+
 		if tool.name == "brew bundle" {
 			// Special check for brew bundle
 			_, err := exec.Run("brew", "bundle", "--help")
-			if err != nil {
+			if !exec.Exists("brew") {
+				results = append(results, checkResult{
+					name:    tool.name,
+					ok:      false,
+					message: "Needs Homebrew",
+				})
+			} else if err != nil {
 				results = append(results, checkResult{
 					name:    tool.name,
 					ok:      false,
@@ -244,10 +275,23 @@ func checkCLITools() []checkResult {
 		}
 
 		if exec.Exists(tool.command) {
+			msg := "Installed"
+			if tool.pkgType != "" {
+				msg = describeCapabilities(mgr.Capabilities(tool.pkgType))
+			}
 			results = append(results, checkResult{
 				name:    tool.name,
 				ok:      true,
-				message: "Installed",
+				message: msg,
+			})
+			if tool.pkgType == brewfile.TypeBrew {
+				results = append(results, checkHomebrewPrefix(goos, arch))
+			}
+		} else if prefix, ok := installer.FindBrew(goos, arch); ok && tool.command == "brew" {
+			results = append(results, checkResult{
+				name:    tool.name,
+				ok:      false,
+				message: fmt.Sprintf("Installed in %s but not on PATH. Run: eval \"$(%s/bin/brew shellenv)\"", prefix, prefix),
 			})
 		} else {
 			msg := "Not found"
@@ -262,9 +306,46 @@ func checkCLITools() []checkResult {
 		}
 	}
 
+	// Custom package types declared in config
+	for _, custom := range installer.CustomInstallers() {
+		name := fmt.Sprintf("%s (custom)", custom.Type())
+		if custom.IsAvailable() {
+			results = append(results, checkResult{name: name, ok: true, message: describeCapabilities(custom.Capabilities())})
+		} else {
+			results = append(results, checkResult{name: name, ok: true, message: fmt.Sprintf("Not found (%s packages won't sync)", custom.Type())})
+		}
+	}
+
 	return results
 }
 
+// checkHomebrewPrefix warns when Homebrew lives outside its default prefix,
+// where bottles cannot be poured and every formula builds from source
+func checkHomebrewPrefix(goos, arch string) checkResult {
+	prefix, err := installer.NewBrewInstaller().Prefix()
+	if err != nil {
+		return checkResult{name: "Homebrew prefix", ok: false, message: fmt.Sprintf("Error: %v", err)}
+	}
+	if installer.IsDefaultPrefix(prefix, goos, arch) {
+		return checkResult{name: "Homebrew prefix", ok: true, message: prefix}
+	}
+	return checkResult{
+		name: "Homebrew prefix",
+		ok:   false,
+		message: fmt.Sprintf("%s is not a default prefix (%s); formulae will build from source",
+			prefix, strings.Join(installer.DefaultPrefixes(goos, arch), " or ")),
+	}
+}
+
+// describeCapabilities summarizes what an installed tool supports
+func describeCapabilities(caps installer.Capabilities) string {
+	msg := "Installed: " + caps.String()
+	if !caps.Uninstall {
+		msg += "; " + installer.ManualRemoval
+	}
+	return msg
+}
+
 func printResults(results []checkResult) {
 	const tableWidth = 80
 
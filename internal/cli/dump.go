@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -15,7 +16,10 @@ import (
 	"github.com/andrew-sameh/brewsync/internal/brewfile"
 	"github.com/andrew-sameh/brewsync/internal/config"
 	"github.com/andrew-sameh/brewsync/internal/exec"
+	"github.com/andrew-sameh/brewsync/internal/hooks"
 	"github.com/andrew-sameh/brewsync/internal/installer"
+	"github.com/andrew-sameh/brewsync/internal/system"
+	"github.com/andrew-sameh/brewsync/pkg/version"
 )
 
 var (
@@ -30,13 +34,16 @@ var dumpCmd = &cobra.Command{
 	Long: `Dump captures the current state of installed packages and writes them
 to the machine's Brewfile. This includes:
 - Homebrew taps, formulae, and casks
+- Which Homebrew services are running (restart_service: :changed)
 - VSCode extensions
 - Cursor extensions
 - Antigravity extensions
 - Go tools
 - Mac App Store apps
 
-The Brewfile location is determined from the config for the current machine.`,
+The Brewfile location is determined from the config for the current machine.
+Installed versions and tap commits are recorded alongside it in a
+Brewfile.lock.json-style lock file (e.g. Brewfile.lock.json).`,
 	RunE: runDump,
 }
 
@@ -55,6 +62,7 @@ type dumpModel struct {
 	err            error
 	packages       brewfile.Packages
 	packagesByType map[brewfile.PackageType]int
+	hookOutput     []string // Recent output of the running hook
 }
 
 type dumpStepMsg struct {
@@ -63,6 +71,11 @@ type dumpStepMsg struct {
 	countInfo string
 }
 
+// dumpHookOutputMsg is a line of output from the pre_dump hook
+type dumpHookOutputMsg struct {
+	line string
+}
+
 type dumpCompleteMsg struct {
 	packages brewfile.Packages
 }
@@ -95,6 +108,9 @@ func (m dumpModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case dumpStepMsg:
+		if msg.step != m.step {
+			m.hookOutput = nil
+		}
 		m.step = msg.step
 		if msg.packages != nil {
 			m.packages = append(m.packages, msg.packages...)
@@ -104,6 +120,13 @@ func (m dumpModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case dumpHookOutputMsg:
+		m.hookOutput = append(m.hookOutput, msg.line)
+		if len(m.hookOutput) > 5 {
+			m.hookOutput = m.hookOutput[len(m.hookOutput)-5:]
+		}
+		return m, nil
+
 	case dumpCompleteMsg:
 		m.done = true
 		m.packages = msg.packages
@@ -138,6 +161,9 @@ func (m dumpModel) View() string {
 	if m.step != "" {
 		s.WriteString(fmt.Sprintf("%s %s\n", m.spinner.View(), m.step))
 	}
+	for _, line := range m.hookOutput {
+		s.WriteString(styleDim.Render("  "+line) + "\n")
+	}
 
 	// Show completed steps
 	for _, info := range m.completed {
@@ -173,18 +199,52 @@ func runDump(cmd *cobra.Command, args []string) error {
 
 	// If quiet mode, run without animation
 	if quiet {
-		return runDumpQuiet(cfg, machine, brewfilePath)
+		return runDumpQuiet(commandContext(cmd), cfg, machine, brewfilePath)
 	}
 
 	// Run with animation
-	return runDumpAnimated(cfg, machine, brewfilePath)
+	return runDumpAnimated(commandContext(cmd), cfg, machine, brewfilePath)
+}
+
+// autoDump dumps the current machine's Brewfile after packages changed,
+// committing and pushing as configured under auto_dump
+func autoDump(cfg *config.Config, currentMachine string) {
+	printInfo("Auto-dumping Brewfile...")
+
+	// Set flags for commit/push based on config
+	oldCommit := dumpCommit
+	oldPush := dumpPush
+	oldMessage := dumpMessage
+
+	dumpCommit = cfg.AutoDump.Commit
+	dumpPush = cfg.AutoDump.Push
+	if cfg.AutoDump.CommitMessage != "" {
+		dumpMessage = strings.ReplaceAll(cfg.AutoDump.CommitMessage, "{machine}", currentMachine)
+	}
+
+	err := runDump(nil, []string{})
+
+	// Restore flags
+	dumpCommit = oldCommit
+	dumpPush = oldPush
+	dumpMessage = oldMessage
+
+	if err != nil {
+		printWarning("Auto-dump failed: %v", err)
+	}
 }
 
-func runDumpQuiet(cfg *config.Config, machine config.Machine, brewfilePath string) error {
-	allPackages, err := collectAllPackages(cfg, brewfilePath)
+func runDumpQuiet(ctx context.Context, cfg *config.Config, machine config.Machine, brewfilePath string) error {
+	event := dumpEvent(cfg, brewfilePath)
+	if err := runPreHook(ctx, cfg, config.HookPreDump, event); err != nil {
+		return err
+	}
+
+	allPackages, err := collectAllPackages(ctx, cfg, brewfilePath)
 	if err != nil {
 		return err
 	}
+	allPackages = withoutIncluded(brewfilePath, allPackages)
 
 	// Dry run
 	if dryRun {
@@ -192,23 +252,41 @@ func runDumpQuiet(cfg *config.Config, machine config.Machine, brewfilePath strin
 		return nil
 	}
 
-	// Write Brewfile
-	writer := brewfile.NewWriter(allPackages)
-	if err := writer.Write(brewfilePath); err != nil {
+	// Update Brewfile in place, keeping hand-written comments and layout
+	if err := brewfile.SyncFile(brewfilePath, allPackages); err != nil {
 		return fmt.Errorf("failed to write Brewfile: %w", err)
 	}
+	writeLockFile(brewfilePath)
+	writeMetadata(cfg.CurrentMachine, brewfilePath)
 
 	printInfo("Wrote %d packages to %s", len(allPackages), brewfilePath)
+
+	event.Packages = allPackages
+	runPostHook(ctx, cfg, config.HookPostDump, event)
 	return nil
 }
 
-func runDumpAnimated(cfg *config.Config, machine config.Machine, brewfilePath string) error {
+func runDumpAnimated(ctx context.Context, cfg *config.Config, machine config.Machine, brewfilePath string) error {
 	// Create Bubble Tea program
 	p := tea.NewProgram(newDumpModel())
 
-	// Run collection in background
+	// Run the pre_dump hook and the collection in background
+	event := dumpEvent(cfg, brewfilePath)
+	runner := hooks.New(cfg.Hooks)
 	go func() {
-		allPackages, err := collectAllPackagesAnimated(cfg, brewfilePath, p)
+		if !dryRun && runner.Enabled(config.HookPreDump) {
+			p.Send(dumpStepMsg{step: "Running pre_dump hook..."})
+			err := runner.Run(ctx, config.HookPreDump, event, func(line string) {
+				p.Send(dumpHookOutputMsg{line: line})
+			})
+			if err != nil {
+				p.Send(dumpErrorMsg{err: fmt.Errorf("aborted: %w", err)})
+				return
+			}
+			p.Send(dumpStepMsg{countInfo: "pre_dump hook"})
+		}
+
+		allPackages, err := collectAllPackagesAnimated(ctx, cfg, brewfilePath, p)
 		if err != nil {
 			p.Send(dumpErrorMsg{err: err})
 			return
@@ -228,7 +306,7 @@ func runDumpAnimated(cfg *config.Config, machine config.Machine, brewfilePath st
 		return model.err
 	}
 
-	allPackages := model.packages
+	allPackages := withoutIncluded(brewfilePath, model.packages)
 
 	// Dry run
 	if dryRun {
@@ -236,15 +314,20 @@ func runDumpAnimated(cfg *config.Config, machine config.Machine, brewfilePath st
 		return nil
 	}
 
-	// Write Brewfile
-	writer := brewfile.NewWriter(allPackages)
-	if err := writer.Write(brewfilePath); err != nil {
+	// Update Brewfile in place, keeping hand-written comments and layout
+	if err := brewfile.SyncFile(brewfilePath, allPackages); err != nil {
 		return fmt.Errorf("failed to write Brewfile: %w", err)
 	}
+	writeLockFile(brewfilePath)
+	writeMetadata(cfg.CurrentMachine, brewfilePath)
 
 	// Print pretty summary
 	printDumpSummary(cfg.CurrentMachine, brewfilePath, allPackages, false)
 
+	// Run the post_dump hook before committing, so it can adjust the Brewfile
+	event.Packages = allPackages
+	runPostHook(ctx, cfg, config.HookPostDump, event)
+
 	// Handle commit and push
 	if dumpCommit || dumpPush {
 		if err := handleGitCommitAndPush(cfg, brewfilePath); err != nil {
@@ -256,7 +339,70 @@ func runDumpAnimated(cfg *config.Config, machine config.Machine, brewfilePath st
 	return nil
 }
 
-func collectAllPackages(cfg *config.Config, brewfilePath string) (brewfile.Packages, error) {
+// dumpEvent describes a dump of the current machine for the dump hooks
+func dumpEvent(cfg *config.Config, brewfilePath string) hooks.Event {
+	return hooks.Event{
+		Operation: hooks.OperationDump,
+		Machine:   cfg.CurrentMachine,
+		Brewfile:  brewfilePath,
+	}
+}
+
+// writeLockFile records the installed version of every Brewfile entry in the
+// Brewfile's lock file. Failures are reported as warnings.
+func writeLockFile(brewfilePath string) {
+	packages, err := brewfile.Parse(brewfilePath)
+	if err != nil {
+		printWarning("Could not write lock file: %v", err)
+		return
+	}
+
+	lockPath := brewfile.LockPath(brewfilePath)
+	lock := installer.NewManager().Lock(packages)
+	if err := lock.Save(lockPath); err != nil {
+		printWarning("Could not write lock file: %v", err)
+		return
+	}
+	printVerbose("Wrote %d versions to %s", lock.Len(), lockPath)
+}
+
+// writeMetadata records the dump time, package counts and the facts of this
+// system in the Brewfile's .brewsync-meta. Failures are reported as warnings.
+func writeMetadata(machineName, brewfilePath string) {
+	packages, err := brewfile.Parse(brewfilePath)
+	if err != nil {
+		printWarning("Could not write metadata: %v", err)
+		return
+	}
+
+	metaPath := brewfile.MetadataPath(brewfilePath)
+	facts := system.Facts(version.Version)
+	if err := brewfile.UpdateMetadata(metaPath, machineName, packages, facts); err != nil {
+		printWarning("Could not write metadata: %v", err)
+		return
+	}
+	printVerbose("Recorded system facts (%s %s, %s) in %s", facts.OS, facts.OSVersion, facts.Arch, metaPath)
+}
+
+// withoutIncluded drops packages that the Brewfile already gets from its
+// include directives, so shared packages are not repeated per machine
+func withoutIncluded(brewfilePath string, packages brewfile.Packages) brewfile.Packages {
+	if _, err := os.Stat(brewfilePath); err != nil {
+		return packages
+	}
+
+	included, err := brewfile.IncludedPackages(brewfilePath)
+	if err != nil {
+		printWarning("Could not resolve includes in %s: %v", brewfilePath, err)
+		return packages
+	}
+	if len(included) > 0 {
+		printVerbose("Skipping %d packages provided by included Brewfiles", len(included))
+	}
+	return packages.Exclude(included)
+}
+
+func collectAllPackages(ctx context.Context, cfg *config.Config, brewfilePath string) (brewfile.Packages, error) {
 	var allPackages brewfile.Packages
 	brewInst := installer.NewBrewInstaller()
 
@@ -273,52 +419,61 @@ func collectAllPackages(cfg *config.Config, brewfilePath string) (brewfile.Packa
 		}
 	} else if brewInst.IsAvailable() {
 		// Manual collection
-		if taps, err := brewInst.ListTaps(); err == nil {
+		if taps, err := brewInst.ListTaps(ctx); err == nil {
 			allPackages = append(allPackages, taps...)
 		}
-		if formulae, err := brewInst.ListFormulae(); err == nil {
+		if formulae, err := brewInst.ListFormulae(ctx); err == nil {
 			allPackages = append(allPackages, formulae...)
 		}
-		if casks, err := brewInst.ListCasks(); err == nil {
+		if casks, err := brewInst.ListCasks(ctx); err == nil {
 			allPackages = append(allPackages, casks...)
 		}
 	}
 
-	// Collect extensions
-	if vscodeInst := installer.NewVSCodeInstaller(); vscodeInst.IsAvailable() {
-		if extensions, err := vscodeInst.List(); err == nil {
-			allPackages = allPackages.AddUnique(extensions...)
+	// Record which formulae have their service running
+	if brewInst.IsAvailable() {
+		if withServices, err := brewInst.WithServices(ctx, allPackages); err == nil {
+			allPackages = withServices
 		}
 	}
 
-	if cursorInst := installer.NewCursorInstaller(); cursorInst.IsAvailable() {
-		if extensions, err := cursorInst.List(); err == nil {
-			allPackages = allPackages.AddUnique(extensions...)
+	// Collect extensions of every built-in and configured editor, replacing
+	// entries from brew bundle dump, which carry no pre-release flag
+	for _, editorInst := range installer.EditorInstallers() {
+		if !editorInst.IsAvailable() {
+			continue
 		}
-	}
-
-	if antigravityInst := installer.NewAntigravityInstaller(); antigravityInst.IsAvailable() {
-		if extensions, err := antigravityInst.List(); err == nil {
-			allPackages = allPackages.AddUnique(extensions...)
+		if extensions, err := editorInst.List(ctx); err == nil {
+			allPackages = append(allPackages.Exclude(extensions), extensions...)
 		}
 	}
 
-	if goInst := installer.NewGoToolsInstaller(); goInst.IsAvailable() {
-		if tools, err := goInst.List(); err == nil {
-			allPackages = allPackages.AddUnique(tools...)
+	if goInst := installer.NewGoToolsInstaller(); goInst.CanList() {
+		if tools, err := goInst.List(ctx); err == nil {
+			// Replace entries from brew bundle dump, which carry no version
+			allPackages = append(allPackages.Exclude(tools), tools...)
 		}
 	}
 
 	if masInst := installer.NewMasInstaller(); masInst.IsAvailable() {
-		if apps, err := masInst.List(); err == nil {
+		if apps, err := masInst.List(ctx); err == nil {
 			allPackages = allPackages.AddUnique(apps...)
 		}
 	}
 
+	for _, customInst := range installer.CustomInstallers() {
+		if !customInst.IsAvailable() {
+			continue
+		}
+		if pkgs, err := customInst.List(ctx); err == nil {
+			allPackages = allPackages.AddUnique(pkgs...)
+		}
+	}
+
 	return allPackages, nil
 }
 
-func collectAllPackagesAnimated(cfg *config.Config, brewfilePath string, p *tea.Program) (brewfile.Packages, error) {
+func collectAllPackagesAnimated(ctx context.Context, cfg *config.Config, brewfilePath string, p *tea.Program) (brewfile.Packages, error) {
 	var allPackages brewfile.Packages
 	brewInst := installer.NewBrewInstaller()
 
@@ -343,15 +498,15 @@ func collectAllPackagesAnimated(cfg *config.Config, brewfilePath string, p *tea.
 		}
 	} else if brewInst.IsAvailable() {
 		var brewCount int
-		if taps, err := brewInst.ListTaps(); err == nil {
+		if taps, err := brewInst.ListTaps(ctx); err == nil {
 			allPackages = append(allPackages, taps...)
 			brewCount += len(taps)
 		}
-		if formulae, err := brewInst.ListFormulae(); err == nil {
+		if formulae, err := brewInst.ListFormulae(ctx); err == nil {
 			allPackages = append(allPackages, formulae...)
 			brewCount += len(formulae)
 		}
-		if casks, err := brewInst.ListCasks(); err == nil {
+		if casks, err := brewInst.ListCasks(ctx); err == nil {
 			allPackages = append(allPackages, casks...)
 			brewCount += len(casks)
 		}
@@ -360,49 +515,45 @@ func collectAllPackagesAnimated(cfg *config.Config, brewfilePath string, p *tea.
 		}
 	}
 
-	// VSCode extensions
-	if vscodeInst := installer.NewVSCodeInstaller(); vscodeInst.IsAvailable() {
-		p.Send(dumpStepMsg{step: "Collecting VSCode extensions..."})
-		time.Sleep(100 * time.Millisecond)
-		if extensions, err := vscodeInst.List(); err == nil {
-			beforeCount := len(allPackages)
-			allPackages = allPackages.AddUnique(extensions...)
-			addedCount := len(allPackages) - beforeCount
-			p.Send(dumpStepMsg{countInfo: fmt.Sprintf("VSCode: %d extensions (%d new)", len(extensions), addedCount)})
+	// Homebrew services
+	if brewInst.IsAvailable() {
+		p.Send(dumpStepMsg{step: "Collecting Homebrew services..."})
+		if withServices, err := brewInst.WithServices(ctx, allPackages); err == nil {
+			allPackages = withServices
+			running := 0
+			for _, pkg := range allPackages {
+				if pkg.ServiceRunning() {
+					running++
+				}
+			}
+			p.Send(dumpStepMsg{countInfo: fmt.Sprintf("Services: %d running", running)})
 		}
 	}
 
-	// Cursor extensions
-	if cursorInst := installer.NewCursorInstaller(); cursorInst.IsAvailable() {
-		p.Send(dumpStepMsg{step: "Collecting Cursor extensions..."})
-		time.Sleep(100 * time.Millisecond)
-		if extensions, err := cursorInst.List(); err == nil {
-			beforeCount := len(allPackages)
-			allPackages = allPackages.AddUnique(extensions...)
-			addedCount := len(allPackages) - beforeCount
-			p.Send(dumpStepMsg{countInfo: fmt.Sprintf("Cursor: %d extensions (%d new)", len(extensions), addedCount)})
+	// Editor extensions, replacing entries from brew bundle dump, which
+	// carry no pre-release flag
+	for _, editorInst := range installer.EditorInstallers() {
+		if !editorInst.IsAvailable() {
+			continue
 		}
-	}
-
-	// Antigravity extensions
-	if antigravityInst := installer.NewAntigravityInstaller(); antigravityInst.IsAvailable() {
-		p.Send(dumpStepMsg{step: "Collecting Antigravity extensions..."})
+		p.Send(dumpStepMsg{step: fmt.Sprintf("Collecting %s extensions...", editorInst.Name())})
 		time.Sleep(100 * time.Millisecond)
-		if extensions, err := antigravityInst.List(); err == nil {
+		if extensions, err := editorInst.List(ctx); err == nil {
 			beforeCount := len(allPackages)
-			allPackages = allPackages.AddUnique(extensions...)
+			allPackages = append(allPackages.Exclude(extensions), extensions...)
 			addedCount := len(allPackages) - beforeCount
-			p.Send(dumpStepMsg{countInfo: fmt.Sprintf("Antigravity: %d extensions (%d new)", len(extensions), addedCount)})
+			p.Send(dumpStepMsg{countInfo: fmt.Sprintf("%s: %d extensions (%d new)", editorInst.Name(), len(extensions), addedCount)})
 		}
 	}
 
 	// Go tools
-	if goInst := installer.NewGoToolsInstaller(); goInst.IsAvailable() {
+	if goInst := installer.NewGoToolsInstaller(); goInst.CanList() {
 		p.Send(dumpStepMsg{step: "Collecting Go tools..."})
 		time.Sleep(100 * time.Millisecond)
-		if tools, err := goInst.List(); err == nil {
+		if tools, err := goInst.List(ctx); err == nil {
 			beforeCount := len(allPackages)
-			allPackages = allPackages.AddUnique(tools...)
+			// Replace entries from brew bundle dump, which carry no version
+			allPackages = append(allPackages.Exclude(tools), tools...)
 			addedCount := len(allPackages) - beforeCount
 			p.Send(dumpStepMsg{countInfo: fmt.Sprintf("Go: %d tools (%d new)", len(tools), addedCount)})
 		}
@@ -412,7 +563,7 @@ func collectAllPackagesAnimated(cfg *config.Config, brewfilePath string, p *tea.
 	if masInst := installer.NewMasInstaller(); masInst.IsAvailable() {
 		p.Send(dumpStepMsg{step: "Collecting Mac App Store apps..."})
 		time.Sleep(100 * time.Millisecond)
-		if apps, err := masInst.List(); err == nil {
+		if apps, err := masInst.List(ctx); err == nil {
 			beforeCount := len(allPackages)
 			allPackages = allPackages.AddUnique(apps...)
 			addedCount := len(allPackages) - beforeCount
@@ -420,6 +571,21 @@ func collectAllPackagesAnimated(cfg *config.Config, brewfilePath string, p *tea.
 		}
 	}
 
+	// Custom types declared in config
+	for _, customInst := range installer.CustomInstallers() {
+		if !customInst.IsAvailable() {
+			continue
+		}
+		p.Send(dumpStepMsg{step: fmt.Sprintf("Collecting %s packages...", customInst.Type())})
+		time.Sleep(100 * time.Millisecond)
+		if pkgs, err := customInst.List(ctx); err == nil {
+			beforeCount := len(allPackages)
+			allPackages = allPackages.AddUnique(pkgs...)
+			addedCount := len(allPackages) - beforeCount
+			p.Send(dumpStepMsg{countInfo: fmt.Sprintf("%s: %d packages (%d new)", customInst.Type(), len(pkgs), addedCount)})
+		}
+	}
+
 	return allPackages, nil
 }
 
@@ -473,6 +639,13 @@ func printDumpSummary(machineName, brewfilePath string, packages brewfile.Packag
 		brewfile.TypeGo:          {"🔷", catSapphire},
 		brewfile.TypeMas:         {"🍎", catRed},
 	}
+	for _, t := range brewfile.CustomTypes() {
+		typeOrder = append(typeOrder, t)
+		typeInfo[t] = struct {
+			icon  string
+			color lipgloss.Color
+		}{customTypeIcon(t), catLavender}
+	}
 
 	for _, t := range typeOrder {
 		if pkgs, ok := byType[t]; ok && len(pkgs) > 0 {
@@ -519,7 +692,7 @@ func printDumpSummary(machineName, brewfilePath string, packages brewfile.Packag
 }
 
 func handleGitCommitAndPush(cfg *config.Config, brewfilePath string) error {
-	runner := exec.NewRunner()
+	runner := exec.Default
 	dir := filepath.Dir(brewfilePath)
 
 	// Check if it's a git repo
@@ -527,14 +700,19 @@ func handleGitCommitAndPush(cfg *config.Config, brewfilePath string) error {
 		return fmt.Errorf("not a git repository: %s", dir)
 	}
 
-	// Add the Brewfile
-	fileName := filepath.Base(brewfilePath)
-	if _, err := runner.Run("git", "-C", dir, "add", fileName); err != nil {
+	// Add the Brewfile, its lock file and metadata
+	files := []string{filepath.Base(brewfilePath)}
+	for _, path := range []string{brewfile.LockPath(brewfilePath), brewfile.MetadataPath(brewfilePath)} {
+		if _, err := os.Stat(path); err == nil {
+			files = append(files, filepath.Base(path))
+		}
+	}
+	if _, err := runner.Run("git", append([]string{"-C", dir, "add"}, files...)...); err != nil {
 		return fmt.Errorf("failed to git add: %w", err)
 	}
 
 	// Check if there are changes to commit
-	status, err := runner.Run("git", "-C", dir, "status", "--porcelain", fileName)
+	status, err := runner.Run("git", append([]string{"-C", dir, "status", "--porcelain"}, files...)...)
 	if err != nil {
 		return fmt.Errorf("failed to check git status: %w", err)
 	}
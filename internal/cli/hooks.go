@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
+	"github.com/andrew-sameh/brewsync/internal/config"
+	"github.com/andrew-sameh/brewsync/internal/hooks"
+	"github.com/andrew-sameh/brewsync/internal/tui/progress"
+)
+
+// runHook runs a configured hook, printing its output as it streams.
+// Hooks never run in dry-run mode.
+func runHook(ctx context.Context, cfg *config.Config, hook string, event hooks.Event) error {
+	runner := hooks.New(cfg.Hooks)
+	if dryRun || !runner.Enabled(hook) {
+		return nil
+	}
+	printInfo("Running %s hook...", hook)
+	return runner.Run(ctx, hook, event, func(line string) {
+		printInfo("  %s", styleDim.Render(line))
+	})
+}
+
+// runPreHook runs a pre hook; its failure aborts the operation
+func runPreHook(ctx context.Context, cfg *config.Config, hook string, event hooks.Event) error {
+	if err := runHook(ctx, cfg, hook, event); err != nil {
+		return fmt.Errorf("aborted: %w", err)
+	}
+	return nil
+}
+
+// runPostHook runs a post hook; the operation already happened, so its
+// failure is only reported, and an interrupt does not stop it from
+// reporting what was done
+func runPostHook(ctx context.Context, cfg *config.Config, hook string, event hooks.Event) {
+	if err := runHook(context.WithoutCancel(ctx), cfg, hook, event); err != nil {
+		printWarning("%v", err)
+	}
+}
+
+// installEvent describes an install operation for the install hooks
+func installEvent(cfg *config.Config, operation, source string, install, remove, change brewfile.Packages) hooks.Event {
+	return hooks.Event{
+		Operation: operation,
+		Machine:   cfg.CurrentMachine,
+		Source:    source,
+		Brewfile:  machineBrewfile(cfg),
+		Install:   install,
+		Remove:    remove,
+		Change:    change,
+	}
+}
+
+// installedEvent turns the event of a pre_install hook into the event of
+// its post_install hook, listing what was done and what failed
+func installedEvent(event hooks.Event, installed, removed, changed, failed brewfile.Packages) hooks.Event {
+	event.Install = installed
+	event.Remove = removed
+	event.Change = changed
+	event.Failed = failed
+	return event
+}
+
+// progressHooks adapts the install hooks to the progress view, which
+// streams their output; hooks that are not configured are nil
+func progressHooks(cfg *config.Config, event hooks.Event) (pre, post progress.HookFunc) {
+	if dryRun {
+		return nil, nil
+	}
+	runner := hooks.New(cfg.Hooks)
+	if runner.Enabled(config.HookPreInstall) {
+		pre = func(ctx context.Context, _ []progress.InstallResult, onOutput func(line string)) error {
+			return runner.Run(ctx, config.HookPreInstall, event, onOutput)
+		}
+	}
+	if runner.Enabled(config.HookPostInstall) {
+		post = func(ctx context.Context, results []progress.InstallResult, onOutput func(line string)) error {
+			var installed, failed brewfile.Packages
+			for _, result := range results {
+				if result.Error != nil {
+					failed = append(failed, result.Package)
+				} else {
+					installed = append(installed, result.Package)
+				}
+			}
+			return runner.Run(ctx, config.HookPostInstall, installedEvent(event, installed, nil, nil, failed), onOutput)
+		}
+	}
+	return pre, post
+}
+
+// machineBrewfile returns the current machine's Brewfile path, if configured
+func machineBrewfile(cfg *config.Config) string {
+	machine, _ := cfg.GetCurrentMachine()
+	return machine.Brewfile
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
 	"github.com/andrew-sameh/brewsync/internal/config"
 )
 
@@ -29,6 +30,7 @@ Subcommands:
 var (
 	ignoreMachine string
 	ignoreGlobal  bool
+	ignorePrune   bool
 )
 
 // Category commands
@@ -75,7 +77,8 @@ Format: type:name
 Examples:
   brewsync ignore add cask:bluestacks              # Add to current machine
   brewsync ignore add brew:postgresql --global     # Add globally
-  brewsync ignore add vscode:ext --machine mini    # Add to specific machine`,
+  brewsync ignore add vscode:ext --machine mini    # Add to specific machine
+  brewsync ignore add cask:bluestacks --prune      # Also remove it from the Brewfile`,
 	Args: cobra.ExactArgs(1),
 	RunE: runIgnoreAdd,
 }
@@ -120,6 +123,7 @@ func init() {
 	// Package command flags
 	ignoreAddCmd.Flags().StringVar(&ignoreMachine, "machine", "", "add to specific machine's ignore list")
 	ignoreAddCmd.Flags().BoolVar(&ignoreGlobal, "global", false, "add to global ignore list")
+	ignoreAddCmd.Flags().BoolVar(&ignorePrune, "prune", false, "also remove the package from the machine's Brewfile")
 	ignoreRemoveCmd.Flags().StringVar(&ignoreMachine, "machine", "", "remove from specific machine's ignore list")
 	ignoreRemoveCmd.Flags().BoolVar(&ignoreGlobal, "global", false, "remove from global ignore list")
 	ignoreListCmd.Flags().StringVar(&ignoreMachine, "machine", "", "show only for specific machine")
@@ -142,8 +146,8 @@ func runIgnoreCategoryAdd(cmd *cobra.Command, args []string) error {
 		"vscode": true, "cursor": true, "antigravity": true,
 		"go": true, "mas": true,
 	}
-	if !validCategories[category] {
-		return fmt.Errorf("invalid category '%s'; valid categories: tap, brew, cask, vscode, cursor, antigravity, go, mas", category)
+	if !validCategories[category] && !brewfile.IsCustomType(brewfile.PackageType(category)) {
+		return fmt.Errorf("invalid category '%s'; valid categories: tap, brew, cask, vscode, cursor, antigravity, go, mas or a custom type", category)
 	}
 
 	// Determine machine
@@ -265,6 +269,47 @@ func runIgnoreAdd(cmd *cobra.Command, args []string) error {
 		printInfo("Added %s to %s ignore list", pkgID, machine)
 	}
 
+	if ignorePrune {
+		return pruneFromBrewfile(machine, pkgID)
+	}
+
+	return nil
+}
+
+// pruneFromBrewfile removes a package entry from a machine's Brewfile,
+// defaulting to the current machine, while keeping the rest of the file intact
+func pruneFromBrewfile(machineName, pkgID string) error {
+	cfg, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if machineName == "" {
+		machineName = cfg.CurrentMachine
+	}
+
+	machine, ok := cfg.Machines[machineName]
+	if !ok || machine.Brewfile == "" {
+		return fmt.Errorf("no Brewfile configured for machine %s", machineName)
+	}
+
+	doc, err := brewfile.LoadDocument(machine.Brewfile)
+	if err != nil {
+		return err
+	}
+	if !doc.Remove(pkgID) {
+		printInfo("%s is not in %s's Brewfile", pkgID, machineName)
+		return nil
+	}
+
+	if dryRun {
+		printInfo("Dry run - would remove %s from %s", pkgID, machine.Brewfile)
+		return nil
+	}
+	if err := doc.Save(machine.Brewfile); err != nil {
+		return fmt.Errorf("failed to write Brewfile: %w", err)
+	}
+
+	printInfo("Removed %s from %s", pkgID, machine.Brewfile)
 	return nil
 }
 
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/andrew-sameh/brewsync/internal/brewfile"
 	"github.com/andrew-sameh/brewsync/internal/config"
 	"github.com/andrew-sameh/brewsync/internal/history"
+	"github.com/andrew-sameh/brewsync/internal/hooks"
 	"github.com/andrew-sameh/brewsync/internal/installer"
 	"github.com/andrew-sameh/brewsync/internal/tui/progress"
 	"github.com/andrew-sameh/brewsync/internal/tui/selection"
@@ -20,6 +22,7 @@ var (
 	importOnly                   string
 	importSkip                   string
 	importIncludeMachineSpecific bool
+	importFormat                 string
 )
 
 var importCmd = &cobra.Command{
@@ -30,6 +33,18 @@ var importCmd = &cobra.Command{
 The import command shows packages that exist on the source machine but not
 on the current machine, and lets you select which ones to install.
 
+Homebrew services running on the source (restart_service in its Brewfile)
+are started for imported formulae and for formulae already installed here
+whose service is stopped, after asking unless --yes is given. Import never
+stops services; sync does. Ignored and machine-specific formulae are left
+alone.
+
+Failed installs are classified (network, not-found, needs-sudo, checksum,
+tap-missing, conflict, mas-not-signed-in, ...). Network failures are
+retried with backoff before giving up. With --format json (requires --yes),
+the installed, failed and skipped packages are printed as JSON, including
+each failure's category and number of attempts.
+
 Examples:
   brewsync import                      # From default source, interactive
   brewsync import --from air           # From specific machine
@@ -37,7 +52,8 @@ Examples:
   brewsync import --only brew,cask     # Filter categories
   brewsync import --skip vscode        # Exclude categories
   brewsync import --yes                # Install all without prompts
-  brewsync import --dry-run            # Show what would be installed`,
+  brewsync import --dry-run            # Show what would be installed
+  brewsync import --yes --format json  # Machine-readable results`,
 	RunE: runImport,
 }
 
@@ -46,11 +62,26 @@ func init() {
 	importCmd.Flags().StringVar(&importOnly, "only", "", "only import these package types (comma-separated)")
 	importCmd.Flags().StringVar(&importSkip, "skip", "", "skip these package types (comma-separated)")
 	importCmd.Flags().BoolVar(&importIncludeMachineSpecific, "include-machine-specific", false, "include machine-specific packages")
+	importCmd.Flags().StringVar(&importFormat, "format", "text", "output format: text, json (requires --yes)")
 
 	rootCmd.AddCommand(importCmd)
 }
 
-func runImport(cmd *cobra.Command, args []string) error {
+func runImport(cmd *cobra.Command, args []string) (err error) {
+	report := &installReport{}
+	if importFormat == "json" {
+		if !assumeYes || dryRun {
+			return fmt.Errorf("--format json requires --yes and cannot be used with --dry-run")
+		}
+		// Progress messages would corrupt the JSON on stdout
+		quiet = true
+		defer func() {
+			if err == nil {
+				err = report.writeJSON(false)
+			}
+		}()
+	}
+
 	// Load config
 	cfg, err := config.Load()
 	if err != nil {
@@ -93,8 +124,7 @@ func runImport(cmd *cobra.Command, args []string) error {
 	}
 
 	// Load and merge source Brewfiles
-	var sourcePkgs brewfile.Packages
-	seen := make(map[string]bool)
+	sourceSet := brewfile.NewPackageSet()
 
 	for _, source := range sources {
 		sourceBrewfile := cfg.Machines[source].Brewfile
@@ -104,21 +134,38 @@ func runImport(cmd *cobra.Command, args []string) error {
 			continue
 		}
 
-		for _, pkg := range pkgs {
-			key := pkg.ID()
-			if !seen[key] {
-				seen[key] = true
-				sourcePkgs = append(sourcePkgs, pkg)
-			}
-		}
+		sourceSet = sourceSet.Union(pkgs.Set())
 	}
 
 	// Compute diff (what's in source but not in current)
-	diff := brewfile.Diff(sourcePkgs, currentPkgs)
+	diff := brewfile.Diff(sourceSet.Packages(), currentPkgs)
+
+	// Casks and App Store apps cannot be installed on Linux
+	diff, unsupported := excludeUnsupported(cfg, diff, currentMachine)
+	for _, note := range unsupported {
+		printInfo("%s", note)
+	}
 	missing := diff.Additions
 
+	// Services running on a source but stopped here; import starts them
+	// but never stops any
+	ctx := commandContext(cmd)
+	var services brewfile.Packages
+	if serviceChanges := diff.ServiceChanges(); len(serviceChanges) > 0 {
+		services = servicesToStart(cfg, currentMachine, serviceChanges)
+		if importOnly != "" {
+			services = filterByCategories(services, parseCategories(importOnly), true)
+		}
+		if importSkip != "" {
+			services = filterByCategories(services, parseCategories(importSkip), false)
+		}
+	}
+
 	if len(missing) == 0 {
 		printInfo("No new packages to import")
+		if confirmServices(services) {
+			startServices(ctx, installer.NewManager(), services, report)
+		}
 		return nil
 	}
 
@@ -223,11 +270,17 @@ func runImport(cmd *cobra.Command, args []string) error {
 
 	if len(packagesToCheck) == 0 {
 		printInfo("No new packages to import (after filters)")
+		if confirmServices(services) {
+			startServices(ctx, installer.NewManager(), services, report)
+		}
 		return nil
 	}
 
 	printInfo("Found %d packages to import", len(packagesToCheck))
 
+	// Warn about packages that may not work on this machine
+	warnCompat(checkCompat(cfg, sources, packagesToCheck))
+
 	// Dry run - just show what would be imported (excluding ignored)
 	if dryRun {
 		fmt.Println("\nWould import:")
@@ -236,6 +289,7 @@ func runImport(cmd *cobra.Command, args []string) error {
 				fmt.Printf("  %s:%s\n", pkg.Type, pkg.Name)
 			}
 		}
+		confirmServices(services)
 		return nil
 	}
 
@@ -300,42 +354,71 @@ func runImport(cmd *cobra.Command, args []string) error {
 
 	if len(toInstall) == 0 {
 		printInfo("No packages selected for installation")
+		if confirmServices(services) {
+			startServices(ctx, installer.NewManager(), services, report)
+		}
 		return nil
 	}
 
-	printInfo("Installing %d packages...", len(toInstall))
+	// Refuse packages this machine cannot install instead of failing each
+	// one during the install
+	mgr := installer.NewManager()
+	var refused brewfile.Packages
+	toInstall, refused = splitInstallable(mgr, toInstall, report)
+	if len(toInstall) == 0 {
+		return fmt.Errorf("none of the %d selected packages can be installed on this machine", len(refused))
+	}
 
 	// Install packages
-	mgr := installer.NewManager()
+	var installedPkgs, skipped brewfile.Packages
+	event := installEvent(cfg, hooks.OperationImport, strings.Join(sources, ","), toInstall, nil, nil)
 
 	if assumeYes {
-		// Non-interactive progress
+		if err := runPreHook(ctx, cfg, config.HookPreInstall, event); err != nil {
+			return err
+		}
+		printInfo("Installing %d packages...", len(toInstall))
+
+		// Non-interactive progress; an interrupt stops the running installs
+		// and skips the rest
 		var installed, failed int
-		mgr.InstallMany(toInstall, func(pkg brewfile.Package, i, total int, err error) {
+		var failedPkgs brewfile.Packages
+		err := mgr.InstallMany(ctx, toInstall, func(pkg brewfile.Package, i, total int, err error) {
 			if err != nil {
-				printError("[%d/%d] Failed: %s:%s - %v", i, total, pkg.Type, pkg.Name, err)
+				kind := report.fail(pkg, err)
+				printError("[%d/%d] Failed: %s:%s (%s) - %v", i, total, pkg.Type, pkg.Name, kind, err)
 				failed++
+				failedPkgs = append(failedPkgs, pkg)
 			} else {
 				printInfo("[%d/%d] Installed: %s:%s", i, total, pkg.Type, pkg.Name)
 				installed++
+				installedPkgs = append(installedPkgs, pkg)
 			}
 		})
 
-		fmt.Println()
-		printInfo("Installed: %d, Failed: %d", installed, failed)
+		skipped = interruptedPackages(err)
 
-		// Log to history
-		var pkgNames []string
-		for _, pkg := range toInstall {
-			pkgNames = append(pkgNames, pkg.ID())
+		if !quiet {
+			fmt.Println()
 		}
-		history.LogImport(currentMachine, strings.Join(sources, ","), pkgNames)
+		printInfo("Installed: %d, Failed: %d", installed, failed)
+		runPostHook(ctx, cfg, config.HookPostInstall, installedEvent(event, installedPkgs, nil, nil, failedPkgs))
 	} else {
-		// Interactive progress UI with streaming support
+		printInfo("Installing %d packages...", len(toInstall))
+
+		// Interactive progress UI; the manager installs independent
+		// packages in parallel and streams their output
 		title := "Installing packages"
-		progressModel := progress.NewWithOutput(title, toInstall, func(pkg brewfile.Package, onOutput func(line string)) error {
-			return mgr.InstallWithProgress(pkg, onOutput)
-		})
+		progressModel := progress.NewBatch(title, toInstall, func(
+			ctx context.Context,
+			onStart func(pkg brewfile.Package),
+			onDone func(pkg brewfile.Package, err error),
+			onOutput func(pkg brewfile.Package, line string),
+		) error {
+			return mgr.InstallManyWithEvents(ctx, toInstall, onStart, func(pkg brewfile.Package, i, total int, err error) {
+				onDone(pkg, err)
+			}, onOutput)
+		}).WithHooks(progressHooks(cfg, event))
 
 		p := tea.NewProgram(progressModel, tea.WithAltScreen())
 		finalModel, err := p.Run()
@@ -344,40 +427,54 @@ func runImport(cmd *cobra.Command, args []string) error {
 		}
 
 		m := finalModel.(progress.Model)
+		if err := m.PreHookError(); err != nil {
+			return fmt.Errorf("aborted: %w", err)
+		}
+		if err := m.PostHookError(); err != nil {
+			printWarning("%v", err)
+		}
 		printInfo("Installed: %d, Failed: %d", m.Installed(), m.Failed())
-
-		// Log to history
-		var pkgNames []string
-		for _, pkg := range toInstall {
-			pkgNames = append(pkgNames, pkg.ID())
+		for _, result := range m.Results() {
+			if result.Error == nil {
+				installedPkgs = append(installedPkgs, result.Package)
+			} else {
+				report.fail(result.Package, result.Error)
+			}
 		}
-		history.LogImport(currentMachine, strings.Join(sources, ","), pkgNames)
+		skipped = m.Skipped()
 	}
 
-	// Auto-dump if enabled and packages were installed
-	if len(toInstall) > 0 && cfg.AutoDump.Enabled && cfg.AutoDump.AfterInstall {
-		printInfo("Auto-dumping Brewfile...")
-
-		// Set flags for commit/push based on config
-		oldCommit := dumpCommit
-		oldPush := dumpPush
-		oldMessage := dumpMessage
-
-		dumpCommit = cfg.AutoDump.Commit
-		dumpPush = cfg.AutoDump.Push
-		if cfg.AutoDump.CommitMessage != "" {
-			dumpMessage = strings.ReplaceAll(cfg.AutoDump.CommitMessage, "{machine}", currentMachine)
+	if len(skipped) > 0 {
+		printWarning("Import interrupted: %d packages not attempted", len(skipped))
+		for _, pkg := range skipped {
+			printVerbose("  not attempted: %s", pkg.ID())
 		}
+	}
 
-		err := runDump(nil, []string{})
+	// Start the services the source runs for installed formulae and for
+	// formulae already here
+	if ctx.Err() == nil {
+		if toStart := append(runningServices(installedPkgs), services...); confirmServices(toStart) {
+			startServices(ctx, mgr, toStart, report)
+		}
+	}
 
-		// Restore flags
-		dumpCommit = oldCommit
-		dumpPush = oldPush
-		dumpMessage = oldMessage
+	// Log to history what was actually installed and why packages failed
+	for _, pkg := range installedPkgs {
+		report.installed = append(report.installed, pkg.ID())
+	}
+	report.skip(skipped)
+	history.LogImport(currentMachine, strings.Join(sources, ","), report.installed, report.historyFailures(), len(skipped))
 
-		if err != nil {
-			printWarning("Auto-dump failed: %v", err)
+	// Auto-dump if enabled and packages were installed; otherwise add the
+	// imported packages to the Brewfile without touching the rest of it
+	if len(toInstall) > 0 && cfg.AutoDump.Enabled && cfg.AutoDump.AfterInstall {
+		autoDump(cfg, currentMachine)
+	} else if len(installedPkgs) > 0 && currentBrewfile != "" {
+		if err := brewfile.Append(currentBrewfile, installedPkgs); err != nil {
+			printWarning("Failed to update Brewfile: %v", err)
+		} else {
+			printInfo("Added %d packages to %s", len(installedPkgs), currentBrewfile)
 		}
 	}
 
@@ -416,3 +513,14 @@ func filterByCategories(pkgs brewfile.Packages, categories []brewfile.PackageTyp
 	}
 	return result
 }
+
+// warnCompat prints platform differences and packages that may not work on
+// this machine as warnings
+func warnCompat(report compatReport) {
+	for _, note := range report.Notes {
+		printWarning("%s", note)
+	}
+	for _, issue := range report.Issues {
+		printWarning("%s", issue)
+	}
+}
@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
+	"github.com/andrew-sameh/brewsync/internal/config"
+)
+
+var (
+	lintMachine string
+	lintAll     bool
+	lintFormat  string
+)
+
+var lintCmd = &cobra.Command{
+	Use:   "lint [Brewfile...]",
+	Short: "Check Brewfiles for problems",
+	Long: `Check Brewfiles for problems that would otherwise be silently skipped.
+
+Lint reports, with file, line and column:
+- Syntax errors and unknown directives (e.g. typos like bew "git")
+- Duplicate entries
+- Malformed options (e.g. link: "yes")
+- mas entries without an id
+- Description comments that are not attached to any package
+
+Exits with a non-zero status when any problem is found, so it can be
+used as a pre-commit hook.
+
+Examples:
+  brewsync lint                  # Current machine's Brewfile
+  brewsync lint --machine mini   # Another machine
+  brewsync lint --all            # Every configured machine
+  brewsync lint ./Brewfile       # Specific files
+  brewsync lint --format json    # JSON output`,
+	RunE: runLint,
+}
+
+func init() {
+	lintCmd.Flags().StringVar(&lintMachine, "machine", "", "machine whose Brewfile to lint")
+	lintCmd.Flags().BoolVar(&lintAll, "all", false, "lint the Brewfiles of all machines")
+	lintCmd.Flags().StringVar(&lintFormat, "format", "text", "output format: text, json")
+	rootCmd.AddCommand(lintCmd)
+}
+
+func runLint(cmd *cobra.Command, args []string) error {
+	paths, err := lintPaths(args)
+	if err != nil {
+		return err
+	}
+
+	var all brewfile.Diagnostics
+	for _, path := range paths {
+		printVerbose("Linting %s", path)
+		diags, err := brewfile.LintFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to lint %s: %w", path, err)
+		}
+		all = append(all, diags...)
+	}
+
+	switch lintFormat {
+	case "json":
+		if all == nil {
+			all = brewfile.Diagnostics{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(all); err != nil {
+			return err
+		}
+	default:
+		printLintDiagnostics(all, len(paths))
+	}
+
+	if len(all) > 0 {
+		return fmt.Errorf("lint found %d errors and %d warnings",
+			all.Count(brewfile.SeverityError), all.Count(brewfile.SeverityWarning))
+	}
+	return nil
+}
+
+// lintPaths resolves which Brewfiles to lint from arguments and flags
+func lintPaths(args []string) ([]string, error) {
+	if len(args) > 0 {
+		return args, nil
+	}
+
+	cfg, err := config.Get()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if lintAll {
+		var names []string
+		for name := range cfg.Machines {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var paths []string
+		for _, name := range names {
+			path := cfg.Machines[name].Brewfile
+			if path == "" {
+				continue
+			}
+			if _, err := os.Stat(path); err != nil {
+				printWarning("Skipping %s: Brewfile not found at %s", name, path)
+				continue
+			}
+			paths = append(paths, path)
+		}
+		return paths, nil
+	}
+
+	machineName := lintMachine
+	if machineName == "" {
+		machineName = cfg.CurrentMachine
+	}
+	if machineName == "" {
+		return nil, fmt.Errorf("no machine specified and current machine not detected")
+	}
+
+	machine, ok := cfg.Machines[machineName]
+	if !ok {
+		return nil, fmt.Errorf("machine '%s' not found in config", machineName)
+	}
+	if machine.Brewfile == "" {
+		return nil, fmt.Errorf("no Brewfile path configured for machine %s", machineName)
+	}
+	return []string{machine.Brewfile}, nil
+}
+
+// printLintDiagnostics prints diagnostics in file:line:col form
+func printLintDiagnostics(diags brewfile.Diagnostics, files int) {
+	for _, d := range diags {
+		location := fmt.Sprintf("%s:%d:%d:", d.File, d.Line, d.Column)
+		severity := string(d.Severity) + ":"
+		if !noColor {
+			location = styleBold.Render(location)
+			if d.Severity == brewfile.SeverityError {
+				severity = styleError.Render(severity)
+			} else {
+				severity = styleWarning.Render(severity)
+			}
+		}
+		fmt.Printf("%s %s %s\n", location, severity, d.Message)
+	}
+
+	if len(diags) == 0 {
+		msg := fmt.Sprintf("✓ No problems found in %d Brewfile(s)", files)
+		if noColor {
+			printInfo("%s", msg)
+		} else {
+			printInfo("%s", styleSuccess.Render(msg))
+		}
+	}
+}
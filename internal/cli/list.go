@@ -96,6 +96,9 @@ func outputListJSON(packages brewfile.Packages, machine string) error {
 		"packages": packageNames(packages),
 		"counts":   packageCounts(packages),
 	}
+	if sources := packageSources(packages); len(sources) > 0 {
+		output["sources"] = sources
+	}
 
 	enc := json.NewEncoder(os.Stdout)
 	enc.SetIndent("", "  ")
@@ -167,6 +170,13 @@ func outputListTable(packages brewfile.Packages, machine string) error {
 		brewfile.TypeGo:          {"🔷", catSapphire},
 		brewfile.TypeMas:         {"🍎", catRed},
 	}
+	for _, t := range brewfile.CustomTypes() {
+		typeOrder = append(typeOrder, t)
+		typeInfo[t] = struct {
+			icon  string
+			color lipgloss.Color
+		}{customTypeIcon(t), catLavender}
+	}
 
 	var allRows []string
 
@@ -200,7 +210,7 @@ func outputListTable(packages brewfile.Packages, machine string) error {
 
 			pkgName := lipgloss.NewStyle().
 				Foreground(catText).
-				Render(pkg.Name)
+				Render(pkg.Name) + formatProvenance(pkg)
 
 			// Add description if available
 			var row string
@@ -0,0 +1,313 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
+	"github.com/andrew-sameh/brewsync/internal/config"
+	"github.com/andrew-sameh/brewsync/internal/exec"
+)
+
+var (
+	mergeFrom          string
+	mergeStdout        bool
+	mergeInstallDriver bool
+)
+
+// mergeDriverName is the merge driver name used in git config and .gitattributes
+const mergeDriverName = "brewsync"
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge [base ours theirs | Brewfile]",
+	Short: "Three-way merge Brewfiles by package",
+	Long: `Merge two versions of a Brewfile against their common ancestor.
+
+Packages are merged by identity rather than by line: additions and
+removals from both sides are kept, and descriptions and options come from
+whichever side changed them. Includes and directives such as cask_args
+are merged by their text. Only real conflicts (the same option set to
+different values, or a package removed on one side and changed on the
+other) are written between conflict markers. The layout and comments of
+our version are preserved.
+
+Forms:
+  brewsync merge BASE OURS THEIRS   Merge files, writing the result to OURS
+  brewsync merge [Brewfile]         Resolve a Brewfile left conflicted by git,
+                                    using the base, ours and theirs versions
+                                    recorded in the index
+  brewsync merge --from REF         Merge REF's version of the Brewfile into
+                                    the working copy, using their merge base
+                                    from git history
+
+Exits with a non-zero status when conflicts remain, so it can be used as
+a git merge driver. Register it with --install-driver, which adds
+
+  [merge "brewsync"]
+      driver = brewsync merge %O %A %B
+
+to the repository's git config and marks the configured Brewfiles with
+merge=brewsync in .gitattributes.
+
+Examples:
+  brewsync merge                       # Resolve the current machine's Brewfile
+  brewsync merge ./Brewfile.mini       # Resolve a specific Brewfile
+  brewsync merge --from origin/main    # Merge in upstream changes
+  brewsync merge base ours theirs -p   # Print the merge of three files
+  brewsync merge --install-driver      # Register the git merge driver`,
+	Args: cobra.MaximumNArgs(3),
+	RunE: runMerge,
+}
+
+func init() {
+	mergeCmd.Flags().StringVar(&mergeFrom, "from", "", "git ref whose version of the Brewfile to merge in")
+	mergeCmd.Flags().BoolVarP(&mergeStdout, "stdout", "p", false, "print the result instead of writing it")
+	mergeCmd.Flags().BoolVar(&mergeInstallDriver, "install-driver", false, "register brewsync as the git merge driver for Brewfiles")
+	rootCmd.AddCommand(mergeCmd)
+}
+
+func runMerge(cmd *cobra.Command, args []string) error {
+	if mergeInstallDriver {
+		return installMergeDriver(args)
+	}
+
+	switch len(args) {
+	case 3:
+		if mergeFrom != "" {
+			return fmt.Errorf("--from cannot be used when merging three files")
+		}
+		return mergeFiles(args[0], args[1], args[2])
+	case 2:
+		return fmt.Errorf("expected a Brewfile or base, ours and theirs files")
+	}
+
+	path, err := mergeTarget(args)
+	if err != nil {
+		return err
+	}
+
+	base, ours, theirs, err := gitMergeVersions(path, mergeFrom)
+	if err != nil {
+		return err
+	}
+	return writeMerge(path, base, ours, theirs)
+}
+
+// mergeFiles merges three files on disk, writing the result to ours
+func mergeFiles(basePath, oursPath, theirsPath string) error {
+	var contents [3]string
+	for i, path := range []string{basePath, oursPath, theirsPath} {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		contents[i] = string(data)
+	}
+	return writeMerge(oursPath, contents[0], contents[1], contents[2])
+}
+
+// writeMerge merges the contents and writes the result to path
+func writeMerge(path, base, ours, theirs string) error {
+	content, result := brewfile.Merge3Content(base, ours, theirs)
+	for _, diag := range result.Diagnostics {
+		printWarning("%s", diag)
+	}
+
+	if mergeStdout {
+		fmt.Print(content)
+	} else if dryRun {
+		printInfo("[dry-run] Would write merged Brewfile to %s", path)
+	} else if content != ours {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+
+	if !result.HasConflicts() {
+		if !mergeStdout {
+			printInfo("✓ Merged %s (%d packages)", path, len(result.Packages))
+		}
+		return nil
+	}
+
+	for _, c := range result.Conflicts {
+		printWarning("conflict: %s", c)
+	}
+	for _, c := range result.Directives {
+		printWarning("conflict: %s", c)
+	}
+	conflicts := len(result.Conflicts) + len(result.Directives)
+	return fmt.Errorf("%d conflicts in %s; resolve the marked entries and run 'brewsync lint'", conflicts, path)
+}
+
+// mergeTarget returns the Brewfile to merge: the argument, or the current
+// machine's Brewfile
+func mergeTarget(args []string) (string, error) {
+	if len(args) == 1 {
+		return args[0], nil
+	}
+
+	cfg, err := config.Get()
+	if err != nil {
+		return "", fmt.Errorf("failed to load config: %w", err)
+	}
+	machine, ok := cfg.GetCurrentMachine()
+	if !ok || machine.Brewfile == "" {
+		return "", fmt.Errorf("no Brewfile specified and current machine has no Brewfile configured")
+	}
+	return machine.Brewfile, nil
+}
+
+// gitMergeVersions returns the base, ours and theirs contents of a Brewfile.
+// Without a ref they come from the index stages of a conflicted merge;
+// with a ref, ours is the working copy, theirs the ref's version and base
+// the version at the merge base of HEAD and the ref.
+func gitMergeVersions(path, ref string) (string, string, string, error) {
+	runner := exec.NewRunner()
+	dir := filepath.Dir(path)
+	name := "./" + filepath.Base(path)
+
+	if _, err := runner.Run("git", "-C", dir, "rev-parse", "--git-dir"); err != nil {
+		return "", "", "", fmt.Errorf("not a git repository: %s", dir)
+	}
+
+	show := func(object string) (string, error) {
+		return runner.Run("git", "-C", dir, "show", object)
+	}
+
+	if ref == "" {
+		var stages [3]string
+		for i := range stages {
+			content, err := show(fmt.Sprintf(":%d:%s", i+1, name))
+			if err != nil && i > 0 {
+				return "", "", "", fmt.Errorf("%s is not in a conflicted merge; use --from to merge a ref", path)
+			}
+			// A missing base stage means both sides added the file
+			stages[i] = content
+		}
+		return stages[0], stages[1], stages[2], nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return "", "", "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	theirs, err := show(ref + ":" + name)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to read %s at %s: %w", path, ref, err)
+	}
+	mergeBase, err := runner.Run("git", "-C", dir, "merge-base", "HEAD", ref)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to find merge base with %s: %w", ref, err)
+	}
+	base, err := show(strings.TrimSpace(mergeBase) + ":" + name)
+	if err != nil {
+		printVerbose("%s did not exist at the merge base; merging without a base", path)
+		base = ""
+	}
+	return base, string(data), theirs, nil
+}
+
+// installMergeDriver registers the merge driver in the repository's git
+// config and marks the configured Brewfiles in .gitattributes
+func installMergeDriver(args []string) error {
+	dir := "."
+	var patterns []string
+	if cfg, err := config.Get(); err == nil {
+		for _, machine := range cfg.Machines {
+			if machine.Brewfile != "" {
+				patterns = append(patterns, filepath.Base(machine.Brewfile))
+			}
+		}
+		if machine, ok := cfg.GetCurrentMachine(); ok && machine.Brewfile != "" {
+			dir = filepath.Dir(machine.Brewfile)
+		}
+	}
+	if len(args) == 1 {
+		dir = args[0]
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("--install-driver takes at most one repository directory")
+	}
+	if len(patterns) == 0 {
+		patterns = []string{"Brewfile"}
+	}
+	patterns = uniqueSorted(patterns)
+
+	runner := exec.NewRunner()
+	top, err := runner.Run("git", "-C", dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return fmt.Errorf("not a git repository: %s", dir)
+	}
+	top = strings.TrimSpace(top)
+	attrPath := filepath.Join(top, ".gitattributes")
+
+	existing, err := os.ReadFile(attrPath)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read %s: %w", attrPath, err)
+	}
+	present := make(map[string]bool)
+	for _, line := range strings.Split(string(existing), "\n") {
+		present[strings.Join(strings.Fields(line), " ")] = true
+	}
+	var missing []string
+	for _, pattern := range patterns {
+		line := fmt.Sprintf("%s merge=%s", pattern, mergeDriverName)
+		if !present[line] {
+			missing = append(missing, line)
+		}
+	}
+
+	driver := "brewsync merge %O %A %B"
+	if dryRun {
+		printInfo("[dry-run] Would set merge.%s.driver = %s in %s", mergeDriverName, driver, top)
+		for _, line := range missing {
+			printInfo("[dry-run] Would add to %s: %s", attrPath, line)
+		}
+		return nil
+	}
+
+	section := "merge." + mergeDriverName
+	if _, err := runner.Run("git", "-C", top, "config", section+".name", "BrewSync package-aware Brewfile merge"); err != nil {
+		return fmt.Errorf("failed to configure merge driver: %w", err)
+	}
+	if _, err := runner.Run("git", "-C", top, "config", section+".driver", driver); err != nil {
+		return fmt.Errorf("failed to configure merge driver: %w", err)
+	}
+	printInfo("✓ Registered merge driver %q in %s", mergeDriverName, top)
+
+	if len(missing) == 0 {
+		printInfo("✓ .gitattributes already routes Brewfiles to the driver")
+		return nil
+	}
+
+	content := string(existing)
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	content += strings.Join(missing, "\n") + "\n"
+	if err := os.WriteFile(attrPath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", attrPath, err)
+	}
+	printInfo("✓ Added %d entries to %s; commit it to share the setting", len(missing), attrPath)
+	return nil
+}
+
+// uniqueSorted returns the distinct strings in sorted order
+func uniqueSorted(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var result []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/cobra"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
+	"github.com/andrew-sameh/brewsync/internal/config"
+	"github.com/andrew-sameh/brewsync/internal/exec"
+)
+
+var (
+	namesFrom   string
+	namesFormat string
+)
+
+var namesCmd = &cobra.Command{
+	Use:   "names",
+	Short: "Manage the package rename and alias table",
+	Long: `Manage the table BrewSync uses to recognise equivalent package names.
+
+Before comparing Brewfiles, package names are canonicalized so that
+tap-qualified names (homebrew/core/node), formula aliases (nodejs),
+renamed packages (youtube-dl → yt-dlp) and differently-cased editor
+extension ids are treated as the same package.
+
+A small built-in table ships with BrewSync. Refresh it from Homebrew's
+metadata to pick up every alias and rename of your installed packages.
+
+Examples:
+  brewsync names                            # Show the table
+  brewsync names refresh                    # Refresh from brew info
+  brewsync names refresh --from info.json   # Refresh from saved brew info output`,
+	RunE: runNamesList,
+}
+
+var namesRefreshCmd = &cobra.Command{
+	Use:   "refresh",
+	Short: "Refresh the table from brew info --json=v2",
+	RunE:  runNamesRefresh,
+}
+
+func init() {
+	namesCmd.Flags().StringVar(&namesFormat, "format", "table", "output format: table, json")
+	namesRefreshCmd.Flags().StringVar(&namesFrom, "from", "", "read brew info --json=v2 output from a file")
+	namesCmd.AddCommand(namesRefreshCmd)
+	rootCmd.AddCommand(namesCmd)
+}
+
+// loadNameTable extends the default normalizer with the refreshed name table
+func loadNameTable() {
+	path, err := config.NamesPath()
+	if err != nil {
+		return
+	}
+	table, err := brewfile.LoadNameTable(path)
+	if err != nil {
+		printWarning("Could not load name table %s: %v", path, err)
+		return
+	}
+	brewfile.DefaultNormalizer.Extend(table)
+}
+
+func runNamesList(cmd *cobra.Command, args []string) error {
+	path, err := config.NamesPath()
+	if err != nil {
+		return err
+	}
+	table := brewfile.DefaultNameTable()
+	refreshed, err := brewfile.LoadNameTable(path)
+	if err != nil {
+		return fmt.Errorf("failed to load name table: %w", err)
+	}
+	table.Merge(refreshed)
+
+	if namesFormat == "json" {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(map[string]interface{}{
+			"renames": table.Renames,
+			"aliases": table.Aliases,
+		})
+	}
+
+	printNameSection("Renames", table.Renames)
+	printNameSection("Aliases", table.Aliases)
+	if refreshed.Len() == 0 {
+		printInfo("Using the built-in table; run 'brewsync names refresh' to update it")
+	} else {
+		printVerbose("Refreshed table: %s", path)
+	}
+	return nil
+}
+
+// printNameSection prints "type: from → to" lines sorted by type and name
+func printNameSection(title string, names map[brewfile.PackageType]map[string]string) {
+	fmt.Println(styleBold.Render(title))
+	empty := true
+	for _, t := range brewfile.AllTypes() {
+		var froms []string
+		for from := range names[t] {
+			froms = append(froms, from)
+		}
+		sort.Strings(froms)
+		for _, from := range froms {
+			fmt.Printf("  %s: %s → %s\n", t, from, names[t][from])
+			empty = false
+		}
+	}
+	if empty {
+		fmt.Println(styleDim.Render("  (none)"))
+	}
+	fmt.Println()
+}
+
+func runNamesRefresh(cmd *cobra.Command, args []string) error {
+	var data []byte
+	if namesFrom != "" {
+		d, err := os.ReadFile(namesFrom)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", namesFrom, err)
+		}
+		data = d
+	} else {
+		if !exec.Exists("brew") {
+			return fmt.Errorf("brew not found; use --from with saved 'brew info --json=v2' output")
+		}
+		printInfo("Reading package metadata from brew...")
+		out, err := exec.Run("brew", "info", "--json=v2", "--installed")
+		if err != nil {
+			return fmt.Errorf("brew info failed: %w", err)
+		}
+		data = []byte(out)
+	}
+
+	table, err := brewfile.ParseBrewInfo(data)
+	if err != nil {
+		return err
+	}
+
+	if dryRun {
+		printInfo("Dry run - would write %d names", table.Len())
+		return nil
+	}
+
+	if err := config.EnsureDir(); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	path, err := config.NamesPath()
+	if err != nil {
+		return err
+	}
+	if err := table.Save(path); err != nil {
+		return fmt.Errorf("failed to write name table: %w", err)
+	}
+
+	printInfo("Wrote %d renames and aliases to %s", table.Len(), path)
+	return nil
+}
@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
+	"github.com/andrew-sameh/brewsync/internal/installer"
+)
+
+var (
+	outdatedOnly   string
+	outdatedSkip   string
+	outdatedFormat string
+)
+
+var outdatedCmd = &cobra.Command{
+	Use:   "outdated",
+	Short: "Show installed packages with newer versions available",
+	Long: `Show installed packages that have a newer version available.
+
+Formulae and casks come from 'brew outdated', App Store apps from
+'mas outdated', VSCode extensions from the Visual Studio Marketplace,
+Cursor and Antigravity extensions from Open VSX, and Go tools from the
+module versions recorded in their binaries compared with the latest
+release of each module. Custom package types are not checked.
+
+Examples:
+  brewsync outdated                 # All package types
+  brewsync outdated --only brew,go  # Filter categories
+  brewsync outdated --skip mas      # Exclude categories
+  brewsync outdated --format json   # Machine-readable output`,
+	RunE: runOutdated,
+}
+
+func init() {
+	outdatedCmd.Flags().StringVar(&outdatedOnly, "only", "", "only check these package types (comma-separated)")
+	outdatedCmd.Flags().StringVar(&outdatedSkip, "skip", "", "skip these package types (comma-separated)")
+	outdatedCmd.Flags().StringVar(&outdatedFormat, "format", "text", "output format: text, json")
+
+	rootCmd.AddCommand(outdatedCmd)
+}
+
+func runOutdated(cmd *cobra.Command, args []string) error {
+	if outdatedFormat == "json" {
+		// Progress messages would corrupt the JSON on stdout
+		quiet = true
+	}
+
+	outdated, err := collectOutdated(commandContext(cmd), installer.NewManager(), outdatedOnly, outdatedSkip)
+	if err != nil {
+		return err
+	}
+
+	if outdatedFormat == "json" {
+		return writeOutdatedJSON(outdated)
+	}
+
+	if len(outdated) == 0 {
+		printInfo("Everything is up to date")
+		return nil
+	}
+	printOutdated(outdated)
+	printInfo("Run 'brewsync upgrade' to upgrade them")
+	return nil
+}
+
+// collectOutdated checks every available installer for outdated packages
+// and applies the --only and --skip filters. Installers whose check failed
+// are reported as warnings.
+func collectOutdated(ctx context.Context, mgr *installer.Manager, only, skip string) ([]installer.Outdated, error) {
+	printInfo("Checking for outdated packages...")
+	outdated, err := mgr.Outdated(ctx)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		for _, line := range strings.Split(err.Error(), "\n") {
+			printWarning("%s", line)
+		}
+	}
+
+	if only == "" && skip == "" {
+		return outdated, nil
+	}
+	var pkgs brewfile.Packages
+	for _, o := range outdated {
+		pkgs = append(pkgs, o.Package)
+	}
+	if only != "" {
+		pkgs = filterByCategories(pkgs, parseCategories(only), true)
+	}
+	if skip != "" {
+		pkgs = filterByCategories(pkgs, parseCategories(skip), false)
+	}
+	keep := make(map[string]bool)
+	for _, pkg := range pkgs {
+		keep[pkg.ID()] = true
+	}
+	var filtered []installer.Outdated
+	for _, o := range outdated {
+		if keep[o.Package.ID()] {
+			filtered = append(filtered, o)
+		}
+	}
+	return filtered, nil
+}
+
+// printOutdated lists outdated packages grouped by type
+func printOutdated(outdated []installer.Outdated) {
+	var order []brewfile.PackageType
+	byType := make(map[brewfile.PackageType][]installer.Outdated)
+	width := 0
+	for _, o := range outdated {
+		if _, seen := byType[o.Package.Type]; !seen {
+			order = append(order, o.Package.Type)
+		}
+		byType[o.Package.Type] = append(byType[o.Package.Type], o)
+		if n := len(outdatedName(o)); n > width {
+			width = n
+		}
+	}
+
+	fmt.Println()
+	fmt.Printf("Outdated packages (%d)\n", len(outdated))
+	fmt.Println(strings.Repeat("─", 50))
+	for _, pkgType := range order {
+		fmt.Printf("\n%s %s (%d)\n", colorYellow("▶"), pkgType, len(byType[pkgType]))
+		for _, o := range byType[pkgType] {
+			line := fmt.Sprintf("  %-*s  %s → %s", width, outdatedName(o), o.Installed, colorGreen(o.Latest))
+			if o.Pinned {
+				line += " (pinned)"
+			}
+			fmt.Println(line)
+		}
+	}
+	fmt.Println()
+}
+
+// outdatedName is the display name of an outdated package; App Store apps
+// show their title next to the ID
+func outdatedName(o installer.Outdated) string {
+	if o.Package.FullName != "" && o.Package.FullName != o.Package.Name {
+		return fmt.Sprintf("%s (%s)", o.Package.FullName, o.Package.Name)
+	}
+	return o.Package.Name
+}
+
+// writeOutdatedJSON prints outdated packages as JSON
+func writeOutdatedJSON(outdated []installer.Outdated) error {
+	type entry struct {
+		Package   string `json:"package"`
+		Installed string `json:"installed"`
+		Latest    string `json:"latest"`
+		Pinned    bool   `json:"pinned,omitempty"`
+	}
+	entries := make([]entry, 0, len(outdated))
+	for _, o := range outdated {
+		entries = append(entries, entry{
+			Package:   o.Package.ID(),
+			Installed: o.Installed,
+			Latest:    o.Latest,
+			Pinned:    o.Pinned,
+		})
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(map[string]interface{}{"outdated": entries})
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/spf13/cobra"
 
 	"github.com/andrew-sameh/brewsync/internal/brewfile"
+	"github.com/andrew-sameh/brewsync/internal/config"
+	"github.com/andrew-sameh/brewsync/internal/hooks"
 	"github.com/andrew-sameh/brewsync/internal/installer"
 	"github.com/andrew-sameh/brewsync/internal/profile"
 )
@@ -231,22 +233,39 @@ func runProfileInstall(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	ctx := commandContext(cmd)
+	event := installEvent(cfg, hooks.OperationProfile, strings.Join(names, ","), packages, nil, nil)
+	if err := runPreHook(ctx, cfg, config.HookPreInstall, event); err != nil {
+		return err
+	}
+
 	// Install packages
 	mgr := installer.NewManager()
 	var installed, failed int
+	var installedPkgs, failedPkgs brewfile.Packages
 
-	mgr.InstallMany(packages, func(pkg brewfile.Package, i, total int, err error) {
+	err = mgr.InstallMany(ctx, packages, func(pkg brewfile.Package, i, total int, err error) {
 		if err != nil {
-			printError("[%d/%d] Failed to install %s:%s: %v", i, total, pkg.Type, pkg.Name, err)
+			printError("[%d/%d] Failed to install %s:%s (%s): %v", i, total, pkg.Type, pkg.Name, installer.Classify(err), err)
+			failedPkgs = append(failedPkgs, pkg)
 			failed++
 		} else {
 			printInfo("[%d/%d] Installed %s:%s", i, total, pkg.Type, pkg.Name)
+			installedPkgs = append(installedPkgs, pkg)
 			installed++
 		}
 	})
 
 	fmt.Println()
 	printInfo("Installed: %d, Failed: %d", installed, failed)
+	runPostHook(ctx, cfg, config.HookPostInstall, installedEvent(event, installedPkgs, nil, nil, failedPkgs))
+	if skipped := interruptedPackages(err); len(skipped) > 0 {
+		return fmt.Errorf("interrupted: %d packages not attempted", len(skipped))
+	}
 
 	return nil
 }
@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
+	"github.com/andrew-sameh/brewsync/internal/history"
+	"github.com/andrew-sameh/brewsync/internal/installer"
+)
+
+// installReport records the outcome of a batch of package operations for
+// the history log and --format json output
+type installReport struct {
+	installed []string
+	removed   []string
+	changed   []string
+	started   []string // formulae whose service was started to match the source
+	manual    []string // packages the installer cannot remove
+	failed    []failureEntry
+	skipped   []string
+	// rolledBack lists the packages whose sync step was undone after a failure
+	rolledBack []string
+}
+
+// failureEntry is the JSON form of a failed package operation
+type failureEntry struct {
+	Package  string `json:"package"`
+	Category string `json:"category"`
+	Attempts int    `json:"attempts"`
+	Error    string `json:"error"`
+}
+
+// fail records a failed package and returns its failure category
+func (r *installReport) fail(pkg brewfile.Package, err error) installer.FailureKind {
+	kind := installer.Classify(err)
+	r.failed = append(r.failed, failureEntry{
+		Package:  pkg.ID(),
+		Category: string(kind),
+		Attempts: installer.Attempts(err),
+		Error:    err.Error(),
+	})
+	return kind
+}
+
+// skip records packages that were never attempted
+func (r *installReport) skip(pkgs brewfile.Packages) {
+	for _, pkg := range pkgs {
+		r.skipped = append(r.skipped, pkg.ID())
+	}
+}
+
+// historyFailures returns the failures in history log form
+func (r *installReport) historyFailures() []history.Failure {
+	var failures []history.Failure
+	for _, f := range r.failed {
+		failures = append(failures, history.Failure{ID: f.Package, Category: f.Category})
+	}
+	return failures
+}
+
+// writeJSON prints the report; removed and changed are included for sync.
+// Services lists the formulae whose service was started.
+func (r *installReport) writeJSON(withRemovals bool) error {
+	output := map[string]interface{}{
+		"installed": nonNil(r.installed),
+		"failed":    r.failed,
+		"skipped":   nonNil(r.skipped),
+		"services":  nonNil(r.started),
+	}
+	if r.failed == nil {
+		output["failed"] = []failureEntry{}
+	}
+	if withRemovals {
+		output["removed"] = nonNil(r.removed)
+		output["changed"] = nonNil(r.changed)
+		output["manual"] = nonNil(r.manual)
+		output["rolled_back"] = nonNil(r.rolledBack)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(output)
+}
+
+// splitInstallable separates packages whose type has no installer here or
+// whose installer is not installed. Each refused package is recorded as a
+// failure and every refused type is reported once with the reason.
+func splitInstallable(mgr *installer.Manager, pkgs brewfile.Packages, report *installReport) (installable, refused brewfile.Packages) {
+	var types []brewfile.PackageType
+	reasons := make(map[brewfile.PackageType]error)
+	counts := make(map[brewfile.PackageType]int)
+	for _, pkg := range pkgs {
+		err := mgr.CanInstall(pkg)
+		if err == nil {
+			installable = append(installable, pkg)
+			continue
+		}
+		refused = append(refused, pkg)
+		report.fail(pkg, err)
+		if _, seen := reasons[pkg.Type]; !seen {
+			types = append(types, pkg.Type)
+			reasons[pkg.Type] = err
+		}
+		counts[pkg.Type]++
+	}
+
+	for _, pkgType := range types {
+		printWarning("Skipping %d %s packages: %v", counts[pkgType], pkgType, reasons[pkgType])
+	}
+	return installable, refused
+}
+
+// nonNil returns values, or an empty slice so JSON shows [] instead of null
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/andrew-sameh/brewsync/internal/config"
+	"github.com/andrew-sameh/brewsync/internal/history"
+	"github.com/andrew-sameh/brewsync/internal/installer"
+	"github.com/andrew-sameh/brewsync/internal/journal"
+)
+
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback",
+	Short: "Undo the last sync",
+	Long: `Undo the completed steps of the last 'brewsync sync --apply'.
+
+Sync writes a journal of every step to ~/.config/brewsync/journal.json
+before running it. Rollback reads it and undoes the completed steps, most
+recent first, where the backend can: installed packages are uninstalled,
+removed packages are installed again with the options they had (link:
+false, running services), and changed packages are converged back to their
+previous declaration. Steps that failed or never ran are left alone.
+
+Steps whose undo fails stay in the journal, so running rollback again
+retries them. The outcome is recorded in history.
+
+Examples:
+  brewsync rollback            # Show the steps and confirm
+  brewsync rollback --dry-run  # Only show what would be undone
+  brewsync rollback --yes      # Undo without confirmation`,
+	RunE: runRollback,
+}
+
+func init() {
+	rootCmd.AddCommand(rollbackCmd)
+}
+
+func runRollback(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	path, err := config.JournalPath()
+	if err != nil {
+		return err
+	}
+	j, err := journal.Load(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no sync to roll back")
+		}
+		return fmt.Errorf("failed to read sync journal: %w", err)
+	}
+	if j.Machine != cfg.CurrentMachine {
+		return fmt.Errorf("the last sync ran on %s, not on this machine (%s)", j.Machine, cfg.CurrentMachine)
+	}
+
+	steps := j.Undoable()
+	if len(steps) == 0 {
+		printInfo("Nothing to roll back: the sync from %s on %s is %s",
+			j.Source, j.Started.Format("2006-01-02 15:04"), j.Status)
+		return nil
+	}
+
+	printInfo("Sync from %s on %s (%s)", j.Source, j.Started.Format("2006-01-02 15:04"), j.Status)
+	printInfo("Rollback would undo %d steps:", len(steps))
+	for _, step := range steps {
+		printInfo("  %s", step.UndoString())
+	}
+
+	if dryRun {
+		printInfo("Dry-run mode - nothing undone")
+		return nil
+	}
+
+	if !assumeYes {
+		fmt.Printf("Roll back these steps? [y/N] ")
+		var response string
+		fmt.Scanln(&response)
+		if response != "y" && response != "Y" {
+			printInfo("Rollback cancelled")
+			return nil
+		}
+	}
+
+	ctx := commandContext(cmd)
+	err = rollbackJournal(ctx, installer.NewManager(), j, nil)
+	if ctx.Err() != nil {
+		return fmt.Errorf("rollback interrupted")
+	}
+	if err != nil {
+		return fmt.Errorf("rollback incomplete; run 'brewsync rollback' again to retry")
+	}
+
+	if cfg.AutoDump.Enabled && cfg.AutoDump.AfterInstall {
+		autoDump(cfg, cfg.CurrentMachine)
+	}
+	return nil
+}
+
+// rollbackJournal undoes the completed steps of a sync journal, reports
+// each one and records the outcome in history. Undone steps are added to
+// report when it is set.
+func rollbackJournal(ctx context.Context, mgr *installer.Manager, j *journal.Journal, report *installReport) error {
+	printInfo("Rolling back %d steps...", len(j.Undoable()))
+	var undone []string
+	var failures []history.Failure
+	err := journal.Rollback(ctx, mgr, j, func(step journal.Step, i, total int, err error) {
+		if err != nil {
+			kind := installer.Classify(err)
+			printError("[%d/%d] Failed to %s (%s): %v", i, total, step.UndoString(), kind, err)
+			failures = append(failures, history.Failure{ID: step.Package.ID(), Category: string(kind)})
+			return
+		}
+		printInfo("[%d/%d] %s", i, total, step.UndoString())
+		undone = append(undone, step.UndoString())
+		if report != nil {
+			report.rolledBack = append(report.rolledBack, step.Package.ID())
+		}
+	})
+
+	if !quiet {
+		fmt.Println()
+	}
+	if j.Status == journal.StatusRolledBack {
+		printInfo("Rollback complete: %d undone", len(undone))
+	} else {
+		printWarning("Rollback incomplete: %d undone, %d failed", len(undone), len(failures))
+	}
+	history.LogRollback(j.Machine, j.Source, undone, failures)
+	return err
+}
@@ -1,15 +1,20 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/spf13/cobra"
 
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
 	"github.com/andrew-sameh/brewsync/internal/config"
 	"github.com/andrew-sameh/brewsync/internal/debug"
+	"github.com/andrew-sameh/brewsync/internal/installer"
 	"github.com/andrew-sameh/brewsync/internal/tui/app"
 	"github.com/andrew-sameh/brewsync/pkg/version"
 )
@@ -84,13 +89,41 @@ between machines.`,
 			return nil
 		}
 
+		// Replay or record commands; may also pick the simulated config
+		if err := setupRunner(); err != nil {
+			return err
+		}
+
 		// Set config path if provided
 		if cfgFile != "" {
 			config.SetConfigPath(cfgFile)
 		}
 
 		// Initialize config
-		return config.Init()
+		if err := config.Init(); err != nil {
+			return err
+		}
+
+		// Recognise renamed and aliased packages when comparing Brewfiles
+		loadNameTable()
+
+		// Register package types declared under custom_types
+		if err := config.LoadCustomTypes(); err != nil {
+			printWarning("custom package types: %v", err)
+		}
+
+		// Register VS Code-family editors declared under editors
+		if err := config.LoadEditors(); err != nil {
+			printWarning("editors: %v", err)
+		}
+
+		// Choose between pinned and latest Go tool versions
+		if policy, err := installer.ParseGoVersionPolicy(config.GoToolVersions()); err != nil {
+			printWarning("go_tools.versions: %v", err)
+		} else {
+			installer.DefaultGoVersions = policy
+		}
+		return nil
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
 		// Launch the full TUI when no subcommand is provided
@@ -137,13 +170,29 @@ func runMainTUI() error {
 	return err
 }
 
-// Execute runs the root command
+// Execute runs the root command. Interrupting brewsync cancels the command's
+// context, which stops running package operations.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	err := rootCmd.ExecuteContext(ctx)
+	saveRecording()
+	if err != nil {
+		stop()
 		os.Exit(1)
 	}
 }
 
+// commandContext returns the context of a running command, or a background
+// context when a command function is called directly (e.g. auto-dump)
+func commandContext(cmd *cobra.Command) context.Context {
+	if cmd == nil || cmd.Context() == nil {
+		return context.Background()
+	}
+	return cmd.Context()
+}
+
 func init() {
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default ~/.config/brewsync/config.yaml)")
@@ -152,6 +201,8 @@ func init() {
 	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "minimal output")
 	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable colored output")
 	rootCmd.PersistentFlags().BoolVarP(&assumeYes, "yes", "y", false, "skip confirmations")
+	rootCmd.PersistentFlags().StringVar(&simulateDir, "simulate", "", "replay the machine recorded in a transcript directory")
+	rootCmd.PersistentFlags().StringVar(&recordDir, "record", "", "record every command run into a transcript directory")
 
 	// Add subcommands
 	rootCmd.AddCommand(dumpCmd)
@@ -191,3 +242,12 @@ func printStyled(text string, style lipgloss.Style) {
 		fmt.Print(style.Render(text))
 	}
 }
+
+// customTypeIcon returns the icon configured for a custom package type, or a
+// generic one
+func customTypeIcon(t brewfile.PackageType) string {
+	if icon := brewfile.TypeIcon(t); icon != "" {
+		return icon
+	}
+	return "🧩"
+}
@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
+	"github.com/andrew-sameh/brewsync/internal/config"
+	"github.com/andrew-sameh/brewsync/internal/installer"
+)
+
+// servicesToStart returns the formulae whose service runs on a source
+// machine but is stopped here. Ignored packages and categories and packages
+// specific to any machine are left alone: their services are that
+// machine's business.
+func servicesToStart(cfg *config.Config, machine string, changes []brewfile.Change) brewfile.Packages {
+	if cfg.IsCategoryIgnored(machine, string(brewfile.TypeBrew)) {
+		return nil
+	}
+	skip := make(map[string]bool)
+	for _, id := range cfg.GetIgnoredPackages(machine) {
+		skip[id] = true
+	}
+	for _, pkgs := range cfg.GetMachineSpecificPackages() {
+		for _, id := range pkgs {
+			skip[id] = true
+		}
+	}
+
+	var pkgs brewfile.Packages
+	for _, change := range changes {
+		if change.ServiceChanged() && change.Source.ServiceRunning() && !skip[change.ID()] {
+			pkgs = append(pkgs, change.Source)
+		}
+	}
+	return pkgs
+}
+
+// runningServices returns the formulae in pkgs declared with their service running
+func runningServices(pkgs brewfile.Packages) brewfile.Packages {
+	var running brewfile.Packages
+	for _, pkg := range pkgs {
+		if pkg.ServiceRunning() {
+			running = append(running, pkg)
+		}
+	}
+	return running
+}
+
+// startServices starts the service of each formula. A service that fails
+// to start is a warning; the formula itself is installed.
+func startServices(ctx context.Context, mgr *installer.Manager, pkgs brewfile.Packages, report *installReport) {
+	for _, pkg := range pkgs {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := mgr.SetService(ctx, pkg, true); err != nil {
+			printWarning("Could not start the %s service: %v", pkg.Name, err)
+			continue
+		}
+		printInfo("Started the %s service", pkg.Name)
+		report.started = append(report.started, pkg.ID())
+	}
+}
+
+// confirmServices lists the services about to be started and asks before
+// starting them unless --yes is given. With --dry-run it only lists them.
+func confirmServices(pkgs brewfile.Packages) bool {
+	if len(pkgs) == 0 {
+		return false
+	}
+	names := getPkgNames(pkgs)
+	if dryRun {
+		printInfo("Would start services: %s", strings.Join(names, ", "))
+		return false
+	}
+	if assumeYes {
+		return true
+	}
+	fmt.Printf("Start services running on the source (%s)? [y/N] ", strings.Join(names, ", "))
+	var response string
+	fmt.Scanln(&response)
+	return response == "y" || response == "Y"
+}
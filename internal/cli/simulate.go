@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/andrew-sameh/brewsync/internal/exec"
+)
+
+var (
+	simulateDir string
+	recordDir   string
+
+	// recorder captures commands for --record; it is saved when brewsync exits
+	recorder *exec.Recorder
+)
+
+// setupRunner swaps the command runner for --simulate or --record. It must
+// run before any installer is created, since installers keep the runner
+// they were created with.
+func setupRunner() error {
+	switch {
+	case simulateDir != "" && recordDir != "":
+		return fmt.Errorf("--simulate and --record cannot be used together")
+
+	case simulateDir != "":
+		fake, err := exec.LoadFakeRunner(simulateDir)
+		if err != nil {
+			return fmt.Errorf("failed to load simulated machine: %w", err)
+		}
+		fake.LatencyScale = 1
+		exec.Default = fake
+
+		// Keep the simulation away from the real config, history, Go tools
+		// and editor extensions: home is a directory inside the transcript
+		home := filepath.Join(simulateDir, "home")
+		if err := os.MkdirAll(home, 0755); err != nil {
+			return fmt.Errorf("failed to create simulated home: %w", err)
+		}
+		os.Setenv("HOME", home)
+		os.Unsetenv("GOBIN")
+		os.Unsetenv("GOPATH")
+
+		if path := filepath.Join(simulateDir, "config.yaml"); cfgFile == "" && fileExists(path) {
+			cfgFile = path
+		}
+		printVerbose("Simulating the machine recorded in %s", simulateDir)
+
+	case recordDir != "":
+		recorder = exec.NewRecorder(exec.NewRunner())
+		exec.Default = recorder
+		printVerbose("Recording commands to %s", filepath.Join(recordDir, exec.TranscriptFile))
+	}
+	return nil
+}
+
+// saveRecording writes the commands captured by --record
+func saveRecording() {
+	if recorder == nil {
+		return
+	}
+	if err := recorder.Save(recordDir); err != nil {
+		printError("failed to save recording: %v", err)
+	}
+}
+
+// fileExists reports whether path is an existing file
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
@@ -3,7 +3,6 @@ package cli
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
@@ -34,12 +33,13 @@ func init() {
 
 // Metadata represents the .brewsync-meta file
 type Metadata struct {
-	Machine         string         `yaml:"machine"`
-	LastDump        time.Time      `yaml:"last_dump"`
-	LastSync        *LastSyncInfo  `yaml:"last_sync,omitempty"`
-	PackageCounts   map[string]int `yaml:"package_counts"`
-	MacOSVersion    string         `yaml:"macos_version,omitempty"`
-	BrewsyncVersion string         `yaml:"brewsync_version,omitempty"`
+	Machine         string               `yaml:"machine"`
+	LastDump        time.Time            `yaml:"last_dump"`
+	LastSync        *LastSyncInfo        `yaml:"last_sync,omitempty"`
+	PackageCounts   map[string]int       `yaml:"package_counts"`
+	MacOSVersion    string               `yaml:"macos_version,omitempty"`
+	BrewsyncVersion string               `yaml:"brewsync_version,omitempty"`
+	System          brewfile.SystemFacts `yaml:"system,omitempty"`
 }
 
 type LastSyncInfo struct {
@@ -140,10 +140,16 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	if err == nil {
 		// Show detailed package counts
 		allLines = append(allLines, formatPackageCountsDetailed(packages))
+
+		// Show where included packages come from
+		if includes := formatIncludeCounts(packages); includes != "" {
+			allLines = append(allLines, "")
+			allLines = append(allLines, formatStatusLine("📎", "Includes", includes, catSubtext0))
+		}
 	}
 
 	// Metadata (if available)
-	metaPath := filepath.Join(filepath.Dir(machine.Brewfile), ".brewsync-meta")
+	metaPath := brewfile.MetadataPath(machine.Brewfile)
 	meta, err := loadMetadata(metaPath)
 	if err == nil && meta != nil {
 		allLines = append(allLines, "")
@@ -157,6 +163,9 @@ func runStatus(cmd *cobra.Command, args []string) error {
 			}
 			allLines = append(allLines, formatStatusLine("🔄", "Last Sync", syncDetails, catBlue))
 		}
+		if facts := formatSystemFacts(meta.System); facts != "" {
+			allLines = append(allLines, formatStatusLine("🧩", "System", facts, catSubtext0))
+		}
 	}
 
 	// Ignored section
@@ -206,7 +215,8 @@ func runStatus(cmd *cobra.Command, args []string) error {
 		if ok {
 			sourcePackages, err := brewfile.Parse(sourceMachine.Brewfile)
 			if err == nil {
-				diff := brewfile.Diff(sourcePackages, packages)
+				diff := brewfile.Diff(sourcePackages, packages).
+					ExcludeTypes(cfg.UnsupportedTypes(cfg.DefaultSource, currentMachine)...)
 
 				// Filter out ignored packages and categories
 				diff = filterIgnoredFromDiff(diff, ignoredCategories, ignoredPkgs)
@@ -239,6 +249,28 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// formatIncludeCounts summarizes included packages, e.g. "12 from common.Brewfile"
+func formatIncludeCounts(packages brewfile.Packages) string {
+	counts := make(map[string]int)
+	var order []string
+	for _, pkg := range packages {
+		if pkg.Source == "" {
+			continue
+		}
+		label := pkg.Provenance()
+		if counts[label] == 0 {
+			order = append(order, label)
+		}
+		counts[label]++
+	}
+
+	var parts []string
+	for _, label := range order {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[label], label))
+	}
+	return strings.Join(parts, ", ")
+}
+
 func printPackageCounts(packages brewfile.Packages) {
 	byType := packages.ByType()
 	typeOrder := []brewfile.PackageType{
@@ -312,6 +344,30 @@ func loadMetadata(path string) (*Metadata, error) {
 	return &meta, nil
 }
 
+// formatSystemFacts summarizes recorded system facts, e.g.
+// "darwin 14.5 · arm64 · Homebrew 4.3.5 at /opt/homebrew"
+func formatSystemFacts(f brewfile.SystemFacts) string {
+	var parts []string
+	if f.OS != "" {
+		parts = append(parts, strings.TrimSpace(f.OS+" "+f.OSVersion))
+	}
+	if f.Arch != "" {
+		arch := f.Arch
+		if f.Rosetta {
+			arch += " (Rosetta)"
+		}
+		parts = append(parts, arch)
+	}
+	if f.HomebrewPrefix != "" {
+		brew := "Homebrew"
+		if f.HomebrewVersion != "" {
+			brew += " " + f.HomebrewVersion
+		}
+		parts = append(parts, brew+" at "+f.HomebrewPrefix)
+	}
+	return strings.Join(parts, " · ")
+}
+
 func formatTimeAgo(t time.Time) string {
 	duration := time.Since(t)
 
@@ -430,6 +486,10 @@ func formatPendingCompact(diff *brewfile.DiffResult) string {
 		brewfile.TypeGo:          "🔷",
 		brewfile.TypeMas:         "🍎",
 	}
+	for _, t := range brewfile.CustomTypes() {
+		typeOrder = append(typeOrder, t)
+		typeIcons[t] = customTypeIcon(t)
+	}
 
 	addByType := diff.AdditionsByType()
 	remByType := diff.RemovalsByType()
@@ -492,6 +552,13 @@ func formatPackageCountsDetailed(packages brewfile.Packages) string {
 		brewfile.TypeGo:          {"🔷", catSapphire},
 		brewfile.TypeMas:         {"🍎", catRed},
 	}
+	for _, t := range brewfile.CustomTypes() {
+		typeOrder = append(typeOrder, t)
+		typeInfo[t] = struct {
+			icon  string
+			color lipgloss.Color
+		}{customTypeIcon(t), catLavender}
+	}
 
 	var lines []string
 	total := len(packages)
@@ -539,6 +606,13 @@ func formatPendingDetailed(diff *brewfile.DiffResult) string {
 			Render(fmt.Sprintf("-%d to remove", remCount))
 		summaryParts = append(summaryParts, remText)
 	}
+	if len(diff.Changed) > 0 {
+		changedText := lipgloss.NewStyle().
+			Foreground(catYellow).
+			Bold(true).
+			Render(fmt.Sprintf("~%d changed", len(diff.Changed)))
+		summaryParts = append(summaryParts, changedText)
+	}
 
 	lines = append(lines, "  "+strings.Join(summaryParts, ", "))
 	lines = append(lines, "")
@@ -565,6 +639,10 @@ func formatPendingDetailed(diff *brewfile.DiffResult) string {
 		brewfile.TypeGo:          "🔷",
 		brewfile.TypeMas:         "🍎",
 	}
+	for _, t := range brewfile.CustomTypes() {
+		typeOrder = append(typeOrder, t)
+		typeIcons[t] = customTypeIcon(t)
+	}
 
 	addByType := diff.AdditionsByType()
 	remByType := diff.RemovalsByType()
@@ -641,9 +719,19 @@ func filterIgnoredFromDiff(diff *brewfile.DiffResult, ignoredCategories, ignored
 		filteredRemovals = append(filteredRemovals, pkg)
 	}
 
+	// Filter changed packages
+	var filteredChanged []brewfile.Change
+	for _, change := range diff.Changed {
+		if ignoredCatMap[string(change.Source.Type)] || ignoredPkgMap[change.ID()] {
+			continue
+		}
+		filteredChanged = append(filteredChanged, change)
+	}
+
 	return &brewfile.DiffResult{
 		Additions: filteredAdditions,
 		Removals:  filteredRemovals,
 		Common:    diff.Common, // Keep common as is
+		Changed:   filteredChanged,
 	}
 }
@@ -1,6 +1,8 @@
 package cli
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -9,14 +11,18 @@ import (
 	"github.com/andrew-sameh/brewsync/internal/brewfile"
 	"github.com/andrew-sameh/brewsync/internal/config"
 	"github.com/andrew-sameh/brewsync/internal/history"
+	"github.com/andrew-sameh/brewsync/internal/hooks"
 	"github.com/andrew-sameh/brewsync/internal/installer"
+	"github.com/andrew-sameh/brewsync/internal/journal"
 )
 
 var (
-	syncFrom    string
-	syncOnly    string
-	syncApply   bool
-	syncPreview bool
+	syncFrom       string
+	syncOnly       string
+	syncApply      bool
+	syncPreview    bool
+	syncFormat     string
+	syncNoRollback bool
 )
 
 var syncCmd = &cobra.Command{
@@ -25,17 +31,35 @@ var syncCmd = &cobra.Command{
 	Long: `Make current machine match source exactly (adds AND removes packages).
 
 Unlike import, sync will both install missing packages and remove packages
-that exist on current but not on source. This makes the machines identical.
+that exist on current but not on source. Packages declared on both with
+different options (e.g. link: false) are reinstalled or relinked to match
+the source. Services running on the source are started and services stopped
+there are stopped here, without reinstalling; services of machine-specific
+formulae are left alone. This makes the machines identical.
 
 By default, sync shows a preview. Use --apply to execute changes.
 
+Before applying, every step is written to a journal. When a step fails,
+the completed steps are rolled back so the machine is left as it was:
+installed packages are uninstalled, removed packages are reinstalled with
+their options and changed packages are changed back. Use --no-rollback to
+keep the completed steps; 'brewsync rollback' undoes them later, as it does
+after an interrupted sync.
+
+Failures are classified (network, not-found, needs-sudo, conflict, ...) and
+network failures are retried with backoff. With --format json (requires
+--apply and --yes), the installed, changed, removed, failed and skipped
+packages are printed as JSON instead of the preview and progress lines.
+
 Examples:
   brewsync sync                    # Preview mode (dry-run)
   brewsync sync --preview          # Explicit preview
   brewsync sync --apply            # Execute changes
   brewsync sync --from air         # Sync from specific machine
   brewsync sync --only brew        # Only sync brews
-  brewsync sync --apply --dry-run  # Preview even with --apply`,
+  brewsync sync --apply --dry-run  # Preview even with --apply
+  brewsync sync --apply --no-rollback  # Keep completed steps on failure
+  brewsync sync --apply --yes --format json  # Machine-readable results`,
 	RunE: runSync,
 }
 
@@ -44,11 +68,31 @@ func init() {
 	syncCmd.Flags().StringVar(&syncOnly, "only", "", "only sync these package types (comma-separated)")
 	syncCmd.Flags().BoolVar(&syncApply, "apply", false, "apply changes (default is preview only)")
 	syncCmd.Flags().BoolVar(&syncPreview, "preview", false, "show preview (default behavior)")
+	syncCmd.Flags().StringVar(&syncFormat, "format", "text", "output format: text, json (requires --apply and --yes)")
+	syncCmd.Flags().BoolVar(&syncNoRollback, "no-rollback", false, "keep completed steps when a step fails")
 
 	rootCmd.AddCommand(syncCmd)
 }
 
-func runSync(cmd *cobra.Command, args []string) error {
+func runSync(cmd *cobra.Command, args []string) (err error) {
+	report := &installReport{}
+	jsonOutput := syncFormat == "json"
+	if jsonOutput {
+		if !syncApply || !assumeYes || dryRun {
+			return fmt.Errorf("--format json requires --apply and --yes and cannot be used with --dry-run")
+		}
+		// Progress messages would corrupt the JSON on stdout
+		quiet = true
+		defer func() {
+			// An interrupted sync still reports what it did
+			if err == nil || len(report.skipped) > 0 {
+				if jsonErr := report.writeJSON(true); err == nil {
+					err = jsonErr
+				}
+			}
+		}()
+	}
+
 	// Load config
 	cfg, err := config.Load()
 	if err != nil {
@@ -92,14 +136,26 @@ func runSync(cmd *cobra.Command, args []string) error {
 
 	// Compute diff
 	diff := brewfile.Diff(sourcePkgs, currentPkgs)
+
+	// Casks and App Store apps are left alone when either machine runs
+	// Linux: a Linux source has none, which would remove every one here
+	diff, unsupported := excludeUnsupported(cfg, diff, source, currentMachine)
+	for _, note := range unsupported {
+		printInfo("%s", note)
+	}
+	for _, n := range diff.Normalized {
+		printVerbose("Normalized %s", n)
+	}
 	additions := diff.Additions
 	removals := diff.Removals
+	changes := diff.Changed
 
 	// Filter by category if specified
 	if syncOnly != "" {
 		categories := parseCategories(syncOnly)
 		additions = filterByCategories(additions, categories, true)
 		removals = filterByCategories(removals, categories, true)
+		changes = filterChangesByCategories(changes, categories)
 	}
 
 	// Filter ignored packages from additions
@@ -117,6 +173,14 @@ func runSync(cmd *cobra.Command, args []string) error {
 	}
 	additions = filteredAdditions
 
+	var filteredChanges []brewfile.Change
+	for _, change := range changes {
+		if !ignoredMap[change.ID()] {
+			filteredChanges = append(filteredChanges, change)
+		}
+	}
+	changes = filteredChanges
+
 	// Get machine-specific packages for current machine (protected from removal)
 	machineSpecific := cfg.GetMachineSpecificPackages()
 	protectedPkgs := make(map[string]bool)
@@ -143,53 +207,30 @@ func runSync(cmd *cobra.Command, args []string) error {
 	}
 	removals = filteredRemovals
 
-	// Check if there's anything to do
-	if len(additions) == 0 && len(removals) == 0 {
-		printInfo("Already in sync - no changes needed")
-		return nil
-	}
-
-	// Display preview
-	fmt.Println()
-	fmt.Printf("Sync Preview: %s → %s\n", source, currentMachine)
-	fmt.Println(strings.Repeat("─", 50))
-
-	if len(additions) > 0 {
-		fmt.Printf("\n%s TO BE INSTALLED (+%d)\n", colorGreen("▶"), len(additions))
-		grouped := groupByType(additions)
-		for pkgType, pkgs := range grouped {
-			names := getPkgNames(pkgs)
-			if len(names) > 5 {
-				fmt.Printf("  %s: %s (+%d more)\n", pkgType, strings.Join(names[:5], ", "), len(names)-5)
-			} else {
-				fmt.Printf("  %s: %s\n", pkgType, strings.Join(names, ", "))
-			}
+	// Services of machine-specific formulae are this machine's business
+	var unprotectedChanges []brewfile.Change
+	for _, change := range changes {
+		if change.ServiceOnly() && protectedPkgs[change.ID()] {
+			printVerbose("Leaving the %s service alone (machine-specific)", change.Source.Name)
+			continue
 		}
+		unprotectedChanges = append(unprotectedChanges, change)
 	}
+	changes = unprotectedChanges
 
-	if len(removals) > 0 {
-		fmt.Printf("\n%s TO BE REMOVED (-%d)\n", colorRed("▶"), len(removals))
-		grouped := groupByType(removals)
-		for pkgType, pkgs := range grouped {
-			names := getPkgNames(pkgs)
-			if len(names) > 5 {
-				fmt.Printf("  %s: %s (+%d more)\n", pkgType, strings.Join(names[:5], ", "), len(names)-5)
-			} else {
-				fmt.Printf("  %s: %s\n", pkgType, strings.Join(names, ", "))
-			}
-		}
-	}
+	// Packages whose installer cannot remove them are left to the user
+	mgr := installer.NewManager()
+	removals, manual := splitManualRemovals(mgr, removals)
 
-	if len(protectedList) > 0 {
-		fmt.Printf("\n%s PROTECTED (machine-specific/ignored, won't be removed: %d)\n", colorYellow("▶"), len(protectedList))
-		grouped := groupByType(protectedList)
-		for pkgType, pkgs := range grouped {
-			names := getPkgNames(pkgs)
-			fmt.Printf("  %s: %s\n", pkgType, strings.Join(names, ", "))
-		}
+	// Check if there's anything to do
+	if len(additions) == 0 && len(removals) == 0 && len(changes) == 0 && len(manual) == 0 {
+		printInfo("Already in sync - no changes needed")
+		return nil
 	}
 
-	fmt.Println()
+	if !jsonOutput {
+		printSyncPreview(source, currentMachine, additions, removals, changes, protectedList, manual)
+	}
 
 	// If preview mode or dry-run, stop here
 	if !syncApply || dryRun {
@@ -212,73 +253,285 @@ func runSync(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Apply changes
-	mgr := installer.NewManager()
-	var installedCount, removedCount, failedCount int
+	ctx := commandContext(cmd)
+	var changed brewfile.Packages
+	for _, change := range changes {
+		changed = append(changed, change.Source)
+	}
+	event := installEvent(cfg, hooks.OperationSync, source, additions, removals, changed)
+	if err := runPreHook(ctx, cfg, config.HookPreInstall, event); err != nil {
+		return err
+	}
+
+	// Write every step to the journal before running any of them, so a
+	// failed or interrupted sync can be rolled back
+	journalPath, err := config.JournalPath()
+	if err != nil {
+		return err
+	}
+	j := journal.New(journalPath, currentMachine, source)
+	present := installedPackages(ctx, mgr)
+	for _, pkg := range additions {
+		if present == nil || present.Has(pkg) {
+			j.AddPresent(pkg)
+		} else {
+			j.Add(journal.ActionInstall, pkg)
+		}
+	}
+	for _, change := range changes {
+		j.AddChange(change)
+	}
+	for _, pkg := range removals {
+		j.Add(journal.ActionUninstall, pkg)
+	}
+	if err := j.Save(); err != nil {
+		return fmt.Errorf("failed to write sync journal: %w", err)
+	}
+	record := func(action journal.Action, pkg brewfile.Package, err error) {
+		if jErr := j.Record(action, pkg, err); jErr != nil {
+			printWarning("Sync journal: %v", jErr)
+		}
+	}
+
+	// Apply changes; an interrupt stops the running step and skips the rest
+	var installedCount, removedCount, changedCount, failedCount int
+	var installedPkgs, changedPkgs, removedPkgs, failedPkgs, skipped brewfile.Packages
 
 	// Install additions first
 	if len(additions) > 0 {
 		printInfo("Installing %d packages...", len(additions))
-		mgr.InstallMany(additions, func(pkg brewfile.Package, i, total int, err error) {
+		err := mgr.InstallMany(ctx, additions, func(pkg brewfile.Package, i, total int, err error) {
+			record(journal.ActionInstall, pkg, err)
 			if err != nil {
-				printError("[%d/%d] Failed to install %s:%s: %v", i, total, pkg.Type, pkg.Name, err)
+				kind := report.fail(pkg, err)
+				printError("[%d/%d] Failed to install %s:%s (%s): %v", i, total, pkg.Type, pkg.Name, kind, err)
+				failedPkgs = append(failedPkgs, pkg)
 				failedCount++
 			} else {
 				printInfo("[%d/%d] Installed %s:%s", i, total, pkg.Type, pkg.Name)
+				report.installed = append(report.installed, pkg.ID())
+				installedPkgs = append(installedPkgs, pkg)
 				installedCount++
 			}
 		})
+		skipped = append(skipped, interruptedPackages(err)...)
+		startServices(ctx, mgr, runningServices(installedPkgs), report)
+	}
+
+	// Reinstall or relink packages whose options differ
+	if len(changes) > 0 && ctx.Err() != nil {
+		for _, change := range changes {
+			skipped = append(skipped, change.Source)
+		}
+	} else if len(changes) > 0 {
+		printInfo("Converging %d changed packages...", len(changes))
+		err := mgr.ConvergeMany(ctx, changes, func(change brewfile.Change, i, total int, err error) {
+			pkg := change.Source
+			record(journal.ActionChange, pkg, err)
+			if err != nil {
+				kind := report.fail(pkg, err)
+				printError("[%d/%d] Failed to update %s:%s (%s): %v", i, total, pkg.Type, pkg.Name, kind, err)
+				failedPkgs = append(failedPkgs, pkg)
+				failedCount++
+			} else {
+				printInfo("[%d/%d] Updated %s:%s", i, total, pkg.Type, pkg.Name)
+				report.changed = append(report.changed, pkg.ID())
+				changedPkgs = append(changedPkgs, pkg)
+				changedCount++
+			}
+		})
+		skipped = append(skipped, interruptedPackages(err)...)
 	}
 
 	// Then remove packages
-	if len(removals) > 0 {
+	if len(removals) > 0 && ctx.Err() != nil {
+		skipped = append(skipped, removals...)
+	} else if len(removals) > 0 {
 		printInfo("Removing %d packages...", len(removals))
-		mgr.UninstallMany(removals, func(pkg brewfile.Package, i, total int, err error) {
+		err := mgr.UninstallMany(ctx, removals, func(pkg brewfile.Package, i, total int, err error) {
+			record(journal.ActionUninstall, pkg, err)
 			if err != nil {
-				printError("[%d/%d] Failed to remove %s:%s: %v", i, total, pkg.Type, pkg.Name, err)
+				kind := report.fail(pkg, err)
+				printError("[%d/%d] Failed to remove %s:%s (%s): %v", i, total, pkg.Type, pkg.Name, kind, err)
+				failedPkgs = append(failedPkgs, pkg)
 				failedCount++
 			} else {
 				printInfo("[%d/%d] Removed %s:%s", i, total, pkg.Type, pkg.Name)
+				report.removed = append(report.removed, pkg.ID())
+				removedPkgs = append(removedPkgs, pkg)
 				removedCount++
 			}
 		})
+		skipped = append(skipped, interruptedPackages(err)...)
 	}
 
-	fmt.Println()
-	printInfo("Sync complete: +%d installed, -%d removed, %d failed",
-		installedCount, removedCount, failedCount)
+	for _, pkg := range manual {
+		printWarning("%s:%s must be removed manually (%s cannot uninstall)", pkg.Type, pkg.Name, pkg.Type)
+		report.manual = append(report.manual, pkg.ID())
+	}
+
+	if !quiet {
+		fmt.Println()
+	}
+	if len(skipped) > 0 {
+		printWarning("Sync interrupted: +%d installed, -%d removed, ~%d changed, %d failed, %d not attempted",
+			installedCount, removedCount, changedCount, failedCount, len(skipped))
+		for _, pkg := range skipped {
+			printVerbose("  not attempted: %s", pkg.ID())
+		}
+	} else {
+		printInfo("Sync complete: +%d installed, -%d removed, ~%d changed, %d failed, %d to remove manually",
+			installedCount, removedCount, changedCount, failedCount, len(manual))
+	}
+
+	runPostHook(ctx, cfg, config.HookPostInstall, installedEvent(event, installedPkgs, removedPkgs, changedPkgs, failedPkgs))
 
 	// Log to history
-	history.LogSync(currentMachine, source, installedCount, removedCount)
+	report.skip(skipped)
+	history.LogSync(currentMachine, source, installedCount, removedCount, report.historyFailures(), len(skipped))
+
+	status := journal.StatusCompleted
+	switch {
+	case len(skipped) > 0:
+		status = journal.StatusInterrupted
+	case failedCount > 0:
+		status = journal.StatusFailed
+	}
+	if err := j.Finish(status); err != nil {
+		printWarning("Sync journal: %v", err)
+	}
 
-	// Auto-dump if enabled and changes were made
-	if (installedCount > 0 || removedCount > 0) && cfg.AutoDump.Enabled && cfg.AutoDump.AfterInstall {
-		printInfo("Auto-dumping Brewfile...")
+	// A failed sync is rolled back so the machine is left as it was
+	undoable := len(j.Undoable())
+	if status == journal.StatusFailed && undoable > 0 && !syncNoRollback {
+		if !quiet {
+			fmt.Println()
+		}
+		if err := rollbackJournal(ctx, mgr, j, report); err != nil {
+			printWarning("Run 'brewsync rollback' to retry the steps that could not be undone")
+		}
+		undoable = len(j.Undoable())
+	} else if status != journal.StatusCompleted && undoable > 0 {
+		printInfo("Run 'brewsync rollback' to undo the %d completed steps", undoable)
+	}
 
-		// Set flags for commit/push based on config
-		oldCommit := dumpCommit
-		oldPush := dumpPush
-		oldMessage := dumpMessage
+	if ctx.Err() != nil {
+		return fmt.Errorf("sync interrupted")
+	}
+
+	// Auto-dump if enabled and changes were made and kept
+	if undoable > 0 && cfg.AutoDump.Enabled && cfg.AutoDump.AfterInstall {
+		autoDump(cfg, currentMachine)
+	}
+
+	return nil
+}
+
+// printSyncPreview shows what sync is about to install, change and remove
+func printSyncPreview(source, currentMachine string, additions, removals brewfile.Packages, changes []brewfile.Change, protectedList, manual brewfile.Packages) {
+	fmt.Println()
+	fmt.Printf("Sync Preview: %s → %s\n", source, currentMachine)
+	fmt.Println(strings.Repeat("─", 50))
 
-		dumpCommit = cfg.AutoDump.Commit
-		dumpPush = cfg.AutoDump.Push
-		if cfg.AutoDump.CommitMessage != "" {
-			dumpMessage = strings.ReplaceAll(cfg.AutoDump.CommitMessage, "{machine}", currentMachine)
+	if len(additions) > 0 {
+		fmt.Printf("\n%s TO BE INSTALLED (+%d)\n", colorGreen("▶"), len(additions))
+		grouped := groupByType(additions)
+		for pkgType, pkgs := range grouped {
+			names := getPkgNames(pkgs)
+			if len(names) > 5 {
+				fmt.Printf("  %s: %s (+%d more)\n", pkgType, strings.Join(names[:5], ", "), len(names)-5)
+			} else {
+				fmt.Printf("  %s: %s\n", pkgType, strings.Join(names, ", "))
+			}
 		}
+	}
 
-		err := runDump(nil, []string{})
+	if len(removals) > 0 {
+		fmt.Printf("\n%s TO BE REMOVED (-%d)\n", colorRed("▶"), len(removals))
+		grouped := groupByType(removals)
+		for pkgType, pkgs := range grouped {
+			names := getPkgNames(pkgs)
+			if len(names) > 5 {
+				fmt.Printf("  %s: %s (+%d more)\n", pkgType, strings.Join(names[:5], ", "), len(names)-5)
+			} else {
+				fmt.Printf("  %s: %s\n", pkgType, strings.Join(names, ", "))
+			}
+		}
+	}
 
-		// Restore flags
-		dumpCommit = oldCommit
-		dumpPush = oldPush
-		dumpMessage = oldMessage
+	if len(changes) > 0 {
+		fmt.Printf("\n%s TO BE CHANGED (~%d)\n", colorYellow("▶"), len(changes))
+		for _, change := range changes {
+			var details []string
+			for _, d := range change.Details() {
+				details = append(details, d.String())
+			}
+			fmt.Printf("  %s: %s (%s)\n", change.Source.Type, change.Source.Name, strings.Join(details, ", "))
+		}
+	}
 
-		if err != nil {
-			printWarning("Auto-dump failed: %v", err)
+	if len(protectedList) > 0 {
+		fmt.Printf("\n%s PROTECTED (machine-specific/ignored, won't be removed: %d)\n", colorYellow("▶"), len(protectedList))
+		grouped := groupByType(protectedList)
+		for pkgType, pkgs := range grouped {
+			names := getPkgNames(pkgs)
+			fmt.Printf("  %s: %s\n", pkgType, strings.Join(names, ", "))
 		}
 	}
 
-	return nil
+	if len(manual) > 0 {
+		fmt.Printf("\n%s MANUAL REMOVAL REQUIRED (not in source, BrewSync cannot remove: %d)\n", colorYellow("▶"), len(manual))
+		grouped := groupByType(manual)
+		for pkgType, pkgs := range grouped {
+			names := getPkgNames(pkgs)
+			fmt.Printf("  %s: %s\n", pkgType, strings.Join(names, ", "))
+		}
+	}
+
+	fmt.Println()
+}
+
+// installedPackages returns the packages installed before a sync, matched
+// by canonical name. It returns nil when they cannot be listed, in which
+// case every addition is treated as already installed so a rollback never
+// removes something the sync did not add.
+func installedPackages(ctx context.Context, mgr *installer.Manager) *brewfile.PackageSet {
+	installed, err := mgr.ListAll(ctx)
+	if err != nil {
+		printWarning("Could not list installed packages; a rollback will not uninstall additions: %v", err)
+		return nil
+	}
+	return brewfile.NewPackageSetFunc(brewfile.DefaultNormalizer.Key, installed...)
+}
+
+// splitManualRemovals separates packages whose installer cannot uninstall
+// them, so sync lists them for manual removal instead of pretending to
+// remove them
+func splitManualRemovals(mgr *installer.Manager, pkgs brewfile.Packages) (removable, manual brewfile.Packages) {
+	for _, pkg := range pkgs {
+		if mgr.Capabilities(pkg.Type).Uninstall {
+			removable = append(removable, pkg)
+		} else {
+			manual = append(manual, pkg)
+		}
+	}
+	return removable, manual
+}
+
+// filterChangesByCategories keeps changes whose package type is in categories
+func filterChangesByCategories(changes []brewfile.Change, categories []brewfile.PackageType) []brewfile.Change {
+	categorySet := make(map[brewfile.PackageType]bool)
+	for _, c := range categories {
+		categorySet[c] = true
+	}
+
+	var result []brewfile.Change
+	for _, change := range changes {
+		if categorySet[change.Source.Type] {
+			result = append(result, change)
+		}
+	}
+	return result
 }
 
 // groupByType groups packages by their type
@@ -320,3 +573,13 @@ func colorYellow(s string) string {
 	}
 	return "\033[33m" + s + "\033[0m"
 }
+
+// interruptedPackages returns the packages a batch operation never attempted
+// because it was interrupted
+func interruptedPackages(err error) brewfile.Packages {
+	var interrupted *installer.InterruptedError
+	if errors.As(err, &interrupted) {
+		return interrupted.Skipped
+	}
+	return nil
+}
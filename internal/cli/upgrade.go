@@ -0,0 +1,157 @@
+package cli
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/spf13/cobra"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
+	"github.com/andrew-sameh/brewsync/internal/config"
+	"github.com/andrew-sameh/brewsync/internal/history"
+	"github.com/andrew-sameh/brewsync/internal/installer"
+	"github.com/andrew-sameh/brewsync/internal/tui/selection"
+)
+
+var (
+	upgradeOnly string
+	upgradeSkip string
+)
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade",
+	Short: "Upgrade outdated packages",
+	Long: `Upgrade installed packages that have a newer version available.
+
+Outdated packages are found the same way as 'brewsync outdated' and offered
+for selection. Pinned formulae are listed but not selected; with --yes they
+are left alone. Upgrades are logged to history and, when auto_dump is
+enabled, the Brewfile is dumped afterwards.
+
+Examples:
+  brewsync upgrade                 # Select packages interactively
+  brewsync upgrade --only brew     # Filter categories
+  brewsync upgrade --skip vscode   # Exclude categories
+  brewsync upgrade --yes           # Upgrade everything without prompts
+  brewsync upgrade --dry-run       # Show what would be upgraded`,
+	RunE: runUpgrade,
+}
+
+func init() {
+	upgradeCmd.Flags().StringVar(&upgradeOnly, "only", "", "only upgrade these package types (comma-separated)")
+	upgradeCmd.Flags().StringVar(&upgradeSkip, "skip", "", "skip these package types (comma-separated)")
+
+	rootCmd.AddCommand(upgradeCmd)
+}
+
+func runUpgrade(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	currentMachine := cfg.CurrentMachine
+
+	ctx := commandContext(cmd)
+	mgr := installer.NewManager()
+	outdated, err := collectOutdated(ctx, mgr, upgradeOnly, upgradeSkip)
+	if err != nil {
+		return err
+	}
+	if len(outdated) == 0 {
+		printInfo("Everything is up to date")
+		return nil
+	}
+
+	var candidates brewfile.Packages
+	details := make(map[string]string)
+	preselected := make(map[string]bool)
+	for _, o := range outdated {
+		candidates = append(candidates, o.Package)
+		details[o.Package.ID()] = fmt.Sprintf("%s → %s", o.Installed, o.Latest)
+		if o.Pinned {
+			details[o.Package.ID()] += " (pinned)"
+			continue
+		}
+		preselected[o.Package.ID()] = true
+	}
+
+	if dryRun {
+		printOutdated(outdated)
+		printInfo("Dry-run mode - no packages upgraded")
+		return nil
+	}
+
+	var toUpgrade brewfile.Packages
+	if assumeYes {
+		for _, pkg := range candidates {
+			if preselected[pkg.ID()] {
+				toUpgrade = append(toUpgrade, pkg)
+			}
+		}
+	} else {
+		model := selection.New("Upgrade - Select packages to upgrade", candidates)
+		model.SetDetails(details)
+		model.SetSelected(preselected)
+
+		p := tea.NewProgram(model, tea.WithAltScreen())
+		finalModel, err := p.Run()
+		if err != nil {
+			return fmt.Errorf("TUI error: %w", err)
+		}
+
+		m := finalModel.(selection.Model)
+		if m.Cancelled() {
+			printInfo("Upgrade cancelled")
+			return nil
+		}
+		toUpgrade = m.Selected()
+	}
+
+	if len(toUpgrade) == 0 {
+		printInfo("No packages selected for upgrade")
+		return nil
+	}
+
+	// Upgrade one package at a time; an interrupt stops the running upgrade
+	// and skips the rest
+	printInfo("Upgrading %d packages...", len(toUpgrade))
+	report := &installReport{}
+	var upgraded []string
+	err = mgr.UpgradeMany(ctx, toUpgrade, func(pkg brewfile.Package, i, total int, err error) {
+		if err != nil {
+			kind := report.fail(pkg, err)
+			printError("[%d/%d] Failed to upgrade %s:%s (%s): %v", i, total, pkg.Type, pkg.Name, kind, err)
+			return
+		}
+		printInfo("[%d/%d] Upgraded %s:%s (%s)", i, total, pkg.Type, pkg.Name, details[pkg.ID()])
+		upgraded = append(upgraded, pkg.ID())
+	})
+	skipped := interruptedPackages(err)
+
+	if !quiet {
+		fmt.Println()
+	}
+	if len(skipped) > 0 {
+		printWarning("Upgrade interrupted: %d upgraded, %d failed, %d not attempted",
+			len(upgraded), len(report.failed), len(skipped))
+		for _, pkg := range skipped {
+			printVerbose("  not attempted: %s", pkg.ID())
+		}
+	} else {
+		printInfo("Upgrade complete: %d upgraded, %d failed", len(upgraded), len(report.failed))
+	}
+
+	report.skip(skipped)
+	history.LogUpgrade(currentMachine, upgraded, report.historyFailures(), len(skipped))
+	if ctx.Err() != nil {
+		return fmt.Errorf("upgrade interrupted")
+	}
+
+	// Upgrades change installed versions, so refresh the Brewfile and its
+	// lock file when auto-dump is enabled
+	if len(upgraded) > 0 && currentMachine != "" && cfg.AutoDump.Enabled && cfg.AutoDump.AfterInstall {
+		autoDump(cfg, currentMachine)
+	}
+
+	return nil
+}
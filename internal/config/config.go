@@ -7,6 +7,8 @@ import (
 
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
 )
 
 const (
@@ -118,6 +120,16 @@ func Load() (*Config, error) {
 	if err := viper.Unmarshal(cfg); err != nil {
 		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
 	}
+	for name, machine := range cfg.Machines {
+		if err := ValidateOS(machine.OS); err != nil {
+			cfg = nil
+			return nil, fmt.Errorf("machine %q: %w", name, err)
+		}
+	}
+	if err := cfg.Hooks.Validate(); err != nil {
+		cfg = nil
+		return nil, fmt.Errorf("hooks: %w", err)
+	}
 
 	// Detect current machine if set to "auto"
 	if cfg.CurrentMachine == "auto" || cfg.CurrentMachine == "" {
@@ -182,6 +194,24 @@ func HistoryPath() (string, error) {
 	return filepath.Join(dir, "history.log"), nil
 }
 
+// JournalPath returns the path to the journal of the last applied sync
+func JournalPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "journal.json"), nil
+}
+
+// NamesPath returns the path to the package name table refreshed from brew
+func NamesPath() (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "names.yaml"), nil
+}
+
 // Save writes the current config to disk
 func Save(c *Config) error {
 	path, err := ConfigPath()
@@ -202,10 +232,13 @@ func Save(c *Config) error {
 		DefaultCategories:  c.DefaultCategories,
 		AutoDump:           c.AutoDump,
 		Dump:               c.Dump,
+		GoTools:            c.GoTools,
 		MachineSpecific:    c.MachineSpecific,
 		ConflictResolution: c.ConflictResolution,
 		Output:             c.Output,
 		Hooks:              c.Hooks,
+		CustomTypes:        c.CustomTypes,
+		Editors:            c.Editors,
 	}
 
 	// Marshal to YAML
@@ -228,16 +261,43 @@ func Save(c *Config) error {
 	return nil
 }
 
+// LoadCustomTypes registers the custom package types declared under
+// custom_types in the config file
+func LoadCustomTypes() error {
+	var types map[string]brewfile.CustomType
+	if err := viper.UnmarshalKey("custom_types", &types); err != nil {
+		return fmt.Errorf("failed to parse custom_types: %w", err)
+	}
+	return RegisterCustomTypes(types)
+}
+
+// LoadEditors registers the editors declared under editors in the config file
+func LoadEditors() error {
+	var editors []brewfile.Editor
+	if err := viper.UnmarshalKey("editors", &editors); err != nil {
+		return fmt.Errorf("failed to parse editors: %w", err)
+	}
+	return RegisterEditors(editors)
+}
+
+// GoToolVersions returns the go_tools.versions setting
+func GoToolVersions() string {
+	return viper.GetString("go_tools.versions")
+}
+
 // saveableConfig is the config structure for YAML serialization (without internal fields)
 type saveableConfig struct {
-	Machines           map[string]Machine    `yaml:"machines"`
-	CurrentMachine     string                `yaml:"current_machine"`
-	DefaultSource      string                `yaml:"default_source"`
-	DefaultCategories  []string              `yaml:"default_categories"`
-	AutoDump           AutoDumpConfig        `yaml:"auto_dump"`
-	Dump               DumpConfig            `yaml:"dump"`
-	MachineSpecific    MachineSpecificConfig `yaml:"machine_specific,omitempty"`
-	ConflictResolution ConflictResolution    `yaml:"conflict_resolution"`
-	Output             OutputConfig          `yaml:"output"`
-	Hooks              HooksConfig           `yaml:"hooks,omitempty"`
+	Machines           map[string]Machine             `yaml:"machines"`
+	CurrentMachine     string                         `yaml:"current_machine"`
+	DefaultSource      string                         `yaml:"default_source"`
+	DefaultCategories  []string                       `yaml:"default_categories"`
+	AutoDump           AutoDumpConfig                 `yaml:"auto_dump"`
+	Dump               DumpConfig                     `yaml:"dump"`
+	GoTools            GoToolsConfig                  `yaml:"go_tools"`
+	MachineSpecific    MachineSpecificConfig          `yaml:"machine_specific,omitempty"`
+	ConflictResolution ConflictResolution             `yaml:"conflict_resolution"`
+	Output             OutputConfig                   `yaml:"output"`
+	Hooks              HooksConfig                    `yaml:"hooks,omitempty"`
+	CustomTypes        map[string]brewfile.CustomType `yaml:"custom_types,omitempty"`
+	Editors            []brewfile.Editor              `yaml:"editors,omitempty"`
 }
@@ -8,6 +8,8 @@ import (
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
 )
 
 func TestConfigPath(t *testing.T) {
@@ -85,6 +87,13 @@ func TestHistoryPath(t *testing.T) {
 	assert.Contains(t, path, ".config/brewsync")
 }
 
+func TestJournalPath(t *testing.T) {
+	path, err := JournalPath()
+	assert.NoError(t, err)
+	assert.Contains(t, path, "journal.json")
+	assert.Contains(t, path, ".config/brewsync")
+}
+
 func TestLoadWithConfigFile(t *testing.T) {
 	// Create temp config file
 	tmpDir := t.TempDir()
@@ -102,6 +111,24 @@ default_source: test
 default_categories:
   - brew
   - cask
+machine_specific:
+  test:
+    brew:
+      - postgresql
+    npm:
+      - typescript
+custom_types:
+  npm:
+    icon: "⬢"
+    list: npm ls -g --depth=0 --parseable
+    pattern: 'node_modules/(?P<name>.+)$'
+    install: npm install -g {name}
+    uninstall: npm uninstall -g {name}
+editors:
+  - name: VSCodium
+    command: codium
+    keyword: vscodium
+    extensions_dir: ~/.vscode-oss/extensions
 `
 	err := os.WriteFile(configFile, []byte(configContent), 0644)
 	require.NoError(t, err)
@@ -132,6 +159,20 @@ default_categories:
 	assert.Equal(t, "test", loadedCfg.CurrentMachine)
 	assert.Contains(t, loadedCfg.Machines, "test")
 	assert.Equal(t, "test-hostname", loadedCfg.Machines["test"].Hostname)
+
+	// Custom types and their machine-specific packages
+	require.Contains(t, loadedCfg.CustomTypes, "npm")
+	assert.Equal(t, "npm install -g {name}", loadedCfg.CustomTypes["npm"].Install)
+	assert.Equal(t, "⬢", loadedCfg.CustomTypes["npm"].Icon)
+	assert.Equal(t, []string{"brew:postgresql", "npm:typescript"}, loadedCfg.GetMachineSpecificPackages()["test"])
+
+	// Editors declared in config
+	require.Len(t, loadedCfg.Editors, 1)
+	assert.Equal(t, "codium", loadedCfg.Editors[0].Command)
+	assert.Equal(t, "~/.vscode-oss/extensions", loadedCfg.Editors[0].ExtensionsDir)
+	require.NoError(t, LoadEditors())
+	defer brewfile.UnregisterType("vscodium")
+	assert.True(t, brewfile.IsEditorType("vscodium"))
 }
 
 func TestLoadWithDefaults(t *testing.T) {
@@ -163,6 +204,7 @@ func TestLoadWithDefaults(t *testing.T) {
 	assert.Equal(t, DefaultCategories, loadedCfg.DefaultCategories)
 	assert.Equal(t, ConflictAsk, loadedCfg.ConflictResolution)
 	assert.True(t, loadedCfg.Output.Color)
+	assert.Equal(t, "latest", loadedCfg.GoTools.Versions)
 }
 
 func TestGet_ReturnsCachedConfig(t *testing.T) {
@@ -35,6 +35,9 @@ func setDefaults() {
 	// Dump settings
 	viper.SetDefault("dump.use_brew_bundle", true) // Use 'brew bundle dump --describe' by default
 
+	// Go tools track their latest release unless pinned
+	viper.SetDefault("go_tools.versions", "latest")
+
 	// Conflict resolution
 	viper.SetDefault("conflict_resolution", string(ConflictAsk))
 
@@ -296,6 +296,13 @@ func addPackageToList(list *PackageIgnoreList, pkgType, pkgName string) {
 		if !contains(list.Mas, pkgName) {
 			list.Mas = append(list.Mas, pkgName)
 		}
+	default:
+		if list.Custom == nil {
+			list.Custom = make(map[string][]string)
+		}
+		if !contains(list.Custom[pkgType], pkgName) {
+			list.Custom[pkgType] = append(list.Custom[pkgType], pkgName)
+		}
 	}
 }
 
@@ -317,5 +324,12 @@ func removePackageFromList(list *PackageIgnoreList, pkgType, pkgName string) {
 		list.Go = removeString(list.Go, pkgName)
 	case "mas":
 		list.Mas = removeString(list.Mas, pkgName)
+	default:
+		if _, ok := list.Custom[pkgType]; ok {
+			list.Custom[pkgType] = removeString(list.Custom[pkgType], pkgName)
+			if len(list.Custom[pkgType]) == 0 {
+				delete(list.Custom, pkgType)
+			}
+		}
 	}
 }
@@ -291,3 +291,26 @@ func TestAddCategoryIgnore_Duplicate(t *testing.T) {
 	}
 	assert.Equal(t, 1, count, "Should only have one entry")
 }
+
+func TestAddPackageIgnore_CustomType(t *testing.T) {
+	tmpDir := t.TempDir()
+	ignorePath := filepath.Join(tmpDir, "ignore.yaml")
+
+	SetIgnorePath(ignorePath)
+	defer func() { SetIgnorePath("") }()
+
+	require.NoError(t, AddPackageIgnore("", "npm:typescript", true))
+	require.NoError(t, AddPackageIgnore("", "npm:eslint", true))
+
+	loaded, err := LoadIgnoreFile()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"typescript", "eslint"}, loaded.Global.Packages.Custom["npm"])
+	assert.Equal(t, []string{"npm:typescript", "npm:eslint"}, loaded.Global.Packages.IDs())
+
+	require.NoError(t, RemovePackageIgnore("", "npm:typescript", true))
+	require.NoError(t, RemovePackageIgnore("", "npm:eslint", true))
+
+	loaded, err = LoadIgnoreFile()
+	require.NoError(t, err)
+	assert.NotContains(t, loaded.Global.Packages.Custom, "npm")
+}
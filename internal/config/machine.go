@@ -2,21 +2,77 @@ package config
 
 import (
 	"fmt"
-	"os/exec"
+	"os"
+	"runtime"
 	"strings"
+	"sync"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
+	"github.com/andrew-sameh/brewsync/internal/exec"
+)
+
+// Operating systems a machine can run, named as Go names them
+const (
+	OSDarwin = "darwin"
+	OSLinux  = "linux"
 )
 
-// GetLocalHostname returns the local hostname using scutil
+var (
+	localOS     string
+	localOSOnce sync.Once
+)
+
+// LocalOS returns the operating system of this machine. It asks uname, so
+// a simulated machine reports the system it was recorded on.
+func LocalOS() string {
+	localOSOnce.Do(func() {
+		localOS = runtime.GOOS
+		if out, err := exec.Run("uname", "-s"); err == nil {
+			if name := strings.ToLower(strings.TrimSpace(out)); name != "" {
+				localOS = name
+			}
+		}
+	})
+	return localOS
+}
+
+// ValidateOS checks that an os attribute names a supported system
+func ValidateOS(goos string) error {
+	switch goos {
+	case "", OSDarwin, OSLinux:
+		return nil
+	default:
+		return fmt.Errorf("unknown os %q (want %q or %q)", goos, OSDarwin, OSLinux)
+	}
+}
+
+// GetLocalHostname returns the local hostname: the LocalHostName from
+// scutil on macOS and the short host name elsewhere
 func GetLocalHostname() (string, error) {
-	cmd := exec.Command("scutil", "--get", "LocalHostName")
-	output, err := cmd.Output()
+	if LocalOS() != OSDarwin {
+		name := ""
+		if out, err := exec.Run("uname", "-n"); err == nil {
+			name = strings.TrimSpace(out)
+		}
+		if name == "" {
+			var err error
+			if name, err = os.Hostname(); err != nil {
+				return "", fmt.Errorf("failed to get hostname: %w", err)
+			}
+		}
+		name, _, _ = strings.Cut(name, ".")
+		return name, nil
+	}
+
+	output, err := exec.Run("scutil", "--get", "LocalHostName")
 	if err != nil {
 		return "", fmt.Errorf("failed to get hostname: %w", err)
 	}
-	return strings.TrimSpace(string(output)), nil
+	return strings.TrimSpace(output), nil
 }
 
-// DetectMachine attempts to detect the current machine based on hostname
+// DetectMachine attempts to detect the current machine based on hostname.
+// Machines declared for another operating system are not considered.
 func DetectMachine(machines map[string]Machine) (string, error) {
 	hostname, err := GetLocalHostname()
 	if err != nil {
@@ -24,10 +80,41 @@ func DetectMachine(machines map[string]Machine) (string, error) {
 	}
 
 	for name, machine := range machines {
-		if machine.Hostname == hostname {
+		if machine.Hostname == hostname && (machine.OS == "" || machine.OS == LocalOS()) {
 			return name, nil
 		}
 	}
 
 	return "", fmt.Errorf("no machine found matching hostname %q", hostname)
 }
+
+// MachineOS returns the operating system of a configured machine: its os
+// attribute, this machine's system for the current machine, or the system
+// recorded by the machine's last dump. It is empty when unknown.
+func (c *Config) MachineOS(name string) string {
+	machine, ok := c.Machines[name]
+	if !ok {
+		return ""
+	}
+	if machine.OS != "" {
+		return machine.OS
+	}
+	if name == c.CurrentMachine {
+		return LocalOS()
+	}
+	if facts, ok := brewfile.LoadSystemFacts(machine.Brewfile); ok {
+		return facts.OS
+	}
+	return ""
+}
+
+// UnsupportedTypes returns the package types that cannot be installed on at
+// least one of the named machines, e.g. casks and App Store apps when one of
+// them runs Linux
+func (c *Config) UnsupportedTypes(machines ...string) []brewfile.PackageType {
+	var systems []string
+	for _, name := range machines {
+		systems = append(systems, c.MachineOS(name))
+	}
+	return brewfile.UnsupportedTypes(systems...)
+}
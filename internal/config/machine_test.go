@@ -4,6 +4,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
 )
 
 func TestDetectMachine(t *testing.T) {
@@ -40,6 +42,21 @@ func TestDetectMachine(t *testing.T) {
 		assert.Error(t, err)
 	})
 
+	t.Run("machine for another os", func(t *testing.T) {
+		other := OSLinux
+		if LocalOS() == OSLinux {
+			other = OSDarwin
+		}
+		machines := map[string]Machine{
+			"elsewhere": {Hostname: actualHostname, OS: other},
+			"here":      {Hostname: actualHostname, OS: LocalOS()},
+		}
+
+		name, err := DetectMachine(machines)
+		assert.NoError(t, err)
+		assert.Equal(t, "here", name)
+	})
+
 	t.Run("multiple machines one match", func(t *testing.T) {
 		machines := map[string]Machine{
 			"other1": {Hostname: "hostname1"},
@@ -56,10 +73,36 @@ func TestDetectMachine(t *testing.T) {
 func TestGetLocalHostname(t *testing.T) {
 	hostname, err := GetLocalHostname()
 
-	// This should work on macOS
+	// This should work on macOS and Linux
 	assert.NoError(t, err)
 	assert.NotEmpty(t, hostname)
 
 	// Hostname shouldn't contain newlines
 	assert.NotContains(t, hostname, "\n")
 }
+
+func TestValidateOS(t *testing.T) {
+	assert.NoError(t, ValidateOS(""))
+	assert.NoError(t, ValidateOS(OSDarwin))
+	assert.NoError(t, ValidateOS(OSLinux))
+	assert.Error(t, ValidateOS("macos"))
+}
+
+func TestConfig_MachineOS(t *testing.T) {
+	cfg := &Config{
+		CurrentMachine: "here",
+		Machines: map[string]Machine{
+			"here":    {Hostname: "here"},
+			"devbox":  {Hostname: "devbox", OS: OSLinux},
+			"unknown": {Hostname: "unknown", Brewfile: "/nonexistent/Brewfile"},
+		},
+	}
+
+	assert.Equal(t, LocalOS(), cfg.MachineOS("here"))
+	assert.Equal(t, OSLinux, cfg.MachineOS("devbox"))
+	assert.Empty(t, cfg.MachineOS("unknown"))
+	assert.Empty(t, cfg.MachineOS("missing"))
+
+	assert.Empty(t, cfg.UnsupportedTypes("unknown"))
+	assert.ElementsMatch(t, []brewfile.PackageType{brewfile.TypeCask, brewfile.TypeMas}, cfg.UnsupportedTypes("unknown", "devbox"))
+}
@@ -1,10 +1,20 @@
 package config
 
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
+)
+
 // Machine represents a macOS machine configuration
 type Machine struct {
 	Hostname    string `yaml:"hostname" mapstructure:"hostname"`
 	Brewfile    string `yaml:"brewfile" mapstructure:"brewfile"`
 	Description string `yaml:"description,omitempty" mapstructure:"description"`
+	OS          string `yaml:"os,omitempty" mapstructure:"os"` // darwin or linux; detected when empty
 }
 
 // AutoDumpConfig configures automatic Brewfile updates
@@ -21,6 +31,11 @@ type DumpConfig struct {
 	UseBrewBundle bool `yaml:"use_brew_bundle" mapstructure:"use_brew_bundle"` // Use 'brew bundle dump --describe' for Homebrew packages
 }
 
+// GoToolsConfig configures how Go tools are versioned
+type GoToolsConfig struct {
+	Versions string `yaml:"versions" mapstructure:"versions"` // "latest" installs module@latest, "pin" dumps and installs recorded versions
+}
+
 // PackageIgnoreList holds ignored packages by type
 type PackageIgnoreList struct {
 	Tap         []string `yaml:"tap,omitempty" mapstructure:"tap"`
@@ -31,6 +46,8 @@ type PackageIgnoreList struct {
 	Antigravity []string `yaml:"antigravity,omitempty" mapstructure:"antigravity"`
 	Go          []string `yaml:"go,omitempty" mapstructure:"go"`
 	Mas         []string `yaml:"mas,omitempty" mapstructure:"mas"`
+	// Custom holds the lists of custom package types, keyed by type name
+	Custom map[string][]string `yaml:",inline" mapstructure:",remain"`
 }
 
 // IgnoreConfig holds category and package-level ignores
@@ -55,12 +72,73 @@ type OutputConfig struct {
 	ShowDescriptions bool `yaml:"show_descriptions" mapstructure:"show_descriptions"`
 }
 
+// Hook names, as used in hooks.timeouts
+const (
+	HookPreInstall  = "pre_install"
+	HookPostInstall = "post_install"
+	HookPreDump     = "pre_dump"
+	HookPostDump    = "post_dump"
+)
+
+// DefaultHookTimeout is how long a hook may run unless configured otherwise
+const DefaultHookTimeout = 5 * time.Minute
+
 // HooksConfig holds shell commands to run at various points
 type HooksConfig struct {
 	PreInstall  string `yaml:"pre_install,omitempty" mapstructure:"pre_install"`
 	PostInstall string `yaml:"post_install,omitempty" mapstructure:"post_install"`
 	PreDump     string `yaml:"pre_dump,omitempty" mapstructure:"pre_dump"`
 	PostDump    string `yaml:"post_dump,omitempty" mapstructure:"post_dump"`
+	// Timeouts limits how long each hook may run, keyed by hook name, e.g.
+	// pre_install: 30s; "0" disables the limit
+	Timeouts map[string]string `yaml:"timeouts,omitempty" mapstructure:"timeouts"`
+}
+
+// Command returns the shell command configured for a hook, e.g. pre_install
+func (h HooksConfig) Command(hook string) string {
+	switch hook {
+	case HookPreInstall:
+		return h.PreInstall
+	case HookPostInstall:
+		return h.PostInstall
+	case HookPreDump:
+		return h.PreDump
+	case HookPostDump:
+		return h.PostDump
+	default:
+		return ""
+	}
+}
+
+// Timeout returns how long a hook may run; zero means no limit
+func (h HooksConfig) Timeout(hook string) (time.Duration, error) {
+	value, ok := h.Timeouts[hook]
+	if !ok || value == "" {
+		return DefaultHookTimeout, nil
+	}
+	if value == "0" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid timeout %q for hook %s", value, hook)
+	}
+	return d, nil
+}
+
+// Validate checks that every timeout names a known hook and parses
+func (h HooksConfig) Validate() error {
+	for hook := range h.Timeouts {
+		switch hook {
+		case HookPreInstall, HookPostInstall, HookPreDump, HookPostDump:
+		default:
+			return fmt.Errorf("timeout set for unknown hook %q", hook)
+		}
+		if _, err := h.Timeout(hook); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // ConflictResolution defines how to handle conflicting ignore lists
@@ -81,10 +159,16 @@ type Config struct {
 	DefaultCategories  []string              `yaml:"default_categories" mapstructure:"default_categories"`
 	AutoDump           AutoDumpConfig        `yaml:"auto_dump" mapstructure:"auto_dump"`
 	Dump               DumpConfig            `yaml:"dump" mapstructure:"dump"`
+	GoTools            GoToolsConfig         `yaml:"go_tools" mapstructure:"go_tools"`
 	MachineSpecific    MachineSpecificConfig `yaml:"machine_specific" mapstructure:"machine_specific"`
 	ConflictResolution ConflictResolution    `yaml:"conflict_resolution" mapstructure:"conflict_resolution"`
 	Output             OutputConfig          `yaml:"output" mapstructure:"output"`
 	Hooks              HooksConfig           `yaml:"hooks" mapstructure:"hooks"`
+	// CustomTypes declares package types managed by command templates, keyed by type name
+	CustomTypes map[string]brewfile.CustomType `yaml:"custom_types,omitempty" mapstructure:"custom_types"`
+	// Editors declares VS Code-family editors whose extensions are synced
+	// like the built-in vscode, cursor and antigravity types
+	Editors []brewfile.Editor `yaml:"editors,omitempty" mapstructure:"editors"`
 
 	// Loaded separately from ignore.yaml (not in YAML)
 	ignoreFile *IgnoreFile
@@ -142,25 +226,11 @@ func (c *Config) GetIgnoredPackages(machine string) []string {
 	var result []string
 
 	// Add global ignored packages
-	result = append(result, addPrefix("tap", c.ignoreFile.Global.Packages.Tap)...)
-	result = append(result, addPrefix("brew", c.ignoreFile.Global.Packages.Brew)...)
-	result = append(result, addPrefix("cask", c.ignoreFile.Global.Packages.Cask)...)
-	result = append(result, addPrefix("vscode", c.ignoreFile.Global.Packages.VSCode)...)
-	result = append(result, addPrefix("cursor", c.ignoreFile.Global.Packages.Cursor)...)
-	result = append(result, addPrefix("antigravity", c.ignoreFile.Global.Packages.Antigravity)...)
-	result = append(result, addPrefix("go", c.ignoreFile.Global.Packages.Go)...)
-	result = append(result, addPrefix("mas", c.ignoreFile.Global.Packages.Mas)...)
+	result = append(result, c.ignoreFile.Global.Packages.IDs()...)
 
 	// Add machine-specific ignored packages
 	if machineIgnore, ok := c.ignoreFile.Machines[machine]; ok {
-		result = append(result, addPrefix("tap", machineIgnore.Packages.Tap)...)
-		result = append(result, addPrefix("brew", machineIgnore.Packages.Brew)...)
-		result = append(result, addPrefix("cask", machineIgnore.Packages.Cask)...)
-		result = append(result, addPrefix("vscode", machineIgnore.Packages.VSCode)...)
-		result = append(result, addPrefix("cursor", machineIgnore.Packages.Cursor)...)
-		result = append(result, addPrefix("antigravity", machineIgnore.Packages.Antigravity)...)
-		result = append(result, addPrefix("go", machineIgnore.Packages.Go)...)
-		result = append(result, addPrefix("mas", machineIgnore.Packages.Mas)...)
+		result = append(result, machineIgnore.Packages.IDs()...)
 	}
 
 	return result
@@ -172,16 +242,7 @@ func (c *Config) GetMachineSpecificPackages() map[string][]string {
 	result := make(map[string][]string)
 
 	for machine, pkgs := range c.MachineSpecific {
-		var ids []string
-		ids = append(ids, addPrefix("tap", pkgs.Tap)...)
-		ids = append(ids, addPrefix("brew", pkgs.Brew)...)
-		ids = append(ids, addPrefix("cask", pkgs.Cask)...)
-		ids = append(ids, addPrefix("vscode", pkgs.VSCode)...)
-		ids = append(ids, addPrefix("cursor", pkgs.Cursor)...)
-		ids = append(ids, addPrefix("antigravity", pkgs.Antigravity)...)
-		ids = append(ids, addPrefix("go", pkgs.Go)...)
-		ids = append(ids, addPrefix("mas", pkgs.Mas)...)
-		result[machine] = ids
+		result[machine] = pkgs.IDs()
 	}
 
 	return result
@@ -224,6 +285,62 @@ func (c *Config) IsPackageIgnored(machine, pkgID string) bool {
 	return false
 }
 
+// IDs returns the listed packages as IDs in format "type:name", built-in
+// types first and custom types sorted by name
+func (l PackageIgnoreList) IDs() []string {
+	var ids []string
+	ids = append(ids, addPrefix("tap", l.Tap)...)
+	ids = append(ids, addPrefix("brew", l.Brew)...)
+	ids = append(ids, addPrefix("cask", l.Cask)...)
+	ids = append(ids, addPrefix("vscode", l.VSCode)...)
+	ids = append(ids, addPrefix("cursor", l.Cursor)...)
+	ids = append(ids, addPrefix("antigravity", l.Antigravity)...)
+	ids = append(ids, addPrefix("go", l.Go)...)
+	ids = append(ids, addPrefix("mas", l.Mas)...)
+	types := make([]string, 0, len(l.Custom))
+	for t := range l.Custom {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	for _, t := range types {
+		ids = append(ids, addPrefix(t, l.Custom[t])...)
+	}
+	return ids
+}
+
+// RegisterCustomTypes registers custom package type definitions keyed by
+// name with the brewfile package, so Brewfiles, diffs and installers treat
+// them like built-in types
+func RegisterCustomTypes(types map[string]brewfile.CustomType) error {
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		def := types[name]
+		def.Name = name
+		if err := brewfile.RegisterType(def); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RegisterEditors registers editor declarations with the brewfile package,
+// so their extensions become package types
+func RegisterEditors(editors []brewfile.Editor) error {
+	var errs []error
+	for _, editor := range editors {
+		if err := brewfile.RegisterEditor(editor); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
 // addPrefix adds a type prefix to each package name
 func addPrefix(pkgType string, names []string) []string {
 	result := make([]string, len(names))
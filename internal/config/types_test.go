@@ -2,8 +2,10 @@ package config
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestConfig_GetMachine(t *testing.T) {
@@ -142,4 +144,47 @@ func TestHooksConfig(t *testing.T) {
 	assert.Equal(t, "echo post-install", hooks.PostInstall)
 	assert.Equal(t, "echo pre-dump", hooks.PreDump)
 	assert.Equal(t, "echo post-dump", hooks.PostDump)
+	assert.Equal(t, "echo pre-install", hooks.Command(HookPreInstall))
+	assert.Equal(t, "echo post-dump", hooks.Command(HookPostDump))
+	assert.Empty(t, hooks.Command("unknown"))
+}
+
+func TestHooksConfig_Timeout(t *testing.T) {
+	hooks := HooksConfig{Timeouts: map[string]string{
+		HookPreInstall: "30s",
+		HookPostDump:   "0",
+	}}
+
+	tests := []struct {
+		hook string
+		want time.Duration
+	}{
+		{HookPreInstall, 30 * time.Second},
+		{HookPostDump, 0},
+		{HookPreDump, DefaultHookTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.hook, func(t *testing.T) {
+			got, err := hooks.Timeout(tt.hook)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+	assert.NoError(t, hooks.Validate())
+}
+
+func TestHooksConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		timeouts map[string]string
+	}{
+		{"unknown hook", map[string]string{"pre_sync": "1m"}},
+		{"bad duration", map[string]string{HookPreInstall: "soon"}},
+		{"negative duration", map[string]string{HookPreInstall: "-1s"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Error(t, HooksConfig{Timeouts: tt.timeouts}.Validate())
+		})
+	}
 }
@@ -0,0 +1,154 @@
+package exec
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FakeRunner replays recorded commands instead of running them. Each
+// command line answers with its recorded entries in order and keeps
+// repeating the last one, so a transcript recorded once can drive a whole
+// session.
+type FakeRunner struct {
+	// Strict fails commands missing from the transcript instead of letting
+	// them succeed with no output
+	Strict bool
+	// LatencyScale multiplies recorded latencies; zero replays instantly
+	LatencyScale float64
+
+	mu      sync.Mutex
+	runs    map[string][]Entry
+	next    map[string]int
+	lookups map[string]bool
+}
+
+// NewFakeRunner creates a runner that replays the given entries
+func NewFakeRunner(entries []Entry) *FakeRunner {
+	f := &FakeRunner{
+		runs:    make(map[string][]Entry),
+		next:    make(map[string]int),
+		lookups: make(map[string]bool),
+	}
+	for _, entry := range entries {
+		if entry.Lookup {
+			f.lookups[entry.Command] = !entry.Missing
+			continue
+		}
+		f.runs[entry.key()] = append(f.runs[entry.key()], entry)
+		if _, ok := f.lookups[entry.Command]; !ok {
+			f.lookups[entry.Command] = !entry.Missing
+		}
+	}
+	return f
+}
+
+// LoadFakeRunner creates a runner that replays the transcript in dir
+func LoadFakeRunner(dir string) (*FakeRunner, error) {
+	entries, err := LoadTranscript(dir)
+	if err != nil {
+		return nil, err
+	}
+	return NewFakeRunner(entries), nil
+}
+
+// entry returns the next recorded entry for a command line
+func (f *FakeRunner) entry(name string, args []string) (Entry, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := commandKey(name, args)
+	recorded := f.runs[key]
+	if len(recorded) == 0 {
+		return Entry{}, false
+	}
+	i := f.next[key]
+	if i < len(recorded)-1 {
+		f.next[key] = i + 1
+	}
+	return recorded[i], true
+}
+
+// replay waits out an entry's latency and returns its result
+func (f *FakeRunner) replay(ctx context.Context, name string, args []string) (Entry, error) {
+	entry, ok := f.entry(name, args)
+	if !ok {
+		if f.Strict {
+			return Entry{}, fmt.Errorf("%s: not in transcript", strings.Join(append([]string{name}, args...), " "))
+		}
+		return Entry{Command: name, Args: args}, nil
+	}
+
+	if wait := time.Duration(float64(entry.Latency()) * f.LatencyScale); wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return Entry{}, fmt.Errorf("%s stopped: %w", name, ctx.Err())
+		case <-timer.C:
+		}
+	}
+
+	if entry.Missing {
+		return entry, fmt.Errorf("exec: %q: executable file not found in $PATH", name)
+	}
+	if entry.ExitCode != 0 {
+		return entry, withStderr(&ExitError{Code: entry.ExitCode}, entry.Stderr)
+	}
+	return entry, nil
+}
+
+// Run replays a command
+func (f *FakeRunner) Run(name string, args ...string) (string, error) {
+	return f.RunContext(context.Background(), name, args...)
+}
+
+// RunContext replays a command; cancelling the context cuts its latency short
+func (f *FakeRunner) RunContext(ctx context.Context, name string, args ...string) (string, error) {
+	entry, err := f.replay(ctx, name, args)
+	if err != nil {
+		return "", err
+	}
+	return entry.Stdout, nil
+}
+
+// RunLines replays a command and returns its output as lines
+func (f *FakeRunner) RunLines(name string, args ...string) ([]string, error) {
+	return f.RunLinesContext(context.Background(), name, args...)
+}
+
+// RunLinesContext replays a command with context and returns its output as lines
+func (f *FakeRunner) RunLinesContext(ctx context.Context, name string, args ...string) ([]string, error) {
+	return splitLines(f.RunContext(ctx, name, args...))
+}
+
+// RunWithOutput replays a command and streams its output to a callback
+func (f *FakeRunner) RunWithOutput(name string, args []string, onOutput func(line string)) error {
+	return f.RunWithOutputContext(context.Background(), name, args, onOutput)
+}
+
+// RunWithOutputContext replays a command with context, streaming its
+// recorded stdout and then its stderr
+func (f *FakeRunner) RunWithOutputContext(ctx context.Context, name string, args []string, onOutput func(line string)) error {
+	entry, err := f.replay(ctx, name, args)
+	if onOutput != nil {
+		for _, output := range []string{entry.Stdout, entry.Stderr} {
+			if output = strings.TrimRight(output, "\n"); output == "" {
+				continue
+			}
+			for _, line := range strings.Split(output, "\n") {
+				onOutput(line)
+			}
+		}
+	}
+	return err
+}
+
+// Exists reports whether the command was found on the recorded machine
+func (f *FakeRunner) Exists(name string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.lookups[name]
+}
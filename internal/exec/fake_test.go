@@ -0,0 +1,121 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFakeRunner_Run(t *testing.T) {
+	runner := NewFakeRunner([]Entry{
+		{Command: "brew", Args: []string{"leaves"}, Stdout: "git\nwget\n"},
+		{Command: "brew", Args: []string{"list", "--cask"}, Stdout: "first\n"},
+		{Command: "brew", Args: []string{"list", "--cask"}, Stdout: "second\n"},
+		{Command: "mas", Args: []string{"list"}, Stderr: "Error: not signed in", ExitCode: 1},
+	})
+
+	t.Run("recorded output", func(t *testing.T) {
+		lines, err := runner.RunLines("brew", "leaves")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"git", "wget"}, lines)
+	})
+
+	t.Run("entries replay in order and the last repeats", func(t *testing.T) {
+		for _, want := range []string{"first\n", "second\n", "second\n"} {
+			output, err := runner.Run("brew", "list", "--cask")
+			require.NoError(t, err)
+			assert.Equal(t, want, output)
+		}
+	})
+
+	t.Run("recorded failure", func(t *testing.T) {
+		_, err := runner.Run("mas", "list")
+		require.Error(t, err)
+		assert.Equal(t, "exit status 1: Error: not signed in", err.Error())
+		var exitErr *ExitError
+		require.True(t, errors.As(err, &exitErr))
+		assert.Equal(t, 1, exitErr.Code)
+	})
+
+	t.Run("unrecorded command succeeds", func(t *testing.T) {
+		output, err := runner.Run("brew", "install", "jq")
+		assert.NoError(t, err)
+		assert.Empty(t, output)
+	})
+
+	t.Run("unrecorded command fails when strict", func(t *testing.T) {
+		strict := NewFakeRunner(nil)
+		strict.Strict = true
+		_, err := strict.Run("brew", "install", "jq")
+		assert.EqualError(t, err, "brew install jq: not in transcript")
+	})
+}
+
+func TestFakeRunner_Exists(t *testing.T) {
+	runner := NewFakeRunner([]Entry{
+		{Command: "brew", Args: []string{"leaves"}},
+		{Command: "code", Lookup: true},
+		{Command: "mas", Lookup: true, Missing: true},
+	})
+
+	assert.True(t, runner.Exists("brew"))
+	assert.True(t, runner.Exists("code"))
+	assert.False(t, runner.Exists("mas"))
+	assert.False(t, runner.Exists("cursor"))
+}
+
+func TestFakeRunner_RunWithOutput(t *testing.T) {
+	runner := NewFakeRunner([]Entry{
+		{Command: "brew", Args: []string{"install", "jq"}, Stdout: "==> Fetching jq\n==> Pouring jq\n", Stderr: "Warning: slow\n"},
+	})
+
+	var lines []string
+	err := runner.RunWithOutput("brew", []string{"install", "jq"}, func(line string) {
+		lines = append(lines, line)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"==> Fetching jq", "==> Pouring jq", "Warning: slow"}, lines)
+}
+
+func TestFakeRunner_Latency(t *testing.T) {
+	entries := []Entry{{Command: "brew", Args: []string{"update"}, LatencyMS: 5000}}
+
+	t.Run("replays instantly by default", func(t *testing.T) {
+		start := time.Now()
+		_, err := NewFakeRunner(entries).Run("brew", "update")
+		require.NoError(t, err)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("cancelled while waiting", func(t *testing.T) {
+		runner := NewFakeRunner(entries)
+		runner.LatencyScale = 1
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err := runner.RunContext(ctx, "brew", "update")
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestTranscript_SaveAndLoad(t *testing.T) {
+	dir := t.TempDir()
+	entries := []Entry{
+		{Command: "brew", Args: []string{"leaves"}, Stdout: "git\n", LatencyMS: 120},
+		{Command: "mas", Lookup: true, Missing: true},
+	}
+
+	require.NoError(t, SaveTranscript(dir, entries[:1]))
+	require.NoError(t, SaveTranscript(dir, entries[1:]))
+
+	loaded, err := LoadTranscript(dir)
+	require.NoError(t, err)
+	assert.Equal(t, entries, loaded)
+
+	_, err = LoadTranscript(t.TempDir())
+	assert.Error(t, err)
+}
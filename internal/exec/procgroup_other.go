@@ -0,0 +1,9 @@
+//go:build !unix
+
+package exec
+
+import "os/exec"
+
+// setProcessGroup is a no-op where process groups are not supported; the
+// command itself is killed on cancellation
+func setProcessGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,118 @@
+package exec
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Recorder runs commands on the local machine and records each one, so
+// the session can be replayed later with a FakeRunner
+type Recorder struct {
+	runner *CommandRunner
+
+	mu      sync.Mutex
+	entries []Entry
+	looked  map[string]bool
+}
+
+// NewRecorder creates a recorder that runs commands with runner
+func NewRecorder(runner *CommandRunner) *Recorder {
+	return &Recorder{runner: runner, looked: make(map[string]bool)}
+}
+
+// Entries returns the commands recorded so far
+func (r *Recorder) Entries() []Entry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Entry(nil), r.entries...)
+}
+
+// Save appends the recorded commands to the transcript in dir
+func (r *Recorder) Save(dir string) error {
+	return SaveTranscript(dir, r.Entries())
+}
+
+// record adds a finished command to the transcript. Commands stopped by
+// their context are left out since their result says nothing about the
+// machine.
+func (r *Recorder) record(ctx context.Context, name string, args []string, stdout, stderr string, err error, start time.Time) {
+	if err != nil && (ctx.Err() != nil || errors.Is(err, context.DeadlineExceeded)) {
+		return
+	}
+	entry := Entry{
+		Command:   name,
+		Args:      append([]string(nil), args...),
+		Stdout:    stdout,
+		Stderr:    stderr,
+		LatencyMS: time.Since(start).Milliseconds(),
+	}
+	if err != nil {
+		entry.ExitCode = exitCode(err)
+		entry.Missing = entry.ExitCode == 127 && !r.runner.Exists(name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = append(r.entries, entry)
+}
+
+// Run runs and records a command
+func (r *Recorder) Run(name string, args ...string) (string, error) {
+	return r.RunContext(context.Background(), name, args...)
+}
+
+// RunContext runs and records a command with the given context
+func (r *Recorder) RunContext(ctx context.Context, name string, args ...string) (string, error) {
+	start := time.Now()
+	stdout, stderr, err := r.runner.run(ctx, name, args...)
+	r.record(ctx, name, args, stdout, stderr, err, start)
+	return stdout, err
+}
+
+// RunLines runs and records a command and returns its output as lines
+func (r *Recorder) RunLines(name string, args ...string) ([]string, error) {
+	return r.RunLinesContext(context.Background(), name, args...)
+}
+
+// RunLinesContext runs and records a command with context and returns its
+// output as lines
+func (r *Recorder) RunLinesContext(ctx context.Context, name string, args ...string) ([]string, error) {
+	return splitLines(r.RunContext(ctx, name, args...))
+}
+
+// RunWithOutput runs and records a command, streaming its output to a callback
+func (r *Recorder) RunWithOutput(name string, args []string, onOutput func(line string)) error {
+	return r.RunWithOutputContext(context.Background(), name, args, onOutput)
+}
+
+// RunWithOutputContext runs and records a command with context, streaming
+// its output to a callback. Streamed stdout and stderr are interleaved, so
+// both are recorded as stdout.
+func (r *Recorder) RunWithOutputContext(ctx context.Context, name string, args []string, onOutput func(line string)) error {
+	var output strings.Builder
+	start := time.Now()
+	err := r.runner.RunWithOutputContext(ctx, name, args, func(line string) {
+		output.WriteString(line + "\n")
+		if onOutput != nil {
+			onOutput(line)
+		}
+	})
+	r.record(ctx, name, args, output.String(), "", err, start)
+	return err
+}
+
+// Exists checks if a command exists in PATH and records the answer once
+func (r *Recorder) Exists(name string) bool {
+	found := r.runner.Exists(name)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.looked[name] {
+		r.looked[name] = true
+		r.entries = append(r.entries, Entry{Command: name, Lookup: true, Missing: !found})
+	}
+	return found
+}
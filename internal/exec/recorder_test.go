@@ -0,0 +1,58 @@
+package exec
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	recorder := NewRecorder(NewRunner())
+
+	output, err := recorder.Run("echo", "hello")
+	require.NoError(t, err)
+	assert.Equal(t, "hello\n", output)
+
+	_, err = recorder.Run("sh", "-c", "echo oops >&2; exit 3")
+	require.Error(t, err)
+
+	var streamed []string
+	err = recorder.RunWithOutput("echo", []string{"streamed"}, func(line string) {
+		streamed = append(streamed, line)
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"streamed"}, streamed)
+
+	assert.True(t, recorder.Exists("sh"))
+	assert.False(t, recorder.Exists("nonexistent-command-12345"))
+	assert.True(t, recorder.Exists("sh"))
+
+	entries := recorder.Entries()
+	require.Len(t, entries, 5)
+	assert.Equal(t, "hello\n", entries[0].Stdout)
+	assert.Equal(t, 3, entries[1].ExitCode)
+	assert.Equal(t, "oops\n", entries[1].Stderr)
+	assert.Equal(t, "streamed\n", entries[2].Stdout)
+	assert.True(t, entries[3].Lookup)
+	assert.False(t, entries[3].Missing)
+	assert.True(t, entries[4].Missing)
+
+	t.Run("replays what it recorded", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, recorder.Save(dir))
+
+		fake, err := LoadFakeRunner(dir)
+		require.NoError(t, err)
+
+		output, err := fake.Run("echo", "hello")
+		require.NoError(t, err)
+		assert.Equal(t, "hello\n", output)
+
+		_, err = fake.Run("sh", "-c", "echo oops >&2; exit 3")
+		assert.EqualError(t, err, "exit status 3: oops")
+
+		assert.True(t, fake.Exists("sh"))
+		assert.False(t, fake.Exists("nonexistent-command-12345"))
+	})
+}
@@ -8,36 +8,57 @@ import (
 	"io"
 	"os/exec"
 	"strings"
+	"sync"
 	"time"
 )
 
 // DefaultTimeout is the default command timeout
 const DefaultTimeout = 5 * time.Minute
 
-// Runner executes shell commands
-type Runner struct {
+// Runner runs external commands. CommandRunner runs them for real,
+// FakeRunner replays recorded transcripts and Recorder captures them.
+type Runner interface {
+	Run(name string, args ...string) (string, error)
+	RunContext(ctx context.Context, name string, args ...string) (string, error)
+	RunLines(name string, args ...string) ([]string, error)
+	RunLinesContext(ctx context.Context, name string, args ...string) ([]string, error)
+	RunWithOutput(name string, args []string, onOutput func(line string)) error
+	RunWithOutputContext(ctx context.Context, name string, args []string, onOutput func(line string)) error
+	Exists(name string) bool
+}
+
+// CommandRunner executes commands on the local machine
+type CommandRunner struct {
 	Timeout time.Duration
 	Verbose bool
 }
 
 // NewRunner creates a new command runner
-func NewRunner() *Runner {
-	return &Runner{
+func NewRunner() *CommandRunner {
+	return &CommandRunner{
 		Timeout: DefaultTimeout,
 	}
 }
 
 // Run executes a command and returns its output
-func (r *Runner) Run(name string, args ...string) (string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), r.Timeout)
-	defer cancel()
+func (r *CommandRunner) Run(name string, args ...string) (string, error) {
+	return r.RunContext(context.Background(), name, args...)
+}
 
-	return r.RunContext(ctx, name, args...)
+// RunContext executes a command with the given context. The runner's timeout
+// still applies. Cancelling the context stops the command's whole process
+// group and returns an error wrapping the context's error.
+func (r *CommandRunner) RunContext(ctx context.Context, name string, args ...string) (string, error) {
+	stdout, _, err := r.run(ctx, name, args...)
+	return stdout, err
 }
 
-// RunContext executes a command with the given context
-func (r *Runner) RunContext(ctx context.Context, name string, args ...string) (string, error) {
-	cmd := exec.CommandContext(ctx, name, args...)
+// run executes a command and returns its stdout and stderr
+func (r *CommandRunner) run(ctx context.Context, name string, args ...string) (string, string, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	cmd := command(ctx, name, args...)
 
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
@@ -45,20 +66,58 @@ func (r *Runner) RunContext(ctx context.Context, name string, args ...string) (s
 
 	err := cmd.Run()
 	if err != nil {
-		// Include stderr in error message for debugging
-		errMsg := stderr.String()
-		if errMsg != "" {
-			return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(errMsg))
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return "", stderr.String(), fmt.Errorf("%s stopped: %w", name, ctxErr)
 		}
-		return "", err
+		return "", stderr.String(), withStderr(err, stderr.String())
 	}
 
-	return stdout.String(), nil
+	return stdout.String(), stderr.String(), nil
 }
 
+// withStderr includes stderr in a command's error message for debugging
+func withStderr(err error, stderr string) error {
+	if msg := strings.TrimSpace(stderr); msg != "" {
+		return fmt.Errorf("%w: %s", err, msg)
+	}
+	return err
+}
+
+// withTimeout applies the runner's timeout to a context
+func (r *CommandRunner) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.Timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, r.Timeout)
+}
+
+// command creates a command that runs in its own process group, so that
+// cancelling ctx also stops the processes it started (brew spawns curl, git
+// and ruby). Processes still running killGrace after the interrupt are killed.
+func command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	setProcessGroup(cmd)
+	cmd.WaitDelay = killGrace + time.Second
+	return cmd
+}
+
+// killGrace is how long a cancelled process group has to exit after being
+// interrupted before it is killed
+const killGrace = 3 * time.Second
+
 // RunLines executes a command and returns output as lines
-func (r *Runner) RunLines(name string, args ...string) ([]string, error) {
-	output, err := r.Run(name, args...)
+func (r *CommandRunner) RunLines(name string, args ...string) ([]string, error) {
+	return r.RunLinesContext(context.Background(), name, args...)
+}
+
+// RunLinesContext executes a command with the given context and returns
+// output as lines
+func (r *CommandRunner) RunLinesContext(ctx context.Context, name string, args ...string) ([]string, error) {
+	return splitLines(r.RunContext(ctx, name, args...))
+}
+
+// splitLines splits command output into lines, dropping surrounding blank space
+func splitLines(output string, err error) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
@@ -72,18 +131,20 @@ func (r *Runner) RunLines(name string, args ...string) ([]string, error) {
 }
 
 // Exists checks if a command exists in PATH
-func (r *Runner) Exists(name string) bool {
+func (r *CommandRunner) Exists(name string) bool {
 	_, err := exec.LookPath(name)
 	return err == nil
 }
 
 // Which returns the path to a command
-func (r *Runner) Which(name string) (string, error) {
+func (r *CommandRunner) Which(name string) (string, error) {
 	return exec.LookPath(name)
 }
 
-// Default is the default runner instance
-var Default = NewRunner()
+// Default is the runner used by installers and the package-level helpers.
+// It is replaced before any installer is created to simulate or record a
+// machine.
+var Default Runner = NewRunner()
 
 // Run executes a command using the default runner
 func Run(name string, args ...string) (string, error) {
@@ -101,16 +162,17 @@ func Exists(name string) bool {
 }
 
 // RunWithOutput executes a command and streams output to a callback
-func (r *Runner) RunWithOutput(name string, args []string, onOutput func(line string)) error {
-	ctx, cancel := context.WithTimeout(context.Background(), r.Timeout)
-	defer cancel()
-
-	return r.RunWithOutputContext(ctx, name, args, onOutput)
+func (r *CommandRunner) RunWithOutput(name string, args []string, onOutput func(line string)) error {
+	return r.RunWithOutputContext(context.Background(), name, args, onOutput)
 }
 
-// RunWithOutputContext executes a command with context and streams output
-func (r *Runner) RunWithOutputContext(ctx context.Context, name string, args []string, onOutput func(line string)) error {
-	cmd := exec.CommandContext(ctx, name, args...)
+// RunWithOutputContext executes a command with context and streams output.
+// Like RunContext, a failure's error includes the end of stderr.
+func (r *CommandRunner) RunWithOutputContext(ctx context.Context, name string, args []string, onOutput func(line string)) error {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	cmd := command(ctx, name, args...)
 
 	// Create pipes for stdout and stderr
 	stdout, err := cmd.StdoutPipe()
@@ -128,17 +190,61 @@ func (r *Runner) RunWithOutputContext(ctx context.Context, name string, args []s
 		return err
 	}
 
-	// Stream both stdout and stderr to the callback
+	// Stream both stdout and stderr to the callback, one line at a time
+	if onOutput != nil {
+		var mu sync.Mutex
+		callback := onOutput
+		onOutput = func(line string) {
+			mu.Lock()
+			defer mu.Unlock()
+			callback(line)
+		}
+	}
+	// Keep the end of stderr for the error, as run does
+	var tail stderrTail
+	onStderr := func(line string) {
+		tail.add(line)
+		if onOutput != nil {
+			onOutput(line)
+		}
+	}
 	done := make(chan error, 2)
 	go streamLines(stdout, onOutput, done)
-	go streamLines(stderr, onOutput, done)
+	go streamLines(stderr, onStderr, done)
 
 	// Wait for streaming to complete
 	<-done
 	<-done
 
 	// Wait for command to finish
-	return cmd.Wait()
+	if err := cmd.Wait(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return fmt.Errorf("%s stopped: %w", name, ctxErr)
+		}
+		return withStderr(err, tail.String())
+	}
+	return nil
+}
+
+// maxStderrTail is how many lines of stderr a streamed failure keeps
+const maxStderrTail = 20
+
+// stderrTail keeps the last lines of a streamed command's stderr
+type stderrTail struct {
+	lines []string
+}
+
+// add appends a line, dropping the oldest beyond maxStderrTail
+func (t *stderrTail) add(line string) {
+	t.lines = append(t.lines, line)
+	if len(t.lines) > maxStderrTail {
+		t.lines = t.lines[len(t.lines)-maxStderrTail:]
+	}
+}
+
+// String returns the kept lines
+func (t *stderrTail) String() string {
+	return strings.Join(t.lines, "\n")
 }
 
 // streamLines reads lines from a reader and sends them to the callback
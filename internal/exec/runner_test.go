@@ -2,6 +2,7 @@ package exec
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -75,6 +76,53 @@ func TestRunner_RunContext(t *testing.T) {
 		_, err := runner.RunContext(ctx, "sleep", "10")
 		assert.Error(t, err)
 	})
+
+	t.Run("cancel stops the process group", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		start := time.Now()
+		// The child sleep inherits the output pipes, so Run only returns
+		// early if the whole group is stopped
+		_, err := runner.RunContext(ctx, "sh", "-c", "sleep 10; echo done")
+		require.Error(t, err)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+}
+
+func TestRunner_RunWithOutputContext(t *testing.T) {
+	runner := NewRunner()
+
+	t.Run("streams lines", func(t *testing.T) {
+		var lines []string
+		err := runner.RunWithOutputContext(context.Background(), "sh", []string{"-c", "echo one; echo two >&2"}, func(line string) {
+			lines = append(lines, line)
+		})
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"one", "two"}, lines)
+	})
+
+	t.Run("failure includes the end of stderr", func(t *testing.T) {
+		var lines []string
+		script := "for i in $(seq 1 30); do echo line$i >&2; done; exit 1"
+		err := runner.RunWithOutputContext(context.Background(), "sh", []string{"-c", script}, func(line string) {
+			lines = append(lines, line)
+		})
+		require.Error(t, err)
+		assert.Len(t, lines, 30, "every line is still streamed")
+		assert.True(t, strings.HasSuffix(err.Error(), "\nline30"))
+		assert.Contains(t, err.Error(), "exit status 1: line11\n")
+		assert.NotContains(t, err.Error(), "line10\n")
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		err := runner.RunWithOutputContext(ctx, "sh", []string{"-c", "echo started; sleep 10"}, nil)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
 }
 
 func TestRunner_RunLines(t *testing.T) {
@@ -135,8 +183,9 @@ func TestRunner_Which(t *testing.T) {
 }
 
 func TestDefaultRunner(t *testing.T) {
-	assert.NotNil(t, Default)
-	assert.Equal(t, DefaultTimeout, Default.Timeout)
+	runner, ok := Default.(*CommandRunner)
+	require.True(t, ok)
+	assert.Equal(t, DefaultTimeout, runner.Timeout)
 }
 
 func TestPackageLevelFunctions(t *testing.T) {
@@ -159,7 +208,7 @@ func TestPackageLevelFunctions(t *testing.T) {
 }
 
 func TestRunner_Timeout(t *testing.T) {
-	runner := &Runner{
+	runner := &CommandRunner{
 		Timeout: 50 * time.Millisecond,
 	}
 
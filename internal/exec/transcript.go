@@ -0,0 +1,118 @@
+package exec
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// TranscriptFile is the file a transcript directory keeps its entries in
+const TranscriptFile = "transcript.jsonl"
+
+// Entry is one recorded command, or one PATH lookup when Lookup is set
+type Entry struct {
+	Command   string   `json:"command"`
+	Args      []string `json:"args,omitempty"`
+	Stdout    string   `json:"stdout,omitempty"`
+	Stderr    string   `json:"stderr,omitempty"`
+	ExitCode  int      `json:"exit_code,omitempty"`
+	LatencyMS int64    `json:"latency_ms,omitempty"`
+	Lookup    bool     `json:"lookup,omitempty"`  // entry records Exists, not a run
+	Missing   bool     `json:"missing,omitempty"` // command was not found on PATH
+}
+
+// Latency returns how long the command took to run
+func (e Entry) Latency() time.Duration {
+	return time.Duration(e.LatencyMS) * time.Millisecond
+}
+
+// key identifies the command line an entry answers
+func (e Entry) key() string {
+	return commandKey(e.Command, e.Args)
+}
+
+// commandKey joins a command and its arguments into a lookup key
+func commandKey(name string, args []string) string {
+	return strings.Join(append([]string{name}, args...), "\x00")
+}
+
+// ExitError is the error a replayed command fails with
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+// ExitCode returns the recorded exit code
+func (e *ExitError) ExitCode() int {
+	return e.Code
+}
+
+// LoadTranscript reads the entries recorded in a transcript directory
+func LoadTranscript(dir string) ([]Entry, error) {
+	f, err := os.Open(filepath.Join(dir, TranscriptFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open transcript: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", TranscriptFile, n, err)
+		}
+		if entry.Command == "" {
+			return nil, fmt.Errorf("%s:%d: entry has no command", TranscriptFile, n)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read transcript: %w", err)
+	}
+	return entries, nil
+}
+
+// SaveTranscript appends entries to the transcript in dir, creating the
+// directory when needed
+func SaveTranscript(dir string, entries []Entry) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create transcript directory: %w", err)
+	}
+	f, err := os.OpenFile(filepath.Join(dir, TranscriptFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open transcript: %w", err)
+	}
+
+	enc := json.NewEncoder(f)
+	for _, entry := range entries {
+		if err := enc.Encode(entry); err != nil {
+			f.Close()
+			return fmt.Errorf("failed to write transcript: %w", err)
+		}
+	}
+	return f.Close()
+}
+
+// exitCode returns the exit code of a failed command, or 127 when it could
+// not be started at all
+func exitCode(err error) int {
+	var exitErr interface{ ExitCode() int }
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return 127
+}
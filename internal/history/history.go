@@ -22,6 +22,8 @@ const (
 	OpProfile   Operation = "profile"
 	OpInstall   Operation = "install"
 	OpUninstall Operation = "uninstall"
+	OpUpgrade   Operation = "upgrade"
+	OpRollback  Operation = "rollback"
 )
 
 // Entry represents a single history log entry
@@ -82,25 +84,98 @@ func LogDump(machine string, counts map[string]int, committed bool) error {
 	return Log(OpDump, machine, details, summary)
 }
 
-// LogImport logs an import operation
-func LogImport(machine, source string, added []string) error {
-	details := fmt.Sprintf("←%s;+%s", source, strings.Join(added, ","))
+// Failure is a package operation that failed, with its failure category
+// (network, not-found, needs-sudo, ...)
+type Failure struct {
+	ID       string
+	Category string
+}
+
+// LogImport logs an import operation. skipped counts packages that were
+// never attempted because the import was interrupted.
+func LogImport(machine, source string, added []string, failures []Failure, skipped int) error {
+	details := fmt.Sprintf("←%s;+%s", source, strings.Join(added, ",")) + failureDetails(failures)
 	summary := fmt.Sprintf("%d packages", len(added))
+	if len(failures) > 0 {
+		summary += fmt.Sprintf(", %d failed", len(failures))
+	}
+	if skipped > 0 {
+		summary += ", " + interruptedSummary(skipped)
+	}
 	return Log(OpImport, machine, details, summary)
 }
 
-// LogSync logs a sync operation
-func LogSync(machine, source string, added, removed int) error {
-	details := fmt.Sprintf("←%s;+%d,-%d", source, added, removed)
+// LogSync logs a sync operation. skipped counts packages that were never
+// attempted because the sync was interrupted.
+func LogSync(machine, source string, added, removed int, failures []Failure, skipped int) error {
+	details := fmt.Sprintf("←%s;+%d,-%d", source, added, removed) + failureDetails(failures)
 	summary := "applied"
+	if len(failures) > 0 {
+		summary = fmt.Sprintf("%d failed", len(failures))
+	}
+	if skipped > 0 {
+		if len(failures) > 0 {
+			summary += ", " + interruptedSummary(skipped)
+		} else {
+			summary = interruptedSummary(skipped)
+		}
+	}
 	return Log(OpSync, machine, details, summary)
 }
 
-// LogInstall logs a single package install operation
-func LogInstall(machine, pkgID string, success bool) error {
+// failureDetails formats failures for the details field, e.g.
+// ";!brew:foo(network),cask:bar(not-found)"
+func failureDetails(failures []Failure) string {
+	if len(failures) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(failures))
+	for _, f := range failures {
+		parts = append(parts, fmt.Sprintf("%s(%s)", f.ID, f.Category))
+	}
+	return ";!" + strings.Join(parts, ",")
+}
+
+// interruptedSummary describes an interrupted batch operation
+func interruptedSummary(skipped int) string {
+	return fmt.Sprintf("interrupted, %d not attempted", skipped)
+}
+
+// LogUpgrade logs an upgrade of outdated packages. skipped counts packages
+// that were never attempted because the upgrade was interrupted.
+func LogUpgrade(machine string, upgraded []string, failures []Failure, skipped int) error {
+	details := "↑" + strings.Join(upgraded, ",") + failureDetails(failures)
+	summary := fmt.Sprintf("%d upgraded", len(upgraded))
+	if len(failures) > 0 {
+		summary += fmt.Sprintf(", %d failed", len(failures))
+	}
+	if skipped > 0 {
+		summary += ", " + interruptedSummary(skipped)
+	}
+	return Log(OpUpgrade, machine, details, summary)
+}
+
+// LogRollback logs the rollback of a sync from source. undone lists the
+// steps taken back, e.g. "uninstall brew:git"; failures are the steps that
+// could not be undone.
+func LogRollback(machine, source string, undone []string, failures []Failure) error {
+	details := fmt.Sprintf("←%s;%s", source, strings.Join(undone, ",")) + failureDetails(failures)
+	summary := fmt.Sprintf("%d undone", len(undone))
+	if len(failures) > 0 {
+		summary += fmt.Sprintf(", %d failed", len(failures))
+	}
+	return Log(OpRollback, machine, details, summary)
+}
+
+// LogInstall logs a single package install operation. category is the
+// failure category of a failed install.
+func LogInstall(machine, pkgID string, success bool, category string) error {
 	summary := "installed"
 	if !success {
 		summary = "failed"
+		if category != "" {
+			summary += " (" + category + ")"
+		}
 	}
 	return Log(OpInstall, machine, pkgID, summary)
 }
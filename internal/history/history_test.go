@@ -81,6 +81,14 @@ func TestEntryFormat(t *testing.T) {
 	})
 }
 
+func TestFailureDetails(t *testing.T) {
+	assert.Equal(t, "", failureDetails(nil))
+	assert.Equal(t, ";!brew:foo(network),cask:bar(not-found)", failureDetails([]Failure{
+		{ID: "brew:foo", Category: "network"},
+		{ID: "cask:bar", Category: "not-found"},
+	}))
+}
+
 func TestParseCounts(t *testing.T) {
 	t.Run("valid counts", func(t *testing.T) {
 		counts := ParseCounts("tap:6,brew:85,cask:42")
@@ -114,6 +122,8 @@ func TestOperationConstants(t *testing.T) {
 	assert.Equal(t, Operation("sync"), OpSync)
 	assert.Equal(t, Operation("ignore"), OpIgnore)
 	assert.Equal(t, Operation("profile"), OpProfile)
+	assert.Equal(t, Operation("upgrade"), OpUpgrade)
+	assert.Equal(t, Operation("rollback"), OpRollback)
 }
 
 func TestFormatAndParse_Roundtrip(t *testing.T) {
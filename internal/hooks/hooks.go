@@ -0,0 +1,142 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
+	"github.com/andrew-sameh/brewsync/internal/config"
+	"github.com/andrew-sameh/brewsync/internal/exec"
+)
+
+// Operations a hook can run around
+const (
+	OperationDump    = "dump"
+	OperationImport  = "import"
+	OperationSync    = "sync"
+	OperationProfile = "profile"
+)
+
+// Event describes the operation a hook runs around. It is written to a
+// temporary JSON file whose path is passed in BREWSYNC_PACKAGES_FILE.
+//
+// For pre_install the package lists hold what is about to be installed,
+// removed and changed; for post_install they hold what succeeded, with the
+// failures in Failed. Dump hooks get the collected packages in Packages
+// (empty for pre_dump).
+type Event struct {
+	Hook      string            `json:"hook"`
+	Operation string            `json:"operation"`
+	Machine   string            `json:"machine"`
+	Source    string            `json:"source,omitempty"` // machine or profile packages come from
+	Brewfile  string            `json:"brewfile,omitempty"`
+	Packages  brewfile.Packages `json:"packages,omitempty"`
+	Install   brewfile.Packages `json:"install,omitempty"`
+	Remove    brewfile.Packages `json:"remove,omitempty"`
+	Change    brewfile.Packages `json:"change,omitempty"`
+	Failed    brewfile.Packages `json:"failed,omitempty"`
+}
+
+// Runner runs the hooks configured in config.yaml
+type Runner struct {
+	config    config.HooksConfig
+	runner    exec.Runner
+	simulated bool
+}
+
+// New creates a runner for the configured hooks. Hooks always run on the
+// local machine with only their own timeout, whatever exec.Default wraps,
+// so they are not recorded by --record. A simulated session cannot replay
+// them and skips them instead.
+func New(cfg config.HooksConfig) *Runner {
+	runner := exec.NewRunner()
+	runner.Timeout = 0
+	_, simulated := exec.Default.(*exec.FakeRunner)
+	return &Runner{config: cfg, runner: runner, simulated: simulated}
+}
+
+// Enabled reports whether a command is configured for hook
+func (r *Runner) Enabled(hook string) bool {
+	return r != nil && r.config.Command(hook) != ""
+}
+
+// Run runs a hook with sh -c, streaming its output lines to onOutput.
+// The event is passed in BREWSYNC_* environment variables and the JSON
+// file. It does nothing when the hook is not configured, and fails when the
+// command exits non-zero or outlives its timeout. In a simulated session it
+// only reports that the hook was skipped.
+func (r *Runner) Run(ctx context.Context, hook string, event Event, onOutput func(line string)) error {
+	if !r.Enabled(hook) {
+		return nil
+	}
+	if r.simulated {
+		if onOutput != nil {
+			onOutput(fmt.Sprintf("%s hook skipped in a simulated session", hook))
+		}
+		return nil
+	}
+	timeout, err := r.config.Timeout(hook)
+	if err != nil {
+		return err
+	}
+	event.Hook = hook
+
+	file, err := writeEvent(event)
+	if err != nil {
+		return fmt.Errorf("%s hook: %w", hook, err)
+	}
+	defer os.Remove(file)
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	args := append(Environment(event, file), "sh", "-c", r.config.Command(hook))
+	err = r.runner.RunWithOutputContext(ctx, "env", args, onOutput)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("%s hook timed out after %s", hook, timeout)
+	}
+	return fmt.Errorf("%s hook failed: %w", hook, err)
+}
+
+// Environment returns the variables a hook runs with, as NAME=value pairs
+func Environment(event Event, packagesFile string) []string {
+	return []string{
+		"BREWSYNC_HOOK=" + event.Hook,
+		"BREWSYNC_OPERATION=" + event.Operation,
+		"BREWSYNC_MACHINE=" + event.Machine,
+		"BREWSYNC_SOURCE=" + event.Source,
+		"BREWSYNC_BREWFILE=" + event.Brewfile,
+		"BREWSYNC_PACKAGES_FILE=" + packagesFile,
+	}
+}
+
+// writeEvent writes an event to a temporary JSON file and returns its path
+func writeEvent(event Event) (string, error) {
+	data, err := json.MarshalIndent(event, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal event: %w", err)
+	}
+	f, err := os.CreateTemp("", "brewsync-hook-*.json")
+	if err != nil {
+		return "", fmt.Errorf("failed to create event file: %w", err)
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write event file: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return "", fmt.Errorf("failed to write event file: %w", err)
+	}
+	return f.Name(), nil
+}
@@ -0,0 +1,135 @@
+package hooks
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
+	"github.com/andrew-sameh/brewsync/internal/config"
+	"github.com/andrew-sameh/brewsync/internal/exec"
+)
+
+func TestRunner_Run(t *testing.T) {
+	runner := New(config.HooksConfig{
+		PreInstall:  `echo "$BREWSYNC_HOOK $BREWSYNC_OPERATION $BREWSYNC_MACHINE $BREWSYNC_SOURCE"; echo warning >&2`,
+		PostInstall: "exit 3",
+	})
+
+	t.Run("streams output with the event environment", func(t *testing.T) {
+		var lines []string
+		err := runner.Run(context.Background(), config.HookPreInstall, Event{
+			Operation: OperationSync,
+			Machine:   "mini",
+			Source:    "air",
+		}, func(line string) { lines = append(lines, line) })
+
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []string{"pre_install sync mini air", "warning"}, lines)
+	})
+
+	t.Run("non-zero exit fails", func(t *testing.T) {
+		err := runner.Run(context.Background(), config.HookPostInstall, Event{}, nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "post_install hook failed")
+	})
+
+	t.Run("unconfigured hook does nothing", func(t *testing.T) {
+		assert.False(t, runner.Enabled(config.HookPreDump))
+		assert.NoError(t, runner.Run(context.Background(), config.HookPreDump, Event{}, nil))
+	})
+}
+
+func TestRunner_PackagesFile(t *testing.T) {
+	runner := New(config.HooksConfig{PostDump: `cat "$BREWSYNC_PACKAGES_FILE"`})
+
+	var out strings.Builder
+	err := runner.Run(context.Background(), config.HookPostDump, Event{
+		Operation: OperationDump,
+		Machine:   "mini",
+		Brewfile:  "/tmp/Brewfile",
+		Packages:  brewfile.Packages{brewfile.NewPackage(brewfile.TypeBrew, "git")},
+	}, func(line string) { out.WriteString(line + "\n") })
+	require.NoError(t, err)
+
+	var event Event
+	require.NoError(t, json.Unmarshal([]byte(out.String()), &event))
+	assert.Equal(t, config.HookPostDump, event.Hook)
+	assert.Equal(t, OperationDump, event.Operation)
+	assert.Equal(t, "/tmp/Brewfile", event.Brewfile)
+	require.Len(t, event.Packages, 1)
+	assert.Equal(t, "brew:git", event.Packages[0].ID())
+}
+
+func TestRunner_RemovesPackagesFile(t *testing.T) {
+	runner := New(config.HooksConfig{PreDump: `echo "$BREWSYNC_PACKAGES_FILE"`})
+
+	var path string
+	err := runner.Run(context.Background(), config.HookPreDump, Event{}, func(line string) { path = line })
+	require.NoError(t, err)
+	require.NotEmpty(t, path)
+
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestRunner_Timeout(t *testing.T) {
+	runner := New(config.HooksConfig{
+		PreDump:  "sleep 5",
+		Timeouts: map[string]string{config.HookPreDump: "100ms"},
+	})
+
+	err := runner.Run(context.Background(), config.HookPreDump, Event{}, nil)
+	require.Error(t, err)
+	assert.Equal(t, "pre_dump hook timed out after 100ms", err.Error())
+}
+
+func TestNew_IgnoresDefaultRunner(t *testing.T) {
+	saved := exec.Default
+	t.Cleanup(func() { exec.Default = saved })
+
+	t.Run("recording keeps the hook timeout", func(t *testing.T) {
+		exec.Default = exec.NewRecorder(exec.NewRunner())
+		runner := New(config.HooksConfig{PreInstall: "echo hi"})
+
+		cmd, ok := runner.runner.(*exec.CommandRunner)
+		require.True(t, ok, "hooks are not recorded")
+		assert.Zero(t, cmd.Timeout)
+	})
+
+	t.Run("simulation skips hooks", func(t *testing.T) {
+		fake := exec.NewFakeRunner(nil)
+		fake.Strict = true
+		exec.Default = fake
+		runner := New(config.HooksConfig{PreInstall: "exit 1"})
+
+		var lines []string
+		err := runner.Run(context.Background(), config.HookPreInstall, Event{}, func(line string) { lines = append(lines, line) })
+		require.NoError(t, err)
+		assert.Equal(t, []string{"pre_install hook skipped in a simulated session"}, lines)
+	})
+}
+
+func TestEnvironment(t *testing.T) {
+	env := Environment(Event{
+		Hook:      config.HookPreInstall,
+		Operation: OperationProfile,
+		Machine:   "mini",
+		Source:    "work",
+		Brewfile:  "/tmp/Brewfile",
+	}, "/tmp/event.json")
+
+	assert.Equal(t, []string{
+		"BREWSYNC_HOOK=pre_install",
+		"BREWSYNC_OPERATION=profile",
+		"BREWSYNC_MACHINE=mini",
+		"BREWSYNC_SOURCE=work",
+		"BREWSYNC_BREWFILE=/tmp/Brewfile",
+		"BREWSYNC_PACKAGES_FILE=/tmp/event.json",
+	}, env)
+}
@@ -1,6 +1,11 @@
 package installer
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/andrew-sameh/brewsync/internal/brewfile"
@@ -9,7 +14,7 @@ import (
 
 // BrewInstaller handles Homebrew formulae and casks
 type BrewInstaller struct {
-	runner *exec.Runner
+	runner exec.Runner
 }
 
 // NewBrewInstaller creates a new Homebrew installer
@@ -20,8 +25,8 @@ func NewBrewInstaller() *BrewInstaller {
 }
 
 // ListTaps returns all installed taps
-func (b *BrewInstaller) ListTaps() (brewfile.Packages, error) {
-	lines, err := b.runner.RunLines("brew", "tap")
+func (b *BrewInstaller) ListTaps(ctx context.Context) (brewfile.Packages, error) {
+	lines, err := b.runner.RunLinesContext(ctx, "brew", "tap")
 	if err != nil {
 		return nil, err
 	}
@@ -38,8 +43,8 @@ func (b *BrewInstaller) ListTaps() (brewfile.Packages, error) {
 
 // ListFormulae returns all installed formulae (without descriptions)
 // Use 'brew bundle dump --describe' via DumpToFile for descriptions
-func (b *BrewInstaller) ListFormulae() (brewfile.Packages, error) {
-	lines, err := b.runner.RunLines("brew", "list", "--formula", "-1")
+func (b *BrewInstaller) ListFormulae(ctx context.Context) (brewfile.Packages, error) {
+	lines, err := b.runner.RunLinesContext(ctx, "brew", "list", "--formula", "-1")
 	if err != nil {
 		return nil, err
 	}
@@ -56,8 +61,8 @@ func (b *BrewInstaller) ListFormulae() (brewfile.Packages, error) {
 
 // ListCasks returns all installed casks (without descriptions)
 // Use 'brew bundle dump --describe' via DumpToFile for descriptions
-func (b *BrewInstaller) ListCasks() (brewfile.Packages, error) {
-	lines, err := b.runner.RunLines("brew", "list", "--cask", "-1")
+func (b *BrewInstaller) ListCasks(ctx context.Context) (brewfile.Packages, error) {
+	lines, err := b.runner.RunLinesContext(ctx, "brew", "list", "--cask", "-1")
 	if err != nil {
 		return nil, err
 	}
@@ -73,22 +78,22 @@ func (b *BrewInstaller) ListCasks() (brewfile.Packages, error) {
 }
 
 // ListAll returns all taps, formulae, and casks
-func (b *BrewInstaller) ListAll() (brewfile.Packages, error) {
+func (b *BrewInstaller) ListAll(ctx context.Context) (brewfile.Packages, error) {
 	var all brewfile.Packages
 
-	taps, err := b.ListTaps()
+	taps, err := b.ListTaps(ctx)
 	if err != nil {
 		return nil, err
 	}
 	all = append(all, taps...)
 
-	formulae, err := b.ListFormulae()
+	formulae, err := b.ListFormulae(ctx)
 	if err != nil {
 		return nil, err
 	}
 	all = append(all, formulae...)
 
-	casks, err := b.ListCasks()
+	casks, err := b.ListCasks(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -98,55 +103,257 @@ func (b *BrewInstaller) ListAll() (brewfile.Packages, error) {
 }
 
 // List returns all installed packages (alias for ListAll)
-func (b *BrewInstaller) List() (brewfile.Packages, error) {
-	return b.ListAll()
+func (b *BrewInstaller) List(ctx context.Context) (brewfile.Packages, error) {
+	return b.ListAll(ctx)
 }
 
 // Install installs a package
-func (b *BrewInstaller) Install(pkg brewfile.Package) error {
-	return b.InstallWithProgress(pkg, nil)
+func (b *BrewInstaller) Install(ctx context.Context, pkg brewfile.Package) error {
+	return b.InstallWithProgress(ctx, pkg, nil)
 }
 
-// InstallWithProgress installs a package and streams output to a callback
-func (b *BrewInstaller) InstallWithProgress(pkg brewfile.Package, onOutput func(line string)) error {
+// InstallWithProgress installs a package and streams output to a callback.
+// The args option of a formula or cask is passed as flags.
+func (b *BrewInstaller) InstallWithProgress(ctx context.Context, pkg brewfile.Package, onOutput func(line string)) error {
 	var args []string
 
 	switch pkg.Type {
 	case brewfile.TypeTap:
 		args = []string{"tap", pkg.Name}
+		if pkg.URL != "" {
+			args = append(args, pkg.URL)
+		}
 	case brewfile.TypeBrew:
-		args = []string{"install", pkg.Name}
+		args = append(append([]string{"install"}, installFlags(pkg)...), pkg.Name)
 	case brewfile.TypeCask:
-		args = []string{"install", "--cask", pkg.Name}
+		args = append(append([]string{"install", "--cask"}, installFlags(pkg)...), pkg.Name)
 	default:
-		return nil
+		return &UnsupportedError{Type: pkg.Type, Operation: "install with brew"}
 	}
 
 	// If no callback provided, use the regular Run method
 	if onOutput == nil {
-		_, err := b.runner.Run("brew", args...)
+		_, err := b.runner.RunContext(ctx, "brew", args...)
 		return err
 	}
 
 	// Use streaming method with callback
-	return b.runner.RunWithOutput("brew", args, onOutput)
+	return b.runner.RunWithOutputContext(ctx, "brew", args, onOutput)
+}
+
+// InstallBatch installs several formulae or several casks with one
+// `brew install` so Homebrew updates and resolves dependencies once.
+// Taps, and packages whose args would apply to the whole batch, are
+// installed one at a time.
+func (b *BrewInstaller) InstallBatch(ctx context.Context, pkgs brewfile.Packages, onOutput func(line string)) error {
+	if len(pkgs) == 0 {
+		return nil
+	}
+	pkgType := pkgs[0].Type
+	if pkgType == brewfile.TypeTap {
+		for _, pkg := range pkgs {
+			if err := b.InstallWithProgress(ctx, pkg, onOutput); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	args := []string{"install"}
+	switch pkgType {
+	case brewfile.TypeBrew:
+	case brewfile.TypeCask:
+		args = append(args, "--cask")
+	default:
+		return &UnsupportedError{Type: pkgType, Operation: "install with brew"}
+	}
+	var separate brewfile.Packages
+	for _, pkg := range pkgs {
+		if pkg.Type != pkgType {
+			return fmt.Errorf("cannot batch %s with %s packages", pkg.ID(), pkgType)
+		}
+		if len(installFlags(pkg)) > 0 {
+			separate = append(separate, pkg)
+			continue
+		}
+		args = append(args, pkg.Name)
+	}
+
+	if len(separate) < len(pkgs) {
+		var err error
+		if onOutput == nil {
+			_, err = b.runner.RunContext(ctx, "brew", args...)
+		} else {
+			err = b.runner.RunWithOutputContext(ctx, "brew", args, onOutput)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	for _, pkg := range separate {
+		if err := b.InstallWithProgress(ctx, pkg, onOutput); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // Uninstall removes a package
-func (b *BrewInstaller) Uninstall(pkg brewfile.Package) error {
+func (b *BrewInstaller) Uninstall(ctx context.Context, pkg brewfile.Package) error {
 	switch pkg.Type {
 	case brewfile.TypeTap:
-		_, err := b.runner.Run("brew", "untap", pkg.Name)
+		_, err := b.runner.RunContext(ctx, "brew", "untap", pkg.Name)
 		return err
 	case brewfile.TypeBrew:
-		_, err := b.runner.Run("brew", "uninstall", pkg.Name)
+		_, err := b.runner.RunContext(ctx, "brew", "uninstall", pkg.Name)
+		return err
+	case brewfile.TypeCask:
+		_, err := b.runner.RunContext(ctx, "brew", "uninstall", "--cask", pkg.Name)
+		return err
+	default:
+		return &UnsupportedError{Type: pkg.Type, Operation: "uninstall with brew"}
+	}
+}
+
+// Reinstall reinstalls a formula or cask so it picks up changed options;
+// a cask's args are passed as flags. For taps it points the tap at its
+// declared clone URL.
+func (b *BrewInstaller) Reinstall(ctx context.Context, pkg brewfile.Package) error {
+	switch pkg.Type {
+	case brewfile.TypeTap:
+		if pkg.URL == "" {
+			return nil
+		}
+		_, err := b.runner.RunContext(ctx, "brew", "tap", "--custom-remote", pkg.Name, pkg.URL)
 		return err
+	case brewfile.TypeBrew:
+		_, err := b.runner.RunContext(ctx, "brew", "reinstall", pkg.Name)
+		if err != nil {
+			return err
+		}
+		if link, ok := pkg.Options["link"]; ok && link.Kind == brewfile.KindBool {
+			return b.Relink(ctx, pkg)
+		}
+		return nil
 	case brewfile.TypeCask:
-		_, err := b.runner.Run("brew", "uninstall", "--cask", pkg.Name)
+		args := append([]string{"reinstall", "--cask"}, installFlags(pkg)...)
+		_, err := b.runner.RunContext(ctx, "brew", append(args, pkg.Name)...)
 		return err
 	default:
+		return &UnsupportedError{Type: pkg.Type, Operation: "reinstall with brew"}
+	}
+}
+
+// installFlags turns the args option of a formula or cask into brew
+// flags, as brew bundle does: a list such as ["HEAD"] becomes --HEAD and a
+// hash such as { appdir: "~/Apps", no_quarantine: true } becomes
+// --appdir=~/Apps --no-quarantine
+func installFlags(pkg brewfile.Package) []string {
+	args, ok := pkg.Options["args"]
+	if !ok {
+		return nil
+	}
+
+	var flags []string
+	switch args.Kind {
+	case brewfile.KindList:
+		for _, arg := range args.Strings() {
+			flags = append(flags, "--"+strings.TrimLeft(arg, "-"))
+		}
+	case brewfile.KindHash:
+		for _, e := range args.Hash {
+			flag := "--" + strings.ReplaceAll(e.Key, "_", "-")
+			switch e.Value.Kind {
+			case brewfile.KindBool:
+				if e.Value.Bool {
+					flags = append(flags, flag)
+				}
+			case brewfile.KindNil:
+			case brewfile.KindSymbol:
+				flags = append(flags, flag+"="+e.Value.Str)
+			default:
+				flags = append(flags, flag+"="+e.Value.String())
+			}
+		}
+	}
+	return flags
+}
+
+// Relink links or unlinks a formula according to its link option
+func (b *BrewInstaller) Relink(ctx context.Context, pkg brewfile.Package) error {
+	if pkg.Type != brewfile.TypeBrew {
 		return nil
 	}
+	args := []string{"link", "--overwrite", pkg.Name}
+	if link, ok := pkg.Options["link"]; ok && link.Kind == brewfile.KindBool && !link.Bool {
+		args = []string{"unlink", pkg.Name}
+	}
+	_, err := b.runner.RunContext(ctx, "brew", args...)
+	return err
+}
+
+// Services returns the formulae with a Homebrew service, reporting whether
+// each service is running
+func (b *BrewInstaller) Services(ctx context.Context) (map[string]bool, error) {
+	out, err := b.runner.RunContext(ctx, "brew", "services", "list", "--json")
+	if err != nil {
+		return nil, err
+	}
+	return parseServices([]byte(out))
+}
+
+// parseServices parses `brew services list --json`. Scheduled services run
+// on their own timer, so they count as running.
+func parseServices(data []byte) (map[string]bool, error) {
+	var entries []struct {
+		Name   string `json:"name"`
+		Status string `json:"status"`
+	}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return map[string]bool{}, nil
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse brew services: %w", err)
+	}
+
+	services := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		if entry.Name != "" {
+			services[entry.Name] = entry.Status == "started" || entry.Status == "scheduled"
+		}
+	}
+	return services, nil
+}
+
+// WithServices records the state of each formula's service in pkgs: running
+// services as restart_service: :changed, stopped ones without service
+// options. Formulae without a service are left as they are.
+func (b *BrewInstaller) WithServices(ctx context.Context, pkgs brewfile.Packages) (brewfile.Packages, error) {
+	services, err := b.Services(ctx)
+	if err != nil {
+		return pkgs, err
+	}
+	result := make(brewfile.Packages, len(pkgs))
+	for i, pkg := range pkgs {
+		if running, ok := services[pkg.Name]; ok && pkg.Type == brewfile.TypeBrew {
+			pkg = pkg.WithService(running)
+		}
+		result[i] = pkg
+	}
+	return result, nil
+}
+
+// SetService starts or stops a formula's service
+func (b *BrewInstaller) SetService(ctx context.Context, pkg brewfile.Package, running bool) error {
+	if pkg.Type != brewfile.TypeBrew {
+		return &UnsupportedError{Type: pkg.Type, Operation: "manage services with brew"}
+	}
+	action := "stop"
+	if running {
+		action = "start"
+	}
+	_, err := b.runner.RunContext(ctx, "brew", "services", action, pkg.Name)
+	return err
 }
 
 // IsAvailable checks if brew is available
@@ -154,10 +361,250 @@ func (b *BrewInstaller) IsAvailable() bool {
 	return b.runner.Exists("brew")
 }
 
+// Capabilities reports that brew supports every optional operation
+func (b *BrewInstaller) Capabilities() Capabilities {
+	return Capabilities{List: true, Uninstall: true, Stream: true, Versions: true, Batch: true, Upgrade: true}
+}
+
+// Outdated returns the formulae and casks with a newer version available
+func (b *BrewInstaller) Outdated(ctx context.Context) ([]Outdated, error) {
+	out, err := b.runner.RunContext(ctx, "brew", "outdated", "--json=v2")
+	if err != nil {
+		return nil, err
+	}
+	return parseBrewOutdated([]byte(out))
+}
+
+// brewOutdatedEntry is one formula or cask in `brew outdated --json=v2`
+type brewOutdatedEntry struct {
+	Name string `json:"name"`
+	// InstalledVersions is a list for formulae and, depending on the
+	// Homebrew version, a list or a single string for casks
+	InstalledVersions json.RawMessage `json:"installed_versions"`
+	CurrentVersion    string          `json:"current_version"`
+	Pinned            bool            `json:"pinned"`
+}
+
+// parseBrewOutdated parses `brew outdated --json=v2` output
+func parseBrewOutdated(data []byte) ([]Outdated, error) {
+	var doc struct {
+		Formulae []brewOutdatedEntry `json:"formulae"`
+		Casks    []brewOutdatedEntry `json:"casks"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse brew outdated output: %w", err)
+	}
+
+	var outdated []Outdated
+	for _, group := range []struct {
+		pkgType brewfile.PackageType
+		entries []brewOutdatedEntry
+	}{
+		{brewfile.TypeBrew, doc.Formulae},
+		{brewfile.TypeCask, doc.Casks},
+	} {
+		for _, e := range group.entries {
+			outdated = append(outdated, Outdated{
+				Package:   brewfile.NewPackage(group.pkgType, e.Name),
+				Installed: lastInstalledVersion(e.InstalledVersions),
+				Latest:    e.CurrentVersion,
+				Pinned:    e.Pinned,
+			})
+		}
+	}
+	return outdated, nil
+}
+
+// lastInstalledVersion returns the newest (last) of the installed versions
+func lastInstalledVersion(raw json.RawMessage) string {
+	var list []string
+	if err := json.Unmarshal(raw, &list); err == nil {
+		if len(list) == 0 {
+			return ""
+		}
+		return list[len(list)-1]
+	}
+	var single string
+	_ = json.Unmarshal(raw, &single)
+	return single
+}
+
+// Upgrade upgrades a formula or cask to its latest version
+func (b *BrewInstaller) Upgrade(ctx context.Context, pkg brewfile.Package) error {
+	switch pkg.Type {
+	case brewfile.TypeBrew:
+		_, err := b.runner.RunContext(ctx, "brew", "upgrade", pkg.Name)
+		return err
+	case brewfile.TypeCask:
+		_, err := b.runner.RunContext(ctx, "brew", "upgrade", "--cask", pkg.Name)
+		return err
+	default:
+		return &UnsupportedError{Type: pkg.Type, Operation: "upgrade with brew"}
+	}
+}
+
 // DumpToFile runs brew bundle dump to a file with descriptions
 // This uses 'brew bundle dump --describe' which automatically includes
-// package descriptions as comments in the output Brewfile
+// package descriptions as comments in the output Brewfile. The dump is
+// read from stdout so it can be recorded and replayed like any other output.
 func (b *BrewInstaller) DumpToFile(path string) error {
-	_, err := b.runner.Run("brew", "bundle", "dump", "--force", "--describe", "--file="+path)
-	return err
+	out, err := b.runner.Run("brew", "bundle", "dump", "--describe", "--file=-")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(out), 0644)
+}
+
+// Versions returns the installed version of every formula and cask, keyed by package ID
+func (b *BrewInstaller) Versions() (map[string]string, error) {
+	versions := make(map[string]string)
+
+	formulae, err := b.runner.RunLines("brew", "list", "--formula", "--versions")
+	if err != nil {
+		return nil, err
+	}
+	parseBrewVersions(formulae, brewfile.TypeBrew, versions)
+
+	casks, err := b.runner.RunLines("brew", "list", "--cask", "--versions")
+	if err != nil {
+		return nil, err
+	}
+	parseBrewVersions(casks, brewfile.TypeCask, versions)
+
+	return versions, nil
+}
+
+// parseBrewVersions parses "name 1.0 1.1" lines, keeping the newest (last) version
+func parseBrewVersions(lines []string, pkgType brewfile.PackageType, versions map[string]string) {
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		versions[string(pkgType)+":"+fields[0]] = fields[len(fields)-1]
+	}
+}
+
+// TapCommits returns the git commit each installed tap is checked out at, keyed by package ID
+func (b *BrewInstaller) TapCommits() (map[string]string, error) {
+	taps, err := b.ListTaps(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	commits := make(map[string]string)
+	for _, tap := range taps {
+		repo, err := b.runner.Run("brew", "--repository", tap.Name)
+		if err != nil {
+			continue
+		}
+		commit, err := b.runner.Run("git", "-C", strings.TrimSpace(repo), "rev-parse", "HEAD")
+		if err != nil {
+			continue
+		}
+		commits[tap.ID()] = strings.TrimSpace(commit)
+	}
+	return commits, nil
+}
+
+// Prefix returns the Homebrew installation prefix, e.g. /opt/homebrew
+func (b *BrewInstaller) Prefix() (string, error) {
+	out, err := b.runner.Run("brew", "--prefix")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out), nil
+}
+
+// DefaultPrefixes returns where Homebrew installs itself on an operating
+// system and architecture: /opt/homebrew on Apple Silicon, /usr/local on
+// Intel Macs, and /home/linuxbrew/.linuxbrew or ~/.linuxbrew on Linux.
+// Bottles are only poured into these prefixes.
+func DefaultPrefixes(goos, arch string) []string {
+	switch goos {
+	case "darwin":
+		if arch == "arm64" {
+			return []string{"/opt/homebrew"}
+		}
+		return []string{"/usr/local"}
+	case "linux":
+		prefixes := []string{"/home/linuxbrew/.linuxbrew"}
+		if home := expandHome("~/.linuxbrew"); home != "" {
+			prefixes = append(prefixes, home)
+		}
+		return prefixes
+	}
+	return nil
+}
+
+// FindBrew returns the default prefix holding a brew executable, for when
+// Homebrew is installed but not on PATH
+func FindBrew(goos, arch string) (string, bool) {
+	for _, prefix := range DefaultPrefixes(goos, arch) {
+		if info, err := os.Stat(filepath.Join(prefix, "bin", "brew")); err == nil && !info.IsDir() {
+			return prefix, true
+		}
+	}
+	return "", false
+}
+
+// IsDefaultPrefix reports whether prefix is one of the default Homebrew
+// prefixes for an operating system and architecture
+func IsDefaultPrefix(prefix, goos, arch string) bool {
+	for _, p := range DefaultPrefixes(goos, arch) {
+		if filepath.Clean(prefix) == p {
+			return true
+		}
+	}
+	return false
+}
+
+// Version returns the Homebrew version, e.g. 4.3.5
+func (b *BrewInstaller) Version() (string, error) {
+	out, err := b.runner.Run("brew", "--version")
+	if err != nil {
+		return "", err
+	}
+	return parseBrewVersion(out), nil
+}
+
+// parseBrewVersion extracts the version from "Homebrew 4.3.5\n..." output
+func parseBrewVersion(output string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(output), "\n")
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return ""
+	}
+	return fields[1]
+}
+
+// Requirements returns the architecture and macOS requirements of the given
+// formulae and casks, keyed by package ID. Other package types are ignored.
+func (b *BrewInstaller) Requirements(packages brewfile.Packages) (map[string]brewfile.Requirements, error) {
+	reqs := make(map[string]brewfile.Requirements)
+	for _, group := range []struct {
+		pkgType brewfile.PackageType
+		flag    string
+	}{
+		{brewfile.TypeBrew, "--formula"},
+		{brewfile.TypeCask, "--cask"},
+	} {
+		names := packages.Filter(group.pkgType).Names()
+		if len(names) == 0 {
+			continue
+		}
+		args := append([]string{"info", "--json=v2", group.flag}, names...)
+		out, err := b.runner.Run("brew", args...)
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := brewfile.ParseBrewRequirements([]byte(out))
+		if err != nil {
+			return nil, err
+		}
+		for id, r := range parsed {
+			reqs[id] = r
+		}
+	}
+	return reqs, nil
 }
@@ -1,10 +1,13 @@
 package installer
 
 import (
+	"context"
 	"testing"
 
 	"github.com/andrew-sameh/brewsync/internal/brewfile"
+	"github.com/andrew-sameh/brewsync/internal/exec"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewBrewInstaller(t *testing.T) {
@@ -26,7 +29,7 @@ func TestBrewInstaller_ListTaps(t *testing.T) {
 		t.Skip("Homebrew not available")
 	}
 
-	taps, err := inst.ListTaps()
+	taps, err := inst.ListTaps(context.Background())
 	assert.NoError(t, err)
 
 	// Verify all returned packages are taps
@@ -42,7 +45,7 @@ func TestBrewInstaller_ListFormulae(t *testing.T) {
 		t.Skip("Homebrew not available")
 	}
 
-	formulae, err := inst.ListFormulae()
+	formulae, err := inst.ListFormulae(context.Background())
 	assert.NoError(t, err)
 
 	// Verify all returned packages are brews
@@ -58,7 +61,7 @@ func TestBrewInstaller_ListCasks(t *testing.T) {
 		t.Skip("Homebrew not available")
 	}
 
-	casks, err := inst.ListCasks()
+	casks, err := inst.ListCasks(context.Background())
 	assert.NoError(t, err)
 
 	// Verify all returned packages are casks
@@ -74,7 +77,7 @@ func TestBrewInstaller_ListAll(t *testing.T) {
 		t.Skip("Homebrew not available")
 	}
 
-	all, err := inst.ListAll()
+	all, err := inst.ListAll(context.Background())
 	assert.NoError(t, err)
 
 	// Should have at least some packages
@@ -108,3 +111,181 @@ func TestBrewInstaller_DumpToFile(t *testing.T) {
 		t.Logf("brew bundle dump failed (may not be installed): %v", err)
 	}
 }
+
+func TestParseBrewVersions(t *testing.T) {
+	versions := make(map[string]string)
+	parseBrewVersions([]string{
+		"git 2.43.0",
+		"python@3.12 3.12.1 3.12.2",
+		"",
+		"broken",
+	}, brewfile.TypeBrew, versions)
+	parseBrewVersions([]string{"firefox 121.0"}, brewfile.TypeCask, versions)
+
+	assert.Equal(t, map[string]string{
+		"brew:git":         "2.43.0",
+		"brew:python@3.12": "3.12.2",
+		"cask:firefox":     "121.0",
+	}, versions)
+}
+
+func TestParseBrewVersion(t *testing.T) {
+	assert.Equal(t, "4.3.5", parseBrewVersion("Homebrew 4.3.5\nHomebrew/homebrew-core (git revision 1a2b)\n"))
+	assert.Equal(t, "4.3.5-12-gabc", parseBrewVersion("Homebrew 4.3.5-12-gabc"))
+	assert.Equal(t, "", parseBrewVersion(""))
+}
+
+func TestParseServices(t *testing.T) {
+	services, err := parseServices([]byte(`[
+		{"name": "postgresql@16", "status": "started", "user": "me", "exit_code": 0},
+		{"name": "redis", "status": "stopped", "user": null},
+		{"name": "backup", "status": "scheduled"},
+		{"name": "nginx", "status": "error", "exit_code": 1},
+		{"name": "", "status": "started"}
+	]`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{
+		"postgresql@16": true,
+		"redis":         false,
+		"backup":        true,
+		"nginx":         false,
+	}, services)
+
+	services, err = parseServices(nil)
+	require.NoError(t, err)
+	assert.Empty(t, services)
+
+	_, err = parseServices([]byte("Error: not json"))
+	assert.Error(t, err)
+}
+
+func TestBrewInstaller_WithServices(t *testing.T) {
+	inst := &BrewInstaller{runner: exec.NewFakeRunner([]exec.Entry{{
+		Command: "brew",
+		Args:    []string{"services", "list", "--json"},
+		Stdout:  `[{"name": "postgresql@16", "status": "started"}, {"name": "redis", "status": "stopped"}]`,
+	}})}
+
+	pkgs, err := inst.WithServices(context.Background(), brewfile.Packages{
+		brewfile.NewPackage(brewfile.TypeBrew, "postgresql@16"),
+		brewfile.NewPackage(brewfile.TypeBrew, "redis").WithOption("restart_service", ":changed"),
+		brewfile.NewPackage(brewfile.TypeBrew, "git").WithOption("start_service", "true"),
+		brewfile.NewPackage(brewfile.TypeCask, "redis"),
+	})
+	require.NoError(t, err)
+	assert.True(t, pkgs[0].ServiceRunning())
+	assert.False(t, pkgs[1].ServiceRunning())
+	assert.True(t, pkgs[2].ServiceRunning(), "formulae without a service are left alone")
+	assert.Nil(t, pkgs[3].Options)
+}
+
+func TestManager_ConvergeService(t *testing.T) {
+	// Strict replay fails on anything but the recorded service commands, so
+	// a reinstall would make Converge fail
+	fake := exec.NewFakeRunner([]exec.Entry{
+		{Command: "brew", Lookup: true},
+		{Command: "brew", Args: []string{"services", "start", "postgresql@16"}},
+		{Command: "brew", Args: []string{"services", "stop", "redis"}},
+	})
+	fake.Strict = true
+	m := NewManager()
+	m.brew.runner = fake
+	ctx := context.Background()
+
+	running := brewfile.NewPackage(brewfile.TypeBrew, "postgresql@16").WithOption("restart_service", ":changed")
+	assert.NoError(t, m.Converge(ctx, brewfile.Change{Source: running, Current: running.WithService(false)}))
+
+	stopped := brewfile.NewPackage(brewfile.TypeBrew, "redis")
+	assert.NoError(t, m.Converge(ctx, brewfile.Change{Source: stopped, Current: stopped.WithService(true)}))
+
+	// Same state spelled differently needs no action
+	started := brewfile.NewPackage(brewfile.TypeBrew, "mysql").WithOption("start_service", "true")
+	assert.NoError(t, m.Converge(ctx, brewfile.Change{Source: started, Current: started.WithOption("restart_service", ":changed")}))
+
+	// Other option changes still reinstall
+	linked := running.WithOption("link", "false")
+	assert.Error(t, m.Converge(ctx, brewfile.Change{Source: linked, Current: running.WithService(false)}))
+}
+
+func TestManager_ConvergeArgs(t *testing.T) {
+	fake := exec.NewFakeRunner([]exec.Entry{
+		{Command: "brew", Lookup: true},
+		{Command: "brew", Args: []string{"reinstall", "--cask", "--appdir=~/Apps", "--no-quarantine", "firefox"}},
+		{Command: "brew", Args: []string{"reinstall", "node"}},
+	})
+	fake.Strict = true
+	m := NewManager()
+	m.brew.runner = fake
+	ctx := context.Background()
+
+	pkgs, err := brewfile.ParseContent(`cask "firefox", args: { appdir: "~/Apps", no_quarantine: true, require_sha: false }
+brew "node", args: ["HEAD"]
+brew "node", args: ["HEAD"], postinstall: "true"
+`)
+	require.NoError(t, err)
+	firefox, node, postinstall := pkgs[0], pkgs[1], pkgs[2]
+
+	assert.NoError(t, m.Converge(ctx, brewfile.Change{Source: firefox, Current: brewfile.NewPackage(brewfile.TypeCask, "firefox")}))
+
+	// A formula's build args cannot be changed by reinstalling it
+	err = m.Converge(ctx, brewfile.Change{Source: node, Current: brewfile.NewPackage(brewfile.TypeBrew, "node")})
+	assert.Equal(t, FailureUnsupported, Classify(err))
+	assert.Contains(t, err.Error(), "brew uninstall node")
+
+	// Unchanged args are kept by brew reinstall
+	assert.NoError(t, m.Converge(ctx, brewfile.Change{Source: postinstall, Current: node}))
+}
+
+func TestBrewInstaller_InstallBatchArgs(t *testing.T) {
+	fake := exec.NewFakeRunner([]exec.Entry{
+		{Command: "brew", Args: []string{"install", "--cask", "slack", "raycast"}},
+		{Command: "brew", Args: []string{"install", "--cask", "--appdir=~/Apps", "firefox"}},
+	})
+	fake.Strict = true
+	b := &BrewInstaller{runner: fake}
+
+	pkgs, err := brewfile.ParseContent("cask \"slack\"\ncask \"firefox\", args: { appdir: \"~/Apps\" }\ncask \"raycast\"\n")
+	require.NoError(t, err)
+	assert.NoError(t, b.InstallBatch(context.Background(), pkgs, nil))
+}
+
+func TestDefaultPrefixes(t *testing.T) {
+	assert.Equal(t, []string{"/opt/homebrew"}, DefaultPrefixes("darwin", "arm64"))
+	assert.Equal(t, []string{"/usr/local"}, DefaultPrefixes("darwin", "x86_64"))
+	assert.Contains(t, DefaultPrefixes("linux", "x86_64"), "/home/linuxbrew/.linuxbrew")
+	assert.Empty(t, DefaultPrefixes("windows", "x86_64"))
+
+	assert.True(t, IsDefaultPrefix("/opt/homebrew/", "darwin", "arm64"))
+	assert.False(t, IsDefaultPrefix("/usr/local", "darwin", "arm64"))
+	assert.True(t, IsDefaultPrefix("/home/linuxbrew/.linuxbrew", "linux", "arm64"))
+	assert.False(t, IsDefaultPrefix("/opt/brew", "linux", "x86_64"))
+}
+
+func TestManager_Restore(t *testing.T) {
+	fake := exec.NewFakeRunner([]exec.Entry{
+		{Command: "brew", Lookup: true},
+		{Command: "brew", Args: []string{"install", "postgresql@16"}},
+		{Command: "brew", Args: []string{"unlink", "postgresql@16"}},
+		{Command: "brew", Args: []string{"services", "start", "postgresql@16"}},
+		{Command: "brew", Args: []string{"install", "--cask", "slack"}},
+		{Command: "brew", Args: []string{"install", "--cask", "--appdir=~/Apps", "firefox"}},
+		{Command: "brew", Args: []string{"install", "--HEAD", "neovim"}},
+	})
+	fake.Strict = true
+	m := NewManager()
+	m.brew.runner = fake
+	ctx := context.Background()
+
+	pkg := brewfile.NewPackage(brewfile.TypeBrew, "postgresql@16").
+		WithValue("link", brewfile.BoolValue(false)).
+		WithService(true)
+	assert.NoError(t, m.Restore(ctx, pkg))
+	assert.NoError(t, m.Restore(ctx, brewfile.NewPackage(brewfile.TypeCask, "slack")))
+
+	// Removed packages come back with the args they were declared with
+	withArgs, err := brewfile.ParseContent("cask \"firefox\", args: { appdir: \"~/Apps\" }\nbrew \"neovim\", args: [\"HEAD\"]\n")
+	require.NoError(t, err)
+	assert.NoError(t, m.Restore(ctx, withArgs[0]))
+	assert.NoError(t, m.Restore(ctx, withArgs[1]))
+	assert.Error(t, m.Restore(ctx, brewfile.NewPackage(brewfile.TypeBrew, "redis")))
+}
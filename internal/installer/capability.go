@@ -0,0 +1,89 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
+)
+
+// Capabilities declares which operations an installer supports besides
+// installing packages
+type Capabilities struct {
+	List      bool // reports installed packages
+	Uninstall bool // removes packages
+	Stream    bool // streams install output line by line
+	Versions  bool // reports installed versions
+	Batch     bool // installs several packages with one command
+	Upgrade   bool // reports outdated packages and upgrades them
+}
+
+// String lists the supported operations, e.g. "list, uninstall, versions"
+func (c Capabilities) String() string {
+	var supported []string
+	for _, op := range []struct {
+		name string
+		ok   bool
+	}{
+		{"list", c.List},
+		{"uninstall", c.Uninstall},
+		{"stream", c.Stream},
+		{"versions", c.Versions},
+		{"batch", c.Batch},
+		{"upgrade", c.Upgrade},
+	} {
+		if op.ok {
+			supported = append(supported, op.name)
+		}
+	}
+	if len(supported) == 0 {
+		return "install only"
+	}
+	return strings.Join(supported, ", ")
+}
+
+// StreamingInstaller is implemented by installers that can stream install
+// output; they declare Capabilities.Stream
+type StreamingInstaller interface {
+	InstallWithProgress(ctx context.Context, pkg brewfile.Package, onOutput func(line string)) error
+}
+
+// VersionLister is implemented by installers that can report installed
+// versions keyed by package ID; they declare Capabilities.Versions
+type VersionLister interface {
+	Versions() (map[string]string, error)
+}
+
+// OfflineLister is implemented by installers that read their packages from
+// disk, so they can list them and report versions without their command
+type OfflineLister interface {
+	CanList() bool
+}
+
+// CanList reports whether an installer can list its packages and versions
+// now: offline listers decide for themselves, the rest need their command
+func CanList(installer Installer) bool {
+	if lister, ok := installer.(OfflineLister); ok {
+		return lister.CanList()
+	}
+	return installer.IsAvailable()
+}
+
+// UnsupportedError is returned for an operation an installer cannot perform
+type UnsupportedError struct {
+	Type      brewfile.PackageType
+	Operation string // e.g. "uninstall"
+	Reason    string // what the user can do instead
+}
+
+func (e *UnsupportedError) Error() string {
+	msg := fmt.Sprintf("%s does not support %s", e.Type, e.Operation)
+	if e.Reason != "" {
+		msg += ": " + e.Reason
+	}
+	return msg
+}
+
+// ManualRemoval is the reason given for packages BrewSync cannot remove
+const ManualRemoval = "manual removal required"
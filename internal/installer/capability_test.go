@@ -0,0 +1,54 @@
+package installer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapabilities_String(t *testing.T) {
+	assert.Equal(t, "list, uninstall, stream, versions, batch, upgrade", NewBrewInstaller().Capabilities().String())
+	assert.Equal(t, "list, versions, upgrade", NewMasInstaller().Capabilities().String())
+	assert.Equal(t, "install only", Capabilities{}.String())
+}
+
+func TestManager_Capabilities(t *testing.T) {
+	m := NewManager()
+
+	for _, pkgType := range []brewfile.PackageType{
+		brewfile.TypeTap, brewfile.TypeBrew, brewfile.TypeCask, brewfile.TypeVSCode,
+		brewfile.TypeCursor, brewfile.TypeAntigravity, brewfile.TypeGo, brewfile.TypeMas,
+	} {
+		assert.True(t, m.Capabilities(pkgType).List, "%s can list", pkgType)
+	}
+	assert.False(t, m.Capabilities(brewfile.TypeMas).Uninstall)
+	assert.Equal(t, Capabilities{}, m.Capabilities("unknown"))
+
+	names := make([]string, 0)
+	for _, src := range m.sources() {
+		names = append(names, src.name)
+	}
+	assert.Contains(t, names, "antigravity", "antigravity extensions are listed")
+}
+
+func TestManager_UninstallUnsupported(t *testing.T) {
+	m := NewManager()
+	err := m.Uninstall(context.Background(), brewfile.NewPackage(brewfile.TypeMas, "497799835"))
+
+	assert.Equal(t, FailureUnsupported, Classify(err))
+	assert.Equal(t, 0, Attempts(err), "nothing was attempted")
+	assert.Contains(t, err.Error(), ManualRemoval)
+}
+
+func TestBrewInstaller_UnknownType(t *testing.T) {
+	b := NewBrewInstaller()
+	pkg := brewfile.NewPackage(brewfile.TypeVSCode, "golang.go")
+
+	var unsupported *UnsupportedError
+	require.ErrorAs(t, b.Install(context.Background(), pkg), &unsupported)
+	require.ErrorAs(t, b.Uninstall(context.Background(), pkg), &unsupported)
+	require.ErrorAs(t, b.InstallBatch(context.Background(), brewfile.Packages{pkg}, nil), &unsupported)
+}
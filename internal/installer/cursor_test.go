@@ -1,6 +1,7 @@
 package installer
 
 import (
+	"context"
 	"testing"
 
 	"github.com/andrew-sameh/brewsync/internal/brewfile"
@@ -26,7 +27,7 @@ func TestCursorInstaller_List(t *testing.T) {
 		t.Skip("Cursor CLI not available")
 	}
 
-	extensions, err := inst.List()
+	extensions, err := inst.List(context.Background())
 	assert.NoError(t, err)
 
 	// Verify all returned packages are cursor type
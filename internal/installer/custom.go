@@ -0,0 +1,289 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
+	"github.com/andrew-sameh/brewsync/internal/exec"
+)
+
+// CustomInstaller runs the command templates of a custom package type
+// declared in config.yaml. Commands are split into arguments and run
+// directly, without a shell.
+type CustomInstaller struct {
+	def    brewfile.CustomType
+	runner exec.Runner
+}
+
+// NewCustomInstaller creates an installer for a custom package type
+func NewCustomInstaller(def brewfile.CustomType) *CustomInstaller {
+	return &CustomInstaller{
+		def:    def,
+		runner: exec.Default,
+	}
+}
+
+// Type returns the package type the installer manages
+func (c *CustomInstaller) Type() brewfile.PackageType {
+	return brewfile.PackageType(c.def.Name)
+}
+
+// List returns the packages printed by the type's list command
+func (c *CustomInstaller) List(ctx context.Context) (brewfile.Packages, error) {
+	args, err := expandCommand(c.def.List, "")
+	if err != nil {
+		return nil, err
+	}
+	lines, err := c.runner.RunLinesContext(ctx, args[0], args[1:]...)
+	if err != nil {
+		return nil, err
+	}
+
+	var pattern *regexp.Regexp
+	if c.def.Pattern != "" {
+		pattern = regexp.MustCompile(c.def.Pattern)
+	}
+
+	var packages brewfile.Packages
+	for _, name := range parseList(lines, pattern) {
+		packages = append(packages, brewfile.NewPackage(c.Type(), name))
+	}
+	return packages, nil
+}
+
+// Versions returns the installed version of every package, keyed by package
+// ID. Types without a version command report no versions.
+func (c *CustomInstaller) Versions() (map[string]string, error) {
+	versions := make(map[string]string)
+	if c.def.Version == "" {
+		return versions, nil
+	}
+
+	var pattern *regexp.Regexp
+	if c.def.VersionPattern != "" {
+		pattern = regexp.MustCompile(c.def.VersionPattern)
+	}
+	prefix := c.def.Name + ":"
+
+	// A per-package template is run once for each installed package
+	if strings.Contains(c.def.Version, "{name}") {
+		pkgs, err := c.List(context.Background())
+		if err != nil {
+			return nil, err
+		}
+		for _, pkg := range pkgs {
+			args, err := expandCommand(c.def.Version, pkg.Name)
+			if err != nil {
+				return nil, err
+			}
+			lines, err := c.runner.RunLines(args[0], args[1:]...)
+			if err != nil {
+				continue
+			}
+			if version := parseVersion(lines, pattern); version != "" {
+				versions[prefix+pkg.Name] = version
+			}
+		}
+		return versions, nil
+	}
+
+	args, err := expandCommand(c.def.Version, "")
+	if err != nil {
+		return nil, err
+	}
+	lines, err := c.runner.RunLines(args[0], args[1:]...)
+	if err != nil {
+		return nil, err
+	}
+	for name, version := range parseVersions(lines, pattern) {
+		versions[prefix+name] = version
+	}
+	return versions, nil
+}
+
+// Install runs the type's install command for a package
+func (c *CustomInstaller) Install(ctx context.Context, pkg brewfile.Package) error {
+	return c.run(ctx, c.def.Install, pkg)
+}
+
+// Uninstall runs the type's uninstall command for a package
+func (c *CustomInstaller) Uninstall(ctx context.Context, pkg brewfile.Package) error {
+	return c.run(ctx, c.def.Uninstall, pkg)
+}
+
+// IsAvailable checks if the program of the list command is installed
+func (c *CustomInstaller) IsAvailable() bool {
+	args, err := splitCommand(c.def.List)
+	if err != nil || len(args) == 0 {
+		return false
+	}
+	return c.runner.Exists(args[0])
+}
+
+// Capabilities reports that custom types list and remove packages, and
+// report versions when they declare a version command
+func (c *CustomInstaller) Capabilities() Capabilities {
+	return Capabilities{List: true, Uninstall: true, Versions: c.def.Version != ""}
+}
+
+// run expands a command template for a package and runs it
+func (c *CustomInstaller) run(ctx context.Context, template string, pkg brewfile.Package) error {
+	args, err := expandCommand(template, pkg.Name)
+	if err != nil {
+		return err
+	}
+	_, err = c.runner.RunContext(ctx, args[0], args[1:]...)
+	return err
+}
+
+// expandCommand splits a command template into arguments and replaces
+// {name} in each of them. The name is substituted after splitting, so it
+// always stays a single argument.
+func expandCommand(template, name string) ([]string, error) {
+	args, err := splitCommand(template)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("empty command")
+	}
+	for i, arg := range args {
+		args[i] = strings.ReplaceAll(arg, "{name}", name)
+	}
+	return args, nil
+}
+
+// splitCommand splits a command line into arguments. Single and double
+// quotes group words and a backslash escapes the next character outside
+// single quotes.
+func splitCommand(command string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		inWord  bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range command {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inWord {
+				args = append(args, current.String())
+				current.Reset()
+				inWord = false
+			}
+		default:
+			current.WriteRune(r)
+			inWord = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape in command %q", command)
+	}
+	if inWord {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// parseList extracts package names from list output. A line matching the
+// pattern yields its "name" group, its first group or the whole match;
+// without a pattern every non-empty line is a name.
+func parseList(lines []string, pattern *regexp.Regexp) []string {
+	var names []string
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if pattern == nil {
+			names = append(names, line)
+			continue
+		}
+		matches := pattern.FindStringSubmatch(line)
+		if matches == nil {
+			continue
+		}
+		name := matches[0]
+		if pattern.NumSubexp() > 0 {
+			name = submatch(pattern, matches, "name", 1)
+		}
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// parseVersions extracts name and version pairs from version output using
+// the pattern's "name" and "version" groups
+func parseVersions(lines []string, pattern *regexp.Regexp) map[string]string {
+	versions := make(map[string]string)
+	if pattern == nil {
+		return versions
+	}
+	for _, line := range lines {
+		matches := pattern.FindStringSubmatch(strings.TrimSpace(line))
+		if matches == nil {
+			continue
+		}
+		name := submatch(pattern, matches, "name", 1)
+		version := submatch(pattern, matches, "version", 2)
+		if name != "" && version != "" {
+			versions[name] = version
+		}
+	}
+	return versions
+}
+
+// parseVersion extracts the version of a single package: the pattern's
+// "version" group (or first group, or the whole match) on the first matching
+// line, or the first non-empty line without a pattern
+func parseVersion(lines []string, pattern *regexp.Regexp) string {
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if pattern == nil {
+			return line
+		}
+		if matches := pattern.FindStringSubmatch(line); matches != nil {
+			if pattern.NumSubexp() == 0 {
+				return matches[0]
+			}
+			return submatch(pattern, matches, "version", 1)
+		}
+	}
+	return ""
+}
+
+// submatch returns a named group of a match, falling back to the group at
+// index, or "" when the pattern has neither
+func submatch(pattern *regexp.Regexp, matches []string, name string, index int) string {
+	if i := pattern.SubexpIndex(name); i > 0 {
+		return matches[i]
+	}
+	if index < len(matches) {
+		return matches[index]
+	}
+	return ""
+}
@@ -0,0 +1,119 @@
+package installer
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		pkg      string
+		want     []string
+		wantErr  bool
+	}{
+		{"plain", "npm install -g {name}", "typescript", []string{"npm", "install", "-g", "typescript"}, false},
+		{"name inside argument", "pipx install {name}==1.0", "black", []string{"pipx", "install", "black==1.0"}, false},
+		{"name with spaces stays one argument", "tool add {name}", "a b", []string{"tool", "add", "a b"}, false},
+		{"double quotes", `sh -c "echo {name}"`, "x", []string{"sh", "-c", "echo x"}, false},
+		{"single quotes keep backslashes", `grep 'a\b' file`, "", []string{"grep", `a\b`, "file"}, false},
+		{"escaped space", `ls my\ dir`, "", []string{"ls", "my dir"}, false},
+		{"empty quoted argument", `cmd ""`, "", []string{"cmd", ""}, false},
+		{"unterminated quote", `cmd "oops`, "", nil, true},
+		{"empty", "   ", "", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandCommand(tt.template, tt.pkg)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseList(t *testing.T) {
+	lines := []string{
+		"/usr/local/lib",
+		"/usr/local/lib/node_modules/typescript",
+		"",
+		"/usr/local/lib/node_modules/@vue/cli",
+	}
+
+	t.Run("named group", func(t *testing.T) {
+		pattern := regexp.MustCompile(`node_modules/(?P<name>.+)$`)
+		assert.Equal(t, []string{"typescript", "@vue/cli"}, parseList(lines, pattern))
+	})
+
+	t.Run("first group", func(t *testing.T) {
+		pattern := regexp.MustCompile(`node_modules/(.+)$`)
+		assert.Equal(t, []string{"typescript", "@vue/cli"}, parseList(lines, pattern))
+	})
+
+	t.Run("whole match", func(t *testing.T) {
+		pattern := regexp.MustCompile(`[^/]+$`)
+		assert.Equal(t, []string{"lib", "typescript", "cli"}, parseList(lines, pattern))
+	})
+
+	t.Run("no pattern", func(t *testing.T) {
+		assert.Equal(t, []string{"black", "ruff"}, parseList([]string{" black ", "", "ruff"}, nil))
+	})
+}
+
+func TestParseVersions(t *testing.T) {
+	lines := []string{
+		"package black 24.2.0, installed using Python 3.12.2",
+		"package ruff 0.3.0, installed using Python 3.12.2",
+		"    - black",
+	}
+
+	named := regexp.MustCompile(`^package (?P<name>\S+) (?P<version>\S+),`)
+	assert.Equal(t, map[string]string{"black": "24.2.0", "ruff": "0.3.0"}, parseVersions(lines, named))
+
+	positional := regexp.MustCompile(`^package (\S+) (\S+),`)
+	assert.Equal(t, map[string]string{"black": "24.2.0", "ruff": "0.3.0"}, parseVersions(lines, positional))
+
+	assert.Empty(t, parseVersions(lines, nil))
+}
+
+func TestParseVersion(t *testing.T) {
+	lines := []string{"", "typescript@5.4.2", "extra"}
+
+	assert.Equal(t, "typescript@5.4.2", parseVersion(lines, nil))
+	assert.Equal(t, "5.4.2", parseVersion(lines, regexp.MustCompile(`@(?P<version>[\d.]+)$`)))
+	assert.Equal(t, "5.4.2", parseVersion(lines, regexp.MustCompile(`[\d.]+$`)))
+	assert.Empty(t, parseVersion(lines, regexp.MustCompile(`^v(\d+)`)))
+}
+
+func TestManager_CustomType(t *testing.T) {
+	require.NoError(t, brewfile.RegisterType(brewfile.CustomType{
+		Name:      "custom_test_tool",
+		List:      "brewsync-missing-tool list",
+		Install:   "brewsync-missing-tool install {name}",
+		Uninstall: "brewsync-missing-tool uninstall {name}",
+	}))
+	defer brewfile.UnregisterType("custom_test_tool")
+
+	m := NewManager()
+	pkg := brewfile.NewPackage("custom_test_tool", "thing")
+
+	inst, err := m.getInstaller(pkg.Type)
+	require.NoError(t, err)
+	assert.IsType(t, &CustomInstaller{}, inst)
+	assert.False(t, m.IsAvailable(pkg.Type))
+	assert.Contains(t, m.AvailableInstallers(), "custom_test_tool")
+	assert.Equal(t, "custom_test_tool", Backend(pkg.Type))
+
+	err = m.Install(context.Background(), pkg)
+	assert.Equal(t, FailureUnavailable, Classify(err))
+}
@@ -0,0 +1,292 @@
+package installer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
+	"github.com/andrew-sameh/brewsync/internal/exec"
+)
+
+// EditorInstaller handles the extensions of a VS Code-family editor through
+// its CLI. When the editor has an extensions directory, installed
+// extensions are read from it instead, which is faster and also reveals
+// pre-release installs.
+type EditorInstaller struct {
+	runner  exec.Runner
+	editor  brewfile.Editor
+	command string
+	gallery extensionGallery
+}
+
+// NewEditorInstaller creates an installer for an editor's extensions
+func NewEditorInstaller(editor brewfile.Editor) *EditorInstaller {
+	var gallery extensionGallery = newOpenVSXGallery()
+	if editor.Gallery == brewfile.GalleryMarketplace {
+		gallery = newMarketplaceGallery()
+	}
+	return &EditorInstaller{
+		runner:  exec.Default,
+		editor:  editor,
+		command: editor.Command,
+		gallery: gallery,
+	}
+}
+
+// NewVSCodeInstaller creates a new VSCode installer
+func NewVSCodeInstaller() *EditorInstaller {
+	editor, _ := brewfile.LookupEditor(brewfile.TypeVSCode)
+	return NewEditorInstaller(editor)
+}
+
+// NewCursorInstaller creates a new Cursor installer
+func NewCursorInstaller() *EditorInstaller {
+	editor, _ := brewfile.LookupEditor(brewfile.TypeCursor)
+	return NewEditorInstaller(editor)
+}
+
+// NewAntigravityInstaller creates a new Antigravity installer
+func NewAntigravityInstaller() *EditorInstaller {
+	editor, _ := brewfile.LookupEditor(brewfile.TypeAntigravity)
+	return NewEditorInstaller(editor)
+}
+
+// EditorInstallers returns an installer for every built-in and configured editor
+func EditorInstallers() []*EditorInstaller {
+	var installers []*EditorInstaller
+	for _, editor := range brewfile.Editors() {
+		installers = append(installers, NewEditorInstaller(editor))
+	}
+	return installers
+}
+
+// Type returns the package type of the editor's extensions
+func (e *EditorInstaller) Type() brewfile.PackageType {
+	return e.editor.Type()
+}
+
+// Name returns the editor's display name
+func (e *EditorInstaller) Name() string {
+	return e.editor.DisplayName()
+}
+
+// List returns all installed extensions; extensions installed from the
+// pre-release channel carry the pre_release option
+func (e *EditorInstaller) List(ctx context.Context) (brewfile.Packages, error) {
+	if exts, ok := e.readExtensionsDir(); ok {
+		var packages brewfile.Packages
+		for _, ext := range exts {
+			pkg := brewfile.NewPackage(e.Type(), ext.ID)
+			if ext.PreRelease {
+				pkg = pkg.WithValue(brewfile.PreReleaseOption, brewfile.BoolValue(true))
+			}
+			packages = append(packages, pkg)
+		}
+		return packages, nil
+	}
+
+	lines, err := e.runner.RunLinesContext(ctx, e.command, "--list-extensions")
+	if err != nil {
+		return nil, err
+	}
+
+	var packages brewfile.Packages
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line != "" {
+			packages = append(packages, brewfile.NewPackage(e.Type(), line))
+		}
+	}
+	return packages, nil
+}
+
+// Versions returns the installed version of every extension, keyed by package ID
+func (e *EditorInstaller) Versions() (map[string]string, error) {
+	if exts, ok := e.readExtensionsDir(); ok {
+		versions := make(map[string]string)
+		for _, ext := range exts {
+			versions[string(e.Type())+":"+ext.ID] = ext.Version
+		}
+		return versions, nil
+	}
+
+	lines, err := e.runner.RunLines(e.command, "--list-extensions", "--show-versions")
+	if err != nil {
+		return nil, err
+	}
+	return parseExtensionVersions(lines, e.Type()), nil
+}
+
+// Install installs an extension, from the pre-release channel when the
+// package asks for it
+func (e *EditorInstaller) Install(ctx context.Context, pkg brewfile.Package) error {
+	args := []string{"--install-extension", pkg.Name}
+	if preRelease(pkg) {
+		args = append(args, "--pre-release")
+	}
+	_, err := e.runner.RunContext(ctx, e.command, args...)
+	return err
+}
+
+// InstallBatch installs several extensions with one CLI call. The
+// --pre-release flag applies to the whole call, so pre-release extensions
+// are installed with a second one.
+func (e *EditorInstaller) InstallBatch(ctx context.Context, pkgs brewfile.Packages, onOutput func(line string)) error {
+	var stable, pre brewfile.Packages
+	for _, pkg := range pkgs {
+		if preRelease(pkg) {
+			pre = append(pre, pkg)
+		} else {
+			stable = append(stable, pkg)
+		}
+	}
+	return errors.Join(
+		installExtensions(ctx, e.runner, e.command, stable, nil, onOutput),
+		installExtensions(ctx, e.runner, e.command, pre, []string{"--pre-release"}, onOutput),
+	)
+}
+
+// Uninstall removes an extension
+func (e *EditorInstaller) Uninstall(ctx context.Context, pkg brewfile.Package) error {
+	_, err := e.runner.RunContext(ctx, e.command, "--uninstall-extension", pkg.Name)
+	return err
+}
+
+// IsAvailable checks if the editor's CLI is available
+func (e *EditorInstaller) IsAvailable() bool {
+	return e.runner.Exists(e.command)
+}
+
+// Capabilities reports that extensions support everything except streaming
+func (e *EditorInstaller) Capabilities() Capabilities {
+	return Capabilities{List: true, Uninstall: true, Versions: true, Batch: true, Upgrade: true}
+}
+
+// Outdated returns the extensions with a newer version in the editor's gallery
+func (e *EditorInstaller) Outdated(ctx context.Context) ([]Outdated, error) {
+	versions, err := e.Versions()
+	if err != nil {
+		return nil, err
+	}
+	return outdatedExtensions(ctx, versions, e.Type(), e.gallery)
+}
+
+// Upgrade installs the latest version of an extension
+func (e *EditorInstaller) Upgrade(ctx context.Context, pkg brewfile.Package) error {
+	return upgradeExtension(ctx, e.runner, e.command, pkg)
+}
+
+// preRelease reports whether a package asks for the pre-release channel
+func preRelease(pkg brewfile.Package) bool {
+	v, ok := pkg.Options[brewfile.PreReleaseOption]
+	return ok && v.Kind == brewfile.KindBool && v.Bool
+}
+
+// installExtensions runs an editor CLI with one --install-extension flag
+// per package, followed by extra flags
+func installExtensions(ctx context.Context, runner exec.Runner, command string, pkgs brewfile.Packages, extra []string, onOutput func(line string)) error {
+	if len(pkgs) == 0 {
+		return nil
+	}
+	args := make([]string, 0, 2*len(pkgs)+len(extra))
+	for _, pkg := range pkgs {
+		args = append(args, "--install-extension", pkg.Name)
+	}
+	args = append(args, extra...)
+	if onOutput == nil {
+		_, err := runner.RunContext(ctx, command, args...)
+		return err
+	}
+	return runner.RunWithOutputContext(ctx, command, args, onOutput)
+}
+
+// parseExtensionVersions parses "publisher.extension@1.2.3" lines
+func parseExtensionVersions(lines []string, pkgType brewfile.PackageType) map[string]string {
+	versions := make(map[string]string)
+	for _, line := range lines {
+		id, version, ok := strings.Cut(strings.TrimSpace(line), "@")
+		if !ok || id == "" {
+			continue
+		}
+		versions[string(pkgType)+":"+id] = version
+	}
+	return versions
+}
+
+// installedExtension is an extension found in an editor's extensions directory
+type installedExtension struct {
+	ID         string
+	Version    string
+	PreRelease bool
+}
+
+// readExtensionsDir reads the installed extensions from the editor's
+// extensions directory, reporting false when it has none or it cannot be read
+func (e *EditorInstaller) readExtensionsDir() ([]installedExtension, bool) {
+	dir := expandHome(e.editor.ExtensionsDir)
+	if dir == "" {
+		return nil, false
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "extensions.json"))
+	if err != nil {
+		return nil, false
+	}
+	// Extensions removed but not yet cleaned up are listed in .obsolete
+	obsolete := make(map[string]bool)
+	if raw, err := os.ReadFile(filepath.Join(dir, ".obsolete")); err == nil {
+		_ = json.Unmarshal(raw, &obsolete)
+	}
+	exts, err := parseExtensionsJSON(data, obsolete)
+	if err != nil {
+		return nil, false
+	}
+	return exts, true
+}
+
+// parseExtensionsJSON parses the extensions.json manifest kept in an
+// editor's extensions directory, skipping obsolete entries
+func parseExtensionsJSON(data []byte, obsolete map[string]bool) ([]installedExtension, error) {
+	var entries []struct {
+		Identifier struct {
+			ID string `json:"id"`
+		} `json:"identifier"`
+		Version          string `json:"version"`
+		RelativeLocation string `json:"relativeLocation"`
+		Metadata         struct {
+			PreRelease bool `json:"preRelease"`
+		} `json:"metadata"`
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	var exts []installedExtension
+	for _, entry := range entries {
+		id := entry.Identifier.ID
+		if id == "" || obsolete[entry.RelativeLocation] || seen[strings.ToLower(id)] {
+			continue
+		}
+		seen[strings.ToLower(id)] = true
+		exts = append(exts, installedExtension{ID: id, Version: entry.Version, PreRelease: entry.Metadata.PreRelease})
+	}
+	sort.Slice(exts, func(i, j int) bool { return exts[i].ID < exts[j].ID })
+	return exts, nil
+}
+
+// expandHome replaces a leading ~ with the home directory
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, strings.TrimPrefix(path, "~"))
+}
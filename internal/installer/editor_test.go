@@ -0,0 +1,88 @@
+package installer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testExtensionsJSON = `[
+  {"identifier":{"id":"golang.go"},"version":"0.41.0","relativeLocation":"golang.go-0.41.0","metadata":{"preRelease":false}},
+  {"identifier":{"id":"ms-python.python"},"version":"2024.1.10291011","relativeLocation":"ms-python.python-2024.1.10291011","metadata":{"preRelease":true}},
+  {"identifier":{"id":"old.removed"},"version":"1.0.0","relativeLocation":"old.removed-1.0.0"}
+]`
+
+func TestParseExtensionsJSON(t *testing.T) {
+	exts, err := parseExtensionsJSON([]byte(testExtensionsJSON), map[string]bool{"old.removed-1.0.0": true})
+	require.NoError(t, err)
+	assert.Equal(t, []installedExtension{
+		{ID: "golang.go", Version: "0.41.0"},
+		{ID: "ms-python.python", Version: "2024.1.10291011", PreRelease: true},
+	}, exts)
+
+	_, err = parseExtensionsJSON([]byte("not json"), nil)
+	assert.Error(t, err)
+}
+
+func TestEditorInstaller_ExtensionsDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "extensions.json"), []byte(testExtensionsJSON), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".obsolete"), []byte(`{"old.removed-1.0.0":true}`), 0644))
+
+	// The CLI does not exist, so everything must come from the directory
+	inst := NewEditorInstaller(brewfile.Editor{Name: "Test", Command: "brewsync-missing-editor", Keyword: "vscodium", ExtensionsDir: dir})
+
+	pkgs, err := inst.List(context.Background())
+	require.NoError(t, err)
+	require.Len(t, pkgs, 2)
+	assert.Equal(t, "vscodium:golang.go", pkgs[0].ID())
+	assert.False(t, preRelease(pkgs[0]))
+	assert.True(t, preRelease(pkgs[1]), "pre-release installs are captured")
+
+	versions, err := inst.Versions()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"vscodium:golang.go":        "0.41.0",
+		"vscodium:ms-python.python": "2024.1.10291011",
+	}, versions)
+
+	// Without a readable directory the CLI is used
+	inst = NewEditorInstaller(brewfile.Editor{Command: "brewsync-missing-editor", Keyword: "vscodium", ExtensionsDir: filepath.Join(dir, "missing")})
+	_, err = inst.List(context.Background())
+	assert.Error(t, err)
+}
+
+func TestNewEditorInstaller_Gallery(t *testing.T) {
+	assert.IsType(t, &marketplaceGallery{}, NewVSCodeInstaller().gallery)
+	assert.IsType(t, &openVSXGallery{}, NewCursorInstaller().gallery)
+	assert.Equal(t, "agy", NewAntigravityInstaller().command)
+	assert.Equal(t, "VSCode", NewVSCodeInstaller().Name())
+}
+
+func TestManager_ConfiguredEditor(t *testing.T) {
+	require.NoError(t, brewfile.RegisterEditor(brewfile.Editor{Name: "Windsurf", Command: "brewsync-missing-windsurf", Keyword: "windsurf"}))
+	defer brewfile.UnregisterType("windsurf")
+
+	m := NewManager()
+	pkgType := brewfile.PackageType("windsurf")
+
+	inst, err := m.getInstaller(pkgType)
+	require.NoError(t, err)
+	assert.IsType(t, &EditorInstaller{}, inst)
+	assert.True(t, Batchable(pkgType))
+	assert.Contains(t, m.AvailableInstallers(), "windsurf")
+	assert.True(t, m.Capabilities(pkgType).Batch)
+
+	for _, custom := range CustomInstallers() {
+		assert.NotEqual(t, pkgType, custom.Type(), "editors are not custom command types")
+	}
+	assert.Equal(t, pkgType, EditorInstallers()[3].Type())
+
+	err = m.Install(context.Background(), brewfile.NewPackage(pkgType, "golang.go"))
+	assert.Equal(t, FailureUnavailable, Classify(err))
+}
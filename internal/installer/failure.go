@@ -0,0 +1,201 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
+)
+
+// FailureKind classifies why a package operation failed
+type FailureKind string
+
+const (
+	FailureNetwork          FailureKind = "network"           // download or connection failure
+	FailureNotFound         FailureKind = "not-found"         // no such formula, cask, extension, app or module
+	FailureAlreadyInstalled FailureKind = "already-installed" // something is already installed in the way
+	FailureNeedsSudo        FailureKind = "needs-sudo"        // needs a password or elevated permissions
+	FailureChecksum         FailureKind = "checksum"          // downloaded file does not match its checksum
+	FailureTapMissing       FailureKind = "tap-missing"       // formula or cask from a tap that is not tapped
+	FailureConflict         FailureKind = "conflict"          // conflicts with an installed formula or cask
+	FailureMasSignIn        FailureKind = "mas-not-signed-in" // App Store account not signed in
+	FailureTimeout          FailureKind = "timeout"           // command exceeded its time limit
+	FailureInterrupted      FailureKind = "interrupted"       // stopped by the user
+	FailureSkipped          FailureKind = "skipped"           // not attempted because a dependency failed
+	FailureUnavailable      FailureKind = "unavailable"       // installer command not installed
+	FailureUnsupported      FailureKind = "unsupported"       // installer cannot perform the operation
+	FailureUnknown          FailureKind = "unknown"
+)
+
+// Transient reports whether retrying the operation may succeed
+func (k FailureKind) Transient() bool {
+	return k == FailureNetwork
+}
+
+// failurePatterns maps lowercase output fragments to failure kinds. Order
+// matters: more specific patterns come first (a formula missing because its
+// tap is not tapped also reports "no available formula", and a 404 from
+// curl also matches its generic "curl: (" prefix).
+var failurePatterns = []struct {
+	kind     FailureKind
+	patterns []string
+}{
+	{FailureMasSignIn, []string{
+		"not signed in", "sign in to the app store", "mas signin", "not logged in",
+	}},
+	{FailureTapMissing, []string{
+		"please tap it", "tap it and then try again", "no such tap", "invalid tap name", "tap does not exist",
+	}},
+	{FailureChecksum, []string{
+		"sha256 mismatch", "checksum mismatch", "checksum does not match", "security error",
+	}},
+	{FailureNotFound, []string{
+		"returned error: 404", "404 not found",
+	}},
+	{FailureNetwork, []string{
+		"could not resolve host", "failed to connect", "connection timed out", "connection reset",
+		"connection refused", "network is unreachable", "operation timed out", "download failed",
+		"failed to download", "temporary failure in name resolution", "i/o timeout",
+		"tls handshake timeout", "ssl_error", "curl: (", "502 bad gateway", "503 service unavailable",
+		"504 gateway", "no such host", "unexpected eof", "econnreset", "etimedout", "getaddrinfo",
+	}},
+	{FailureNeedsSudo, []string{
+		"sudo: a terminal is required", "sudo: a password is required", "sudo: no tty present",
+		"password:", "permission denied", "operation not permitted", "must be run as root",
+		"administrator privileges",
+	}},
+	{FailureConflict, []string{
+		"conflicts with", "conflicting formulae", "conflicting cask", "could not symlink", "already exists",
+	}},
+	{FailureAlreadyInstalled, []string{
+		"is already installed", "already an app at", "already a binary at",
+	}},
+	{FailureUnavailable, []string{
+		"executable file not found", "installer not available",
+	}},
+	{FailureNotFound, []string{
+		"no available formula", "no available cask", "no formulae or casks found", "no formulae found",
+		"no casks found", "is unavailable", "cask with this name", "not found in gallery",
+		"no apps found", "app not found", "no results found", "unknown app",
+		"cannot find module", "unknown revision", "404 not found", "not found",
+	}},
+}
+
+// Classify determines the failure kind of an installer error from its
+// message, which includes the command's stderr
+func Classify(err error) FailureKind {
+	if err == nil {
+		return ""
+	}
+	var installErr *InstallError
+	if errors.As(err, &installErr) {
+		return installErr.Kind
+	}
+	var unsupported *UnsupportedError
+	if errors.As(err, &unsupported) {
+		return FailureUnsupported
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		return FailureInterrupted
+	case errors.Is(err, context.DeadlineExceeded):
+		return FailureTimeout
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, group := range failurePatterns {
+		for _, pattern := range group.patterns {
+			if strings.Contains(msg, pattern) {
+				return group.kind
+			}
+		}
+	}
+	return FailureUnknown
+}
+
+// InstallError is a classified package operation failure
+type InstallError struct {
+	Package  brewfile.Package
+	Kind     FailureKind
+	Attempts int // number of times the operation was tried
+	Err      error
+}
+
+func (e *InstallError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *InstallError) Unwrap() error {
+	return e.Err
+}
+
+// Attempts returns how many times the operation behind err was tried: 0 for
+// skipped packages and 1 for errors that were not retried
+func Attempts(err error) int {
+	var installErr *InstallError
+	if errors.As(err, &installErr) {
+		return installErr.Attempts
+	}
+	return 1
+}
+
+// RetryPolicy controls how transient failures are retried
+type RetryPolicy struct {
+	Attempts   int           // total tries, including the first
+	Backoff    time.Duration // delay before the first retry, doubled for each further retry
+	MaxBackoff time.Duration
+}
+
+// DefaultRetry retries network failures twice, after 2s and 4s
+var DefaultRetry = RetryPolicy{
+	Attempts:   3,
+	Backoff:    2 * time.Second,
+	MaxBackoff: 30 * time.Second,
+}
+
+// Do runs op until it succeeds, fails with a non-transient error, runs out
+// of attempts or ctx is cancelled. The returned error is an *InstallError.
+// onRetry, when set, is called before waiting for each retry.
+func (p RetryPolicy) Do(
+	ctx context.Context,
+	pkg brewfile.Package,
+	op func() error,
+	onRetry func(kind FailureKind, attempt int, delay time.Duration),
+) error {
+	delay := p.Backoff
+	for attempt := 1; ; attempt++ {
+		err := op()
+		if err == nil {
+			return nil
+		}
+
+		kind := Classify(err)
+		if !kind.Transient() || attempt >= p.Attempts || ctx.Err() != nil {
+			if ctx.Err() != nil {
+				kind = Classify(ctx.Err())
+			}
+			return &InstallError{Package: pkg, Kind: kind, Attempts: attempt, Err: err}
+		}
+
+		if onRetry != nil {
+			onRetry(kind, attempt, delay)
+		}
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return &InstallError{Package: pkg, Kind: Classify(ctx.Err()), Attempts: attempt, Err: err}
+		}
+		delay *= 2
+		if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+			delay = p.MaxBackoff
+		}
+	}
+}
+
+// retryMessage describes a retry in installer output
+func retryMessage(kind FailureKind, attempt int, delay time.Duration) string {
+	return fmt.Sprintf("%s failure on attempt %d, retrying in %s", kind, attempt, delay)
+}
@@ -0,0 +1,125 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
+	"github.com/andrew-sameh/brewsync/internal/exec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want FailureKind
+	}{
+		{"nil", nil, ""},
+		{"curl failure", errors.New("brew install foo failed: exit status 1\ncurl: (6) Could not resolve host: ghcr.io"), FailureNetwork},
+		{"curl 404", errors.New("Error: Download failed\ncurl: (22) The requested URL returned error: 404"), FailureNotFound},
+		{"download failed", errors.New("Error: Download failed: https://example.com/foo.tar.gz"), FailureNetwork},
+		{"go proxy timeout", errors.New("go install failed: dial tcp: i/o timeout"), FailureNetwork},
+		{"no formula", errors.New("Error: No available formula with the name \"nope\"."), FailureNotFound},
+		{"no cask", errors.New("Error: Cask 'nope' is unavailable: No Cask with this name exists."), FailureNotFound},
+		{"extension", errors.New("Extension 'foo.bar' not found."), FailureNotFound},
+		{"tap missing", errors.New("Error: No available formula with the name \"user/tap/foo\".\nPlease tap it and then try again: brew tap user/tap"), FailureTapMissing},
+		{"already installed", errors.New("Warning: git 2.43.0 is already installed and up-to-date."), FailureAlreadyInstalled},
+		{"app in the way", errors.New("Error: It seems there is already an App at '/Applications/Slack.app'."), FailureAlreadyInstalled},
+		{"sudo", errors.New("sudo: a terminal is required to read the password"), FailureNeedsSudo},
+		{"checksum", errors.New("Error: SHA256 mismatch\nExpected: abc\nActual: def"), FailureChecksum},
+		{"conflict", errors.New("Error: Cannot install foo because conflicting formulae are installed."), FailureConflict},
+		{"link conflict", errors.New("Error: Could not symlink bin/node\nTarget /usr/local/bin/node already exists."), FailureConflict},
+		{"mas", errors.New("Error: Not signed in"), FailureMasSignIn},
+		{"missing command", errors.New("exec: \"code\": executable file not found in $PATH"), FailureUnavailable},
+		{"cancelled", fmt.Errorf("brew stopped: %w", context.Canceled), FailureInterrupted},
+		{"timeout", fmt.Errorf("go stopped: %w", context.DeadlineExceeded), FailureTimeout},
+		{"install error", &InstallError{Kind: FailureSkipped, Err: errors.New("skipped")}, FailureSkipped},
+		{"unknown", errors.New("exit status 1"), FailureUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Classify(tt.err))
+		})
+	}
+}
+
+func TestClassify_StreamedFailure(t *testing.T) {
+	// Streamed commands keep stderr in their error, so a real failure is
+	// classified the same whether or not its output was shown
+	script := "echo 'curl: (6) Could not resolve host: ghcr.io' >&2; exit 1"
+	runner := exec.NewRunner()
+
+	_, err := runner.RunContext(context.Background(), "sh", "-c", script)
+	assert.Equal(t, FailureNetwork, Classify(err))
+
+	err = runner.RunWithOutputContext(context.Background(), "sh", []string{"-c", script}, nil)
+	assert.Equal(t, FailureNetwork, Classify(err))
+}
+
+func TestRetryPolicy_Do(t *testing.T) {
+	pkg := brewfile.NewPackage(brewfile.TypeBrew, "git")
+	policy := RetryPolicy{Attempts: 3, Backoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond}
+	networkErr := errors.New("curl: (7) Failed to connect to ghcr.io")
+
+	t.Run("retries transient failures until success", func(t *testing.T) {
+		calls := 0
+		var retries []int
+		err := policy.Do(context.Background(), pkg, func() error {
+			calls++
+			if calls < 3 {
+				return networkErr
+			}
+			return nil
+		}, func(kind FailureKind, attempt int, delay time.Duration) {
+			assert.Equal(t, FailureNetwork, kind)
+			retries = append(retries, attempt)
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 3, calls)
+		assert.Equal(t, []int{1, 2}, retries)
+	})
+
+	t.Run("gives up after the last attempt", func(t *testing.T) {
+		calls := 0
+		err := policy.Do(context.Background(), pkg, func() error {
+			calls++
+			return networkErr
+		}, nil)
+
+		var installErr *InstallError
+		require.ErrorAs(t, err, &installErr)
+		assert.Equal(t, 3, calls)
+		assert.Equal(t, FailureNetwork, installErr.Kind)
+		assert.Equal(t, 3, Attempts(err))
+		assert.ErrorIs(t, err, networkErr)
+	})
+
+	t.Run("does not retry permanent failures", func(t *testing.T) {
+		calls := 0
+		err := policy.Do(context.Background(), pkg, func() error {
+			calls++
+			return errors.New("Error: No available formula with the name \"git\"")
+		}, nil)
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, FailureNotFound, Classify(err))
+		assert.Equal(t, 1, Attempts(err))
+	})
+
+	t.Run("stops waiting when cancelled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		slow := RetryPolicy{Attempts: 3, Backoff: time.Hour}
+		err := slow.Do(ctx, pkg, func() error {
+			return networkErr
+		}, func(FailureKind, int, time.Duration) {
+			cancel()
+		})
+		assert.Equal(t, FailureInterrupted, Classify(err))
+		assert.Equal(t, 1, Attempts(err))
+	})
+}
@@ -0,0 +1,250 @@
+package installer
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
+	"github.com/andrew-sameh/brewsync/internal/exec"
+)
+
+// Extension galleries queried for the latest published extension versions
+const (
+	MarketplaceURL = "https://marketplace.visualstudio.com/_apis/public/gallery/extensionquery"
+	OpenVSXURL     = "https://open-vsx.org/api"
+)
+
+// galleryTimeout bounds every gallery request
+const galleryTimeout = 30 * time.Second
+
+// extensionGallery looks up the latest published version of extensions,
+// keyed by lowercase extension ID. Extensions the gallery does not know are
+// left out.
+type extensionGallery interface {
+	Latest(ctx context.Context, ids []string) (map[string]string, error)
+}
+
+// marketplaceGallery queries the Visual Studio Marketplace used by VS Code
+type marketplaceGallery struct {
+	url    string
+	client *http.Client
+}
+
+// newMarketplaceGallery creates a client for the Visual Studio Marketplace
+func newMarketplaceGallery() *marketplaceGallery {
+	return &marketplaceGallery{url: MarketplaceURL, client: &http.Client{Timeout: galleryTimeout}}
+}
+
+// marketplaceFlags asks for all versions with their properties so
+// pre-releases can be told apart (IncludeVersions | IncludeVersionProperties)
+const marketplaceFlags = 0x1 | 0x10
+
+// Latest returns the newest stable version of each extension with a single query
+func (g *marketplaceGallery) Latest(ctx context.Context, ids []string) (map[string]string, error) {
+	latest := make(map[string]string)
+	if len(ids) == 0 {
+		return latest, nil
+	}
+
+	type criterion struct {
+		FilterType int    `json:"filterType"`
+		Value      string `json:"value"`
+	}
+	criteria := []criterion{{FilterType: 8, Value: "Microsoft.VisualStudio.Code"}}
+	for _, id := range ids {
+		criteria = append(criteria, criterion{FilterType: 7, Value: id})
+	}
+	body, err := json.Marshal(map[string]any{
+		"filters": []map[string]any{{"criteria": criteria, "pageSize": len(ids)}},
+		"flags":   marketplaceFlags,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json;api-version=3.0-preview.1")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("marketplace query failed: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("marketplace query failed: %s", resp.Status)
+	}
+
+	var result struct {
+		Results []struct {
+			Extensions []struct {
+				Name      string `json:"extensionName"`
+				Publisher struct {
+					Name string `json:"publisherName"`
+				} `json:"publisher"`
+				Versions []struct {
+					Version    string `json:"version"`
+					Properties []struct {
+						Key   string `json:"key"`
+						Value string `json:"value"`
+					} `json:"properties"`
+				} `json:"versions"`
+			} `json:"extensions"`
+		} `json:"results"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse marketplace response: %w", err)
+	}
+
+	for _, r := range result.Results {
+		for _, ext := range r.Extensions {
+			id := strings.ToLower(ext.Publisher.Name + "." + ext.Name)
+			// Versions are listed newest first
+			for _, v := range ext.Versions {
+				prerelease := false
+				for _, p := range v.Properties {
+					if p.Key == "Microsoft.VisualStudio.Code.PreRelease" && p.Value == "true" {
+						prerelease = true
+					}
+				}
+				if !prerelease {
+					latest[id] = v.Version
+					break
+				}
+			}
+		}
+	}
+	return latest, nil
+}
+
+// openVSXGallery queries the Open VSX registry used by VS Code forks
+type openVSXGallery struct {
+	url    string
+	client *http.Client
+}
+
+// newOpenVSXGallery creates a client for the Open VSX registry
+func newOpenVSXGallery() *openVSXGallery {
+	return &openVSXGallery{url: OpenVSXURL, client: &http.Client{Timeout: galleryTimeout}}
+}
+
+// openVSXWorkers limits concurrent Open VSX requests
+const openVSXWorkers = 8
+
+// Latest looks up each extension with one request, a few at a time
+func (g *openVSXGallery) Latest(ctx context.Context, ids []string) (map[string]string, error) {
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		firstErr error
+		latest   = make(map[string]string)
+		slots    = make(chan struct{}, openVSXWorkers)
+	)
+	for _, id := range ids {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(id string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			version, err := g.latest(ctx, id)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err != nil && firstErr == nil:
+				firstErr = err
+			case version != "":
+				latest[strings.ToLower(id)] = version
+			}
+		}(id)
+	}
+	wg.Wait()
+	return latest, firstErr
+}
+
+// latest returns the latest version of one extension, or "" when Open VSX
+// does not publish it
+func (g *openVSXGallery) latest(ctx context.Context, id string) (string, error) {
+	namespace, name, ok := strings.Cut(id, ".")
+	if !ok {
+		return "", nil
+	}
+	endpoint := fmt.Sprintf("%s/%s/%s/latest", g.url, url.PathEscape(namespace), url.PathEscape(name))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("open vsx query for %s failed: %w", id, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("open vsx query for %s failed: %s", id, resp.Status)
+	}
+
+	var ext struct {
+		Version string `json:"version"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&ext); err != nil {
+		return "", fmt.Errorf("failed to parse open vsx response for %s: %w", id, err)
+	}
+	return ext.Version, nil
+}
+
+// outdatedExtensions compares installed extension versions, keyed by package
+// ID, with the latest versions published in a gallery. A gallery error only
+// fails the check when no version could be looked up at all.
+func outdatedExtensions(ctx context.Context, versions map[string]string, pkgType brewfile.PackageType, gallery extensionGallery) ([]Outdated, error) {
+	prefix := string(pkgType) + ":"
+	installed := make(map[string]string)
+	var ids []string
+	for id, version := range versions {
+		name := strings.TrimPrefix(id, prefix)
+		installed[name] = version
+		ids = append(ids, name)
+	}
+	sort.Strings(ids)
+
+	latest, err := gallery.Latest(ctx, ids)
+	if err != nil && len(latest) == 0 {
+		return nil, err
+	}
+
+	var outdated []Outdated
+	for _, id := range ids {
+		version := latest[strings.ToLower(id)]
+		if newer(installed[id], version) {
+			outdated = append(outdated, Outdated{
+				Package:   brewfile.NewPackage(pkgType, id),
+				Installed: installed[id],
+				Latest:    version,
+			})
+		}
+	}
+	return outdated, nil
+}
+
+// upgradeExtension reinstalls an extension at its latest version, staying
+// on the pre-release channel when the package asks for it
+func upgradeExtension(ctx context.Context, runner exec.Runner, command string, pkg brewfile.Package) error {
+	args := []string{"--install-extension", pkg.Name, "--force"}
+	if preRelease(pkg) {
+		args = append(args, "--pre-release")
+	}
+	_, err := runner.RunContext(ctx, command, args...)
+	return err
+}
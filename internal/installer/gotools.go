@@ -1,28 +1,134 @@
 package installer
 
 import (
+	"context"
+	"debug/buildinfo"
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"runtime/debug"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/andrew-sameh/brewsync/internal/brewfile"
 	"github.com/andrew-sameh/brewsync/internal/exec"
 )
 
+// GoVersionPolicy chooses which version of a Go tool is installed
+type GoVersionPolicy string
+
+const (
+	// GoVersionsLatest installs the latest release of every tool and dumps
+	// tools without a version
+	GoVersionsLatest GoVersionPolicy = "latest"
+	// GoVersionsPin dumps the version recorded in each binary and installs
+	// that version
+	GoVersionsPin GoVersionPolicy = "pin"
+)
+
+// GoVersionOption is the Brewfile option holding a Go tool's version
+const GoVersionOption = "version"
+
+// DefaultGoVersions is the version policy of new Go tools installers
+var DefaultGoVersions = GoVersionsLatest
+
+// ParseGoVersionPolicy parses a go_tools.versions setting; empty means latest
+func ParseGoVersionPolicy(s string) (GoVersionPolicy, error) {
+	switch GoVersionPolicy(s) {
+	case "", GoVersionsLatest:
+		return GoVersionsLatest, nil
+	case GoVersionsPin:
+		return GoVersionsPin, nil
+	}
+	return "", fmt.Errorf("unknown Go version policy %q (want %q or %q)", s, GoVersionsLatest, GoVersionsPin)
+}
+
 // GoToolsInstaller handles Go tools
 type GoToolsInstaller struct {
-	runner *exec.Runner
+	runner   exec.Runner
+	versions GoVersionPolicy
 }
 
 // NewGoToolsInstaller creates a new Go tools installer
 func NewGoToolsInstaller() *GoToolsInstaller {
 	return &GoToolsInstaller{
-		runner: exec.Default,
+		runner:   exec.Default,
+		versions: DefaultGoVersions,
+	}
+}
+
+// List returns all installed Go tools from GOPATH/bin or GOBIN. When
+// versions are pinned, each tool carries the version it was built from.
+func (g *GoToolsInstaller) List(ctx context.Context) (brewfile.Packages, error) {
+	packages, _, err := g.scan(ctx)
+	return packages, err
+}
+
+// Versions returns the module version of every installed Go tool, keyed by package ID
+func (g *GoToolsInstaller) Versions() (map[string]string, error) {
+	_, versions, err := g.scan(context.Background())
+	return versions, err
+}
+
+// scan inspects the binaries in the Go bin directory
+func (g *GoToolsInstaller) scan(ctx context.Context) (brewfile.Packages, map[string]string, error) {
+	versions := make(map[string]string)
+	binaries, err := g.binaries(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var packages brewfile.Packages
+	for _, bin := range binaries {
+		pkg := brewfile.NewPackage(brewfile.TypeGo, bin.Path)
+		if g.versions == GoVersionsPin && bin.released() {
+			pkg = pkg.WithValue(GoVersionOption, brewfile.StringValue(bin.Version))
+		}
+		packages = append(packages, pkg)
+		if version := bin.lockVersion(); version != "" {
+			versions[pkg.ID()] = version
+		}
+	}
+	return packages, versions, nil
+}
+
+// goBinary is a Go executable in the Go bin directory and the build
+// information embedded in it
+type goBinary struct {
+	Name     string            // file name
+	Path     string            // main package path, e.g. golang.org/x/tools/gopls
+	Module   string            // main module path, e.g. golang.org/x/tools/gopls
+	Version  string            // main module version, e.g. v0.15.0 or (devel)
+	Settings map[string]string // build settings, e.g. vcs.revision, CGO_ENABLED
+}
+
+// released reports whether the binary was built from a published module
+// version rather than a local checkout, reported as (devel)
+func (b goBinary) released() bool {
+	return strings.HasPrefix(b.Version, "v")
+}
+
+// lockVersion is the version recorded in lock files: the module version,
+// with the VCS revision for binaries built from a local checkout
+func (b goBinary) lockVersion() string {
+	revision := b.Settings["vcs.revision"]
+	if b.released() || revision == "" {
+		return b.Version
+	}
+	if len(revision) > 12 {
+		revision = revision[:12]
 	}
+	return b.Version + " " + revision
 }
 
-// List returns all installed Go tools from GOPATH/bin or GOBIN
-func (g *GoToolsInstaller) List() (brewfile.Packages, error) {
+// buildInfoWorkers limits how many binaries are read at once
+const buildInfoWorkers = 8
+
+// binaries returns the Go executables in the Go bin directory with their
+// build information, read in parallel. Other executables are skipped.
+func (g *GoToolsInstaller) binaries(ctx context.Context) ([]goBinary, error) {
 	binDir := g.getBinDir()
 	if binDir == "" {
 		return nil, nil
@@ -36,7 +142,7 @@ func (g *GoToolsInstaller) List() (brewfile.Packages, error) {
 		return nil, err
 	}
 
-	var packages brewfile.Packages
+	var names []string
 	for _, entry := range entries {
 		if entry.IsDir() {
 			continue
@@ -49,18 +155,65 @@ func (g *GoToolsInstaller) List() (brewfile.Packages, error) {
 		if info.Mode()&0111 == 0 {
 			continue
 		}
+		names = append(names, entry.Name())
+	}
 
-		name := entry.Name()
-		// Try to get the full module path from go version -m
-		modulePath := g.getModulePath(filepath.Join(binDir, name))
-		if modulePath != "" {
-			packages = append(packages, brewfile.NewPackage(brewfile.TypeGo, modulePath))
-		} else {
-			// Fallback to just the binary name (not ideal but better than nothing)
-			packages = append(packages, brewfile.NewPackage(brewfile.TypeGo, name))
+	var (
+		wg    sync.WaitGroup
+		found = make([]bool, len(names))
+		read  = make([]goBinary, len(names))
+		slots = make(chan struct{}, buildInfoWorkers)
+	)
+	for i, name := range names {
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(i int, name string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			if ctx.Err() != nil {
+				return
+			}
+			read[i], found[i] = readGoBinary(filepath.Join(binDir, name))
+		}(i, name)
+	}
+	wg.Wait()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var binaries []goBinary
+	for i := range names {
+		if found[i] {
+			binaries = append(binaries, read[i])
 		}
 	}
-	return packages, nil
+	return binaries, nil
+}
+
+// readGoBinary reads the build information embedded in an executable,
+// reporting false for files that are not Go binaries built with modules
+func readGoBinary(path string) (goBinary, bool) {
+	info, err := buildinfo.ReadFile(path)
+	if err != nil || info.Path == "" {
+		return goBinary{}, false
+	}
+	bin := fromBuildInfo(info)
+	bin.Name = filepath.Base(path)
+	return bin, true
+}
+
+// fromBuildInfo converts build information read from a binary
+func fromBuildInfo(info *debug.BuildInfo) goBinary {
+	bin := goBinary{
+		Path:     info.Path,
+		Module:   info.Main.Path,
+		Version:  info.Main.Version,
+		Settings: make(map[string]string, len(info.Settings)),
+	}
+	for _, s := range info.Settings {
+		bin.Settings[s.Key] = s.Value
+	}
+	return bin
 }
 
 // getBinDir returns the Go bin directory
@@ -84,54 +237,158 @@ func (g *GoToolsInstaller) getBinDir() string {
 	return filepath.Join(gopath, "bin")
 }
 
-// getModulePath tries to get the module path for a binary using go version -m
-func (g *GoToolsInstaller) getModulePath(binPath string) string {
-	output, err := g.runner.Run("go", "version", "-m", binPath)
+// Install installs a Go tool
+func (g *GoToolsInstaller) Install(ctx context.Context, pkg brewfile.Package) error {
+	_, err := g.runner.RunContext(ctx, "go", "install", g.installTarget(pkg))
+	return err
+}
+
+// installTarget returns the go install argument for a tool: a version in
+// the name is kept, the version option is used when versions are pinned,
+// and anything else installs @latest
+func (g *GoToolsInstaller) installTarget(pkg brewfile.Package) string {
+	if strings.Contains(pkg.Name, "@") {
+		return pkg.Name
+	}
+	if g.versions == GoVersionsPin {
+		if version, ok := pkg.Options[GoVersionOption]; ok && version.String() != "" {
+			return pkg.Name + "@" + version.String()
+		}
+	}
+	return pkg.Name + "@latest"
+}
+
+// Uninstall removes a Go tool binary. The binary is found by the package
+// path recorded in its build information, so tools whose binary is not
+// named after the last path element are removed too.
+func (g *GoToolsInstaller) Uninstall(ctx context.Context, pkg brewfile.Package) error {
+	binDir := g.getBinDir()
+	if binDir == "" {
+		return fmt.Errorf("cannot find the Go bin directory to remove %s", pkg.Name)
+	}
+
+	path, _, _ := strings.Cut(pkg.Name, "@")
+	binName := goBinaryName(path)
+	binaries, err := g.binaries(ctx)
 	if err != nil {
-		return ""
-	}
-
-	// Parse output to find the path line
-	// Format: binary: path\n\tpath\tmodule/path\tversion\n...
-	lines := strings.Split(output, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "path") {
-			parts := strings.Fields(line)
-			if len(parts) >= 2 {
-				return parts[1]
-			}
+		return err
+	}
+	for _, bin := range binaries {
+		if bin.Path == path {
+			binName = bin.Name
+			break
 		}
 	}
-	return ""
+
+	return os.Remove(filepath.Join(binDir, binName))
 }
 
-// Install installs a Go tool
-func (g *GoToolsInstaller) Install(pkg brewfile.Package) error {
-	// Add @latest if no version specified
-	name := pkg.Name
-	if !strings.Contains(name, "@") {
-		name += "@latest"
+// goBinaryName returns the name go install gives the binary of a package:
+// its last path element, or the one before a major version suffix such as
+// /v2
+func goBinaryName(path string) string {
+	elems := strings.Split(strings.Trim(path, "/"), "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && majorVersionSuffix(name) {
+		name = elems[len(elems)-2]
 	}
-	_, err := g.runner.Run("go", "install", name)
-	return err
+	return name
+}
+
+// majorVersionSuffix reports whether a path element is a major version
+// suffix like v2
+func majorVersionSuffix(elem string) bool {
+	if len(elem) < 2 || elem[0] != 'v' {
+		return false
+	}
+	n, err := strconv.Atoi(elem[1:])
+	return err == nil && n >= 2
+}
+
+// IsAvailable checks if go is available, which installing, upgrading and
+// checking for newer releases need
+func (g *GoToolsInstaller) IsAvailable() bool {
+	return g.runner.Exists("go")
 }
 
-// Uninstall removes a Go tool binary
-func (g *GoToolsInstaller) Uninstall(pkg brewfile.Package) error {
+// CanList reports whether the Go bin directory exists. Tools are listed and
+// versioned from their binaries, so this works without go on PATH.
+func (g *GoToolsInstaller) CanList() bool {
 	binDir := g.getBinDir()
 	if binDir == "" {
-		return nil
+		return false
 	}
+	info, err := os.Stat(binDir)
+	return err == nil && info.IsDir()
+}
+
+// Capabilities reports that Go tools are listed, removed, versioned and
+// upgraded from their binaries but installed one at a time
+func (g *GoToolsInstaller) Capabilities() Capabilities {
+	return Capabilities{List: true, Uninstall: true, Versions: true, Upgrade: true}
+}
 
-	// Extract binary name from module path
-	binName := filepath.Base(pkg.Name)
-	binPath := filepath.Join(binDir, binName)
+// Outdated returns the Go tools whose module has a newer release, comparing
+// the version recorded in each binary with `go list -m module@latest`.
+// Tools built from a local checkout, reported as (devel), are skipped.
+func (g *GoToolsInstaller) Outdated(ctx context.Context) ([]Outdated, error) {
+	binaries, err := g.binaries(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	return os.Remove(binPath)
+	latest := make(map[string]string)
+	var outdated []Outdated
+	for _, bin := range binaries {
+		if bin.Module == "" || !bin.released() {
+			continue
+		}
+		version, ok := latest[bin.Module]
+		if !ok {
+			version, err = g.latestVersion(ctx, bin.Module)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				// Modules that cannot be resolved (private, removed) are skipped
+				version = ""
+			}
+			latest[bin.Module] = version
+		}
+		if newer(bin.Version, version) {
+			outdated = append(outdated, Outdated{
+				Package:   brewfile.NewPackage(brewfile.TypeGo, bin.Path),
+				Installed: bin.Version,
+				Latest:    version,
+			})
+		}
+	}
+	return outdated, nil
 }
 
-// IsAvailable checks if go is available
-func (g *GoToolsInstaller) IsAvailable() bool {
-	return g.runner.Exists("go")
+// latestVersion returns the latest release of a module
+func (g *GoToolsInstaller) latestVersion(ctx context.Context, module string) (string, error) {
+	output, err := g.runner.RunContext(ctx, "go", "list", "-m", "-json", module+"@latest")
+	if err != nil {
+		return "", err
+	}
+	return parseGoListVersion([]byte(output))
+}
+
+// parseGoListVersion extracts the version from `go list -m -json` output
+func parseGoListVersion(data []byte) (string, error) {
+	var mod struct {
+		Version string `json:"Version"`
+	}
+	if err := json.Unmarshal(data, &mod); err != nil {
+		return "", fmt.Errorf("failed to parse go list output: %w", err)
+	}
+	return mod.Version, nil
+}
+
+// Upgrade installs the latest release of a Go tool
+func (g *GoToolsInstaller) Upgrade(ctx context.Context, pkg brewfile.Package) error {
+	name, _, _ := strings.Cut(pkg.Name, "@")
+	_, err := g.runner.RunContext(ctx, "go", "install", name+"@latest")
+	return err
 }
@@ -1,12 +1,16 @@
 package installer
 
 import (
+	"context"
 	"os"
 	"path/filepath"
+	"runtime/debug"
 	"testing"
 
 	"github.com/andrew-sameh/brewsync/internal/brewfile"
+	"github.com/andrew-sameh/brewsync/internal/exec"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewGoToolsInstaller(t *testing.T) {
@@ -71,7 +75,7 @@ func TestGoToolsInstaller_List(t *testing.T) {
 		t.Skip("Go not available")
 	}
 
-	tools, err := inst.List()
+	tools, err := inst.List(context.Background())
 	// This might return empty if no tools installed, which is OK
 	assert.NoError(t, err)
 
@@ -86,10 +90,163 @@ func TestGoToolsInstaller_Install_Uninstall(t *testing.T) {
 	t.Skip("Skipping install/uninstall tests to avoid system modification")
 }
 
-func TestGoToolsInstaller_getModulePath(t *testing.T) {
-	inst := NewGoToolsInstaller()
+func TestReadGoBinary(t *testing.T) {
+	// The test binary itself is a Go binary built with modules
+	exe, err := os.Executable()
+	require.NoError(t, err)
+	bin, ok := readGoBinary(exe)
+	require.True(t, ok)
+	assert.Equal(t, filepath.Base(exe), bin.Name)
+	assert.NotEmpty(t, bin.Path)
+
+	script := filepath.Join(t.TempDir(), "script")
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\n"), 0755))
+	_, ok = readGoBinary(script)
+	assert.False(t, ok, "non-Go executables are skipped")
+
+	_, ok = readGoBinary("/nonexistent/binary")
+	assert.False(t, ok)
+}
+
+func TestFromBuildInfo(t *testing.T) {
+	bin := fromBuildInfo(&debug.BuildInfo{
+		Path: "github.com/golangci/golangci-lint/cmd/golangci-lint",
+		Main: debug.Module{Path: "github.com/golangci/golangci-lint", Version: "v1.56.2"},
+		Settings: []debug.BuildSetting{
+			{Key: "CGO_ENABLED", Value: "0"},
+			{Key: "vcs.revision", Value: "0123456789abcdef0123"},
+		},
+	})
+	assert.Equal(t, "github.com/golangci/golangci-lint/cmd/golangci-lint", bin.Path)
+	assert.Equal(t, "github.com/golangci/golangci-lint", bin.Module, "the module is looked up for updates")
+	assert.Equal(t, "v1.56.2", bin.Version)
+	assert.Equal(t, "0", bin.Settings["CGO_ENABLED"])
+	assert.Equal(t, "v1.56.2", bin.lockVersion())
+
+	bin.Version = "(devel)"
+	assert.Equal(t, "(devel) 0123456789ab", bin.lockVersion(), "local builds record their revision")
+}
+
+func TestGoToolsInstaller_binaries(t *testing.T) {
+	binDir := t.TempDir()
+	t.Setenv("GOBIN", binDir)
+
+	exe, err := os.Executable()
+	require.NoError(t, err)
+	data, err := os.ReadFile(exe)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "tool"), data, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "script"), []byte("#!/bin/sh\n"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "notes.txt"), []byte("notes"), 0644))
+
+	binaries, err := NewGoToolsInstaller().binaries(context.Background())
+	require.NoError(t, err)
+	require.Len(t, binaries, 1)
+	assert.Equal(t, "tool", binaries[0].Name)
+}
+
+func TestGoToolsInstaller_CanList(t *testing.T) {
+	binDir := t.TempDir()
+	t.Setenv("GOBIN", binDir)
+
+	exe, err := os.Executable()
+	require.NoError(t, err)
+	data, err := os.ReadFile(exe)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "tool"), data, 0755))
+
+	// go is not on PATH, but the binaries can still be read
+	fake := exec.NewFakeRunner([]exec.Entry{
+		{Command: "go", Lookup: true, Missing: true},
+		{Command: "brew", Lookup: true, Missing: true},
+	})
+	fake.Strict = true
+	m := NewManager()
+	m.go_.runner = fake
+	m.brew.runner = fake
+
+	assert.False(t, m.go_.IsAvailable())
+	assert.True(t, m.go_.CanList())
+	assert.True(t, CanList(m.go_))
+
+	pkgs, err := m.ListAll(context.Background())
+	require.NoError(t, err)
+	tools := pkgs.ByType()[brewfile.TypeGo]
+	require.Len(t, tools, 1)
+	assert.NotEmpty(t, m.Versions()[tools[0].ID()])
+
+	err = m.Install(context.Background(), brewfile.NewPackage(brewfile.TypeGo, "golang.org/x/tools/gopls"))
+	assert.Equal(t, FailureUnavailable, Classify(err), "installing still needs go")
+
+	t.Setenv("GOBIN", filepath.Join(binDir, "missing"))
+	assert.False(t, m.go_.CanList())
+}
+
+func TestParseGoVersionPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		want GoVersionPolicy
+	}{
+		{"", GoVersionsLatest},
+		{"latest", GoVersionsLatest},
+		{"pin", GoVersionsPin},
+	} {
+		policy, err := ParseGoVersionPolicy(tc.in)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.want, policy)
+	}
+
+	_, err := ParseGoVersionPolicy("newest")
+	assert.Error(t, err)
+}
+
+func TestGoToolsInstaller_installTarget(t *testing.T) {
+	lint := brewfile.NewPackage(brewfile.TypeGo, "github.com/golangci/golangci-lint/cmd/golangci-lint")
+	pinned := lint.WithValue(GoVersionOption, brewfile.StringValue("v1.61.0"))
+
+	tests := []struct {
+		name     string
+		versions GoVersionPolicy
+		pkg      brewfile.Package
+		want     string
+	}{
+		{"latest without version", GoVersionsLatest, lint, lint.Name + "@latest"},
+		{"latest ignores version", GoVersionsLatest, pinned, lint.Name + "@latest"},
+		{"pin without version", GoVersionsPin, lint, lint.Name + "@latest"},
+		{"pin with version", GoVersionsPin, pinned, lint.Name + "@v1.61.0"},
+		{"version in name", GoVersionsLatest, brewfile.NewPackage(brewfile.TypeGo, "golang.org/x/tools/gopls@v0.15.0"), "golang.org/x/tools/gopls@v0.15.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inst := &GoToolsInstaller{versions: tt.versions}
+			assert.Equal(t, tt.want, inst.installTarget(tt.pkg))
+		})
+	}
+}
 
-	// Test with non-existent binary
-	path := inst.getModulePath("/nonexistent/binary")
-	assert.Empty(t, path)
+func TestGoBinaryName(t *testing.T) {
+	tests := map[string]string{
+		"golang.org/x/tools/gopls":                            "gopls",
+		"github.com/golangci/golangci-lint/cmd/golangci-lint": "golangci-lint",
+		"github.com/go-delve/delve/cmd/dlv":                   "dlv",
+		"github.com/segmentio/golines/v2":                     "golines",
+		"example.com/tool/v1":                                 "v1",
+		"mockgen":                                             "mockgen",
+	}
+	for path, want := range tests {
+		assert.Equal(t, want, goBinaryName(path), path)
+	}
+}
+
+func TestGoToolsInstaller_Uninstall(t *testing.T) {
+	binDir := t.TempDir()
+	t.Setenv("GOBIN", binDir)
+	bin := filepath.Join(binDir, "golines")
+	require.NoError(t, os.WriteFile(bin, []byte("#!/bin/sh\n"), 0755))
+
+	// Without build information the binary is found by the go install naming rule
+	inst := NewGoToolsInstaller()
+	err := inst.Uninstall(context.Background(), brewfile.NewPackage(brewfile.TypeGo, "github.com/segmentio/golines/v2"))
+	require.NoError(t, err)
+	assert.NoFileExists(t, bin)
 }
@@ -1,108 +1,329 @@
 package installer
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/andrew-sameh/brewsync/internal/brewfile"
 )
 
 // Installer is the interface for package installers
 type Installer interface {
-	Install(pkg brewfile.Package) error
-	Uninstall(pkg brewfile.Package) error
-	List() (brewfile.Packages, error)
+	Install(ctx context.Context, pkg brewfile.Package) error
+	Uninstall(ctx context.Context, pkg brewfile.Package) error
+	List(ctx context.Context) (brewfile.Packages, error)
 	IsAvailable() bool
+	// Capabilities declares the optional operations the installer supports
+	Capabilities() Capabilities
+}
+
+// BatchInstaller is implemented by installers that can install several
+// packages of one type with a single command
+type BatchInstaller interface {
+	InstallBatch(ctx context.Context, pkgs brewfile.Packages, onOutput func(line string)) error
 }
 
 // Manager orchestrates installations across different package types
 type Manager struct {
-	brew        *BrewInstaller
-	vscode      *VSCodeInstaller
-	cursor      *CursorInstaller
-	antigravity *AntigravityInstaller
-	mas         *MasInstaller
-	go_         *GoToolsInstaller
+	brew   *BrewInstaller
+	mas    *MasInstaller
+	go_    *GoToolsInstaller
+	limits map[string]int
+	retry  RetryPolicy
 }
 
 // NewManager creates a new installation manager
 func NewManager() *Manager {
 	return &Manager{
-		brew:        NewBrewInstaller(),
-		vscode:      NewVSCodeInstaller(),
-		cursor:      NewCursorInstaller(),
-		antigravity: NewAntigravityInstaller(),
-		mas:         NewMasInstaller(),
-		go_:         NewGoToolsInstaller(),
+		brew:   NewBrewInstaller(),
+		mas:    NewMasInstaller(),
+		go_:    NewGoToolsInstaller(),
+		limits: DefaultLimits,
+		retry:  DefaultRetry,
 	}
 }
 
 // Install installs a package using the appropriate installer
-func (m *Manager) Install(pkg brewfile.Package) error {
-	return m.InstallWithProgress(pkg, nil)
+func (m *Manager) Install(ctx context.Context, pkg brewfile.Package) error {
+	return m.InstallWithProgress(ctx, pkg, nil)
 }
 
-// InstallWithProgress installs a package and streams output to a callback
-func (m *Manager) InstallWithProgress(pkg brewfile.Package, onOutput func(line string)) error {
+// InstallWithProgress installs a package and streams output to a callback.
+// Transient failures are retried with backoff; failures are returned as an
+// *InstallError carrying the failure kind.
+func (m *Manager) InstallWithProgress(ctx context.Context, pkg brewfile.Package, onOutput func(line string)) error {
 	installer, err := m.getInstaller(pkg.Type)
 	if err != nil {
 		return err
 	}
 
 	if !installer.IsAvailable() {
-		return fmt.Errorf("%s installer not available", pkg.Type)
+		return unavailable(pkg)
 	}
 
-	// Use specialized method for brew packages that support streaming
-	if pkg.Type == brewfile.TypeTap || pkg.Type == brewfile.TypeBrew || pkg.Type == brewfile.TypeCask {
-		return m.brew.InstallWithProgress(pkg, onOutput)
+	return m.retry.Do(ctx, pkg, func() error {
+		if streamer, ok := installer.(StreamingInstaller); ok && installer.Capabilities().Stream {
+			return streamer.InstallWithProgress(ctx, pkg, onOutput)
+		}
+		return installer.Install(ctx, pkg)
+	}, retryOutput(onOutput))
+}
+
+// Converge brings an installed package in line with its changed source
+// declaration. Formulae whose only difference is the link option are
+// relinked and those whose only difference is their service have it started
+// or stopped; other brew packages are reinstalled and the rest are installed
+// again from the source declaration. Full-name-only changes need no action.
+// brew reinstall cannot change a formula's build args, so that change is
+// returned as an *UnsupportedError.
+func (m *Manager) Converge(ctx context.Context, change brewfile.Change) error {
+	pkg := change.Source
+	fields := change.Fields()
+	if len(fields) == 1 && fields[0] == brewfile.ChangedFullName {
+		return nil
 	}
 
-	// Other installers don't support streaming yet, use regular install
-	return installer.Install(pkg)
+	installer, err := m.getInstaller(pkg.Type)
+	if err != nil {
+		return err
+	}
+	if !installer.IsAvailable() {
+		return unavailable(pkg)
+	}
+
+	return m.retry.Do(ctx, pkg, func() error {
+		switch pkg.Type {
+		case brewfile.TypeBrew:
+			if change.ServiceOnly() {
+				if !change.ServiceChanged() {
+					return nil
+				}
+				return m.brew.SetService(ctx, pkg, pkg.ServiceRunning())
+			}
+			if change.OptionsOnly("link") {
+				return m.brew.Relink(ctx, pkg)
+			}
+			if !pkg.Options["args"].Equal(change.Current.Options["args"]) {
+				return &UnsupportedError{
+					Type:      pkg.Type,
+					Operation: "changing the build args of an installed formula",
+					Reason:    fmt.Sprintf("run 'brew uninstall %s' and sync again", pkg.Name),
+				}
+			}
+			if err := m.brew.Reinstall(ctx, pkg); err != nil {
+				return err
+			}
+			if change.ServiceChanged() {
+				return m.brew.SetService(ctx, pkg, pkg.ServiceRunning())
+			}
+			return nil
+		case brewfile.TypeTap, brewfile.TypeCask:
+			return m.brew.Reinstall(ctx, pkg)
+		default:
+			return installer.Install(ctx, pkg)
+		}
+	}, nil)
+}
+
+// Restore installs a removed package again, with its args, and reapplies
+// what an install leaves out: an unlinked formula is unlinked and a
+// service that was running is started
+func (m *Manager) Restore(ctx context.Context, pkg brewfile.Package) error {
+	if err := m.Install(ctx, pkg); err != nil {
+		return err
+	}
+	if pkg.Type != brewfile.TypeBrew {
+		return nil
+	}
+	return m.retry.Do(ctx, pkg, func() error {
+		if link, ok := pkg.Options["link"]; ok && link.Kind == brewfile.KindBool && !link.Bool {
+			if err := m.brew.Relink(ctx, pkg); err != nil {
+				return err
+			}
+		}
+		if pkg.ServiceRunning() {
+			return m.brew.SetService(ctx, pkg, true)
+		}
+		return nil
+	}, nil)
+}
+
+// SetService starts or stops a formula's service, retrying network failures
+func (m *Manager) SetService(ctx context.Context, pkg brewfile.Package, running bool) error {
+	if !m.brew.IsAvailable() {
+		return unavailable(pkg)
+	}
+	return m.retry.Do(ctx, pkg, func() error {
+		return m.brew.SetService(ctx, pkg, running)
+	}, nil)
 }
 
 // Uninstall removes a package using the appropriate installer
-func (m *Manager) Uninstall(pkg brewfile.Package) error {
+func (m *Manager) Uninstall(ctx context.Context, pkg brewfile.Package) error {
 	installer, err := m.getInstaller(pkg.Type)
 	if err != nil {
 		return err
 	}
 
+	if !installer.Capabilities().Uninstall {
+		return unsupported(pkg, "uninstall", ManualRemoval)
+	}
 	if !installer.IsAvailable() {
-		return fmt.Errorf("%s installer not available", pkg.Type)
+		return unavailable(pkg)
+	}
+
+	// Removals are not retried: a failed removal leaves nothing to clean up
+	if err := installer.Uninstall(ctx, pkg); err != nil {
+		return &InstallError{Package: pkg, Kind: Classify(err), Attempts: 1, Err: err}
+	}
+	return nil
+}
+
+// unavailable is the error for a package whose installer is not installed
+func unavailable(pkg brewfile.Package) error {
+	return &InstallError{
+		Package: pkg,
+		Kind:    FailureUnavailable,
+		Err:     fmt.Errorf("%s installer not available", pkg.Type),
 	}
+}
+
+// unsupported is the error for an operation the package's installer cannot
+// perform; nothing was attempted
+func unsupported(pkg brewfile.Package, operation, reason string) error {
+	return &InstallError{
+		Package: pkg,
+		Kind:    FailureUnsupported,
+		Err:     &UnsupportedError{Type: pkg.Type, Operation: operation, Reason: reason},
+	}
+}
 
-	return installer.Uninstall(pkg)
+// retryOutput reports retries as installer output lines
+func retryOutput(onOutput func(line string)) func(kind FailureKind, attempt int, delay time.Duration) {
+	if onOutput == nil {
+		return nil
+	}
+	return func(kind FailureKind, attempt int, delay time.Duration) {
+		onOutput(retryMessage(kind, attempt, delay))
+	}
 }
 
 // InstallMany installs multiple packages, returning errors for each failure
-func (m *Manager) InstallMany(packages brewfile.Packages, onProgress func(pkg brewfile.Package, i, total int, err error)) error {
-	return m.InstallManyWithOutput(packages, onProgress, nil)
+func (m *Manager) InstallMany(ctx context.Context, packages brewfile.Packages, onProgress func(pkg brewfile.Package, i, total int, err error)) error {
+	return m.InstallManyWithOutput(ctx, packages, onProgress, nil)
 }
 
-// InstallManyWithOutput installs multiple packages with progress and output streaming
+// InstallManyWithOutput installs multiple packages with progress and output streaming.
+// See InstallManyWithEvents for the install order and concurrency.
 func (m *Manager) InstallManyWithOutput(
+	ctx context.Context,
 	packages brewfile.Packages,
 	onProgress func(pkg brewfile.Package, i, total int, err error),
 	onOutput func(pkg brewfile.Package, line string),
 ) error {
-	var lastErr error
-	total := len(packages)
+	return m.InstallManyWithEvents(ctx, packages, nil, onProgress, onOutput)
+}
 
-	for i, pkg := range packages {
-		var err error
+// InstallManyWithEvents installs multiple packages in dependency order: taps,
+// then formulae and casks, then editor extensions, App Store apps and Go
+// tools. Packages of different backends are installed concurrently within
+// the manager's per-backend limits, and formulae, casks and extensions that
+// are ready together are installed with one batched command. onStart and
+// onProgress are never called concurrently; i counts finished packages.
+// onOutput calls are serialized with each other, but lines of packages
+// installing in parallel may interleave; output of a batched command is
+// attributed to the first package of the batch.
+//
+// Cancelling ctx stops the installs in flight and skips the rest; the
+// returned *InterruptedError lists the packages that were never attempted.
+func (m *Manager) InstallManyWithEvents(
+	ctx context.Context,
+	packages brewfile.Packages,
+	onStart func(pkg brewfile.Package),
+	onProgress func(pkg brewfile.Package, i, total int, err error),
+	onOutput func(pkg brewfile.Package, line string),
+) error {
+	var mu sync.Mutex
+	output := func(pkg brewfile.Package) func(line string) {
+		if onOutput == nil {
+			return nil
+		}
+		return func(line string) {
+			mu.Lock()
+			defer mu.Unlock()
+			onOutput(pkg, line)
+		}
+	}
+	install := func(ctx context.Context, pkg brewfile.Package) error {
+		return m.InstallWithProgress(ctx, pkg, output(pkg))
+	}
+	batch := func(ctx context.Context, pkgs brewfile.Packages, onDone func(pkg brewfile.Package, err error)) {
+		m.installBatch(ctx, pkgs, output, onDone)
+	}
+
+	return RunPlan(ctx, Plan(packages), m.limits, install, batch, onStart, onProgress)
+}
 
-		// If output callback is provided, use streaming install
+// installBatch installs packages of one type with a single command. When
+// that fails, the packages are installed one at a time so that only the
+// culprits are reported as failed.
+func (m *Manager) installBatch(
+	ctx context.Context,
+	pkgs brewfile.Packages,
+	output func(pkg brewfile.Package) func(line string),
+	onDone func(pkg brewfile.Package, err error),
+) {
+	installer, err := m.getInstaller(pkgs[0].Type)
+	if err == nil && installer.Capabilities().Batch && installer.IsAvailable() {
+		batcher := installer.(BatchInstaller)
+		onOutput := output(pkgs[0])
+		err := batcher.InstallBatch(ctx, pkgs, onOutput)
+		if err == nil {
+			for _, pkg := range pkgs {
+				onDone(pkg, nil)
+			}
+			return
+		}
+		if ctx.Err() != nil {
+			for _, pkg := range pkgs {
+				onDone(pkg, &InstallError{Package: pkg, Kind: Classify(err), Attempts: 1, Err: err})
+			}
+			return
+		}
 		if onOutput != nil {
-			err = m.InstallWithProgress(pkg, func(line string) {
-				onOutput(pkg, line)
-			})
-		} else {
-			err = m.Install(pkg)
+			onOutput(fmt.Sprintf("batch install failed (%s), installing %d packages one at a time",
+				Classify(err), len(pkgs)))
+		}
+	}
+
+	for _, pkg := range pkgs {
+		if ctx.Err() != nil {
+			return
 		}
+		onDone(pkg, m.InstallWithProgress(ctx, pkg, output(pkg)))
+	}
+}
 
+// ConvergeMany converges multiple changed packages. Cancelling ctx stops
+// the current package and skips the rest, returning an *InterruptedError.
+func (m *Manager) ConvergeMany(ctx context.Context, changes []brewfile.Change, onProgress func(change brewfile.Change, i, total int, err error)) error {
+	var lastErr error
+	total := len(changes)
+
+	for i, change := range changes {
+		if ctx.Err() != nil {
+			var skipped brewfile.Packages
+			for _, c := range changes[i:] {
+				skipped = append(skipped, c.Source)
+			}
+			return &InterruptedError{Err: ctx.Err(), Skipped: skipped}
+		}
+		err := m.Converge(ctx, change)
 		if onProgress != nil {
-			onProgress(pkg, i+1, total, err)
+			onProgress(change, i+1, total, err)
 		}
 		if err != nil {
 			lastErr = err
@@ -112,13 +333,17 @@ func (m *Manager) InstallManyWithOutput(
 	return lastErr
 }
 
-// UninstallMany removes multiple packages
-func (m *Manager) UninstallMany(packages brewfile.Packages, onProgress func(pkg brewfile.Package, i, total int, err error)) error {
+// UninstallMany removes multiple packages. Cancelling ctx stops the current
+// package and skips the rest, returning an *InterruptedError.
+func (m *Manager) UninstallMany(ctx context.Context, packages brewfile.Packages, onProgress func(pkg brewfile.Package, i, total int, err error)) error {
 	var lastErr error
 	total := len(packages)
 
 	for i, pkg := range packages {
-		err := m.Uninstall(pkg)
+		if ctx.Err() != nil {
+			return &InterruptedError{Err: ctx.Err(), Skipped: packages[i:]}
+		}
+		err := m.Uninstall(ctx, pkg)
 		if onProgress != nil {
 			onProgress(pkg, i+1, total, err)
 		}
@@ -130,56 +355,171 @@ func (m *Manager) UninstallMany(packages brewfile.Packages, onProgress func(pkg
 	return lastErr
 }
 
-// ListAll returns all installed packages from all available installers
-func (m *Manager) ListAll() (brewfile.Packages, error) {
-	var all brewfile.Packages
-
-	// Brew handles tap, brew, cask
-	if m.brew.IsAvailable() {
-		pkgs, err := m.brew.ListAll()
+// Outdated returns the outdated packages of every available installer that
+// can upgrade them. Installers whose check fails are skipped and their
+// errors returned alongside the packages found by the others.
+func (m *Manager) Outdated(ctx context.Context) ([]Outdated, error) {
+	var (
+		all  []Outdated
+		errs []error
+	)
+	for _, src := range m.sources() {
+		upgrader, ok := src.installer.(Upgrader)
+		if !ok || !src.installer.Capabilities().Upgrade || !src.installer.IsAvailable() {
+			continue
+		}
+		outdated, err := upgrader.Outdated(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("brew list failed: %w", err)
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			errs = append(errs, fmt.Errorf("%s outdated check failed: %w", src.name, err))
+			continue
 		}
-		all = append(all, pkgs...)
+		all = append(all, outdated...)
 	}
+	return all, errors.Join(errs...)
+}
 
-	// VSCode
-	if m.vscode.IsAvailable() {
-		pkgs, err := m.vscode.List()
-		if err != nil {
-			return nil, fmt.Errorf("vscode list failed: %w", err)
-		}
-		all = append(all, pkgs...)
+// Upgrade upgrades a package to its latest version. Transient failures are
+// retried with backoff like installs.
+func (m *Manager) Upgrade(ctx context.Context, pkg brewfile.Package) error {
+	installer, err := m.getInstaller(pkg.Type)
+	if err != nil {
+		return err
 	}
 
-	// Cursor
-	if m.cursor.IsAvailable() {
-		pkgs, err := m.cursor.List()
+	upgrader, ok := installer.(Upgrader)
+	if !ok || !installer.Capabilities().Upgrade {
+		return unsupported(pkg, "upgrade", "")
+	}
+	if !installer.IsAvailable() {
+		return unavailable(pkg)
+	}
+
+	return m.retry.Do(ctx, pkg, func() error {
+		return upgrader.Upgrade(ctx, pkg)
+	}, nil)
+}
+
+// UpgradeMany upgrades multiple packages one at a time. Cancelling ctx stops
+// the current package and skips the rest, returning an *InterruptedError.
+func (m *Manager) UpgradeMany(ctx context.Context, packages brewfile.Packages, onProgress func(pkg brewfile.Package, i, total int, err error)) error {
+	var lastErr error
+	total := len(packages)
+
+	for i, pkg := range packages {
+		if ctx.Err() != nil {
+			return &InterruptedError{Err: ctx.Err(), Skipped: packages[i:]}
+		}
+		err := m.Upgrade(ctx, pkg)
+		if onProgress != nil {
+			onProgress(pkg, i+1, total, err)
+		}
 		if err != nil {
-			return nil, fmt.Errorf("cursor list failed: %w", err)
+			lastErr = err
 		}
-		all = append(all, pkgs...)
 	}
 
-	// Go tools
-	if m.go_.IsAvailable() {
-		pkgs, err := m.go_.List()
+	return lastErr
+}
+
+// ListAll returns all installed packages from all available installers
+// that can list them
+func (m *Manager) ListAll(ctx context.Context) (brewfile.Packages, error) {
+	var all brewfile.Packages
+	for _, src := range m.sources() {
+		if !src.installer.Capabilities().List || !CanList(src.installer) {
+			continue
+		}
+		pkgs, err := src.installer.List(ctx)
 		if err != nil {
-			return nil, fmt.Errorf("go tools list failed: %w", err)
+			return nil, fmt.Errorf("%s list failed: %w", src.name, err)
 		}
 		all = append(all, pkgs...)
 	}
+	return all, nil
+}
+
+// source is a named installer
+type source struct {
+	name      string
+	installer Installer
+}
 
-	// MAS
-	if m.mas.IsAvailable() {
-		pkgs, err := m.mas.List()
+// sources returns every installer: brew (taps, formulae and casks), the
+// editors, Go tools, mas and the custom types declared in config
+func (m *Manager) sources() []source {
+	sources := []source{{"brew", m.brew}}
+	for _, editor := range EditorInstallers() {
+		sources = append(sources, source{string(editor.Type()), editor})
+	}
+	sources = append(sources, source{"go", m.go_}, source{"mas", m.mas})
+	for _, custom := range CustomInstallers() {
+		sources = append(sources, source{string(custom.Type()), custom})
+	}
+	return sources
+}
+
+// Versions returns the installed version of every package across all
+// available installers, keyed by package ID. Installers whose version query
+// fails are skipped so one broken tool does not prevent writing a lock file.
+func (m *Manager) Versions() map[string]string {
+	versions := make(map[string]string)
+	for _, src := range m.sources() {
+		lister, ok := src.installer.(VersionLister)
+		if !ok || !src.installer.Capabilities().Versions || !CanList(src.installer) {
+			continue
+		}
+		found, err := lister.Versions()
 		if err != nil {
-			return nil, fmt.Errorf("mas list failed: %w", err)
+			continue
 		}
-		all = append(all, pkgs...)
+		for id, version := range found {
+			versions[id] = version
+		}
+	}
+	return versions
+}
+
+// TapCommits returns the commit each installed tap is checked out at, keyed by package ID
+func (m *Manager) TapCommits() map[string]string {
+	if !m.brew.IsAvailable() {
+		return nil
+	}
+	commits, err := m.brew.TapCommits()
+	if err != nil {
+		return nil
 	}
+	return commits
+}
 
-	return all, nil
+// Lock builds a lock file for the given packages from their installed versions
+func (m *Manager) Lock(packages brewfile.Packages) *brewfile.Lock {
+	return brewfile.BuildLock(packages, m.Versions(), m.TapCommits())
+}
+
+// Capabilities returns the optional operations supported for a package
+// type; unknown types support none
+func (m *Manager) Capabilities(pkgType brewfile.PackageType) Capabilities {
+	installer, err := m.getInstaller(pkgType)
+	if err != nil {
+		return Capabilities{}
+	}
+	return installer.Capabilities()
+}
+
+// CanInstall returns why a package cannot be installed on this machine, or
+// nil when its installer is known and available
+func (m *Manager) CanInstall(pkg brewfile.Package) error {
+	installer, err := m.getInstaller(pkg.Type)
+	if err != nil {
+		return unsupported(pkg, "install", "no installer for this type; declare it under custom_types")
+	}
+	if !installer.IsAvailable() {
+		return unavailable(pkg)
+	}
+	return nil
 }
 
 // IsAvailable checks if the installer for a package type is available
@@ -196,29 +536,38 @@ func (m *Manager) getInstaller(pkgType brewfile.PackageType) (Installer, error)
 	switch pkgType {
 	case brewfile.TypeTap, brewfile.TypeBrew, brewfile.TypeCask:
 		return m.brew, nil
-	case brewfile.TypeVSCode:
-		return m.vscode, nil
-	case brewfile.TypeCursor:
-		return m.cursor, nil
-	case brewfile.TypeAntigravity:
-		return m.antigravity, nil
 	case brewfile.TypeMas:
 		return m.mas, nil
 	case brewfile.TypeGo:
 		return m.go_, nil
 	default:
+		if editor, ok := brewfile.LookupEditor(pkgType); ok {
+			return NewEditorInstaller(editor), nil
+		}
+		if def, ok := brewfile.LookupCustomType(pkgType); ok {
+			return NewCustomInstaller(def), nil
+		}
 		return nil, fmt.Errorf("unknown package type: %s", pkgType)
 	}
 }
 
 // AvailableInstallers returns a map of installer types to availability
 func (m *Manager) AvailableInstallers() map[string]bool {
-	return map[string]bool{
-		"brew":        m.brew.IsAvailable(),
-		"vscode":      m.vscode.IsAvailable(),
-		"cursor":      m.cursor.IsAvailable(),
-		"antigravity": m.antigravity.IsAvailable(),
-		"mas":         m.mas.IsAvailable(),
-		"go":          m.go_.IsAvailable(),
+	available := make(map[string]bool)
+	for _, src := range m.sources() {
+		available[src.name] = src.installer.IsAvailable()
+	}
+	return available
+}
+
+// CustomInstallers returns an installer for every registered custom type
+// other than the configured editors
+func CustomInstallers() []*CustomInstaller {
+	var installers []*CustomInstaller
+	for _, t := range brewfile.CustomTypes() {
+		if def, ok := brewfile.LookupCustomType(t); ok && def.Editor == nil {
+			installers = append(installers, NewCustomInstaller(def))
+		}
 	}
+	return installers
 }
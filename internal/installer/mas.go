@@ -1,6 +1,7 @@
 package installer
 
 import (
+	"context"
 	"regexp"
 	"strings"
 
@@ -10,7 +11,7 @@ import (
 
 // MasInstaller handles Mac App Store apps
 type MasInstaller struct {
-	runner *exec.Runner
+	runner exec.Runner
 }
 
 // NewMasInstaller creates a new Mac App Store installer
@@ -21,11 +22,11 @@ func NewMasInstaller() *MasInstaller {
 }
 
 // masListPattern matches "123456789 App Name (1.0.0)"
-var masListPattern = regexp.MustCompile(`^(\d+)\s+(.+?)\s+\([\d.]+\)$`)
+var masListPattern = regexp.MustCompile(`^(\d+)\s+(.+?)\s+\(([\d.]+)\)$`)
 
 // List returns all installed Mac App Store apps
-func (m *MasInstaller) List() (brewfile.Packages, error) {
-	lines, err := m.runner.RunLines("mas", "list")
+func (m *MasInstaller) List(ctx context.Context) (brewfile.Packages, error) {
+	lines, err := m.runner.RunLinesContext(ctx, "mas", "list")
 	if err != nil {
 		return nil, err
 	}
@@ -53,23 +54,85 @@ func (m *MasInstaller) List() (brewfile.Packages, error) {
 	return packages, nil
 }
 
+// Versions returns the installed version of every Mac App Store app, keyed by package ID
+func (m *MasInstaller) Versions() (map[string]string, error) {
+	lines, err := m.runner.RunLines("mas", "list")
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make(map[string]string)
+	for _, line := range lines {
+		matches := masListPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if matches == nil {
+			continue
+		}
+		versions[string(brewfile.TypeMas)+":"+matches[1]] = matches[3]
+	}
+	return versions, nil
+}
+
 // Install installs a Mac App Store app by ID
-func (m *MasInstaller) Install(pkg brewfile.Package) error {
+func (m *MasInstaller) Install(ctx context.Context, pkg brewfile.Package) error {
 	id := pkg.Name
 	if idOpt, ok := pkg.Options["id"]; ok {
-		id = idOpt
+		id = idOpt.String()
 	}
-	_, err := m.runner.Run("mas", "install", id)
+	_, err := m.runner.RunContext(ctx, "mas", "install", id)
 	return err
 }
 
-// Uninstall is not supported for Mac App Store apps
-func (m *MasInstaller) Uninstall(pkg brewfile.Package) error {
-	// mas doesn't support uninstall, need to use the App Store or manual deletion
-	return nil
+// Uninstall is not supported for Mac App Store apps: they are removed from
+// /Applications or Launchpad by hand
+func (m *MasInstaller) Uninstall(ctx context.Context, pkg brewfile.Package) error {
+	return &UnsupportedError{Type: brewfile.TypeMas, Operation: "uninstall", Reason: ManualRemoval}
 }
 
 // IsAvailable checks if mas CLI is available
 func (m *MasInstaller) IsAvailable() bool {
 	return m.runner.Exists("mas")
 }
+
+// Capabilities reports that mas can list, version and upgrade apps but not
+// remove them
+func (m *MasInstaller) Capabilities() Capabilities {
+	return Capabilities{List: true, Versions: true, Upgrade: true}
+}
+
+// masOutdatedPattern matches "497799835 Xcode (15.0 -> 15.1)"
+var masOutdatedPattern = regexp.MustCompile(`^(\d+)\s+(.+?)\s+\((\S+)\s+->\s+(\S+)\)$`)
+
+// Outdated returns the Mac App Store apps with an update available
+func (m *MasInstaller) Outdated(ctx context.Context) ([]Outdated, error) {
+	lines, err := m.runner.RunLinesContext(ctx, "mas", "outdated")
+	if err != nil {
+		return nil, err
+	}
+	return parseMasOutdated(lines), nil
+}
+
+// parseMasOutdated parses `mas outdated` lines
+func parseMasOutdated(lines []string) []Outdated {
+	var outdated []Outdated
+	for _, line := range lines {
+		matches := masOutdatedPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if matches == nil {
+			continue
+		}
+		pkg := brewfile.NewPackage(brewfile.TypeMas, matches[1])
+		pkg.FullName = matches[2]
+		pkg = pkg.WithOption("id", matches[1])
+		outdated = append(outdated, Outdated{Package: pkg, Installed: matches[3], Latest: matches[4]})
+	}
+	return outdated
+}
+
+// Upgrade updates a Mac App Store app by ID
+func (m *MasInstaller) Upgrade(ctx context.Context, pkg brewfile.Package) error {
+	id := pkg.Name
+	if idOpt, ok := pkg.Options["id"]; ok {
+		id = idOpt.String()
+	}
+	_, err := m.runner.RunContext(ctx, "mas", "upgrade", id)
+	return err
+}
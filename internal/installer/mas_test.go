@@ -1,10 +1,12 @@
 package installer
 
 import (
+	"context"
 	"testing"
 
 	"github.com/andrew-sameh/brewsync/internal/brewfile"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewMasInstaller(t *testing.T) {
@@ -25,7 +27,7 @@ func TestMasInstaller_List(t *testing.T) {
 		t.Skip("mas CLI not available")
 	}
 
-	apps, err := inst.List()
+	apps, err := inst.List(context.Background())
 	assert.NoError(t, err)
 
 	// Verify all returned packages are mas type
@@ -40,12 +42,12 @@ func TestMasInstaller_List(t *testing.T) {
 func TestMasListPattern(t *testing.T) {
 	testCases := []struct {
 		input    string
-		expected []string // [id, name] or nil if no match
+		expected []string // [id, name, version] or nil if no match
 	}{
-		{"497799835 Xcode (15.2)", []string{"497799835", "Xcode"}},
-		{"1480933944 Vimari (2.2)", []string{"1480933944", "Vimari"}},
-		{"899247664 TestFlight (3.3.0)", []string{"899247664", "TestFlight"}},
-		{"1274495053 Microsoft To Do (2.99)", []string{"1274495053", "Microsoft To Do"}},
+		{"497799835 Xcode (15.2)", []string{"497799835", "Xcode", "15.2"}},
+		{"1480933944 Vimari (2.2)", []string{"1480933944", "Vimari", "2.2"}},
+		{"899247664 TestFlight (3.3.0)", []string{"899247664", "TestFlight", "3.3.0"}},
+		{"1274495053 Microsoft To Do (2.99)", []string{"1274495053", "Microsoft To Do", "2.99"}},
 		{"invalid line", nil},
 		{"", nil},
 		{"123 NoVersion", nil},
@@ -60,6 +62,7 @@ func TestMasListPattern(t *testing.T) {
 				assert.NotNil(t, matches)
 				assert.Equal(t, tc.expected[0], matches[1]) // id
 				assert.Equal(t, tc.expected[1], matches[2]) // name
+				assert.Equal(t, tc.expected[2], matches[3]) // version
 			}
 		})
 	}
@@ -73,7 +76,11 @@ func TestMasInstaller_Uninstall_NotSupported(t *testing.T) {
 	inst := NewMasInstaller()
 	pkg := brewfile.NewPackage(brewfile.TypeMas, "123")
 
-	// Uninstall should return nil (not supported)
-	err := inst.Uninstall(pkg)
-	assert.NoError(t, err)
+	// Uninstall is refused instead of silently succeeding
+	err := inst.Uninstall(context.Background(), pkg)
+	var unsupported *UnsupportedError
+	require.ErrorAs(t, err, &unsupported)
+	assert.Equal(t, "uninstall", unsupported.Operation)
+	assert.Equal(t, FailureUnsupported, Classify(err))
+	assert.False(t, inst.Capabilities().Uninstall)
 }
@@ -0,0 +1,85 @@
+package installer
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
+)
+
+// Outdated is an installed package with a newer version available
+type Outdated struct {
+	Package   brewfile.Package
+	Installed string
+	Latest    string
+	// Pinned formulae are reported but skipped by `brew upgrade`
+	Pinned bool
+}
+
+// Upgrader is implemented by installers that can report outdated packages
+// and upgrade them; they declare Capabilities.Upgrade
+type Upgrader interface {
+	Outdated(ctx context.Context) ([]Outdated, error)
+	Upgrade(ctx context.Context, pkg brewfile.Package) error
+}
+
+// compareVersions compares two dotted version strings such as "1.10.2" and
+// "v1.9.0", returning -1, 0 or 1. Numeric parts compare as numbers, other
+// parts as strings, and a release sorts after its pre-releases.
+func compareVersions(a, b string) int {
+	a, preA, _ := strings.Cut(strings.TrimPrefix(a, "v"), "-")
+	b, preB, _ := strings.Cut(strings.TrimPrefix(b, "v"), "-")
+
+	partsA := strings.Split(a, ".")
+	partsB := strings.Split(b, ".")
+	for i := 0; i < len(partsA) || i < len(partsB); i++ {
+		var pa, pb string
+		if i < len(partsA) {
+			pa = partsA[i]
+		}
+		if i < len(partsB) {
+			pb = partsB[i]
+		}
+		if c := comparePart(pa, pb); c != 0 {
+			return c
+		}
+	}
+
+	switch {
+	case preA == preB:
+		return 0
+	case preA == "":
+		return 1
+	case preB == "":
+		return -1
+	}
+	return comparePart(preA, preB)
+}
+
+// comparePart compares one version component; a missing component counts as 0
+func comparePart(a, b string) int {
+	if a == "" {
+		a = "0"
+	}
+	if b == "" {
+		b = "0"
+	}
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	if errA == nil && errB == nil {
+		switch {
+		case na < nb:
+			return -1
+		case na > nb:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(a, b)
+}
+
+// newer reports whether latest is a newer version than installed
+func newer(installed, latest string) bool {
+	return latest != "" && compareVersions(installed, latest) < 0
+}
@@ -0,0 +1,178 @@
+package installer
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.2.3", "1.10.0", -1},
+		{"v0.15.0", "v0.14.2", 1},
+		{"1.2", "1.2.0", 0},
+		{"1.2.0-rc1", "1.2.0", -1},
+		{"1.2.0", "1.2.0-rc1", 1},
+		{"2024.2.1", "2024.10.0", -1},
+		{"15.0", "15.1", -1},
+		{"1.0.0_1", "1.0.0_2", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			assert.Equal(t, tt.want, compareVersions(tt.a, tt.b))
+		})
+	}
+}
+
+func TestParseBrewOutdated(t *testing.T) {
+	data := []byte(`{
+		"formulae": [
+			{"name": "git", "installed_versions": ["2.43.0", "2.43.1"], "current_version": "2.44.0", "pinned": false, "pinned_version": null},
+			{"name": "node@18", "installed_versions": ["18.19.0"], "current_version": "18.20.0", "pinned": true, "pinned_version": "18.19.0"}
+		],
+		"casks": [
+			{"name": "firefox", "installed_versions": "122.0", "current_version": "123.0"},
+			{"name": "raycast", "installed_versions": ["1.66.0"], "current_version": "1.67.0"}
+		]
+	}`)
+
+	outdated, err := parseBrewOutdated(data)
+	require.NoError(t, err)
+	require.Len(t, outdated, 4)
+
+	assert.Equal(t, Outdated{Package: brewfile.NewPackage(brewfile.TypeBrew, "git"), Installed: "2.43.1", Latest: "2.44.0"}, outdated[0])
+	assert.True(t, outdated[1].Pinned)
+	assert.Equal(t, "cask:firefox", outdated[2].Package.ID())
+	assert.Equal(t, "122.0", outdated[2].Installed, "older Homebrew reports cask versions as a string")
+	assert.Equal(t, "1.66.0", outdated[3].Installed)
+
+	_, err = parseBrewOutdated([]byte("Error: not json"))
+	assert.Error(t, err)
+}
+
+func TestParseMasOutdated(t *testing.T) {
+	outdated := parseMasOutdated([]string{
+		"497799835 Xcode (15.0 -> 15.1)",
+		"1333542190  1Password 7 - Password Manager  (7.9.10 -> 7.9.11)",
+		"Warning: something",
+		"",
+	})
+	require.Len(t, outdated, 2)
+
+	assert.Equal(t, "497799835", outdated[0].Package.Name)
+	assert.Equal(t, "Xcode", outdated[0].Package.FullName)
+	assert.Equal(t, "15.0", outdated[0].Installed)
+	assert.Equal(t, "15.1", outdated[0].Latest)
+	assert.Equal(t, "1Password 7 - Password Manager", outdated[1].Package.FullName)
+}
+
+func TestParseGoListVersion(t *testing.T) {
+	version, err := parseGoListVersion([]byte(`{"Path": "golang.org/x/tools/gopls", "Version": "v0.16.1", "Time": "2024-07-03T00:00:00Z"}`))
+	require.NoError(t, err)
+	assert.Equal(t, "v0.16.1", version)
+
+	_, err = parseGoListVersion([]byte("go: module not found"))
+	assert.Error(t, err)
+}
+
+func TestMarketplaceGallery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		var body struct {
+			Filters []struct {
+				Criteria []struct {
+					FilterType int    `json:"filterType"`
+					Value      string `json:"value"`
+				} `json:"criteria"`
+			} `json:"filters"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Len(t, body.Filters[0].Criteria, 3, "target plus one criterion per extension")
+
+		_, _ = w.Write([]byte(`{"results": [{"extensions": [
+			{"extensionName": "go", "publisher": {"publisherName": "golang"}, "versions": [
+				{"version": "0.42.0", "properties": [{"key": "Microsoft.VisualStudio.Code.PreRelease", "value": "true"}]},
+				{"version": "0.41.2", "properties": []}
+			]},
+			{"extensionName": "python", "publisher": {"publisherName": "ms-python"}, "versions": [
+				{"version": "2024.2.1"}
+			]}
+		]}]}`))
+	}))
+	defer server.Close()
+
+	g := &marketplaceGallery{url: server.URL, client: server.Client()}
+	latest, err := g.Latest(context.Background(), []string{"golang.Go", "ms-python.python"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"golang.go": "0.41.2", "ms-python.python": "2024.2.1"}, latest,
+		"pre-releases are skipped")
+}
+
+func TestOpenVSXGallery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/golang/Go/latest":
+			_, _ = w.Write([]byte(`{"namespace": "golang", "name": "go", "version": "0.41.2"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	g := &openVSXGallery{url: server.URL, client: server.Client()}
+	latest, err := g.Latest(context.Background(), []string{"golang.Go", "ms-vscode.cpptools"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"golang.go": "0.41.2"}, latest, "unpublished extensions are left out")
+}
+
+// fakeGallery serves fixed latest versions
+type fakeGallery map[string]string
+
+func (f fakeGallery) Latest(ctx context.Context, ids []string) (map[string]string, error) {
+	return f, nil
+}
+
+func TestOutdatedExtensions(t *testing.T) {
+	installed := map[string]string{
+		"cursor:golang.Go":         "0.40.0",
+		"cursor:esbenp.prettier":   "10.1.0",
+		"cursor:private.extension": "1.0.0",
+	}
+	gallery := fakeGallery{"golang.go": "0.41.2", "esbenp.prettier": "10.1.0"}
+
+	outdated, err := outdatedExtensions(context.Background(), installed, brewfile.TypeCursor, gallery)
+	require.NoError(t, err)
+	assert.Equal(t, []Outdated{{
+		Package:   brewfile.NewPackage(brewfile.TypeCursor, "golang.Go"),
+		Installed: "0.40.0",
+		Latest:    "0.41.2",
+	}}, outdated)
+}
+
+func TestManager_UpgradeUnsupported(t *testing.T) {
+	m := NewManager()
+	assert.True(t, m.Capabilities(brewfile.TypeBrew).Upgrade)
+
+	require.NoError(t, brewfile.RegisterType(brewfile.CustomType{
+		Name:      "custom_upgrade_tool",
+		List:      "brewsync-missing-tool list",
+		Install:   "brewsync-missing-tool install {name}",
+		Uninstall: "brewsync-missing-tool uninstall {name}",
+	}))
+	defer brewfile.UnregisterType("custom_upgrade_tool")
+
+	err := m.Upgrade(context.Background(), brewfile.NewPackage("custom_upgrade_tool", "thing"))
+	assert.Equal(t, FailureUnsupported, Classify(err))
+	assert.Equal(t, 0, Attempts(err))
+}
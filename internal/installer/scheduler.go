@@ -0,0 +1,332 @@
+package installer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
+)
+
+// DefaultLimits is the number of packages each backend may install at once.
+// Homebrew holds a global lock, so taps, formulae and casks are installed one
+// at a time; editor extensions and Go tools are independent of each other.
+var DefaultLimits = map[string]int{
+	"brew":        1,
+	"mas":         1,
+	"vscode":      4,
+	"cursor":      4,
+	"antigravity": 4,
+	"go":          4,
+}
+
+// Backend returns the tool that installs a package type. Packages sharing a
+// backend share its concurrency limit.
+func Backend(pkgType brewfile.PackageType) string {
+	switch pkgType {
+	case brewfile.TypeTap, brewfile.TypeBrew, brewfile.TypeCask:
+		return "brew"
+	default:
+		return string(pkgType)
+	}
+}
+
+// MaxBatch is the largest number of packages installed by one batched
+// backend call
+const MaxBatch = 25
+
+// Batchable reports whether packages of a type can be installed together in
+// one backend call (`brew install a b c`, repeated --install-extension)
+func Batchable(pkgType brewfile.PackageType) bool {
+	switch pkgType {
+	case brewfile.TypeBrew, brewfile.TypeCask:
+		return true
+	default:
+		return brewfile.IsEditorType(pkgType)
+	}
+}
+
+// stage returns the install order of a package type: taps, then formulae
+// and casks, then everything that may be provided by a formula or cask
+// (editors, mas, go)
+func stage(pkgType brewfile.PackageType) int {
+	switch pkgType {
+	case brewfile.TypeTap:
+		return 0
+	case brewfile.TypeBrew, brewfile.TypeCask:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Task is a package in an install plan
+type Task struct {
+	Package brewfile.Package
+	// After lists the tasks that must finish before this one starts
+	After []int
+	// Requires lists the tasks that must succeed for this one to be
+	// attempted, e.g. the tap of a fully qualified formula
+	Requires []int
+}
+
+// Plan builds the dependency DAG for installing packages. Every package waits
+// for all packages of earlier stages; formulae and casks named
+// "user/repo/name" also require the tap "user/repo" when it is in the list.
+func Plan(packages brewfile.Packages) []Task {
+	tasks := make([]Task, len(packages))
+	taps := make(map[string]int)
+	for i, pkg := range packages {
+		tasks[i].Package = pkg
+		if pkg.Type == brewfile.TypeTap {
+			taps[strings.ToLower(pkg.Name)] = i
+		}
+	}
+
+	for i, pkg := range packages {
+		s := stage(pkg.Type)
+		for j, other := range packages {
+			if stage(other.Type) < s {
+				tasks[i].After = append(tasks[i].After, j)
+			}
+		}
+		if tap, ok := formulaTap(pkg); ok {
+			if j, ok := taps[tap]; ok {
+				tasks[i].Requires = append(tasks[i].Requires, j)
+			}
+		}
+	}
+	return tasks
+}
+
+// formulaTap returns the tap of a fully qualified formula or cask name
+func formulaTap(pkg brewfile.Package) (string, bool) {
+	if pkg.Type != brewfile.TypeBrew && pkg.Type != brewfile.TypeCask {
+		return "", false
+	}
+	parts := strings.Split(pkg.Name, "/")
+	if len(parts) != 3 {
+		return "", false
+	}
+	return strings.ToLower(parts[0] + "/" + parts[1]), true
+}
+
+// InterruptedError is returned by batch operations whose context was
+// cancelled. Packages in flight are reported with their own errors;
+// Skipped lists the packages that were never attempted.
+type InterruptedError struct {
+	Err     error
+	Skipped brewfile.Packages
+}
+
+func (e *InterruptedError) Error() string {
+	return fmt.Sprintf("interrupted: %d packages not attempted: %v", len(e.Skipped), e.Err)
+}
+
+func (e *InterruptedError) Unwrap() error {
+	return e.Err
+}
+
+// BatchFunc installs several packages of one type together and reports each
+// package's result exactly once through onDone, which must not be called
+// concurrently. Packages left unreported when ctx is cancelled count as not
+// attempted.
+type BatchFunc func(ctx context.Context, pkgs brewfile.Packages, onDone func(pkg brewfile.Package, err error))
+
+// RunPlan executes the tasks of a plan. A task starts once everything it comes
+// after has finished and its backend has a free slot (limits are keyed by
+// Backend; missing entries allow one at a time). When batch is set, ready
+// tasks of the same Batchable type are started together as one batch of up
+// to MaxBatch packages that takes a single backend slot. Tasks whose required
+// tasks failed are skipped with an error. onStart and onDone are called from
+// the calling goroutine, never concurrently; done counts finished tasks.
+// Returns the last error, or an *InterruptedError once ctx is cancelled
+// and the tasks in flight have stopped.
+func RunPlan(
+	ctx context.Context,
+	tasks []Task,
+	limits map[string]int,
+	install func(ctx context.Context, pkg brewfile.Package) error,
+	batch BatchFunc,
+	onStart func(pkg brewfile.Package),
+	onDone func(pkg brewfile.Package, done, total int, err error),
+) error {
+	// result reports a finished task, or with index -1 that a unit (a single
+	// task or a batch) released its backend slot
+	type result struct {
+		index   int
+		err     error
+		backend string
+	}
+
+	total := len(tasks)
+	waiting := make([]int, total)
+	dependents := make([][]int, total)
+	for i, task := range tasks {
+		for _, j := range append(append([]int(nil), task.After...), task.Requires...) {
+			waiting[i]++
+			dependents[j] = append(dependents[j], i)
+		}
+	}
+
+	errs := make([]error, total)
+	finished := make([]bool, total)
+	running := make(map[string]int)
+	results := make(chan result)
+	var ready []int
+	var lastErr error
+	done, inFlight := 0, 0
+
+	for i := range tasks {
+		if waiting[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	finish := func(i int, err error) {
+		finished[i] = true
+		errs[i] = err
+		done++
+		if err != nil {
+			lastErr = err
+		}
+		if onDone != nil {
+			onDone(tasks[i].Package, done, total, err)
+		}
+		for _, d := range dependents[i] {
+			waiting[d]--
+			if waiting[d] == 0 && !finished[d] {
+				ready = append(ready, d)
+			}
+		}
+	}
+
+	receive := func(r result) {
+		if r.index < 0 {
+			inFlight--
+			running[r.backend]--
+			return
+		}
+		finish(r.index, r.err)
+	}
+
+	// start launches a unit of tasks sharing a backend slot
+	start := func(unit []int) {
+		backend := Backend(tasks[unit[0]].Package.Type)
+		running[backend]++
+		inFlight++
+		pkgs := make(brewfile.Packages, len(unit))
+		for k, i := range unit {
+			pkgs[k] = tasks[i].Package
+			if onStart != nil {
+				onStart(tasks[i].Package)
+			}
+		}
+		go func() {
+			if len(unit) == 1 {
+				results <- result{index: unit[0], err: install(ctx, pkgs[0])}
+			} else {
+				indexOf := make(map[string]int, len(unit))
+				for k, i := range unit {
+					indexOf[pkgs[k].ID()] = i
+				}
+				batch(ctx, pkgs, func(pkg brewfile.Package, err error) {
+					results <- result{index: indexOf[pkg.ID()], err: err}
+				})
+			}
+			results <- result{index: -1, backend: backend}
+		}()
+	}
+
+	for done < total || inFlight > 0 {
+		if ctx.Err() != nil {
+			break
+		}
+
+		// Start every ready task whose backend has capacity, in plan order.
+		// Skipping a task can make its dependents ready, so ready is
+		// drained rather than ranged over.
+		var blocked []int
+		for len(ready) > 0 {
+			i := ready[0]
+			ready = ready[1:]
+			task := tasks[i]
+
+			if failed, ok := failedRequirement(tasks, i, errs); ok {
+				finish(i, &InstallError{
+					Package: task.Package,
+					Kind:    FailureSkipped,
+					Err:     fmt.Errorf("skipped: %s failed", failed.ID()),
+				})
+				continue
+			}
+
+			backend := Backend(task.Package.Type)
+			limit, ok := limits[backend]
+			if !ok && brewfile.IsEditorType(task.Package.Type) {
+				// Configured editors share the limit of the built-in ones
+				limit = limits[string(brewfile.TypeVSCode)]
+			}
+			if limit < 1 {
+				limit = 1
+			}
+			if running[backend] >= limit {
+				blocked = append(blocked, i)
+				continue
+			}
+
+			unit := []int{i}
+			if batch != nil && Batchable(task.Package.Type) {
+				var rest []int
+				for _, j := range ready {
+					if len(unit) < MaxBatch && tasks[j].Package.Type == task.Package.Type {
+						if _, failed := failedRequirement(tasks, j, errs); !failed {
+							unit = append(unit, j)
+							continue
+						}
+					}
+					rest = append(rest, j)
+				}
+				ready = rest
+			}
+			start(unit)
+		}
+		ready = blocked
+
+		if inFlight == 0 {
+			break
+		}
+
+		receive(<-results)
+		sort.Ints(ready)
+	}
+
+	if ctx.Err() != nil {
+		// Let the installs in flight observe the cancellation and report
+		for inFlight > 0 {
+			receive(<-results)
+		}
+		var skipped brewfile.Packages
+		for i, task := range tasks {
+			if !finished[i] {
+				skipped = append(skipped, task.Package)
+			}
+		}
+		if len(skipped) > 0 {
+			return &InterruptedError{Err: ctx.Err(), Skipped: skipped}
+		}
+	}
+
+	return lastErr
+}
+
+// failedRequirement returns the first required package that failed
+func failedRequirement(tasks []Task, i int, errs []error) (brewfile.Package, bool) {
+	for _, j := range tasks[i].Requires {
+		if errs[j] != nil {
+			return tasks[j].Package, true
+		}
+	}
+	return brewfile.Package{}, false
+}
@@ -0,0 +1,270 @@
+package installer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBackend(t *testing.T) {
+	assert.Equal(t, "brew", Backend(brewfile.TypeTap))
+	assert.Equal(t, "brew", Backend(brewfile.TypeBrew))
+	assert.Equal(t, "brew", Backend(brewfile.TypeCask))
+	assert.Equal(t, "vscode", Backend(brewfile.TypeVSCode))
+	assert.Equal(t, "go", Backend(brewfile.TypeGo))
+}
+
+func TestPlan(t *testing.T) {
+	packages := brewfile.Packages{
+		brewfile.NewPackage(brewfile.TypeVSCode, "golang.go"),
+		brewfile.NewPackage(brewfile.TypeBrew, "oven-sh/bun/bun"),
+		brewfile.NewPackage(brewfile.TypeTap, "oven-sh/bun"),
+		brewfile.NewPackage(brewfile.TypeBrew, "git"),
+	}
+
+	tasks := Plan(packages)
+	require.Len(t, tasks, 4)
+
+	assert.Equal(t, []int{1, 2, 3}, tasks[0].After, "extensions wait for taps, formulae and casks")
+	assert.Equal(t, []int{2}, tasks[1].After)
+	assert.Equal(t, []int{2}, tasks[1].Requires, "qualified formula requires its tap")
+	assert.Empty(t, tasks[2].After)
+	assert.Equal(t, []int{2}, tasks[3].After)
+	assert.Empty(t, tasks[3].Requires)
+}
+
+// recorder is a fake install function that tracks order and concurrency
+type recorder struct {
+	mu       sync.Mutex
+	started  []string
+	running  map[string]int
+	peak     map[string]int
+	failures map[string]bool
+	batches  [][]string
+}
+
+func newRecorder(failures ...string) *recorder {
+	r := &recorder{running: map[string]int{}, peak: map[string]int{}, failures: map[string]bool{}}
+	for _, id := range failures {
+		r.failures[id] = true
+	}
+	return r
+}
+
+func (r *recorder) install(ctx context.Context, pkg brewfile.Package) error {
+	backend := Backend(pkg.Type)
+	r.mu.Lock()
+	r.started = append(r.started, pkg.ID())
+	r.running[backend]++
+	if r.running[backend] > r.peak[backend] {
+		r.peak[backend] = r.running[backend]
+	}
+	r.mu.Unlock()
+
+	var err error
+	select {
+	case <-time.After(10 * time.Millisecond):
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	r.mu.Lock()
+	r.running[backend]--
+	r.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+	if r.failures[pkg.ID()] {
+		return errors.New("install failed")
+	}
+	return nil
+}
+
+// batch is a fake BatchFunc that records each batch and reports every
+// package through install's failure rules
+func (r *recorder) batch(ctx context.Context, pkgs brewfile.Packages, onDone func(pkg brewfile.Package, err error)) {
+	r.mu.Lock()
+	r.batches = append(r.batches, packageIDs(pkgs))
+	r.mu.Unlock()
+	for _, pkg := range pkgs {
+		onDone(pkg, r.install(ctx, pkg))
+	}
+}
+
+func TestRunPlan(t *testing.T) {
+	limits := map[string]int{"brew": 1, "vscode": 3, "go": 2}
+
+	t.Run("respects stages and backend limits", func(t *testing.T) {
+		packages := brewfile.Packages{
+			brewfile.NewPackage(brewfile.TypeVSCode, "a.one"),
+			brewfile.NewPackage(brewfile.TypeVSCode, "a.two"),
+			brewfile.NewPackage(brewfile.TypeVSCode, "a.three"),
+			brewfile.NewPackage(brewfile.TypeGo, "example.com/x"),
+			brewfile.NewPackage(brewfile.TypeGo, "example.com/y"),
+			brewfile.NewPackage(brewfile.TypeBrew, "git"),
+			brewfile.NewPackage(brewfile.TypeCask, "raycast"),
+			brewfile.NewPackage(brewfile.TypeTap, "homebrew/bundle"),
+		}
+		r := newRecorder()
+
+		var done []int
+		err := RunPlan(context.Background(), Plan(packages), limits, r.install, nil, nil, func(pkg brewfile.Package, i, total int, err error) {
+			done = append(done, i)
+			assert.Equal(t, 8, total)
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"tap:homebrew/bundle", "brew:git", "cask:raycast"}, r.started[:3])
+		assert.ElementsMatch(t, []string{"vscode:a.one", "vscode:a.two", "vscode:a.three", "go:example.com/x", "go:example.com/y"}, r.started[3:])
+		assert.Equal(t, 1, r.peak["brew"])
+		assert.Equal(t, 3, r.peak["vscode"])
+		assert.Equal(t, 2, r.peak["go"])
+		assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8}, done)
+	})
+
+	t.Run("skips packages whose tap failed", func(t *testing.T) {
+		packages := brewfile.Packages{
+			brewfile.NewPackage(brewfile.TypeTap, "oven-sh/bun"),
+			brewfile.NewPackage(brewfile.TypeBrew, "oven-sh/bun/bun"),
+			brewfile.NewPackage(brewfile.TypeBrew, "git"),
+		}
+		r := newRecorder("tap:oven-sh/bun")
+
+		errs := map[string]error{}
+		var started []string
+		err := RunPlan(context.Background(), Plan(packages), limits, r.install, nil, func(pkg brewfile.Package) {
+			started = append(started, pkg.ID())
+		}, func(pkg brewfile.Package, i, total int, err error) {
+			errs[pkg.ID()] = err
+		})
+		require.Error(t, err)
+
+		assert.Equal(t, []string{"tap:oven-sh/bun", "brew:git"}, r.started)
+		assert.Equal(t, r.started, started)
+		require.Len(t, errs, 3)
+		assert.EqualError(t, errs["brew:oven-sh/bun/bun"], "skipped: tap:oven-sh/bun failed")
+		assert.Equal(t, FailureSkipped, Classify(errs["brew:oven-sh/bun/bun"]))
+		assert.NoError(t, errs["brew:git"])
+	})
+
+	t.Run("cancellation skips the rest", func(t *testing.T) {
+		packages := brewfile.Packages{
+			brewfile.NewPackage(brewfile.TypeBrew, "git"),
+			brewfile.NewPackage(brewfile.TypeBrew, "jq"),
+			brewfile.NewPackage(brewfile.TypeBrew, "fzf"),
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		r := newRecorder()
+
+		var reported []string
+		err := RunPlan(ctx, Plan(packages), limits, r.install, nil, func(pkg brewfile.Package) {
+			// Cancel while the first package is installing
+			cancel()
+		}, func(pkg brewfile.Package, i, total int, err error) {
+			reported = append(reported, pkg.ID())
+			assert.ErrorIs(t, err, context.Canceled)
+		})
+
+		var interrupted *InterruptedError
+		require.ErrorAs(t, err, &interrupted)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, []string{"brew:git"}, reported)
+		assert.Equal(t, []string{"brew:jq", "brew:fzf"}, packageIDs(interrupted.Skipped))
+	})
+
+	t.Run("batches ready packages of one type", func(t *testing.T) {
+		packages := brewfile.Packages{
+			brewfile.NewPackage(brewfile.TypeTap, "oven-sh/bun"),
+			brewfile.NewPackage(brewfile.TypeBrew, "git"),
+			brewfile.NewPackage(brewfile.TypeBrew, "oven-sh/bun/bun"),
+			brewfile.NewPackage(brewfile.TypeCask, "raycast"),
+			brewfile.NewPackage(brewfile.TypeBrew, "jq"),
+			brewfile.NewPackage(brewfile.TypeVSCode, "a.one"),
+			brewfile.NewPackage(brewfile.TypeVSCode, "a.two"),
+			brewfile.NewPackage(brewfile.TypeGo, "example.com/x"),
+		}
+		r := newRecorder("brew:jq")
+
+		var started []string
+		errs := map[string]error{}
+		var last int
+		err := RunPlan(context.Background(), Plan(packages), limits, r.install, r.batch, func(pkg brewfile.Package) {
+			started = append(started, pkg.ID())
+		}, func(pkg brewfile.Package, i, total int, err error) {
+			errs[pkg.ID()] = err
+			last = i
+		})
+		require.Error(t, err)
+
+		assert.Equal(t, [][]string{
+			{"brew:git", "brew:oven-sh/bun/bun", "brew:jq"},
+			{"vscode:a.one", "vscode:a.two"},
+		}, r.batches, "taps and go tools are not batched; casks alone form no batch")
+		assert.Len(t, started, 8)
+		assert.Equal(t, 8, last)
+		assert.Error(t, errs["brew:jq"])
+		assert.NoError(t, errs["brew:git"])
+		assert.NoError(t, errs["cask:raycast"])
+		assert.Equal(t, 1, r.peak["brew"], "a batch holds the backend's only slot")
+	})
+
+	t.Run("batch skips packages whose tap failed", func(t *testing.T) {
+		packages := brewfile.Packages{
+			brewfile.NewPackage(brewfile.TypeTap, "oven-sh/bun"),
+			brewfile.NewPackage(brewfile.TypeBrew, "git"),
+			brewfile.NewPackage(brewfile.TypeBrew, "oven-sh/bun/bun"),
+			brewfile.NewPackage(brewfile.TypeBrew, "jq"),
+		}
+		r := newRecorder("tap:oven-sh/bun")
+
+		errs := map[string]error{}
+		err := RunPlan(context.Background(), Plan(packages), limits, r.install, r.batch, nil, func(pkg brewfile.Package, i, total int, err error) {
+			errs[pkg.ID()] = err
+		})
+		require.Error(t, err)
+
+		assert.Equal(t, [][]string{{"brew:git", "brew:jq"}}, r.batches)
+		assert.Equal(t, FailureSkipped, Classify(errs["brew:oven-sh/bun/bun"]))
+	})
+
+	t.Run("every task is reported when skipping frees dependents", func(t *testing.T) {
+		packages := brewfile.Packages{
+			brewfile.NewPackage(brewfile.TypeTap, "user/repo"),
+			brewfile.NewPackage(brewfile.TypeBrew, "user/repo/x"),
+			brewfile.NewPackage(brewfile.TypeGo, "example.com/tool"),
+		}
+		for _, batch := range []BatchFunc{nil, newRecorder("tap:user/repo").batch} {
+			r := newRecorder("tap:user/repo")
+
+			errs := map[string]error{}
+			err := RunPlan(context.Background(), Plan(packages), limits, r.install, batch, nil, func(pkg brewfile.Package, i, total int, err error) {
+				errs[pkg.ID()] = err
+			})
+			require.Error(t, err)
+
+			require.Len(t, errs, 3)
+			assert.Equal(t, FailureSkipped, Classify(errs["brew:user/repo/x"]))
+			assert.NoError(t, errs["go:example.com/tool"])
+			assert.Contains(t, r.started, "go:example.com/tool")
+		}
+	})
+
+	t.Run("empty plan", func(t *testing.T) {
+		assert.NoError(t, RunPlan(context.Background(), nil, limits, newRecorder().install, nil, nil, nil))
+	})
+}
+
+func packageIDs(pkgs brewfile.Packages) []string {
+	ids := make([]string, 0, len(pkgs))
+	for _, pkg := range pkgs {
+		ids = append(ids, pkg.ID())
+	}
+	return ids
+}
@@ -1,6 +1,7 @@
 package installer
 
 import (
+	"context"
 	"testing"
 
 	"github.com/andrew-sameh/brewsync/internal/brewfile"
@@ -26,7 +27,7 @@ func TestVSCodeInstaller_List(t *testing.T) {
 		t.Skip("VSCode CLI not available")
 	}
 
-	extensions, err := inst.List()
+	extensions, err := inst.List(context.Background())
 	assert.NoError(t, err)
 
 	// Verify all returned packages are vscode type
@@ -39,3 +40,17 @@ func TestVSCodeInstaller_List(t *testing.T) {
 func TestVSCodeInstaller_Install_Uninstall(t *testing.T) {
 	t.Skip("Skipping install/uninstall tests to avoid system modification")
 }
+
+func TestParseExtensionVersions(t *testing.T) {
+	versions := parseExtensionVersions([]string{
+		"golang.go@0.41.0",
+		"  ms-python.python@2024.0.1  ",
+		"no-version",
+		"",
+	}, brewfile.TypeVSCode)
+
+	assert.Equal(t, map[string]string{
+		"vscode:golang.go":        "0.41.0",
+		"vscode:ms-python.python": "2024.0.1",
+	}, versions)
+}
@@ -0,0 +1,196 @@
+package journal
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
+)
+
+// Action is what a sync step does to a package
+type Action string
+
+const (
+	ActionInstall   Action = "install"
+	ActionChange    Action = "change"
+	ActionUninstall Action = "uninstall"
+)
+
+// StepStatus is the state of a single step
+type StepStatus string
+
+const (
+	StepPending    StepStatus = "pending"
+	StepDone       StepStatus = "done"
+	StepFailed     StepStatus = "failed"
+	StepUndone     StepStatus = "undone"
+	StepUndoFailed StepStatus = "undo-failed"
+)
+
+// Status is the state of the sync a journal records
+type Status string
+
+const (
+	StatusRunning            Status = "running"
+	StatusCompleted          Status = "completed"
+	StatusFailed             Status = "failed"
+	StatusInterrupted        Status = "interrupted"
+	StatusRolledBack         Status = "rolled-back"
+	StatusRollbackIncomplete Status = "rollback-incomplete"
+)
+
+// Step is one package operation of a sync
+type Step struct {
+	Action  Action           `json:"action"`
+	Package brewfile.Package `json:"package"`
+	// Previous is the declaration a change replaced, so it can be changed back
+	Previous *brewfile.Package `json:"previous,omitempty"`
+	// Present marks an install of a package that was already installed
+	// before the sync, which a rollback must not remove
+	Present bool       `json:"present,omitempty"`
+	Status  StepStatus `json:"status"`
+	Error   string     `json:"error,omitempty"`
+}
+
+// Journal records every step of a sync before it runs and the outcome of
+// each step as it completes, so the sync can be rolled back afterwards.
+// It is saved after every update.
+type Journal struct {
+	Machine string    `json:"machine"`
+	Source  string    `json:"source"`
+	Started time.Time `json:"started"`
+	Status  Status    `json:"status"`
+	Steps   []Step    `json:"steps"`
+
+	path string
+}
+
+// New starts a journal for a sync of machine from source, saved at path
+func New(path, machine, source string) *Journal {
+	return &Journal{
+		Machine: machine,
+		Source:  source,
+		Started: time.Now(),
+		Status:  StatusRunning,
+		path:    path,
+	}
+}
+
+// Load reads the journal saved at path
+func Load(path string) (*Journal, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	j := &Journal{path: path}
+	if err := json.Unmarshal(data, j); err != nil {
+		return nil, fmt.Errorf("failed to parse journal: %w", err)
+	}
+	return j, nil
+}
+
+// Save writes the journal, replacing the previous one in a single rename so
+// a crash never leaves it half written
+func (j *Journal) Save() error {
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(j.path), 0755); err != nil {
+		return fmt.Errorf("failed to create journal directory: %w", err)
+	}
+	tmp := j.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	if err := os.Rename(tmp, j.path); err != nil {
+		return fmt.Errorf("failed to write journal: %w", err)
+	}
+	return nil
+}
+
+// Add plans a step for pkg
+func (j *Journal) Add(action Action, pkg brewfile.Package) {
+	j.Steps = append(j.Steps, Step{Action: action, Package: pkg, Status: StepPending})
+}
+
+// AddPresent plans an install step for a package that is already
+// installed, so a rollback leaves it in place
+func (j *Journal) AddPresent(pkg brewfile.Package) {
+	j.Steps = append(j.Steps, Step{Action: ActionInstall, Package: pkg, Present: true, Status: StepPending})
+}
+
+// AddChange plans a step converging a changed package to its source declaration
+func (j *Journal) AddChange(change brewfile.Change) {
+	previous := change.Current
+	j.Steps = append(j.Steps, Step{Action: ActionChange, Package: change.Source, Previous: &previous, Status: StepPending})
+}
+
+// Record marks the pending step for pkg as done, or failed when err is set,
+// and saves the journal
+func (j *Journal) Record(action Action, pkg brewfile.Package, err error) error {
+	for i := range j.Steps {
+		step := &j.Steps[i]
+		if step.Action != action || step.Status != StepPending || step.Package.ID() != pkg.ID() {
+			continue
+		}
+		step.Status = StepDone
+		if err != nil {
+			step.Status = StepFailed
+			step.Error = err.Error()
+		}
+		return j.Save()
+	}
+	return fmt.Errorf("no pending %s step for %s", action, pkg.ID())
+}
+
+// Finish sets the outcome of the sync and saves the journal
+func (j *Journal) Finish(status Status) error {
+	j.Status = status
+	return j.Save()
+}
+
+// Undoable returns the steps a rollback would undo, most recent first:
+// completed steps and those whose undo failed before
+func (j *Journal) Undoable() []Step {
+	var steps []Step
+	for _, i := range j.undoable() {
+		steps = append(steps, j.Steps[i])
+	}
+	return steps
+}
+
+// undoable returns the indexes of the undoable steps, most recent first.
+// Installs of packages that were already present are never undone.
+func (j *Journal) undoable() []int {
+	var indexes []int
+	for i := len(j.Steps) - 1; i >= 0; i-- {
+		if j.Steps[i].Present {
+			continue
+		}
+		switch j.Steps[i].Status {
+		case StepDone, StepUndoFailed:
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// Count returns the number of steps with the given status
+func (j *Journal) Count(status StepStatus) int {
+	n := 0
+	for _, step := range j.Steps {
+		if step.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// String describes a step, e.g. "install brew:git"
+func (s Step) String() string {
+	return fmt.Sprintf("%s %s", s.Action, s.Package.ID())
+}
@@ -0,0 +1,61 @@
+package journal
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
+)
+
+func TestJournal_RecordAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.json")
+	git := brewfile.NewPackage(brewfile.TypeBrew, "git")
+	slack := brewfile.NewPackage(brewfile.TypeCask, "slack")
+	bat := brewfile.NewPackage(brewfile.TypeBrew, "bat").WithValue("link", brewfile.BoolValue(false))
+	redis := brewfile.NewPackage(brewfile.TypeBrew, "redis")
+
+	j := New(path, "air", "mini")
+	j.Add(ActionInstall, git)
+	j.Add(ActionInstall, slack)
+	j.AddChange(brewfile.Change{Source: redis.WithService(true), Current: redis})
+	j.Add(ActionUninstall, bat)
+	require.NoError(t, j.Save())
+
+	require.NoError(t, j.Record(ActionInstall, git, nil))
+	require.NoError(t, j.Record(ActionInstall, slack, errors.New("download failed")))
+	require.NoError(t, j.Record(ActionUninstall, bat, nil))
+	assert.Error(t, j.Record(ActionUninstall, bat, nil), "step already recorded")
+	require.NoError(t, j.Finish(StatusFailed))
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, "air", loaded.Machine)
+	assert.Equal(t, "mini", loaded.Source)
+	assert.Equal(t, StatusFailed, loaded.Status)
+	require.Len(t, loaded.Steps, 4)
+	assert.Equal(t, StepDone, loaded.Steps[0].Status)
+	assert.Equal(t, StepFailed, loaded.Steps[1].Status)
+	assert.Equal(t, "download failed", loaded.Steps[1].Error)
+	assert.Equal(t, StepPending, loaded.Steps[2].Status)
+	require.NotNil(t, loaded.Steps[2].Previous)
+	assert.False(t, loaded.Steps[2].Previous.ServiceRunning())
+	assert.True(t, loaded.Steps[2].Package.ServiceRunning())
+	assert.Equal(t, bat.Options, loaded.Steps[3].Package.Options, "options survive the round trip")
+
+	// Most recent completed step first
+	var undo []string
+	for _, step := range loaded.Undoable() {
+		undo = append(undo, step.UndoString())
+	}
+	assert.Equal(t, []string{"reinstall brew:bat", "uninstall brew:git"}, undo)
+	assert.Equal(t, 2, loaded.Count(StepDone))
+}
+
+func TestLoad_Missing(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "journal.json"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,87 @@
+package journal
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
+	"github.com/andrew-sameh/brewsync/internal/installer"
+)
+
+// Rollback undoes the completed steps of a journal, most recent first,
+// where the backend can: packages the sync installed are uninstalled, removed
+// packages are installed again with the options they had, and changed
+// packages are converged back to their previous declaration. Each outcome
+// is saved as it completes. Cancelling ctx stops before the next step.
+func Rollback(ctx context.Context, mgr *installer.Manager, j *Journal, onStep func(step Step, i, total int, err error)) error {
+	indexes := j.undoable()
+	total := len(indexes)
+	var lastErr error
+
+	for n, i := range indexes {
+		if ctx.Err() != nil {
+			j.Status = StatusRollbackIncomplete
+			if err := j.Save(); err != nil {
+				return err
+			}
+			return ctx.Err()
+		}
+
+		step := &j.Steps[i]
+		err := undo(ctx, mgr, *step)
+		if err != nil {
+			step.Status = StepUndoFailed
+			step.Error = err.Error()
+			lastErr = err
+		} else {
+			step.Status = StepUndone
+			step.Error = ""
+		}
+		if onStep != nil {
+			onStep(*step, n+1, total, err)
+		}
+		if err := j.Save(); err != nil {
+			return err
+		}
+	}
+
+	j.Status = StatusRolledBack
+	if lastErr != nil {
+		j.Status = StatusRollbackIncomplete
+	}
+	if err := j.Save(); err != nil {
+		return err
+	}
+	return lastErr
+}
+
+// undo reverses a single completed step
+func undo(ctx context.Context, mgr *installer.Manager, step Step) error {
+	switch step.Action {
+	case ActionInstall:
+		return mgr.Uninstall(ctx, step.Package)
+	case ActionUninstall:
+		return mgr.Restore(ctx, step.Package)
+	case ActionChange:
+		if step.Previous == nil {
+			return fmt.Errorf("%s: previous declaration not recorded", step.Package.ID())
+		}
+		return mgr.Converge(ctx, brewfile.Change{Source: *step.Previous, Current: step.Package})
+	default:
+		return fmt.Errorf("unknown journal action %q", step.Action)
+	}
+}
+
+// UndoString describes how a step is undone, e.g. "uninstall brew:git"
+func (s Step) UndoString() string {
+	switch s.Action {
+	case ActionInstall:
+		return "uninstall " + s.Package.ID()
+	case ActionUninstall:
+		return "reinstall " + s.Package.ID()
+	case ActionChange:
+		return "revert " + s.Package.ID()
+	default:
+		return s.String()
+	}
+}
@@ -0,0 +1,120 @@
+package journal
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
+	"github.com/andrew-sameh/brewsync/internal/exec"
+	"github.com/andrew-sameh/brewsync/internal/installer"
+)
+
+// fakeManager returns a manager whose installers replay only the given
+// commands
+func fakeManager(t *testing.T, entries []exec.Entry) *installer.Manager {
+	fake := exec.NewFakeRunner(append([]exec.Entry{{Command: "brew", Lookup: true}}, entries...))
+	fake.Strict = true
+	saved := exec.Default
+	exec.Default = fake
+	t.Cleanup(func() { exec.Default = saved })
+	return installer.NewManager()
+}
+
+func TestRollback(t *testing.T) {
+	mgr := fakeManager(t, []exec.Entry{
+		{Command: "brew", Args: []string{"uninstall", "git"}},
+		{Command: "brew", Args: []string{"services", "stop", "redis"}},
+		{Command: "brew", Args: []string{"install", "bat"}},
+		{Command: "brew", Args: []string{"unlink", "bat"}},
+	})
+
+	git := brewfile.NewPackage(brewfile.TypeBrew, "git")
+	slack := brewfile.NewPackage(brewfile.TypeCask, "slack")
+	fzf := brewfile.NewPackage(brewfile.TypeBrew, "fzf")
+	redis := brewfile.NewPackage(brewfile.TypeBrew, "redis")
+	bat := brewfile.NewPackage(brewfile.TypeBrew, "bat").WithValue("link", brewfile.BoolValue(false))
+
+	j := New(filepath.Join(t.TempDir(), "journal.json"), "air", "mini")
+	j.Add(ActionInstall, git)
+	j.Add(ActionInstall, slack)
+	j.AddChange(brewfile.Change{Source: redis.WithService(true), Current: redis})
+	j.Add(ActionUninstall, bat)
+	j.Add(ActionUninstall, fzf)
+	require.NoError(t, j.Record(ActionInstall, git, nil))
+	require.NoError(t, j.Record(ActionInstall, slack, assert.AnError))
+	require.NoError(t, j.Record(ActionChange, redis, nil))
+	require.NoError(t, j.Record(ActionUninstall, bat, nil))
+
+	var undone []string
+	err := Rollback(context.Background(), mgr, j, func(step Step, i, total int, err error) {
+		assert.NoError(t, err)
+		assert.Equal(t, 3, total)
+		undone = append(undone, step.UndoString())
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"reinstall brew:bat", "revert brew:redis", "uninstall brew:git"}, undone)
+	assert.Equal(t, StatusRolledBack, j.Status)
+	assert.Equal(t, 3, j.Count(StepUndone))
+	assert.Equal(t, StepFailed, j.Steps[1].Status, "failed steps are not undone")
+	assert.Equal(t, StepPending, j.Steps[4].Status, "steps never run are not undone")
+	assert.Empty(t, j.Undoable())
+}
+
+func TestRollback_Incomplete(t *testing.T) {
+	mgr := fakeManager(t, []exec.Entry{
+		{Command: "brew", Args: []string{"uninstall", "git"}},
+	})
+
+	git := brewfile.NewPackage(brewfile.TypeBrew, "git")
+	fzf := brewfile.NewPackage(brewfile.TypeBrew, "fzf")
+	path := filepath.Join(t.TempDir(), "journal.json")
+	j := New(path, "air", "mini")
+	j.Add(ActionInstall, git)
+	j.Add(ActionUninstall, fzf)
+	require.NoError(t, j.Record(ActionInstall, git, nil))
+	require.NoError(t, j.Record(ActionUninstall, fzf, nil))
+
+	err := Rollback(context.Background(), mgr, j, nil)
+	assert.Error(t, err)
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, StatusRollbackIncomplete, loaded.Status)
+	assert.Equal(t, StepUndone, loaded.Steps[0].Status)
+	assert.Equal(t, StepUndoFailed, loaded.Steps[1].Status)
+	assert.NotEmpty(t, loaded.Steps[1].Error)
+
+	// A second rollback retries the step whose undo failed
+	require.Len(t, loaded.Undoable(), 1)
+	assert.Equal(t, "reinstall brew:fzf", loaded.Undoable()[0].UndoString())
+}
+
+func TestRollback_KeepsPresentPackages(t *testing.T) {
+	// The strict runner fails any uninstall of jq
+	mgr := fakeManager(t, []exec.Entry{
+		{Command: "brew", Args: []string{"uninstall", "git"}},
+	})
+
+	git := brewfile.NewPackage(brewfile.TypeBrew, "git")
+	jq := brewfile.NewPackage(brewfile.TypeBrew, "jq")
+	path := filepath.Join(t.TempDir(), "journal.json")
+	j := New(path, "air", "mini")
+	j.Add(ActionInstall, git)
+	j.AddPresent(jq)
+	require.NoError(t, j.Record(ActionInstall, git, nil))
+	require.NoError(t, j.Record(ActionInstall, jq, nil))
+
+	loaded, err := Load(path)
+	require.NoError(t, err)
+	assert.True(t, loaded.Steps[1].Present)
+	require.Len(t, loaded.Undoable(), 1)
+
+	require.NoError(t, Rollback(context.Background(), mgr, loaded, nil))
+	assert.Equal(t, StatusRolledBack, loaded.Status)
+	assert.Equal(t, StepUndone, loaded.Steps[0].Status)
+	assert.Equal(t, StepDone, loaded.Steps[1].Status, "packages installed before the sync stay installed")
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"gopkg.in/yaml.v3"
@@ -28,6 +29,8 @@ type Packages struct {
 	Cursor []string `yaml:"cursor,omitempty"`
 	Go     []string `yaml:"go,omitempty"`
 	Mas    []string `yaml:"mas,omitempty"`
+	// Custom holds packages of custom types, keyed by type name
+	Custom map[string][]string `yaml:",inline"`
 }
 
 // ToBrewfilePackages converts profile packages to brewfile.Packages
@@ -56,13 +59,28 @@ func (p *Packages) ToBrewfilePackages() brewfile.Packages {
 		result = append(result, brewfile.NewPackage(brewfile.TypeMas, name))
 	}
 
+	types := make([]string, 0, len(p.Custom))
+	for t := range p.Custom {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	for _, t := range types {
+		for _, name := range p.Custom[t] {
+			result = append(result, brewfile.NewPackage(brewfile.PackageType(t), name))
+		}
+	}
+
 	return result
 }
 
 // Count returns the total number of packages
 func (p *Packages) Count() int {
-	return len(p.Tap) + len(p.Brew) + len(p.Cask) +
+	count := len(p.Tap) + len(p.Brew) + len(p.Cask) +
 		len(p.VSCode) + len(p.Cursor) + len(p.Go) + len(p.Mas)
+	for _, names := range p.Custom {
+		count += len(names)
+	}
+	return count
 }
 
 // Load loads a profile by name
@@ -226,20 +244,11 @@ func GetPath(name string) (string, error) {
 
 // MergePackages merges packages from multiple profiles
 func MergePackages(profiles []*Profile) brewfile.Packages {
-	seen := make(map[string]bool)
-	var result brewfile.Packages
-
+	merged := brewfile.NewPackageSet()
 	for _, p := range profiles {
-		for _, pkg := range p.Packages.ToBrewfilePackages() {
-			key := pkg.ID()
-			if !seen[key] {
-				seen[key] = true
-				result = append(result, pkg)
-			}
-		}
+		merged.Add(p.Packages.ToBrewfilePackages()...)
 	}
-
-	return result
+	return merged.Packages()
 }
 
 // FromBrewfilePackages creates a Packages struct from brewfile.Packages
@@ -262,6 +271,13 @@ func FromBrewfilePackages(packages brewfile.Packages) Packages {
 			p.Go = append(p.Go, pkg.Name)
 		case brewfile.TypeMas:
 			p.Mas = append(p.Mas, pkg.Name)
+		default:
+			if brewfile.IsCustomType(pkg.Type) {
+				if p.Custom == nil {
+					p.Custom = make(map[string][]string)
+				}
+				p.Custom[string(pkg.Type)] = append(p.Custom[string(pkg.Type)], pkg.Name)
+			}
 		}
 	}
 
@@ -0,0 +1,44 @@
+package system
+
+import (
+	"runtime"
+	"strings"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
+	"github.com/andrew-sameh/brewsync/internal/config"
+	"github.com/andrew-sameh/brewsync/internal/exec"
+	"github.com/andrew-sameh/brewsync/internal/installer"
+)
+
+// Facts collects the system facts of the current machine. Facts that
+// cannot be determined are left empty.
+func Facts(brewsyncVersion string) brewfile.SystemFacts {
+	facts := brewfile.SystemFacts{
+		OS:              config.LocalOS(),
+		Arch:            brewfile.NormalizeArch(runtime.GOARCH),
+		BrewsyncVersion: brewsyncVersion,
+	}
+
+	if facts.OS == config.OSDarwin {
+		if out, err := exec.Run("sw_vers", "-productVersion"); err == nil {
+			facts.OSVersion = strings.TrimSpace(out)
+		}
+		// An x86_64 build running under Rosetta 2 is still on Apple Silicon
+		if out, err := exec.Run("sysctl", "-n", "sysctl.proc_translated"); err == nil && strings.TrimSpace(out) == "1" {
+			facts.Arch = "arm64"
+			facts.Rosetta = true
+		}
+	}
+
+	brew := installer.NewBrewInstaller()
+	if brew.IsAvailable() {
+		if prefix, err := brew.Prefix(); err == nil {
+			facts.HomebrewPrefix = prefix
+		}
+		if version, err := brew.Version(); err == nil {
+			facts.HomebrewVersion = version
+		}
+	}
+
+	return facts
+}
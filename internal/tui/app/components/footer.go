@@ -256,3 +256,14 @@ func IgnoreKeybindings() []KeyBinding {
 		{Key: "Esc", Desc: "Dashboard"},
 	}
 }
+
+// OutdatedKeybindings returns keybindings for the outdated screen
+func OutdatedKeybindings() []KeyBinding {
+	return []KeyBinding{
+		{Key: "j/k", Desc: "Navigate"},
+		{Key: "Space", Desc: "Toggle"},
+		{Key: "Enter", Desc: "Upgrade"},
+		{Key: "r", Desc: "Refresh"},
+		{Key: "Esc", Desc: "Dashboard"},
+	}
+}
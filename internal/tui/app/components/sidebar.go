@@ -114,5 +114,6 @@ func DefaultMenuItems() []MenuItem {
 		{Label: "0 Profiles", Screen: 9},
 		{Separator: true},
 		{Label: "! Doctor", Screen: 10},
+		{Label: "@ Outdated", Screen: 12},
 	}
 }
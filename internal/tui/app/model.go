@@ -1,6 +1,8 @@
 package app
 
 import (
+	"context"
+
 	"github.com/charmbracelet/bubbles/help"
 	tea "github.com/charmbracelet/bubbletea"
 
@@ -29,6 +31,7 @@ const (
 	ScreenProfile
 	ScreenDoctor
 	ScreenSetup
+	ScreenOutdated
 )
 
 // Model is the main TUI model that manages all screens
@@ -58,6 +61,7 @@ type Model struct {
 	profile   *screens.ProfileModel
 	configM   *screens.ConfigModel
 	setup     *screens.SetupModel
+	outdated  *screens.OutdatedModel
 
 	// State
 	statusMessage string
@@ -160,8 +164,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.propagateResize(msg)
 
 	case tea.KeyMsg:
-		// ctrl+c always quits (even in setup)
+		// ctrl+c always quits (even in setup), except that it first stops
+		// a running sync
 		if msg.String() == "ctrl+c" {
+			if m.busy() {
+				return m.routeToScreen(msg)
+			}
 			return m, tea.Quit
 		}
 
@@ -170,8 +178,11 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.routeToScreen(msg)
 		}
 
-		// q always quits
+		// q always quits, once a running sync has been stopped
 		if msg.String() == "q" {
+			if m.busy() {
+				return m.routeToScreen(tea.KeyMsg{Type: tea.KeyCtrlC})
+			}
 			return m, tea.Quit
 		}
 
@@ -251,7 +262,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		pkgID := msg.PkgType + ":" + msg.PkgName
 		if msg.Action == "install" {
-			history.LogInstall(machine, pkgID, msg.Success)
+			history.LogInstall(machine, pkgID, msg.Success, string(installer.Classify(msg.Error)))
 		} else {
 			history.LogUninstall(machine, pkgID, msg.Success)
 		}
@@ -263,6 +274,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m.routeToScreen(msg)
 }
 
+// busy reports whether the active screen is running package operations,
+// so ctrl+c and q should stop them rather than quit
+func (m Model) busy() bool {
+	switch m.screen {
+	case ScreenSync:
+		return m.syncM != nil && m.syncM.Busy()
+	case ScreenOutdated:
+		return m.outdated != nil && m.outdated.Busy()
+	}
+	return false
+}
+
 // getScreenFromShortcut returns the screen for a shortcut key, or -1 if not a shortcut
 func (m Model) getScreenFromShortcut(key string) Screen {
 	switch key {
@@ -288,6 +311,8 @@ func (m Model) getScreenFromShortcut(key string) Screen {
 		return ScreenProfile
 	case "!":
 		return ScreenDoctor
+	case "@":
+		return ScreenOutdated
 	}
 	return -1
 }
@@ -348,6 +373,11 @@ func (m Model) navigateToScreen(screen Screen) (tea.Model, tea.Cmd) {
 	case ScreenDoctor:
 		m.doctor = screens.NewDoctorModel(m.config)
 		return m, m.doctor.Init()
+
+	case ScreenOutdated:
+		m.outdated = screens.NewOutdatedModel(m.config)
+		m.outdated.SetSize(m.layout.ContentWidth(), m.layout.ContentHeight())
+		return m, m.outdated.Init()
 	}
 
 	return m, nil
@@ -370,6 +400,8 @@ func (m *Model) updateFooterKeybindings() {
 		m.footer.SetKeybindings(components.DumpKeybindings())
 	case ScreenIgnore:
 		m.footer.SetKeybindings(components.IgnoreKeybindings())
+	case ScreenOutdated:
+		m.footer.SetKeybindings(components.OutdatedKeybindings())
 	default:
 		m.footer.SetKeybindings(components.ContentKeybindings())
 	}
@@ -423,6 +455,10 @@ func (m Model) renderContent() string {
 		if m.doctor != nil {
 			return m.doctor.ViewContent(width, height)
 		}
+	case ScreenOutdated:
+		if m.outdated != nil {
+			return m.outdated.ViewContent(width, height)
+		}
 	case ScreenDump:
 		if m.dump != nil {
 			return m.dump.ViewContent(width, height)
@@ -496,6 +532,13 @@ func (m Model) routeToScreen(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, cmd
 		}
 
+	case ScreenOutdated:
+		if m.outdated != nil {
+			newOutdated, cmd := m.outdated.Update(msg)
+			m.outdated = newOutdated.(*screens.OutdatedModel)
+			return m, cmd
+		}
+
 	case ScreenDump:
 		if m.dump != nil {
 			newDump, cmd := m.dump.Update(msg)
@@ -576,6 +619,8 @@ func (m Model) handleNavigation(msg screens.NavigateMsg) (tea.Model, tea.Cmd) {
 		return m.navigateToScreen(ScreenProfile)
 	case "doctor":
 		return m.navigateToScreen(ScreenDoctor)
+	case "outdated":
+		return m.navigateToScreen(ScreenOutdated)
 	}
 
 	return m, nil
@@ -607,9 +652,9 @@ func (m Model) handlePackageAction(msg screens.PackageActionMsg) (tea.Model, tea
 
 			var err error
 			if msg.Action == "install" {
-				err = mgr.Install(pkg)
+				err = mgr.Install(context.Background(), pkg)
 			} else {
-				err = mgr.Uninstall(pkg)
+				err = mgr.Uninstall(context.Background(), pkg)
 			}
 
 			return screens.PackageActionDoneMsg{
@@ -657,6 +702,11 @@ func (m Model) propagateResize(msg tea.WindowSizeMsg) (tea.Model, tea.Cmd) {
 			newDoctor, _ := m.doctor.Update(contentMsg)
 			m.doctor = newDoctor.(*screens.DoctorModel)
 		}
+	case ScreenOutdated:
+		if m.outdated != nil {
+			newOutdated, _ := m.outdated.Update(contentMsg)
+			m.outdated = newOutdated.(*screens.OutdatedModel)
+		}
 	case ScreenDump:
 		if m.dump != nil {
 			newDump, _ := m.dump.Update(contentMsg)
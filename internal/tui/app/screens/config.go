@@ -193,6 +193,14 @@ func (m *ConfigModel) buildItems() {
 			itemType:    "bool",
 			description: "Use 'brew bundle dump --describe' for better output",
 		},
+		{
+			key:         "go_tools.versions",
+			label:       "Go Tool Versions",
+			value:       goVersionsValue(m.config.GoTools.Versions),
+			itemType:    "select",
+			options:     []string{"latest", "pin"},
+			description: "Install latest Go tools or pin the dumped versions",
+		},
 	}
 
 	// Output section items
@@ -646,6 +654,8 @@ func (m *ConfigModel) applyChange(key, value string) {
 		m.config.AutoDump.CommitMessage = value
 	case "dump.use_brew_bundle":
 		m.config.Dump.UseBrewBundle = value == "Yes"
+	case "go_tools.versions":
+		m.config.GoTools.Versions = value
 	case "output.color":
 		m.config.Output.Color = value == "Yes"
 	case "output.verbose":
@@ -1080,3 +1090,11 @@ func boolToYesNo(b bool) string {
 	}
 	return "No"
 }
+
+// goVersionsValue shows an unset Go version policy as its default
+func goVersionsValue(policy string) string {
+	if policy == "" {
+		return "latest"
+	}
+	return policy
+}
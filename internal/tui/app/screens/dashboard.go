@@ -18,18 +18,19 @@ import (
 
 // DashboardKeyMap defines keybindings for the dashboard
 type DashboardKeyMap struct {
-	Import  key.Binding
-	Sync    key.Binding
-	Diff    key.Binding
-	Dump    key.Binding
-	List    key.Binding
-	Ignore  key.Binding
-	Config  key.Binding
-	History key.Binding
-	Profile key.Binding
-	Doctor  key.Binding
-	Help    key.Binding
-	Quit    key.Binding
+	Import   key.Binding
+	Sync     key.Binding
+	Diff     key.Binding
+	Dump     key.Binding
+	List     key.Binding
+	Ignore   key.Binding
+	Config   key.Binding
+	History  key.Binding
+	Profile  key.Binding
+	Doctor   key.Binding
+	Outdated key.Binding
+	Help     key.Binding
+	Quit     key.Binding
 }
 
 // DefaultDashboardKeyMap returns the default dashboard keybindings
@@ -75,6 +76,10 @@ func DefaultDashboardKeyMap() DashboardKeyMap {
 			key.WithKeys("!"),
 			key.WithHelp("!", "doctor"),
 		),
+		Outdated: key.NewBinding(
+			key.WithKeys("o"),
+			key.WithHelp("o", "outdated"),
+		),
 		Help: key.NewBinding(
 			key.WithKeys("?"),
 			key.WithHelp("?", "help"),
@@ -206,7 +211,7 @@ func (m *DashboardModel) loadData() tea.Cmd {
 		}
 
 		// Load metadata for last dump time
-		metaPath := filepath.Join(filepath.Dir(machine.Brewfile), ".brewsync-meta")
+		metaPath := brewfile.MetadataPath(machine.Brewfile)
 		debug.Log("Dashboard.loadData: loading metadata from: %s", metaPath)
 		meta, err := brewfile.LoadMetadata(metaPath)
 		if err != nil {
@@ -224,7 +229,8 @@ func (m *DashboardModel) loadData() tea.Cmd {
 				if err != nil {
 					debug.Log("Dashboard.loadData: source brewfile parse error: %v", err)
 				} else {
-					diff := brewfile.Diff(sourcePackages, packages)
+					diff := brewfile.Diff(sourcePackages, packages).
+						ExcludeTypes(m.config.UnsupportedTypes(m.config.DefaultSource, m.config.CurrentMachine)...)
 
 					// Categorize additions by type, separating ignored
 					for _, pkg := range diff.Additions {
@@ -316,6 +322,8 @@ func (m *DashboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, func() tea.Msg { return Navigate("profile") }
 		case key.Matches(msg, m.keys.Doctor):
 			return m, func() tea.Msg { return Navigate("doctor") }
+		case key.Matches(msg, m.keys.Outdated):
+			return m, func() tea.Msg { return Navigate("outdated") }
 		case key.Matches(msg, m.keys.Quit):
 			return m, tea.Quit
 		}
@@ -800,6 +808,7 @@ func (m *DashboardModel) renderActionsSection() string {
 		{"h", "History"},
 		{"p", "Profiles"},
 		{"!", "Doctor"},
+		{"o", "Outdated"},
 	}
 
 	// Render in two rows
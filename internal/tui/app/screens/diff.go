@@ -38,6 +38,7 @@ type DiffModel struct {
 	source       string
 	additions    brewfile.Packages
 	removals     brewfile.Packages
+	changes      []brewfile.Change
 	addItems     []diffItem // Flattened additions with headers
 	remItems     []diffItem // Flattened removals with headers
 	column       DiffColumn // Current column focus
@@ -76,6 +77,7 @@ func NewDiffModel(cfg *config.Config) *DiffModel {
 type diffLoadedMsg struct {
 	additions brewfile.Packages
 	removals  brewfile.Packages
+	changes   []brewfile.Change
 	err       error
 }
 
@@ -107,10 +109,12 @@ func (m *DiffModel) Init() tea.Cmd {
 			return diffLoadedMsg{err: fmt.Errorf("failed to parse source Brewfile: %w", err)}
 		}
 
-		diff := brewfile.Diff(sourcePkgs, currentPkgs)
+		diff := brewfile.Diff(sourcePkgs, currentPkgs).
+			ExcludeTypes(m.config.UnsupportedTypes(m.source, m.config.CurrentMachine)...)
 		return diffLoadedMsg{
 			additions: diff.Additions,
 			removals:  diff.Removals,
+			changes:   diff.Changed,
 		}
 	}
 }
@@ -127,6 +131,7 @@ func (m *DiffModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.loading = false
 		m.additions = msg.additions
 		m.removals = msg.removals
+		m.changes = msg.changes
 		m.err = msg.err
 		m.buildItems()
 		// Start in additions if available, otherwise removals
@@ -247,6 +252,7 @@ func (m *DiffModel) buildItems() {
 func (m *DiffModel) buildItemsForPackages(pkgs brewfile.Packages) []diffItem {
 	var items []diffItem
 	byType := pkgs.ByType()
+	isIgnored := ignoredFilter(m.config)
 	types := []brewfile.PackageType{
 		brewfile.TypeTap,
 		brewfile.TypeBrew,
@@ -257,6 +263,7 @@ func (m *DiffModel) buildItemsForPackages(pkgs brewfile.Packages) []diffItem {
 		brewfile.TypeGo,
 		brewfile.TypeMas,
 	}
+	types = append(types, brewfile.CustomTypes()...)
 
 	for _, t := range types {
 		typePkgs := byType[t]
@@ -268,9 +275,7 @@ func (m *DiffModel) buildItemsForPackages(pkgs brewfile.Packages) []diffItem {
 		var visiblePkgs []brewfile.Package
 		var ignoredPkgs []brewfile.Package
 		for _, pkg := range typePkgs {
-			isIgnored := m.config != nil && (m.config.IsCategoryIgnored(m.config.CurrentMachine, string(pkg.Type)) ||
-				m.config.IsPackageIgnored(m.config.CurrentMachine, pkg.ID()))
-			if isIgnored {
+			if isIgnored(pkg) {
 				ignoredPkgs = append(ignoredPkgs, pkg)
 			} else {
 				visiblePkgs = append(visiblePkgs, pkg)
@@ -405,6 +410,10 @@ func (m *DiffModel) adjustRemOffset() {
 // getColumnHeight returns the visible height for each column
 func (m *DiffModel) getColumnHeight() int {
 	h := m.height - 4 // Just title and scroll indicator
+	// Reserve space for the changed packages section
+	if n := len(m.changes); n > 0 {
+		h -= min(n, maxChangedRows) + 3
+	}
 	// Reserve space for confirmation dialog if showing
 	if m.showConfirm {
 		h -= 5
@@ -446,7 +455,7 @@ func (m *DiffModel) ViewContent(width, height int) string {
 	}
 
 	// No changes
-	if len(m.additions) == 0 && len(m.removals) == 0 {
+	if len(m.additions) == 0 && len(m.removals) == 0 && len(m.changes) == 0 {
 		b.WriteString(styles.SelectedStyle.Render("✓ "))
 		b.WriteString("Machines are in sync!")
 		b.WriteString("\n")
@@ -517,6 +526,12 @@ func (m *DiffModel) ViewContent(width, height int) string {
 		b.WriteString("\n")
 	}
 
+	// Packages on both machines with different options
+	if len(m.changes) > 0 {
+		b.WriteString("\n")
+		b.WriteString(m.renderChanges(width))
+	}
+
 	// Confirmation dialog overlay
 	if m.showConfirm {
 		b.WriteString("\n")
@@ -526,6 +541,40 @@ func (m *DiffModel) ViewContent(width, height int) string {
 	return b.String()
 }
 
+// maxChangedRows is the number of changed packages listed below the columns
+const maxChangedRows = 5
+
+// renderChanges renders packages whose options differ between machines
+func (m *DiffModel) renderChanges(width int) string {
+	var b strings.Builder
+
+	headerStyle := lipgloss.NewStyle().Foreground(styles.CatYellow).Bold(true)
+	b.WriteString(headerStyle.Render(fmt.Sprintf("CHANGED (~%d)", len(m.changes))))
+	b.WriteString("\n")
+	b.WriteString(styles.DimmedStyle.Render(strings.Repeat("─", max(width-4, 10))))
+	b.WriteString("\n")
+
+	for i, change := range m.changes {
+		if i == maxChangedRows {
+			b.WriteString(styles.DimmedStyle.Render(fmt.Sprintf("  … and %d more", len(m.changes)-i)))
+			b.WriteString("\n")
+			break
+		}
+		var details []string
+		for _, d := range change.Details() {
+			details = append(details, d.String())
+		}
+		icon := getTypeIcon(change.Source.Type)
+		line := fmt.Sprintf("  ~ %s %s", icon, change.Source.Name)
+		b.WriteString(lipgloss.NewStyle().Foreground(styles.CatYellow).Render(line))
+		b.WriteString("  ")
+		b.WriteString(styles.DimmedStyle.Render(strings.Join(details, ", ")))
+		b.WriteString("\n")
+	}
+
+	return b.String()
+}
+
 // renderConfirmDialog renders the confirmation dialog
 func (m *DiffModel) renderConfirmDialog() string {
 	actionLabel := "Uninstall"
@@ -2,14 +2,17 @@ package screens
 
 import (
 	"fmt"
-	"os/exec"
+	"runtime"
 	"strings"
 
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
 	"github.com/andrew-sameh/brewsync/internal/config"
+	"github.com/andrew-sameh/brewsync/internal/exec"
+	"github.com/andrew-sameh/brewsync/internal/installer"
 	"github.com/andrew-sameh/brewsync/internal/tui/styles"
 )
 
@@ -77,41 +80,89 @@ func (m *DoctorModel) Init() tea.Cmd {
 		}
 
 		// Tool checks
-		tools := []struct {
+		mgr := installer.NewManager()
+		type tool struct {
 			name     string
 			cmd      string
 			optional bool
-		}{
-			{"Homebrew", "brew", false},
-			{"brew bundle", "brew", false},
-			{"VSCode CLI", "code", true},
-			{"Cursor CLI", "cursor", true},
-			{"Antigravity CLI", "antigravity", true},
-			{"Mac App Store CLI", "mas", true},
-			{"Go", "go", true},
+			pkgType  brewfile.PackageType // type whose capabilities are shown
 		}
+		tools := []tool{
+			{"Homebrew", "brew", false, brewfile.TypeBrew},
+			{"brew bundle", "brew", false, ""},
+		}
+		// Built-in editors and those declared under editors in config
+		for _, editor := range brewfile.Editors() {
+			tools = append(tools, tool{editor.DisplayName() + " CLI", editor.Command, true, editor.Type()})
+		}
+		tools = append(tools,
+			tool{"Mac App Store CLI", "mas", true, brewfile.TypeMas},
+			tool{"Go", "go", true, brewfile.TypeGo},
+		)
 
+		goos := config.LocalOS()
+		arch := brewfile.NormalizeArch(runtime.GOARCH)
 		for _, tool := range tools {
-			_, err := exec.LookPath(tool.cmd)
 			status := "pass"
-			if err != nil {
+			message := ""
+			if tool.pkgType != "" && !tool.pkgType.SupportedOn(goos) {
+				message = "not applicable on " + goos
+			} else if !exec.Exists(tool.cmd) {
 				if tool.optional {
 					status = "warn"
 				} else {
 					status = "fail"
 				}
+				if prefix, ok := installer.FindBrew(goos, arch); ok && tool.cmd == "brew" {
+					message = "in " + prefix + " but not on PATH"
+				}
+			} else if tool.pkgType != "" {
+				message = capabilitySummary(mgr.Capabilities(tool.pkgType))
 			}
 			checks = append(checks, Check{
 				Name:     tool.name,
 				Status:   status,
+				Message:  message,
 				Optional: tool.optional,
 			})
+
+			if tool.pkgType == brewfile.TypeBrew && status == "pass" {
+				check := Check{Name: "Homebrew prefix", Status: "pass", Optional: true}
+				if prefix, err := installer.NewBrewInstaller().Prefix(); err != nil {
+					check.Status = "warn"
+				} else {
+					check.Message = prefix
+					if !installer.IsDefaultPrefix(prefix, goos, arch) {
+						check.Status = "warn"
+						check.Message = prefix + " is not a default prefix; formulae build from source"
+					}
+				}
+				checks = append(checks, check)
+			}
+		}
+
+		for _, custom := range installer.CustomInstallers() {
+			check := Check{Name: string(custom.Type()) + " (custom)", Status: "warn", Optional: true}
+			if custom.IsAvailable() {
+				check.Status = "pass"
+				check.Message = capabilitySummary(custom.Capabilities())
+			}
+			checks = append(checks, check)
 		}
 
 		return doctorDoneMsg{checks: checks}
 	}
 }
 
+// capabilitySummary lists what an installer supports, noting when removal
+// is manual
+func capabilitySummary(caps installer.Capabilities) string {
+	if !caps.Uninstall {
+		return caps.String() + "; " + installer.ManualRemoval
+	}
+	return caps.String()
+}
+
 func boolToStatus(b bool) string {
 	if b {
 		return "pass"
@@ -1,6 +1,7 @@
 package screens
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -12,8 +13,11 @@ import (
 
 	"github.com/andrew-sameh/brewsync/internal/brewfile"
 	"github.com/andrew-sameh/brewsync/internal/config"
+	"github.com/andrew-sameh/brewsync/internal/hooks"
 	"github.com/andrew-sameh/brewsync/internal/installer"
+	"github.com/andrew-sameh/brewsync/internal/system"
 	"github.com/andrew-sameh/brewsync/internal/tui/styles"
+	"github.com/andrew-sameh/brewsync/pkg/version"
 )
 
 // DumpModel is the model for the dump screen
@@ -29,6 +33,10 @@ type DumpModel struct {
 	err      error
 	counts   map[string]int
 	total    int
+
+	events     chan tea.Msg // Progress of the running dump
+	hookOutput []string     // Recent output of the running hook
+	hookErr    error        // post_dump hook failure
 }
 
 // NewDumpModel creates a new dump model
@@ -53,9 +61,10 @@ type dumpStepMsg struct {
 }
 
 type dumpCompleteMsg struct {
-	counts map[string]int
-	total  int
-	err    error
+	counts  map[string]int
+	total   int
+	err     error
+	hookErr error
 }
 
 // Init initializes the dump model
@@ -64,55 +73,102 @@ func (m *DumpModel) Init() tea.Cmd {
 	return nil
 }
 
+// runDump runs the dump in the background, reporting hook progress on the
+// model's event channel until it completes
 func (m *DumpModel) runDump() tea.Cmd {
-	return func() tea.Msg {
-		if m.config == nil {
-			return dumpCompleteMsg{err: fmt.Errorf("no config loaded")}
-		}
+	events := make(chan tea.Msg, 64)
+	m.events = events
+	return tea.Batch(
+		func() tea.Msg {
+			events <- m.dump(events)
+			close(events)
+			return nil
+		},
+		listenForEvents(events),
+	)
+}
 
-		machine, ok := m.config.GetCurrentMachine()
-		if !ok {
-			return dumpCompleteMsg{err: fmt.Errorf("current machine not configured")}
-		}
+// dump collects the installed packages and writes them to the Brewfile,
+// running the dump hooks around it
+func (m *DumpModel) dump(events chan<- tea.Msg) tea.Msg {
+	if m.config == nil {
+		return dumpCompleteMsg{err: fmt.Errorf("no config loaded")}
+	}
 
-		brewfilePath := machine.Brewfile
-		if brewfilePath == "" {
-			return dumpCompleteMsg{err: fmt.Errorf("no Brewfile path configured")}
-		}
+	machine, ok := m.config.GetCurrentMachine()
+	if !ok {
+		return dumpCompleteMsg{err: fmt.Errorf("current machine not configured")}
+	}
 
-		// Ensure directory exists
-		dir := filepath.Dir(brewfilePath)
-		if err := os.MkdirAll(dir, 0755); err != nil {
-			return dumpCompleteMsg{err: fmt.Errorf("failed to create directory: %w", err)}
-		}
+	brewfilePath := machine.Brewfile
+	if brewfilePath == "" {
+		return dumpCompleteMsg{err: fmt.Errorf("no Brewfile path configured")}
+	}
 
-		// Collect all packages
-		allPackages, err := collectAllPackages(m.config, brewfilePath)
-		if err != nil {
-			return dumpCompleteMsg{err: err}
-		}
+	// Ensure directory exists
+	dir := filepath.Dir(brewfilePath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return dumpCompleteMsg{err: fmt.Errorf("failed to create directory: %w", err)}
+	}
 
-		// Write Brewfile
-		writer := brewfile.NewWriter(allPackages)
-		if err := writer.Write(brewfilePath); err != nil {
-			return dumpCompleteMsg{err: fmt.Errorf("failed to write Brewfile: %w", err)}
-		}
+	ctx := context.Background()
+	event := hooks.Event{
+		Operation: hooks.OperationDump,
+		Machine:   m.config.CurrentMachine,
+		Brewfile:  brewfilePath,
+	}
+	if err := runHook(ctx, m.config, config.HookPreDump, event, events); err != nil {
+		return dumpCompleteMsg{err: fmt.Errorf("aborted: %w", err)}
+	}
 
-		// Count by type
-		counts := make(map[string]int)
-		for _, pkg := range allPackages {
-			counts[string(pkg.Type)]++
-		}
+	// Collect all packages
+	events <- dumpStepMsg{step: "Collecting packages..."}
+	allPackages, err := collectAllPackages(m.config, brewfilePath)
+	if err != nil {
+		return dumpCompleteMsg{err: err}
+	}
+
+	// Skip packages already provided by included Brewfiles
+	if included, err := brewfile.IncludedPackages(brewfilePath); err == nil {
+		allPackages = allPackages.Exclude(included)
+	}
+
+	// Update Brewfile in place, keeping hand-written comments and layout
+	if err := brewfile.SyncFile(brewfilePath, allPackages); err != nil {
+		return dumpCompleteMsg{err: fmt.Errorf("failed to write Brewfile: %w", err)}
+	}
 
-		return dumpCompleteMsg{
-			counts: counts,
-			total:  len(allPackages),
+	// Record installed versions in the lock file and system facts in the metadata
+	if packages, err := brewfile.Parse(brewfilePath); err == nil {
+		lock := installer.NewManager().Lock(packages)
+		if err := lock.Save(brewfile.LockPath(brewfilePath)); err != nil {
+			return dumpCompleteMsg{err: fmt.Errorf("failed to write lock file: %w", err)}
 		}
+		facts := system.Facts(version.Version)
+		if err := brewfile.UpdateMetadata(brewfile.MetadataPath(brewfilePath), m.config.CurrentMachine, packages, facts); err != nil {
+			return dumpCompleteMsg{err: fmt.Errorf("failed to write metadata: %w", err)}
+		}
+	}
+
+	// Count by type
+	counts := make(map[string]int)
+	for _, pkg := range allPackages {
+		counts[string(pkg.Type)]++
+	}
+
+	event.Packages = allPackages
+	hookErr := runHook(ctx, m.config, config.HookPostDump, event, events)
+
+	return dumpCompleteMsg{
+		counts:  counts,
+		total:   len(allPackages),
+		hookErr: hookErr,
 	}
 }
 
 // collectAllPackages collects all installed packages
 func collectAllPackages(cfg *config.Config, brewfilePath string) (brewfile.Packages, error) {
+	ctx := context.Background()
 	var allPackages brewfile.Packages
 	brewInst := installer.NewBrewInstaller()
 
@@ -129,48 +185,57 @@ func collectAllPackages(cfg *config.Config, brewfilePath string) (brewfile.Packa
 		}
 	} else if brewInst.IsAvailable() {
 		// Manual collection
-		if taps, err := brewInst.ListTaps(); err == nil {
+		if taps, err := brewInst.ListTaps(ctx); err == nil {
 			allPackages = append(allPackages, taps...)
 		}
-		if formulae, err := brewInst.ListFormulae(); err == nil {
+		if formulae, err := brewInst.ListFormulae(ctx); err == nil {
 			allPackages = append(allPackages, formulae...)
 		}
-		if casks, err := brewInst.ListCasks(); err == nil {
+		if casks, err := brewInst.ListCasks(ctx); err == nil {
 			allPackages = append(allPackages, casks...)
 		}
 	}
 
-	// Collect extensions
-	if vscodeInst := installer.NewVSCodeInstaller(); vscodeInst.IsAvailable() {
-		if extensions, err := vscodeInst.List(); err == nil {
-			allPackages = allPackages.AddUnique(extensions...)
+	// Record which formulae have their service running
+	if brewInst.IsAvailable() {
+		if withServices, err := brewInst.WithServices(ctx, allPackages); err == nil {
+			allPackages = withServices
 		}
 	}
 
-	if cursorInst := installer.NewCursorInstaller(); cursorInst.IsAvailable() {
-		if extensions, err := cursorInst.List(); err == nil {
-			allPackages = allPackages.AddUnique(extensions...)
+	// Collect extensions of every built-in and configured editor, replacing
+	// entries from brew bundle dump, which carry no pre-release flag
+	for _, editorInst := range installer.EditorInstallers() {
+		if !editorInst.IsAvailable() {
+			continue
 		}
-	}
-
-	if antigravityInst := installer.NewAntigravityInstaller(); antigravityInst.IsAvailable() {
-		if extensions, err := antigravityInst.List(); err == nil {
-			allPackages = allPackages.AddUnique(extensions...)
+		if extensions, err := editorInst.List(ctx); err == nil {
+			allPackages = append(allPackages.Exclude(extensions), extensions...)
 		}
 	}
 
-	if goInst := installer.NewGoToolsInstaller(); goInst.IsAvailable() {
-		if tools, err := goInst.List(); err == nil {
-			allPackages = allPackages.AddUnique(tools...)
+	if goInst := installer.NewGoToolsInstaller(); goInst.CanList() {
+		if tools, err := goInst.List(ctx); err == nil {
+			// Replace entries from brew bundle dump, which carry no version
+			allPackages = append(allPackages.Exclude(tools), tools...)
 		}
 	}
 
 	if masInst := installer.NewMasInstaller(); masInst.IsAvailable() {
-		if apps, err := masInst.List(); err == nil {
+		if apps, err := masInst.List(ctx); err == nil {
 			allPackages = allPackages.AddUnique(apps...)
 		}
 	}
 
+	for _, customInst := range installer.CustomInstallers() {
+		if !customInst.IsAvailable() {
+			continue
+		}
+		if pkgs, err := customInst.List(ctx); err == nil {
+			allPackages = allPackages.AddUnique(pkgs...)
+		}
+	}
+
 	return allPackages, nil
 }
 
@@ -193,13 +258,28 @@ func (m *DumpModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case dumpStepMsg:
 		m.steps = append(m.steps, m.step)
 		m.step = msg.step
-		return m, nil
+		m.hookOutput = nil
+		return m, listenForEvents(m.events)
+
+	case hookStartMsg:
+		m.steps = append(m.steps, m.step)
+		m.step = fmt.Sprintf("Running %s hook...", msg.hook)
+		m.hookOutput = nil
+		return m, listenForEvents(m.events)
+
+	case hookOutputMsg:
+		m.hookOutput = appendHookOutput(m.hookOutput, msg.line)
+		return m, listenForEvents(m.events)
+
+	case hookDoneMsg:
+		return m, listenForEvents(m.events)
 
 	case dumpCompleteMsg:
 		m.done = true
 		m.counts = msg.counts
 		m.total = msg.total
 		m.err = msg.err
+		m.hookErr = msg.hookErr
 		if m.err == nil {
 			m.steps = append(m.steps, m.step)
 			m.step = "Complete!"
@@ -255,6 +335,10 @@ func (m *DumpModel) ViewContent(width, height int) string {
 
 	if m.err != nil {
 		b.WriteString(styles.ErrorStyle.Render("Error: " + m.err.Error()))
+		if len(m.hookOutput) > 0 {
+			b.WriteString("\n\n")
+			b.WriteString(renderHookOutput(m.hookOutput))
+		}
 		return b.String()
 	}
 
@@ -267,6 +351,7 @@ func (m *DumpModel) ViewContent(width, height int) string {
 	if !m.done {
 		b.WriteString(m.spinner.View() + " " + m.step)
 		b.WriteString("\n")
+		b.WriteString(renderHookOutput(m.hookOutput))
 	}
 
 	// Results
@@ -276,6 +361,12 @@ func (m *DumpModel) ViewContent(width, height int) string {
 		b.WriteString("\n\n")
 		b.WriteString(styles.SelectedStyle.Render(fmt.Sprintf("✓ Dumped %d packages to Brewfile", m.total)))
 		b.WriteString("\n\n")
+		if m.hookErr != nil {
+			b.WriteString(styles.WarningStyle.Render("⚠ " + m.hookErr.Error()))
+			b.WriteString("\n")
+			b.WriteString(renderHookOutput(m.hookOutput))
+			b.WriteString("\n")
+		}
 		b.WriteString("Press Enter to return to dashboard")
 	}
 
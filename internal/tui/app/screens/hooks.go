@@ -0,0 +1,76 @@
+package screens
+
+import (
+	"context"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/andrew-sameh/brewsync/internal/config"
+	"github.com/andrew-sameh/brewsync/internal/hooks"
+	"github.com/andrew-sameh/brewsync/internal/tui/styles"
+)
+
+// maxHookOutput is how many lines of hook output a screen shows
+const maxHookOutput = 8
+
+// hookStartMsg is sent when a hook starts running
+type hookStartMsg struct {
+	hook string
+}
+
+// hookOutputMsg is a line of output from a running hook
+type hookOutputMsg struct {
+	line string
+}
+
+// hookDoneMsg is sent when a hook finishes
+type hookDoneMsg struct {
+	hook string
+}
+
+// runHook runs a configured hook from a screen's background operation,
+// forwarding its start, output lines and end as messages on events
+func runHook(ctx context.Context, cfg *config.Config, hook string, event hooks.Event, events chan<- tea.Msg) error {
+	runner := hooks.New(cfg.Hooks)
+	if !runner.Enabled(hook) {
+		return nil
+	}
+	events <- hookStartMsg{hook: hook}
+	err := runner.Run(ctx, hook, event, func(line string) {
+		events <- hookOutputMsg{line: line}
+	})
+	events <- hookDoneMsg{hook: hook}
+	return err
+}
+
+// listenForEvents returns a command that waits for the next message of a
+// background operation, until its channel is closed
+func listenForEvents(events <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-events
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+// appendHookOutput adds a line of hook output, keeping the latest ones
+func appendHookOutput(lines []string, line string) []string {
+	lines = append(lines, line)
+	if len(lines) > maxHookOutput {
+		lines = lines[len(lines)-maxHookOutput:]
+	}
+	return lines
+}
+
+// renderHookOutput renders hook output lines, dimmed and indented
+func renderHookOutput(lines []string) string {
+	var b strings.Builder
+	for _, line := range lines {
+		b.WriteString(styles.DimmedStyle.Render("  " + line))
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -9,6 +9,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
 	"github.com/andrew-sameh/brewsync/internal/config"
 	"github.com/andrew-sameh/brewsync/internal/tui/styles"
 )
@@ -74,6 +75,15 @@ var packageTypes = []string{"tap", "brew", "cask", "vscode", "cursor", "antigrav
 // Available categories (same as package types)
 var categoryTypes = []string{"tap", "brew", "cask", "vscode", "cursor", "antigravity", "go", "mas"}
 
+// withCustomTypes appends the custom package types declared in config
+func withCustomTypes(types []string) []string {
+	result := append([]string(nil), types...)
+	for _, t := range brewfile.CustomTypes() {
+		result = append(result, string(t))
+	}
+	return result
+}
+
 // NewIgnoreModel creates a new ignore model
 func NewIgnoreModel(cfg *config.Config) *IgnoreModel {
 	ti := textinput.New()
@@ -242,9 +252,9 @@ func (m *IgnoreModel) handleConfirmInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m *IgnoreModel) handleTypeMenuInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	types := categoryTypes
+	types := withCustomTypes(categoryTypes)
 	if m.inputType == "package" {
-		types = packageTypes
+		types = withCustomTypes(packageTypes)
 	}
 
 	switch msg.String() {
@@ -663,10 +673,10 @@ func (m *IgnoreModel) renderSection(title string, items []ignoreItem, width, vis
 func (m *IgnoreModel) renderTypeMenu() string {
 	var b strings.Builder
 
-	types := categoryTypes
+	types := withCustomTypes(categoryTypes)
 	label := "Select category type:"
 	if m.inputType == "package" {
-		types = packageTypes
+		types = withCustomTypes(packageTypes)
 		label = "Select package type:"
 	}
 
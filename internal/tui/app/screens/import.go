@@ -94,7 +94,8 @@ func (m *ImportModel) Init() tea.Cmd {
 		}
 
 		// Get packages to import (in source but not in current)
-		diff := brewfile.Diff(sourcePkgs, currentPkgs)
+		diff := brewfile.Diff(sourcePkgs, currentPkgs).
+			ExcludeTypes(m.config.UnsupportedTypes(m.config.CurrentMachine)...)
 		return importLoadedMsg{packages: diff.Additions}
 	}
 }
@@ -231,11 +232,10 @@ func (m *ImportModel) filterPackages(pkgs brewfile.Packages) brewfile.Packages {
 		return pkgs
 	}
 
+	isIgnored := ignoredFilter(m.config)
 	var filtered brewfile.Packages
 	for _, pkg := range pkgs {
-		isIgnored := m.config.IsCategoryIgnored(m.config.CurrentMachine, string(pkg.Type)) ||
-			m.config.IsPackageIgnored(m.config.CurrentMachine, pkg.ID())
-		if !isIgnored {
+		if !isIgnored(pkg) {
 			filtered = append(filtered, pkg)
 		}
 	}
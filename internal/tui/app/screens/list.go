@@ -10,6 +10,7 @@ import (
 
 	"github.com/andrew-sameh/brewsync/internal/brewfile"
 	"github.com/andrew-sameh/brewsync/internal/config"
+	"github.com/andrew-sameh/brewsync/internal/installer"
 	"github.com/andrew-sameh/brewsync/internal/tui/styles"
 )
 
@@ -40,6 +41,9 @@ type ListModel struct {
 
 	// Task state
 	taskRunning bool
+
+	// notice explains why an action was refused
+	notice string
 }
 
 // NewListModel creates a new list model
@@ -103,6 +107,7 @@ func (m *ListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.showConfirm {
 			return m.handleConfirmInput(msg)
 		}
+		m.notice = ""
 
 		switch {
 		case key.Matches(msg, key.NewBinding(key.WithKeys("up", "k"))):
@@ -123,7 +128,10 @@ func (m *ListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Uninstall current package
 			if !m.taskRunning {
 				pkg := m.getCurrentPackage()
-				if pkg != nil {
+				if pkg != nil && !installer.NewManager().Capabilities(pkg.Type).Uninstall {
+					m.notice = fmt.Sprintf("%s:%s cannot be uninstalled by BrewSync: %s",
+						pkg.Type, pkg.Name, installer.ManualRemoval)
+				} else if pkg != nil {
 					m.confirmPkg = *pkg
 					m.confirmAction = "uninstall"
 					m.showConfirm = true
@@ -182,6 +190,7 @@ func (m *ListModel) buildItems() {
 		brewfile.TypeGo,
 		brewfile.TypeMas,
 	}
+	types = append(types, brewfile.CustomTypes()...)
 
 	for _, t := range types {
 		pkgs := byType[t]
@@ -326,6 +335,11 @@ func (m *ListModel) ViewContent(width, height int) string {
 		b.WriteString(styles.DimmedStyle.Render(scrollInfo))
 	}
 
+	if m.notice != "" {
+		b.WriteString("\n")
+		b.WriteString(styles.WarningStyle.Render(m.notice))
+	}
+
 	// Confirmation dialog overlay
 	if m.showConfirm {
 		b.WriteString("\n\n")
@@ -380,10 +394,40 @@ func getTypeIcon(t brewfile.PackageType) string {
 	case brewfile.TypeMas:
 		return "🍎"
 	default:
+		if icon := brewfile.TypeIcon(t); icon != "" {
+			return icon
+		}
 		return "•"
 	}
 }
 
+// ignoredFilter returns a predicate reporting whether a package is ignored on
+// the current machine. The ignore list is indexed once so rebuilding large
+// package lists stays linear.
+func ignoredFilter(cfg *config.Config) func(brewfile.Package) bool {
+	if cfg == nil {
+		return func(brewfile.Package) bool { return false }
+	}
+
+	machine := cfg.CurrentMachine
+	ignored := brewfile.NewPackageSet()
+	for _, id := range cfg.GetIgnoredPackages(machine) {
+		if pkg, ok := brewfile.ParseID(id); ok {
+			ignored.Add(pkg)
+		}
+	}
+
+	categories := make(map[brewfile.PackageType]bool)
+	return func(pkg brewfile.Package) bool {
+		ignoredCategory, ok := categories[pkg.Type]
+		if !ok {
+			ignoredCategory = cfg.IsCategoryIgnored(machine, string(pkg.Type))
+			categories[pkg.Type] = ignoredCategory
+		}
+		return ignoredCategory || ignored.Has(pkg)
+	}
+}
+
 func truncate(s string, max int) string {
 	if len(s) <= max {
 		return s
@@ -0,0 +1,507 @@
+package screens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/andrew-sameh/brewsync/internal/brewfile"
+	"github.com/andrew-sameh/brewsync/internal/config"
+	"github.com/andrew-sameh/brewsync/internal/history"
+	"github.com/andrew-sameh/brewsync/internal/installer"
+	"github.com/andrew-sameh/brewsync/internal/tui/styles"
+)
+
+// OutdatedPhase represents the current phase of the outdated flow
+type OutdatedPhase int
+
+const (
+	OutdatedPhaseLoading OutdatedPhase = iota
+	OutdatedPhaseList
+	OutdatedPhaseUpgrading
+	OutdatedPhaseDone
+)
+
+// outdatedRow is a category header or an outdated package in the list
+type outdatedRow struct {
+	isHeader    bool
+	headerType  brewfile.PackageType
+	headerCount int
+	index       int // into OutdatedModel.outdated
+}
+
+// OutdatedModel is the model for the outdated screen
+type OutdatedModel struct {
+	config      *config.Config
+	width       int
+	height      int
+	phase       OutdatedPhase
+	outdated    []installer.Outdated
+	selected    map[int]bool
+	rows        []outdatedRow
+	cursor      int // index into rows, always on a package
+	offset      int
+	warning     error // installers whose check failed
+	showConfirm bool
+
+	// Upgrade state
+	spinner    spinner.Model
+	cancel     context.CancelFunc // Stops the running upgrade
+	cancelling bool
+	upgraded   brewfile.Packages
+	failures   []upgradeFailure
+	skipped    brewfile.Packages
+}
+
+// upgradeFailure is a package whose upgrade failed
+type upgradeFailure struct {
+	pkg brewfile.Package
+	err error
+}
+
+// NewOutdatedModel creates a new outdated model
+func NewOutdatedModel(cfg *config.Config) *OutdatedModel {
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = lipgloss.NewStyle().Foreground(styles.CatMauve)
+
+	return &OutdatedModel{
+		config:   cfg,
+		width:    80,
+		height:   24,
+		phase:    OutdatedPhaseLoading,
+		selected: make(map[int]bool),
+		spinner:  s,
+	}
+}
+
+type outdatedLoadedMsg struct {
+	outdated []installer.Outdated
+	err      error
+}
+
+type upgradeDoneMsg struct {
+	upgraded brewfile.Packages
+	failures []upgradeFailure
+	skipped  brewfile.Packages
+}
+
+// Init checks every installer for outdated packages
+func (m *OutdatedModel) Init() tea.Cmd {
+	return tea.Batch(
+		m.spinner.Tick,
+		func() tea.Msg {
+			outdated, err := installer.NewManager().Outdated(context.Background())
+			return outdatedLoadedMsg{outdated: outdated, err: err}
+		},
+	)
+}
+
+// Update handles messages
+func (m *OutdatedModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case spinner.TickMsg:
+		if m.phase == OutdatedPhaseLoading || m.phase == OutdatedPhaseUpgrading {
+			var cmd tea.Cmd
+			m.spinner, cmd = m.spinner.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+
+	case outdatedLoadedMsg:
+		m.phase = OutdatedPhaseList
+		m.outdated = msg.outdated
+		m.warning = msg.err
+		for i, o := range m.outdated {
+			// Pinned formulae are not upgraded unless selected
+			m.selected[i] = !o.Pinned
+		}
+		m.buildRows()
+		return m, nil
+
+	case upgradeDoneMsg:
+		m.phase = OutdatedPhaseDone
+		m.upgraded = msg.upgraded
+		m.failures = msg.failures
+		m.skipped = msg.skipped
+		m.cancel = nil
+		return m, nil
+
+	case tea.KeyMsg:
+		if m.showConfirm {
+			switch msg.String() {
+			case "y", "Y":
+				m.showConfirm = false
+				m.phase = OutdatedPhaseUpgrading
+				ctx, cancel := context.WithCancel(context.Background())
+				m.cancel = cancel
+				return m, tea.Batch(m.spinner.Tick, m.executeUpgrade(ctx))
+			case "n", "N", "esc":
+				m.showConfirm = false
+			}
+			return m, nil
+		}
+
+		// Stop a running upgrade; the package in flight is interrupted and
+		// the rest are skipped
+		if m.phase == OutdatedPhaseUpgrading {
+			if (msg.String() == "ctrl+c" || msg.String() == "esc") && m.cancel != nil && !m.cancelling {
+				m.cancelling = true
+				m.cancel()
+			}
+			return m, nil
+		}
+
+		if m.phase == OutdatedPhaseList {
+			switch {
+			case key.Matches(msg, key.NewBinding(key.WithKeys("up", "k"))):
+				m.move(-1)
+			case key.Matches(msg, key.NewBinding(key.WithKeys("down", "j"))):
+				m.move(1)
+			case key.Matches(msg, key.NewBinding(key.WithKeys(" "))):
+				if i, ok := m.current(); ok {
+					m.selected[i] = !m.selected[i]
+				}
+			case key.Matches(msg, key.NewBinding(key.WithKeys("a"))):
+				m.selectAll(true)
+			case key.Matches(msg, key.NewBinding(key.WithKeys("n"))):
+				m.selectAll(false)
+			case key.Matches(msg, key.NewBinding(key.WithKeys("enter", "u"))):
+				if len(m.toUpgrade()) > 0 {
+					m.showConfirm = true
+				}
+			case key.Matches(msg, key.NewBinding(key.WithKeys("r"))):
+				return m, m.reload()
+			case key.Matches(msg, key.NewBinding(key.WithKeys("esc"))):
+				return m, func() tea.Msg { return Navigate("dashboard") }
+			}
+			return m, nil
+		}
+
+		if m.phase == OutdatedPhaseDone {
+			switch {
+			case key.Matches(msg, key.NewBinding(key.WithKeys("r"))):
+				return m, m.reload()
+			case key.Matches(msg, key.NewBinding(key.WithKeys("enter", "esc"))):
+				return m, func() tea.Msg { return Navigate("dashboard") }
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// Busy reports whether an upgrade is running, so ctrl+c should stop it
+// rather than quit
+func (m *OutdatedModel) Busy() bool {
+	return m.phase == OutdatedPhaseUpgrading
+}
+
+// reload checks for outdated packages again
+func (m *OutdatedModel) reload() tea.Cmd {
+	width, height := m.width, m.height
+	*m = *NewOutdatedModel(m.config)
+	m.width, m.height = width, height
+	return m.Init()
+}
+
+// buildRows groups the outdated packages by type under category headers
+func (m *OutdatedModel) buildRows() {
+	m.rows = nil
+	byType := make(map[brewfile.PackageType][]int)
+	for i, o := range m.outdated {
+		byType[o.Package.Type] = append(byType[o.Package.Type], i)
+	}
+	types := []brewfile.PackageType{
+		brewfile.TypeBrew,
+		brewfile.TypeCask,
+		brewfile.TypeVSCode,
+		brewfile.TypeCursor,
+		brewfile.TypeAntigravity,
+		brewfile.TypeGo,
+		brewfile.TypeMas,
+	}
+	for _, t := range types {
+		indices := byType[t]
+		if len(indices) == 0 {
+			continue
+		}
+		m.rows = append(m.rows, outdatedRow{isHeader: true, headerType: t, headerCount: len(indices)})
+		for _, i := range indices {
+			m.rows = append(m.rows, outdatedRow{index: i})
+		}
+	}
+	m.cursor = 0
+	m.move(1)
+}
+
+// current returns the package under the cursor
+func (m *OutdatedModel) current() (int, bool) {
+	if m.cursor < 0 || m.cursor >= len(m.rows) || m.rows[m.cursor].isHeader {
+		return 0, false
+	}
+	return m.rows[m.cursor].index, true
+}
+
+// move moves the cursor by delta, skipping category headers
+func (m *OutdatedModel) move(delta int) {
+	for next := m.cursor + delta; next >= 0 && next < len(m.rows); next += delta {
+		if !m.rows[next].isHeader {
+			m.cursor = next
+			break
+		}
+	}
+
+	visible := m.visibleRows()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+visible {
+		m.offset = m.cursor - visible + 1
+	}
+	// Keep the category header of the first package in view
+	if m.offset > 0 && m.offset == m.cursor && m.rows[m.offset-1].isHeader {
+		m.offset--
+	}
+}
+
+// visibleRows returns how many rows fit between the title and action bar
+func (m *OutdatedModel) visibleRows() int {
+	if rows := m.height - 8; rows > 3 {
+		return rows
+	}
+	return 3
+}
+
+// selectAll selects or deselects every package
+func (m *OutdatedModel) selectAll(selected bool) {
+	for i := range m.outdated {
+		m.selected[i] = selected
+	}
+}
+
+// toUpgrade returns the selected packages
+func (m *OutdatedModel) toUpgrade() brewfile.Packages {
+	var pkgs brewfile.Packages
+	for i, o := range m.outdated {
+		if m.selected[i] {
+			pkgs = append(pkgs, o.Package)
+		}
+	}
+	return pkgs
+}
+
+// executeUpgrade upgrades the selected packages and logs the result
+func (m *OutdatedModel) executeUpgrade(ctx context.Context) tea.Cmd {
+	pkgs := m.toUpgrade()
+	machine := ""
+	if m.config != nil {
+		machine = m.config.CurrentMachine
+	}
+
+	return func() tea.Msg {
+		var done upgradeDoneMsg
+		err := installer.NewManager().UpgradeMany(ctx, pkgs, func(pkg brewfile.Package, i, total int, err error) {
+			if err != nil {
+				done.failures = append(done.failures, upgradeFailure{pkg: pkg, err: err})
+			} else {
+				done.upgraded = append(done.upgraded, pkg)
+			}
+		})
+
+		var interrupted *installer.InterruptedError
+		if errors.As(err, &interrupted) {
+			done.skipped = interrupted.Skipped
+		}
+
+		var upgraded []string
+		for _, pkg := range done.upgraded {
+			upgraded = append(upgraded, pkg.ID())
+		}
+		var failures []history.Failure
+		for _, f := range done.failures {
+			failures = append(failures, history.Failure{ID: f.pkg.ID(), Category: string(installer.Classify(f.err))})
+		}
+		history.LogUpgrade(machine, upgraded, failures, len(done.skipped))
+
+		return done
+	}
+}
+
+// SetSize updates the outdated screen dimensions
+func (m *OutdatedModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+}
+
+// View renders the outdated screen (legacy)
+func (m *OutdatedModel) View() string {
+	return m.ViewContent(m.width, m.height)
+}
+
+// ViewContent renders just the content area (for use in layout)
+func (m *OutdatedModel) ViewContent(width, height int) string {
+	var b strings.Builder
+	titleStyle := lipgloss.NewStyle().Bold(true).Foreground(styles.CatMauve)
+	b.WriteString(titleStyle.Render("Outdated Packages"))
+	b.WriteString("\n\n")
+
+	switch m.phase {
+	case OutdatedPhaseLoading:
+		b.WriteString(m.spinner.View())
+		b.WriteString(" ")
+		b.WriteString(styles.DimmedStyle.Render("Checking for updates..."))
+	case OutdatedPhaseList:
+		b.WriteString(m.renderList(width))
+	case OutdatedPhaseUpgrading:
+		b.WriteString(m.spinner.View())
+		b.WriteString(" ")
+		if m.cancelling {
+			b.WriteString(styles.WarningStyle.Render("Stopping... waiting for the running upgrade to exit"))
+		} else {
+			b.WriteString(styles.DimmedStyle.Render(fmt.Sprintf("Upgrading %d packages... (ctrl+c to stop)", len(m.toUpgrade()))))
+		}
+	case OutdatedPhaseDone:
+		b.WriteString(m.renderDone())
+	}
+
+	return b.String()
+}
+
+func (m *OutdatedModel) renderList(width int) string {
+	var b strings.Builder
+
+	if m.warning != nil {
+		for _, line := range strings.Split(m.warning.Error(), "\n") {
+			b.WriteString(styles.WarningStyle.Render("⚠ " + line))
+			b.WriteString("\n")
+		}
+		b.WriteString("\n")
+	}
+
+	if len(m.outdated) == 0 {
+		b.WriteString(styles.SelectedStyle.Render("✓ "))
+		b.WriteString("Everything is up to date!")
+		return b.String()
+	}
+
+	nameWidth := 0
+	for _, o := range m.outdated {
+		if n := len(o.Package.Name); n > nameWidth {
+			nameWidth = n
+		}
+	}
+	if limit := width/2 - 8; nameWidth > limit && limit > 10 {
+		nameWidth = limit
+	}
+
+	end := m.offset + m.visibleRows()
+	if end > len(m.rows) {
+		end = len(m.rows)
+	}
+	for i := m.offset; i < end; i++ {
+		row := m.rows[i]
+		if row.isHeader {
+			catStyle := styles.GetCategoryStyle(string(row.headerType)).Bold(true)
+			b.WriteString(catStyle.Render(fmt.Sprintf("%s %s (%d)", getTypeIcon(row.headerType), row.headerType, row.headerCount)))
+			b.WriteString("\n")
+			continue
+		}
+
+		o := m.outdated[row.index]
+		prefix := "  "
+		if i == m.cursor {
+			prefix = styles.CursorStyle.Render("> ")
+		}
+		checkbox := "[ ]"
+		if m.selected[row.index] {
+			checkbox = styles.SelectedStyle.Render("[x]")
+		}
+		name := o.Package.Name
+		if len(name) > nameWidth {
+			name = name[:nameWidth-3] + "..."
+		}
+		line := fmt.Sprintf("%s%s %-*s  %s → %s", prefix, checkbox, nameWidth, name,
+			styles.DimmedStyle.Render(o.Installed), styles.AddedStyle.Render(o.Latest))
+		if o.Pinned {
+			line += styles.WarningStyle.Render(" (pinned)")
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	if m.showConfirm {
+		confirmStyle := lipgloss.NewStyle().
+			Background(styles.CatSurface0).
+			Foreground(styles.CatYellow).
+			Padding(0, 1).
+			Bold(true)
+		b.WriteString(confirmStyle.Render(fmt.Sprintf("Upgrade %d packages? (y/n)", len(m.toUpgrade()))))
+	} else {
+		actionStyle := lipgloss.NewStyle().Foreground(styles.CatSubtext0)
+		b.WriteString(actionStyle.Render(fmt.Sprintf("%d of %d selected • ", len(m.toUpgrade()), len(m.outdated))))
+		b.WriteString(lipgloss.NewStyle().Foreground(styles.CatGreen).Bold(true).Render("enter"))
+		b.WriteString(actionStyle.Render(" to upgrade • "))
+		b.WriteString(lipgloss.NewStyle().Foreground(styles.CatText).Render("space"))
+		b.WriteString(actionStyle.Render(" toggle • "))
+		b.WriteString(lipgloss.NewStyle().Foreground(styles.CatText).Render("a/n"))
+		b.WriteString(actionStyle.Render(" all/none • "))
+		b.WriteString(lipgloss.NewStyle().Foreground(styles.CatText).Render("r"))
+		b.WriteString(actionStyle.Render(" refresh"))
+	}
+
+	return b.String()
+}
+
+func (m *OutdatedModel) renderDone() string {
+	var b strings.Builder
+
+	switch {
+	case len(m.skipped) > 0:
+		b.WriteString(styles.WarningStyle.Render("⚠ Upgrade interrupted"))
+	case len(m.failures) == 0:
+		b.WriteString(styles.SelectedStyle.Render("✓ Upgrade complete!"))
+	default:
+		b.WriteString(styles.WarningStyle.Render("⚠ Upgrade completed with errors"))
+	}
+	b.WriteString("\n\n")
+
+	b.WriteString(styles.AddedStyle.Render(fmt.Sprintf("  ↑%d upgraded", len(m.upgraded))))
+	b.WriteString("\n")
+	if len(m.failures) > 0 {
+		b.WriteString(styles.ErrorStyle.Render(fmt.Sprintf("  ✗%d failed", len(m.failures))))
+		b.WriteString("\n\n")
+		b.WriteString(styles.ErrorStyle.Render("Failed packages:"))
+		b.WriteString("\n")
+		for _, f := range m.failures {
+			b.WriteString(styles.DimmedStyle.Render(fmt.Sprintf("  • %s:%s [%s]: %v", f.pkg.Type, f.pkg.Name, installer.Classify(f.err), f.err)))
+			b.WriteString("\n")
+		}
+	}
+	if len(m.skipped) > 0 {
+		b.WriteString("\n")
+		b.WriteString(styles.WarningStyle.Render("Not attempted:"))
+		b.WriteString("\n")
+		for _, pkg := range m.skipped {
+			b.WriteString(styles.DimmedStyle.Render(fmt.Sprintf("  ○ %s:%s", pkg.Type, pkg.Name)))
+			b.WriteString("\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(styles.DimmedStyle.Render("Press r to check again, enter to continue"))
+
+	return b.String()
+}
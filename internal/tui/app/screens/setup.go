@@ -1,6 +1,7 @@
 package screens
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -467,6 +468,7 @@ func (m *SetupModel) createConfig() tea.Cmd {
 				"hostname":    hostnameVal,
 				"brewfile":    brewfilePath,
 				"description": fmt.Sprintf("Machine %s", machineName),
+				"os":          config.LocalOS(),
 			},
 		}
 
@@ -506,6 +508,9 @@ func (m *SetupModel) createConfig() tea.Cmd {
 			"dump": map[string]interface{}{
 				"use_brew_bundle": true,
 			},
+			"go_tools": map[string]interface{}{
+				"versions": "latest",
+			},
 			"machine_specific": map[string]interface{}{},
 			"output": map[string]interface{}{
 				"color":             true,
@@ -589,6 +594,7 @@ func (m *SetupModel) runDump() tea.Cmd {
 
 // collectPackagesForSetup collects all installed packages (similar to dump screen)
 func collectPackagesForSetup(cfg *config.Config, brewfilePath string) (brewfile.Packages, error) {
+	ctx := context.Background()
 	var allPackages brewfile.Packages
 	brewInst := installer.NewBrewInstaller()
 
@@ -605,44 +611,44 @@ func collectPackagesForSetup(cfg *config.Config, brewfilePath string) (brewfile.
 		}
 	} else if brewInst.IsAvailable() {
 		// Manual collection
-		if taps, err := brewInst.ListTaps(); err == nil {
+		if taps, err := brewInst.ListTaps(ctx); err == nil {
 			allPackages = append(allPackages, taps...)
 		}
-		if formulae, err := brewInst.ListFormulae(); err == nil {
+		if formulae, err := brewInst.ListFormulae(ctx); err == nil {
 			allPackages = append(allPackages, formulae...)
 		}
-		if casks, err := brewInst.ListCasks(); err == nil {
+		if casks, err := brewInst.ListCasks(ctx); err == nil {
 			allPackages = append(allPackages, casks...)
 		}
 	}
 
-	// Collect extensions
-	if vscodeInst := installer.NewVSCodeInstaller(); vscodeInst.IsAvailable() {
-		if extensions, err := vscodeInst.List(); err == nil {
-			allPackages = allPackages.AddUnique(extensions...)
+	// Record which formulae have their service running
+	if brewInst.IsAvailable() {
+		if withServices, err := brewInst.WithServices(ctx, allPackages); err == nil {
+			allPackages = withServices
 		}
 	}
 
-	if cursorInst := installer.NewCursorInstaller(); cursorInst.IsAvailable() {
-		if extensions, err := cursorInst.List(); err == nil {
-			allPackages = allPackages.AddUnique(extensions...)
+	// Collect extensions of every built-in and configured editor, replacing
+	// entries from brew bundle dump, which carry no pre-release flag
+	for _, editorInst := range installer.EditorInstallers() {
+		if !editorInst.IsAvailable() {
+			continue
 		}
-	}
-
-	if antigravityInst := installer.NewAntigravityInstaller(); antigravityInst.IsAvailable() {
-		if extensions, err := antigravityInst.List(); err == nil {
-			allPackages = allPackages.AddUnique(extensions...)
+		if extensions, err := editorInst.List(ctx); err == nil {
+			allPackages = append(allPackages.Exclude(extensions), extensions...)
 		}
 	}
 
-	if goInst := installer.NewGoToolsInstaller(); goInst.IsAvailable() {
-		if tools, err := goInst.List(); err == nil {
-			allPackages = allPackages.AddUnique(tools...)
+	if goInst := installer.NewGoToolsInstaller(); goInst.CanList() {
+		if tools, err := goInst.List(ctx); err == nil {
+			// Replace entries from brew bundle dump, which carry no version
+			allPackages = append(allPackages.Exclude(tools), tools...)
 		}
 	}
 
 	if masInst := installer.NewMasInstaller(); masInst.IsAvailable() {
-		if apps, err := masInst.List(); err == nil {
+		if apps, err := masInst.List(ctx); err == nil {
 			allPackages = allPackages.AddUnique(apps...)
 		}
 	}
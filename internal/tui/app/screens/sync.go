@@ -1,6 +1,8 @@
 package screens
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
 
@@ -11,6 +13,7 @@ import (
 
 	"github.com/andrew-sameh/brewsync/internal/brewfile"
 	"github.com/andrew-sameh/brewsync/internal/config"
+	"github.com/andrew-sameh/brewsync/internal/hooks"
 	"github.com/andrew-sameh/brewsync/internal/installer"
 	"github.com/andrew-sameh/brewsync/internal/tui/styles"
 )
@@ -41,6 +44,7 @@ type syncItem struct {
 	headerCount int
 	pkg         brewfile.Package
 	isIgnored   bool
+	isManual    bool // removal the installer cannot perform
 }
 
 // SyncModel is the model for the sync screen
@@ -76,6 +80,15 @@ type SyncModel struct {
 	progress      int
 	total         int
 	results       []syncResult
+	cancel        context.CancelFunc // Stops the running sync
+	cancelling    bool
+	skipped       brewfile.Packages // Packages never attempted after cancelling
+	manual        brewfile.Packages // Removals left to the user
+	events        chan tea.Msg      // Progress of the running sync
+	hook          string            // Hook currently running
+	hookOutput    []string          // Recent output of the running hook
+	aborted       error             // pre_install hook failure that stopped the sync
+	hookErr       error             // post_install hook failure
 }
 
 type syncResult struct {
@@ -127,6 +140,10 @@ type syncDoneMsg struct {
 	removed   int
 	failed    int
 	results   []syncResult
+	skipped   brewfile.Packages
+	manual    brewfile.Packages
+	aborted   error
+	hookErr   error
 }
 
 // Init initializes the sync model
@@ -159,22 +176,23 @@ func (m *SyncModel) Init() tea.Cmd {
 				return syncLoadedMsg{err: fmt.Errorf("failed to parse source Brewfile: %w", err)}
 			}
 
-			diff := brewfile.Diff(sourcePkgs, currentPkgs)
+			diff := brewfile.Diff(sourcePkgs, currentPkgs).
+				ExcludeTypes(m.config.UnsupportedTypes(m.source, m.config.CurrentMachine)...)
 
 			// Filter out machine-specific packages from removals
 			var removals, protected brewfile.Packages
 			machineSpecific := m.config.GetMachineSpecificPackages()
 			currentMachineSpecific := machineSpecific[m.config.CurrentMachine]
 
-			for _, pkg := range diff.Removals {
-				isProtected := false
-				for _, ms := range currentMachineSpecific {
-					if pkg.ID() == ms {
-						isProtected = true
-						break
-					}
+			protectedSet := brewfile.NewPackageSet()
+			for _, id := range currentMachineSpecific {
+				if pkg, ok := brewfile.ParseID(id); ok {
+					protectedSet.Add(pkg)
 				}
-				if isProtected {
+			}
+
+			for _, pkg := range diff.Removals {
+				if protectedSet.Has(pkg) {
 					protected = append(protected, pkg)
 				} else {
 					removals = append(removals, pkg)
@@ -261,8 +279,27 @@ func (m *SyncModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.removed = msg.removed
 		m.failed = msg.failed
 		m.results = msg.results
+		m.skipped = msg.skipped
+		m.manual = msg.manual
+		m.aborted = msg.aborted
+		m.hookErr = msg.hookErr
+		m.hook = ""
+		m.cancel = nil
 		return m, nil
 
+	case hookStartMsg:
+		m.hook = msg.hook
+		m.hookOutput = nil
+		return m, listenForEvents(m.events)
+
+	case hookOutputMsg:
+		m.hookOutput = appendHookOutput(m.hookOutput, msg.line)
+		return m, listenForEvents(m.events)
+
+	case hookDoneMsg:
+		m.hook = ""
+		return m, listenForEvents(m.events)
+
 	case tea.KeyMsg:
 		// Handle confirmation dialog
 		if m.showConfirm {
@@ -270,7 +307,9 @@ func (m *SyncModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "y", "Y":
 				m.showConfirm = false
 				m.phase = SyncPhaseExecuting
-				return m, tea.Batch(m.spinner.Tick, m.executeSync())
+				ctx, cancel := context.WithCancel(context.Background())
+				m.cancel = cancel
+				return m, tea.Batch(m.spinner.Tick, m.executeSync(ctx))
 			case "n", "N", "esc":
 				m.showConfirm = false
 				return m, nil
@@ -278,6 +317,16 @@ func (m *SyncModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
+		// Stop a running sync; packages in flight are interrupted and the
+		// rest are skipped
+		if m.phase == SyncPhaseExecuting {
+			if (msg.String() == "ctrl+c" || msg.String() == "esc") && m.cancel != nil && !m.cancelling {
+				m.cancelling = true
+				m.cancel()
+			}
+			return m, nil
+		}
+
 		// Preview phase navigation
 		if m.phase == SyncPhasePreview {
 			switch {
@@ -315,34 +364,88 @@ func (m *SyncModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-// executeSync runs the actual sync operation
-func (m *SyncModel) executeSync() tea.Cmd {
-	return func() tea.Msg {
-		mgr := installer.NewManager()
-		var results []syncResult
-		var installed, removed, failed int
-
-		// Install additions
-		for _, pkg := range m.additions {
-			// We can't send messages from here directly, so we'll just execute
-			err := mgr.Install(pkg)
-			result := syncResult{
-				pkg:     pkg,
-				action:  "installed",
-				success: err == nil,
-				err:     err,
-			}
-			results = append(results, result)
-			if err != nil {
-				failed++
-			} else {
-				installed++
-			}
+// Busy reports whether a sync is running, so ctrl+c should stop it rather
+// than quit
+func (m *SyncModel) Busy() bool {
+	return m.phase == SyncPhaseExecuting
+}
+
+// executeSync runs the sync in the background, reporting hook progress on
+// the model's event channel until it completes
+func (m *SyncModel) executeSync(ctx context.Context) tea.Cmd {
+	events := make(chan tea.Msg, 64)
+	m.events = events
+	return tea.Batch(
+		func() tea.Msg {
+			events <- m.sync(ctx, events)
+			close(events)
+			return nil
+		},
+		listenForEvents(events),
+	)
+}
+
+// sync installs the additions and removes the removals, running the install
+// hooks around them
+func (m *SyncModel) sync(ctx context.Context, events chan<- tea.Msg) tea.Msg {
+	mgr := installer.NewManager()
+	var results []syncResult
+	var installed, removed, failed int
+	var skipped brewfile.Packages
+
+	// Removals the installer cannot perform are listed for manual removal
+	var removals, manual brewfile.Packages
+	for _, pkg := range m.removals {
+		if mgr.Capabilities(pkg.Type).Uninstall {
+			removals = append(removals, pkg)
+		} else {
+			manual = append(manual, pkg)
+		}
+	}
+
+	currentMachine, _ := m.config.GetCurrentMachine()
+	event := hooks.Event{
+		Operation: hooks.OperationSync,
+		Machine:   m.config.CurrentMachine,
+		Source:    m.source,
+		Brewfile:  currentMachine.Brewfile,
+		Install:   m.additions,
+		Remove:    removals,
+	}
+	if err := runHook(ctx, m.config, config.HookPreInstall, event, events); err != nil {
+		return syncDoneMsg{
+			skipped: append(append(brewfile.Packages{}, m.additions...), removals...),
+			manual:  manual,
+			aborted: err,
 		}
+	}
+
+	// Install additions, in dependency order and in parallel where possible
+	err := mgr.InstallMany(ctx, m.additions, func(pkg brewfile.Package, i, total int, err error) {
+		result := syncResult{
+			pkg:     pkg,
+			action:  "installed",
+			success: err == nil,
+			err:     err,
+		}
+		results = append(results, result)
+		if err != nil {
+			failed++
+		} else {
+			installed++
+		}
+	})
 
-		// Remove removals
-		for _, pkg := range m.removals {
-			err := mgr.Uninstall(pkg)
+	var interrupted *installer.InterruptedError
+	if errors.As(err, &interrupted) {
+		skipped = append(skipped, interrupted.Skipped...)
+	}
+
+	// Then remove removals
+	if ctx.Err() != nil {
+		skipped = append(skipped, removals...)
+	} else {
+		err = mgr.UninstallMany(ctx, removals, func(pkg brewfile.Package, i, total int, err error) {
 			result := syncResult{
 				pkg:     pkg,
 				action:  "removed",
@@ -355,15 +458,36 @@ func (m *SyncModel) executeSync() tea.Cmd {
 			} else {
 				removed++
 			}
+		})
+		if errors.As(err, &interrupted) {
+			skipped = append(skipped, interrupted.Skipped...)
 		}
+	}
 
-		return syncDoneMsg{
-			installed: installed,
-			removed:   removed,
-			failed:    failed,
-			results:   results,
+	// The post_install hook still reports what was done when the sync
+	// was stopped early
+	event.Install, event.Remove = nil, nil
+	for _, r := range results {
+		switch {
+		case !r.success:
+			event.Failed = append(event.Failed, r.pkg)
+		case r.action == "installed":
+			event.Install = append(event.Install, r.pkg)
+		default:
+			event.Remove = append(event.Remove, r.pkg)
 		}
 	}
+	hookErr := runHook(context.WithoutCancel(ctx), m.config, config.HookPostInstall, event, events)
+
+	return syncDoneMsg{
+		installed: installed,
+		removed:   removed,
+		failed:    failed,
+		results:   results,
+		skipped:   skipped,
+		manual:    manual,
+		hookErr:   hookErr,
+	}
 }
 
 // buildItems creates flattened lists with category headers
@@ -386,6 +510,7 @@ func (m *SyncModel) buildItemsForPackages(pkgs brewfile.Packages, isAdditions bo
 		brewfile.TypeGo,
 		brewfile.TypeMas,
 	}
+	types = append(types, brewfile.CustomTypes()...)
 
 	// Get all packages for ignore checking
 	var allPkgs brewfile.Packages
@@ -395,6 +520,10 @@ func (m *SyncModel) buildItemsForPackages(pkgs brewfile.Packages, isAdditions bo
 		allPkgs = m.allRemovals
 	}
 
+	allByType := allPkgs.ByType()
+	isIgnored := ignoredFilter(m.config)
+	mgr := installer.NewManager()
+
 	for _, t := range types {
 		typePkgs := byType[t]
 		if len(typePkgs) == 0 {
@@ -406,11 +535,8 @@ func (m *SyncModel) buildItemsForPackages(pkgs brewfile.Packages, isAdditions bo
 		var ignoredPkgs []brewfile.Package
 
 		// Check all packages of this type from the full list
-		allByType := allPkgs.ByType()
 		for _, pkg := range allByType[t] {
-			isIgnored := m.config != nil && (m.config.IsCategoryIgnored(m.config.CurrentMachine, string(pkg.Type)) ||
-				m.config.IsPackageIgnored(m.config.CurrentMachine, pkg.ID()))
-			if isIgnored {
+			if isIgnored(pkg) {
 				ignoredPkgs = append(ignoredPkgs, pkg)
 			} else {
 				visiblePkgs = append(visiblePkgs, pkg)
@@ -436,8 +562,9 @@ func (m *SyncModel) buildItemsForPackages(pkgs brewfile.Packages, isAdditions bo
 		})
 
 		// Add visible packages first
+		manual := !isAdditions && !mgr.Capabilities(t).Uninstall
 		for _, pkg := range visiblePkgs {
-			items = append(items, syncItem{pkg: pkg, isIgnored: false})
+			items = append(items, syncItem{pkg: pkg, isIgnored: false, isManual: manual})
 		}
 
 		// Add ignored packages if showing ignored
@@ -779,6 +906,8 @@ func (m *SyncModel) renderColumn(
 			if item.isIgnored {
 				nameStyle := styles.DimmedStyle
 				line = linePrefix + nameStyle.Render(prefix+" "+name+" (ignored)")
+			} else if item.isManual {
+				line = linePrefix + styles.WarningStyle.Render(prefix+" "+name+" (manual removal)")
 			} else {
 				nameStyle := baseStyle
 				if isCursor {
@@ -809,8 +938,18 @@ func (m *SyncModel) renderExecuting() string {
 	// Spinner with current action
 	b.WriteString(m.spinner.View())
 	b.WriteString(" ")
-	b.WriteString(styles.DimmedStyle.Render("Syncing packages..."))
+	if m.cancelling {
+		b.WriteString(styles.WarningStyle.Render("Stopping... waiting for running installs to exit"))
+	} else if m.hook != "" {
+		b.WriteString(styles.DimmedStyle.Render(fmt.Sprintf("Running %s hook... (ctrl+c to stop)", m.hook)))
+	} else {
+		b.WriteString(styles.DimmedStyle.Render("Syncing packages... (ctrl+c to stop)"))
+	}
 	b.WriteString("\n\n")
+	if m.hook != "" && len(m.hookOutput) > 0 {
+		b.WriteString(renderHookOutput(m.hookOutput))
+		b.WriteString("\n")
+	}
 
 	// Progress
 	total := len(m.additions) + len(m.removals)
@@ -833,7 +972,7 @@ func (m *SyncModel) renderExecuting() string {
 				b.WriteString(fmt.Sprintf("%s %s %s:%s\n", icon, action, r.pkg.Type, r.pkg.Name))
 			} else {
 				icon := styles.ErrorStyle.Render("✗")
-				b.WriteString(fmt.Sprintf("%s failed %s:%s\n", icon, r.pkg.Type, r.pkg.Name))
+				b.WriteString(fmt.Sprintf("%s failed %s:%s [%s]\n", icon, r.pkg.Type, r.pkg.Name, installer.Classify(r.err)))
 			}
 		}
 	}
@@ -849,7 +988,13 @@ func (m *SyncModel) renderDone() string {
 	b.WriteString("\n\n")
 
 	// Summary
-	if m.failed == 0 {
+	if m.aborted != nil {
+		b.WriteString(styles.ErrorStyle.Render("✗ Sync aborted: " + m.aborted.Error()))
+		b.WriteString("\n\n")
+		b.WriteString(renderHookOutput(m.hookOutput))
+	} else if len(m.skipped) > 0 {
+		b.WriteString(styles.WarningStyle.Render("⚠ Sync interrupted"))
+	} else if m.failed == 0 {
 		b.WriteString(styles.SelectedStyle.Render("✓ Sync complete!"))
 	} else {
 		b.WriteString(styles.WarningStyle.Render("⚠ Sync completed with errors"))
@@ -869,6 +1014,10 @@ func (m *SyncModel) renderDone() string {
 		b.WriteString(styles.ErrorStyle.Render(fmt.Sprintf("  ✗%d failed", m.failed)))
 		b.WriteString("\n")
 	}
+	if len(m.skipped) > 0 {
+		b.WriteString(styles.DimmedStyle.Render(fmt.Sprintf("  ○%d not attempted", len(m.skipped))))
+		b.WriteString("\n")
+	}
 
 	// Show failed packages
 	if m.failed > 0 {
@@ -879,7 +1028,7 @@ func (m *SyncModel) renderDone() string {
 			if !r.success {
 				errMsg := ""
 				if r.err != nil {
-					errMsg = ": " + r.err.Error()
+					errMsg = fmt.Sprintf(" [%s]: %v", installer.Classify(r.err), r.err)
 				}
 				b.WriteString(styles.DimmedStyle.Render(fmt.Sprintf("  • %s:%s%s", r.pkg.Type, r.pkg.Name, errMsg)))
 				b.WriteString("\n")
@@ -887,6 +1036,36 @@ func (m *SyncModel) renderDone() string {
 		}
 	}
 
+	// Show why the post_install hook failed
+	if m.hookErr != nil {
+		b.WriteString("\n")
+		b.WriteString(styles.WarningStyle.Render("⚠ " + m.hookErr.Error()))
+		b.WriteString("\n")
+		b.WriteString(renderHookOutput(m.hookOutput))
+	}
+
+	// Show removals BrewSync cannot perform
+	if len(m.manual) > 0 {
+		b.WriteString("\n")
+		b.WriteString(styles.WarningStyle.Render("Manual removal required:"))
+		b.WriteString("\n")
+		for _, pkg := range m.manual {
+			b.WriteString(styles.DimmedStyle.Render(fmt.Sprintf("  • %s:%s", pkg.Type, pkg.Name)))
+			b.WriteString("\n")
+		}
+	}
+
+	// Show packages skipped after the sync was stopped
+	if len(m.skipped) > 0 {
+		b.WriteString("\n")
+		b.WriteString(styles.WarningStyle.Render("Not attempted:"))
+		b.WriteString("\n")
+		for _, pkg := range m.skipped {
+			b.WriteString(styles.DimmedStyle.Render(fmt.Sprintf("  ○ %s:%s", pkg.Type, pkg.Name)))
+			b.WriteString("\n")
+		}
+	}
+
 	b.WriteString("\n")
 	b.WriteString(styles.DimmedStyle.Render("Press enter to continue"))
 
@@ -1,6 +1,7 @@
 package progress
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/andrew-sameh/brewsync/internal/brewfile"
+	"github.com/andrew-sameh/brewsync/internal/installer"
 	"github.com/andrew-sameh/brewsync/internal/tui/styles"
 )
 
@@ -25,6 +27,50 @@ type InstallFunc func(pkg brewfile.Package) error
 // InstallWithOutputFunc is the function that installs a package with output streaming
 type InstallWithOutputFunc func(pkg brewfile.Package, onOutput func(line string)) error
 
+// BatchInstallFunc installs all packages, possibly several at once, reporting
+// each package as it starts and finishes. Output lines are attributed to
+// their package. It should stop promptly once ctx is cancelled.
+type BatchInstallFunc func(
+	ctx context.Context,
+	onStart func(pkg brewfile.Package),
+	onDone func(pkg brewfile.Package, err error),
+	onOutput func(pkg brewfile.Package, line string),
+) error
+
+// HookFunc runs a hook around a batch install, streaming its output lines.
+// A post hook gets the results of the install; a pre hook gets none.
+type HookFunc func(ctx context.Context, results []InstallResult, onOutput func(line string)) error
+
+// Names the hooks are shown under
+const (
+	preHookName  = "pre-install"
+	postHookName = "post-install"
+)
+
+// HookStartMsg is sent when a hook starts in batch mode
+type HookStartMsg struct {
+	Name string
+}
+
+// HookOutputMsg is sent when a line of output is received from a hook
+type HookOutputMsg struct {
+	Line string
+}
+
+// HookDoneMsg is sent when a hook finishes
+type HookDoneMsg struct {
+	Name  string
+	Error error
+}
+
+// StartMsg is sent when a package installation starts in batch mode
+type StartMsg struct {
+	Package brewfile.Package
+}
+
+// batchFinishedMsg is sent when the batch install function returns
+type batchFinishedMsg struct{}
+
 // InstallMsg is sent when a package installation completes
 type InstallMsg struct {
 	Package brewfile.Package
@@ -64,6 +110,17 @@ type Model struct {
 	outputLines     []string          // Recent output lines
 	maxOutputLines  int               // Max lines to keep
 	currentPkg      *brewfile.Package // Current package being installed
+	batchFn         BatchInstallFunc
+	events          chan tea.Msg      // Batch mode events from the install goroutine
+	running         brewfile.Packages // Packages currently installing in batch mode
+	ctx             context.Context   // Cancelled to stop a batch install
+	cancel          context.CancelFunc
+	cancelling      bool
+	preHook         HookFunc // Runs before a batch; its failure skips the install
+	postHook        HookFunc // Runs after a batch with its results
+	hook            string   // Hook currently running
+	preHookErr      error
+	postHookErr     error
 }
 
 // New creates a new progress model
@@ -112,14 +169,101 @@ func NewWithOutput(title string, packages brewfile.Packages, installOutputFn Ins
 	}
 }
 
+// NewBatch creates a progress model that hands all packages to fn at once,
+// so the installer can schedule them in parallel
+func NewBatch(title string, packages brewfile.Packages, fn BatchInstallFunc) Model {
+	m := NewWithOutput(title, packages, nil)
+	m.batchFn = fn
+	m.events = make(chan tea.Msg, 64)
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+	return m
+}
+
+// WithHooks runs pre before a batch install and post after it. When pre
+// fails, nothing is installed and every package is reported as skipped.
+func (m Model) WithHooks(pre, post HookFunc) Model {
+	m.preHook = pre
+	m.postHook = post
+	return m
+}
+
 // Init starts the installation process
 func (m Model) Init() tea.Cmd {
+	if m.batchFn != nil {
+		return tea.Batch(
+			m.spinner.Tick,
+			m.runBatch(),
+			waitForEvent(m.events),
+		)
+	}
 	return tea.Batch(
 		m.spinner.Tick,
 		m.installNext(),
 	)
 }
 
+// runBatch runs the batch install function, forwarding its events to the
+// model. The event channel is closed when it returns.
+func (m Model) runBatch() tea.Cmd {
+	fn, events := m.batchFn, m.events
+	pre, post := m.preHook, m.postHook
+	total := len(m.packages)
+	ctx, cancel := m.ctx, m.cancel
+	return func() tea.Msg {
+		defer cancel()
+		defer close(events)
+
+		if pre != nil && runHook(ctx, preHookName, pre, nil, events) != nil {
+			return nil
+		}
+
+		done := 0
+		var results []InstallResult
+		_ = fn(
+			ctx,
+			func(pkg brewfile.Package) {
+				events <- StartMsg{Package: pkg}
+			},
+			func(pkg brewfile.Package, err error) {
+				events <- InstallMsg{Package: pkg, Index: done, Total: total, Error: err}
+				results = append(results, InstallResult{Package: pkg, Error: err})
+				done++
+			},
+			func(pkg brewfile.Package, line string) {
+				events <- OutputLineMsg{Package: pkg, Line: line}
+			},
+		)
+
+		// The post hook still reports what was installed when the batch
+		// was stopped early
+		if post != nil {
+			_ = runHook(context.WithoutCancel(ctx), postHookName, post, results, events)
+		}
+		return nil
+	}
+}
+
+// runHook runs a hook, forwarding its progress and output as events
+func runHook(ctx context.Context, name string, fn HookFunc, results []InstallResult, events chan tea.Msg) error {
+	events <- HookStartMsg{Name: name}
+	err := fn(ctx, results, func(line string) {
+		events <- HookOutputMsg{Line: line}
+	})
+	events <- HookDoneMsg{Name: name, Error: err}
+	return err
+}
+
+// waitForEvent returns a command that waits for the next batch event
+func waitForEvent(events chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-events
+		if !ok {
+			return batchFinishedMsg{}
+		}
+		return msg
+	}
+}
+
 // Update handles messages
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
@@ -135,6 +279,13 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		switch msg.String() {
 		case "ctrl+c", "q":
+			// Stop a running batch and wait for it to report what happened;
+			// a second press quits immediately
+			if m.batchFn != nil && !m.done && !m.cancelling && m.cancel != nil {
+				m.cancelling = true
+				m.cancel()
+				return m, nil
+			}
 			return m, tea.Quit
 		}
 
@@ -143,14 +294,52 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.spinner, cmd = m.spinner.Update(msg)
 		return m, cmd
 
+	case StartMsg:
+		m.running = append(m.running, msg.Package)
+		return m, waitForEvent(m.events)
+
+	case HookStartMsg:
+		m.hook = msg.Name
+		m.outputLines = []string{}
+		return m, waitForEvent(m.events)
+
+	case HookOutputMsg:
+		m.outputLines = append(m.outputLines, msg.Line)
+		if len(m.outputLines) > m.maxOutputLines {
+			m.outputLines = m.outputLines[len(m.outputLines)-m.maxOutputLines:]
+		}
+		return m, waitForEvent(m.events)
+
+	case HookDoneMsg:
+		m.hook = ""
+		if msg.Name == preHookName {
+			m.preHookErr = msg.Error
+		} else {
+			m.postHookErr = msg.Error
+		}
+		return m, waitForEvent(m.events)
+
+	case batchFinishedMsg:
+		// Packages the install function never reported did not run
+		m.running = nil
+		m.done = true
+		return m, tea.Quit
+
 	case OutputLineMsg:
 		// Add line to output buffer
-		m.outputLines = append(m.outputLines, msg.Line)
+		line := msg.Line
+		if m.batchFn != nil {
+			line = msg.Package.Name + ": " + line
+		}
+		m.outputLines = append(m.outputLines, line)
 
 		// Keep only the last N lines
 		if len(m.outputLines) > m.maxOutputLines {
 			m.outputLines = m.outputLines[len(m.outputLines)-m.maxOutputLines:]
 		}
+		if m.batchFn != nil {
+			return m, waitForEvent(m.events)
+		}
 		return m, nil
 
 	case InstallMsg:
@@ -168,6 +357,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		m.current = msg.Index + 1
 		m.currentPkg = nil
+
+		if m.batchFn != nil {
+			m.running = m.running.Exclude(brewfile.Packages{msg.Package})
+			return m, waitForEvent(m.events)
+		}
+
 		m.outputLines = []string{} // Clear output for next package
 
 		if m.current >= len(m.packages) {
@@ -248,7 +443,24 @@ func (m Model) View() string {
 	b.WriteString("\n\n")
 
 	// Current status
-	if !m.done && m.current < len(m.packages) {
+	if m.hook != "" && !m.done {
+		b.WriteString(m.spinner.View())
+		b.WriteString(fmt.Sprintf(" Running %s hook...", m.hook))
+	} else if m.batchFn != nil && !m.done && len(m.running) > 0 {
+		names := make([]string, 0, len(m.running))
+		for _, pkg := range m.running {
+			names = append(names, styles.GetCategoryStyle(string(pkg.Type)).Render(string(pkg.Type))+": "+
+				lipgloss.NewStyle().Bold(true).Render(pkg.Name))
+		}
+		b.WriteString(m.spinner.View())
+		b.WriteString(" Installing ")
+		b.WriteString(strings.Join(names, ", "))
+		b.WriteString(fmt.Sprintf(" (%d/%d done)", m.current, len(m.packages)))
+		if m.cancelling {
+			b.WriteString("\n")
+			b.WriteString(styles.WarningStyle.Render("Stopping... remaining packages will be skipped (ctrl+c again to quit now)"))
+		}
+	} else if m.batchFn == nil && !m.done && m.current < len(m.packages) {
 		pkg := m.packages[m.current]
 		b.WriteString(m.spinner.View())
 		b.WriteString(" Installing ")
@@ -259,6 +471,17 @@ func (m Model) View() string {
 	}
 	b.WriteString("\n\n")
 
+	// Hook output, while the hook runs or after it failed
+	if m.hook != "" || m.preHookErr != nil || m.postHookErr != nil {
+		for _, line := range m.outputLines {
+			b.WriteString(styles.DimmedStyle.Render("  " + line))
+			b.WriteString("\n")
+		}
+		if len(m.outputLines) > 0 {
+			b.WriteString("\n")
+		}
+	}
+
 	// Recent results (last 5)
 	b.WriteString("Recent:\n")
 	start := len(m.results) - 5
@@ -271,7 +494,8 @@ func (m Model) View() string {
 			b.WriteString(styles.CrossStyle.String())
 			b.WriteString(" ")
 			b.WriteString(styles.ErrorStyle.Render(
-				fmt.Sprintf("%s:%s - %v", result.Package.Type, result.Package.Name, result.Error)))
+				fmt.Sprintf("%s:%s [%s] - %v", result.Package.Type, result.Package.Name,
+					installer.Classify(result.Error), result.Error)))
 		} else {
 			b.WriteString(styles.CheckmarkStyle.String())
 			b.WriteString(" ")
@@ -286,8 +510,20 @@ func (m Model) View() string {
 	summary := fmt.Sprintf("Installed: %s | Failed: %s",
 		styles.AddedStyle.Render(fmt.Sprintf("%d", m.installed)),
 		styles.ErrorStyle.Render(fmt.Sprintf("%d", m.failed)))
+	if skipped := len(m.Skipped()); m.done && skipped > 0 {
+		summary += fmt.Sprintf(" | Not attempted: %d", skipped)
+	}
 	b.WriteString(summary)
 
+	if m.preHookErr != nil {
+		b.WriteString("\n\n")
+		b.WriteString(styles.ErrorStyle.Render(fmt.Sprintf("Install aborted: %v", m.preHookErr)))
+	}
+	if m.postHookErr != nil {
+		b.WriteString("\n\n")
+		b.WriteString(styles.WarningStyle.Render(m.postHookErr.Error()))
+	}
+
 	if m.done {
 		b.WriteString("\n\n")
 		b.WriteString(styles.DimmedStyle.Render("Press q to exit"))
@@ -311,7 +547,27 @@ func (m Model) Failed() int {
 	return m.failed
 }
 
+// PreHookError returns why the pre hook aborted the install, if it did
+func (m Model) PreHookError() error {
+	return m.preHookErr
+}
+
+// PostHookError returns the error of the post hook, if it failed
+func (m Model) PostHookError() error {
+	return m.postHookErr
+}
+
 // Done returns true if all installations are complete
 func (m Model) Done() bool {
 	return m.done
 }
+
+// Skipped returns the packages that were never attempted, because the
+// install was stopped or the UI quit before reaching them
+func (m Model) Skipped() brewfile.Packages {
+	attempted := make(brewfile.Packages, 0, len(m.results))
+	for _, result := range m.results {
+		attempted = append(attempted, result.Package)
+	}
+	return m.packages.Exclude(attempted)
+}
@@ -47,6 +47,7 @@ type Item struct {
 	Package  brewfile.Package
 	Selected bool
 	Ignored  bool
+	Detail   string // shown dimmed after the name, e.g. a version change
 }
 
 // FilterValue returns the value used for filtering
@@ -119,6 +120,13 @@ func (m *Model) SetIgnored(ignored map[string]bool) {
 	}
 }
 
+// SetDetails sets the detail shown next to packages, keyed by package ID
+func (m *Model) SetDetails(details map[string]string) {
+	for i := range m.items {
+		m.items[i].Detail = details[m.items[i].Package.ID()]
+	}
+}
+
 // SetSelected marks specific packages as pre-selected
 func (m *Model) SetSelected(selected map[string]bool) {
 	for i := range m.items {
@@ -219,6 +219,11 @@ func (m Model) renderItem(item Item, isCursor bool) string {
 		b.WriteString(name)
 	}
 
+	if item.Detail != "" {
+		b.WriteString(" ")
+		b.WriteString(styles.DimmedStyle.Render(item.Detail))
+	}
+
 	return b.String()
 }
 